@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"hammerclock/internal/hammerclock/campaign"
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+	"hammerclock/internal/hammerclock/palette"
+	"hammerclock/internal/hammerclock/ui"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// setupCampaignGame prepares the next game of a campaign: the players of the options keep the factions they have in
+// the campaign, and the campaign name and game number are shown in the banner. New players join the campaign when
+// the game is recorded. It returns the campaign, so the players can take their rosters into the game.
+func setupCampaignGame(campaignFile string, opts *options.Options) (*campaign.Campaign, error) {
+	c, err := campaign.LoadOrNew(campaignFile)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.PlayerFactions = make([]string, len(opts.PlayerNames))
+	for i, name := range opts.PlayerNames {
+		if member := c.Member(name); member != nil {
+			opts.PlayerFactions[i] = member.Faction
+		}
+	}
+	opts.Banner = fmt.Sprintf("%s - game %d", c.Name, len(c.Games)+1)
+	return c, nil
+}
+
+// applyCampaignRosters replaces the army lists of the players with their rosters in the campaign, where they have one
+func applyCampaignRosters(players []*common.Player, c *campaign.Campaign) {
+	for _, player := range players {
+		member := c.Member(player.Name)
+		if member == nil || len(member.Roster) == 0 {
+			continue
+		}
+		player.ArmyList = make([]common.Unit, len(member.Roster))
+		for i, unit := range member.Roster {
+			player.ArmyList[i] = common.Unit{Name: unit.Name, Points: unit.Points, Wounds: unit.Wounds}
+		}
+	}
+}
+
+// runCampaign shows the campaign summary, the members with their records and the campaign log, until the user quits.
+// Selecting a member opens the form for changing the member's experience, territory and injuries.
+func runCampaign(campaignFile string, colors palette.ColorPalette) error {
+	app := tview.NewApplication()
+	log := ui.CreateCampaignLog(colors)
+	status := tview.NewTextView().SetDynamicColors(true)
+	layout := tview.NewFlex()
+
+	var members *tview.Table
+	refresh := func() {
+		c, err := campaign.Load(campaignFile)
+		if err != nil {
+			status.SetText("[red]" + tview.Escape(err.Error()))
+			return
+		}
+		ui.UpdateCampaignTable(members, c, colors)
+		ui.UpdateCampaignLog(log, c)
+	}
+
+	var showMembers func()
+	members = ui.CreateCampaignTable(colors, func(name string) {
+		c, err := campaign.Load(campaignFile)
+		if err != nil || c.Member(name) == nil {
+			return
+		}
+		save := func(change campaign.Change) {
+			// The file is read again, so games recorded in the meantime are kept
+			c, err := campaign.Load(campaignFile)
+			if err == nil {
+				err = c.Adjust(name, change, time.Now())
+			}
+			if err == nil {
+				err = c.Save(campaignFile)
+			}
+			if err != nil {
+				status.SetText("[red]" + tview.Escape(err.Error()))
+				return
+			}
+			showMembers()
+			status.SetText("[green]" + tview.Escape(name) + " saved")
+		}
+		form := ui.CreateCampaignForm(*c.Member(name), colors, save, showMembers)
+		layout.Clear().
+			AddItem(form, 0, 1, true).
+			AddItem(members, 0, 2, false)
+		app.SetFocus(form)
+	})
+	members.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			app.Stop()
+		}
+	})
+
+	showMembers = func() {
+		status.SetText("ENTER to change a player's experience, territory or injuries, ESC to quit")
+		refresh()
+		layout.Clear().AddItem(members, 0, 1, true)
+		app.SetFocus(members)
+	}
+	showMembers()
+
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(layout, 0, 1, true).
+		AddItem(log, 0, 1, false).
+		AddItem(status, 1, 0, false)
+	return app.SetRoot(root, true).EnableMouse(true).Run()
+}
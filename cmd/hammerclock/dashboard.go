@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hammerclock/internal/hammerclock"
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/link"
+	"hammerclock/internal/hammerclock/palette"
+	"hammerclock/internal/hammerclock/ui"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// runDashboard shows the organizer dashboard: the tables at the given addresses and the ones announcing themselves
+// on the local network, until the user quits. The round countdown and announcements entered on the dashboard are
+// pushed to every table. The tables are linked with the credentials, which need the link secret of the tables.
+func runDashboard(addrs []string, credentials link.Credentials, colors palette.ColorPalette) error {
+	if credentials.Secret == "" {
+		return fmt.Errorf("%w, set the linkSecret of the tables in the options", link.ErrNoSecret)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	msgChan := make(chan common.Message)
+	found := make(chan string)
+	if err := link.Discover(found, done); err != nil {
+		fmt.Printf("Error looking for tables on the local network: %v\n", err)
+	}
+
+	// Each table is linked with its own client, whose states are tagged with the table's address. The clients are
+	// only used by the goroutine updating the dashboard once it runs.
+	var dashboard hammerclock.Dashboard
+	var clients []*link.Client
+	joinTable := func(addr string) {
+		client := link.Join(addr, credentials)
+		if !dashboard.RoundEnds.IsZero() || dashboard.Announcement != "" {
+			client.SetRound(dashboard.RoundEnds, dashboard.Announcement)
+		}
+		clients = append(clients, client)
+
+		states := make(chan common.Message)
+		go client.Run(states, done)
+		go func() {
+			for {
+				select {
+				case msg := <-states:
+					if state, ok := msg.(*common.LinkStateMsg); ok {
+						msgChan <- &common.TableStateMsg{Addr: addr, State: state}
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	for _, addr := range addrs {
+		joinTable(addr)
+	}
+
+	app := tview.NewApplication()
+	table := ui.CreateDashboard(colors)
+	prompt := ui.CreateDashboardPrompt(colors)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(prompt, 1, 0, false)
+
+	// The round timer is edited by the input callbacks only, the goroutine below gets copies of it
+	var roundTimer common.RoundTimerMsg
+	editing := ""
+	prompt.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			switch editing {
+			case "round":
+				minutes, err := strconv.Atoi(strings.TrimSpace(prompt.GetText()))
+				if err != nil || minutes < 0 {
+					break
+				}
+				roundTimer.Ends = time.Time{}
+				if minutes > 0 {
+					roundTimer.Ends = time.Now().Add(time.Duration(minutes) * time.Minute)
+				}
+			case "announcement":
+				roundTimer.Announcement = strings.TrimSpace(prompt.GetText())
+			}
+			msg := roundTimer
+			go func() {
+				msgChan <- &msg
+			}()
+		}
+		editing = ""
+		prompt.SetLabel("").SetText("")
+		app.SetFocus(table)
+	})
+
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if editing != "" {
+			return event
+		}
+		switch {
+		case event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyCtrlC || event.Rune() == 'q' || event.Rune() == 'Q':
+			app.Stop()
+			return nil
+		case event.Rune() == 'r' || event.Rune() == 'R':
+			editing = "round"
+			prompt.SetLabel("Minutes left in the round (0 to clear): ").SetText("")
+			app.SetFocus(prompt)
+			return nil
+		case event.Rune() == 'a' || event.Rune() == 'A':
+			editing = "announcement"
+			prompt.SetLabel("Announcement (empty to clear): ").SetText(roundTimer.Announcement)
+			app.SetFocus(prompt)
+			return nil
+		}
+		return event
+	})
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case addr := <-found:
+				joinTable(addr)
+			case msg := <-msgChan:
+				dashboard = hammerclock.UpdateDashboard(msg, dashboard, time.Now())
+				if msg, ok := msg.(*common.RoundTimerMsg); ok {
+					for _, client := range clients {
+						client.SetRound(msg.Ends, msg.Announcement)
+					}
+				}
+			case <-ticker.C:
+				// Redrawn regularly, so tables that stopped sending are marked as lost
+			case <-done:
+				return
+			}
+			rows := hammerclock.DashboardRows(dashboard, time.Now())
+			roundTimerText := ui.RoundTimerText(dashboard.RoundEnds, dashboard.Announcement, time.Now())
+			app.QueueUpdateDraw(func() {
+				ui.UpdateDashboard(table, rows, roundTimerText, colors)
+			})
+		}
+	}()
+
+	return app.SetRoot(layout, true).Run()
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"hammerclock/internal/hammerclock/paths"
+	"hammerclock/internal/hammerclock/platform"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Smallest terminal the player panels, status bar and menu fit in without cutting off content
+const (
+	doctorMinWidth  = 80
+	doctorMinHeight = 24
+)
+
+// doctorRunes are the block and box-drawing characters of the gauges, sparklines and borders
+var doctorRunes = []rune("█▏▁═║╔╭─│")
+
+// check is the result of a diagnostic check, with advice on fixing it if it failed
+type check struct {
+	name   string
+	ok     bool
+	result string
+	advice string
+}
+
+// runDoctor checks the terminal and the directories Hammerclock writes to, prints the results and returns whether
+// all checks passed
+func runDoctor(dirs paths.Dirs) bool {
+	checks := []check{
+		writeCheck("Options directory", dirs.Config),
+		writeCheck("Data directory", dirs.Data),
+	}
+	screen, err := tcell.NewScreen()
+	if err == nil {
+		err = screen.Init()
+	}
+	if err != nil {
+		checks = append(checks, check{name: "Terminal", result: err.Error(),
+			advice: "run Hammerclock in an interactive terminal, and check that TERM is set to the terminal's type"})
+	} else {
+		screen.EnableMouse()
+		terminal := terminalChecks(screen)
+		screen.Fini()
+		checks = append(terminal, checks...)
+	}
+
+	allOK := true
+	for _, c := range checks {
+		status := "OK  "
+		if !c.ok {
+			status = "FAIL"
+			allOK = false
+		}
+		fmt.Printf("[%s] %-18s %s\n", status, c.name, c.result)
+		if !c.ok && c.advice != "" {
+			fmt.Printf("       %-18s -> %s\n", "", c.advice)
+		}
+	}
+	return allOK
+}
+
+// terminalChecks checks the size, colors, Unicode rendering and mouse support of the terminal behind the screen
+func terminalChecks(screen tcell.Screen) []check {
+	width, height := screen.Size()
+	size := check{name: "Terminal size", ok: width >= doctorMinWidth && height >= doctorMinHeight,
+		result: fmt.Sprintf("%dx%d", width, height),
+		advice: fmt.Sprintf("enlarge the window or reduce the font size to at least %dx%d", doctorMinWidth, doctorMinHeight)}
+
+	colors := check{name: "Colors", ok: screen.Colors() >= 1<<24, result: fmt.Sprintf("%d colors", screen.Colors()),
+		advice: "the color palettes are approximated; set COLORTERM=truecolor if the terminal supports true colors"}
+	if screen.Colors() >= 1<<24 {
+		colors.result = "true colors"
+	}
+	if platform.LegacyConsole() {
+		colors.advice = "the legacy console only has 16 colors, use Windows Terminal for the color palettes"
+	}
+
+	var missing []string
+	for _, r := range doctorRunes {
+		if !screen.CanDisplay(r, false) {
+			missing = append(missing, string(r))
+		}
+	}
+	unicode := check{name: "Unicode", ok: len(missing) == 0, result: "character set " + screen.CharacterSet(),
+		advice: "use a UTF-8 locale (e.g. LANG=en_US.UTF-8) and a font with box-drawing characters, " +
+			"or set borderStyle to ascii"}
+	if len(missing) > 0 {
+		unicode.result += ", cannot display " + strings.Join(missing, " ")
+	}
+
+	mouse := check{name: "Mouse", ok: screen.HasMouse(), result: "supported",
+		advice: "clicks on the panels are ignored, use the keyboard shortcuts instead"}
+	if !mouse.ok {
+		mouse.result = "not supported"
+	}
+	return []check{size, colors, unicode, mouse}
+}
+
+// writeCheck checks that a file can be created in the directory
+func writeCheck(name, dir string) check {
+	c := check{name: name, result: dir,
+		advice: "fix the permissions of the directory, or run with --portable to keep the files next to the executable"}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		c.result = err.Error()
+		return c
+	}
+	file, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		c.result = err.Error()
+		return c
+	}
+	_ = file.Close()
+	_ = os.Remove(file.Name())
+	c.ok = true
+	return c
+}
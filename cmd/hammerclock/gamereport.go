@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"hammerclock/internal/hammerclock/report"
+)
+
+// printGameReport prints the summary of a game of a CSV log, the last one unless another is picked with -game
+func printGameReport(args []string, defaultLog string) error {
+	flags := flag.NewFlagSet("report", flag.ContinueOnError)
+	markdown := flags.Bool("markdown", false, "Print the summary as Markdown")
+	number := flags.Int("game", 0, "Game of the log to summarize, counting from 1 (default: the last game)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	logFile := defaultLog
+	if flags.NArg() > 0 {
+		logFile = flags.Arg(0)
+	}
+
+	games, err := report.ReadLog(logFile)
+	if err != nil {
+		return err
+	}
+	if len(games) == 0 {
+		return fmt.Errorf("no game in %s", logFile)
+	}
+	if *number == 0 {
+		*number = len(games)
+	}
+	if *number < 1 || *number > len(games) {
+		return fmt.Errorf("there are %d games in %s", len(games), logFile)
+	}
+
+	game := games[*number-1]
+	if *markdown {
+		fmt.Print(report.LogMarkdown(game))
+	} else {
+		fmt.Print(report.LogText(game))
+	}
+	return nil
+}
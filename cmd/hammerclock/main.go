@@ -1,54 +1,363 @@
 package main
 
 import (
+	_ "embed"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"hammerclock/internal/hammerclock"
+	"hammerclock/internal/hammerclock/api"
+	"hammerclock/internal/hammerclock/audit"
+	"hammerclock/internal/hammerclock/battlescribe"
+	"hammerclock/internal/hammerclock/campaign"
 	"hammerclock/internal/hammerclock/common"
 	hammerclockConfig "hammerclock/internal/hammerclock/config"
+	"hammerclock/internal/hammerclock/gpio"
+	"hammerclock/internal/hammerclock/hotkey"
+	"hammerclock/internal/hammerclock/input"
+	"hammerclock/internal/hammerclock/link"
 	"hammerclock/internal/hammerclock/logging"
+	"hammerclock/internal/hammerclock/notify"
 	"hammerclock/internal/hammerclock/options"
-	"hammerclock/internal/hammerclock/palette"
+	"hammerclock/internal/hammerclock/paths"
+	"hammerclock/internal/hammerclock/platform"
+	"hammerclock/internal/hammerclock/report"
+	"hammerclock/internal/hammerclock/selfupdate"
+	"hammerclock/internal/hammerclock/spectate"
+	"hammerclock/internal/hammerclock/tournament"
 )
 
+// usageText is the CLI usage information, embedded with the version as a {version} placeholder
+//
+//go:embed usage.txt
+var usageText string
+
 // CLI usage information
-var cliUsage = `
-Hammerclock ` + hammerclockConfig.Version + `
-Terminal-based chess clock and tracker for tabletop games
+var cliUsage = "\n" + strings.ReplaceAll(usageText, "{version}", hammerclockConfig.Version)
 
-Usage:
-  hammerclock [options]
+// resolveOptionsFile returns the options file to load: the one given with -o, a default.json in the current
+// directory as used by older versions (except in portable mode), or default.json in the config directory.
+func resolveOptionsFile(optionsFileFlag string, portable bool, dirs paths.Dirs) string {
+	explicit := false
+	flag.Visit(func(f *flag.Flag) {
+		explicit = explicit || f.Name == "o"
+	})
+	if explicit {
+		return optionsFileFlag
+	}
 
-options:
-  -o <file>    Specify a custom options file (default: default.json)
-  -h, --help   Show this help message
+	if _, err := os.Stat(hammerclockConfig.DefaultOptionsFilename); err == nil && !portable {
+		return hammerclockConfig.DefaultOptionsFilename
+	}
+	return dirs.OptionsFile()
+}
 
-Examples:
-  hammerclock                     # Run with default options
-  hammerclock -o myOptions.json   # Run with custom options
-`
+// listFlag collects the values of a repeatable flag, e.g. the key=value settings of --set
+type listFlag []string
 
-func main() {
-	logging.Initialise()
-	fmt.Println("Hammerclock", hammerclockConfig.Version, "starting up...")
-	fmt.Println("Logs will be written to logs.csv in the current directory")
+func (s *listFlag) String() string { return strings.Join(*s, " ") }
+
+func (s *listFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// setupTableGame seats the players assigned to a table of a tournament event, with their factions from the roster,
+// identifies the table by the event name and table number and shows the round in the banner. The event file is
+// locked while the tables of the round are assigned, so the tables starting at the same time agree on them.
+func setupTableGame(eventFile string, round, table int, opts *options.Options) error {
+	var event *tournament.Event
+	var pairing tournament.Pairing
+	err := tournament.Update(eventFile, func(e *tournament.Event) error {
+		var err error
+		event = e
+		pairing, err = e.Table(round, table)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	opts.PlayerCount = len(pairing.Players)
+	opts.PlayerNames = pairing.Players
+	opts.PlayerBanners = nil
+	opts.PlayerFactions = make([]string, len(pairing.Players))
+	for i, name := range pairing.Players {
+		opts.PlayerFactions[i] = event.Entrant(name).Faction
+	}
+	opts.EventName = event.Name
+	opts.TableNumber = table
+	opts.Banner = fmt.Sprintf("Round %d", round)
+	return nil
+}
+
+// selfUpdate replaces the executable with the binary of the latest release, unless it is the latest version already
+func selfUpdate() error {
+	release, err := selfupdate.Latest(selfupdate.LatestReleaseURL)
+	if err != nil {
+		return err
+	}
+	if release.Version() == hammerclockConfig.Version {
+		fmt.Println("Hammerclock", hammerclockConfig.Version, "is the latest version")
+		return nil
+	}
+	executable, err := os.Executable()
+	if err == nil {
+		executable, err = filepath.EvalSymlinks(executable)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("Downloading Hammerclock", release.Version(), "...")
+	if err := selfupdate.Install(release, executable); err != nil {
+		return err
+	}
+	fmt.Println("Hammerclock updated to", release.Version())
+	return nil
+}
 
+func main() {
 	optionsFileFlag := flag.String("o", hammerclockConfig.DefaultOptionsFilename, "Path to the loadedOptions file")
+	bannerFlag := flag.String("b", "", "Custom banner shown in the top bar")
+	portableFlag := flag.Bool("portable", false, "Keep all files in a directory next to the executable")
+	hostFlag := flag.String("host", "", "Share the clocks with linked terminals, listening on this address")
+	joinFlag := flag.String("join", "", "Mirror the clocks of the host at this address")
+	sharedFlag := flag.Bool("shared", false, "Let the terminals linked to the host play the game, not just mirror it")
+	apiFlag := flag.String("api", "", "Serve the web clock and the game state as JSON over HTTP at this address")
+	overlayFlag := flag.String("overlay", "", "Keep the clocks in this file for streaming software, as JSON if it ends in .json")
+	spectateFlag := flag.String("spectate", "", "Let spectators watch the game over SSH at this address")
+	dashboardFlag := flag.Bool("dashboard", false, "Show the tables on the local network and the given addresses")
+	eventFlag := flag.String("event", "", "Tournament event file to play a table of, or to show the record of")
+	roundFlag := flag.Int("round", 1, "Round of the tournament event to play")
+	tableFlag := flag.Int("table", 0, "Table of the tournament event to play")
+	standingsFlag := flag.Bool("standings", false, "Enter the results of the tournament event and show its standings")
+	exportFlag := flag.String("export", "", "Export the results of the tournament event to a CSV or JSON file")
+	campaignFlag := flag.String("campaign", "", "Campaign file to play the next game of, or to show the summary of")
+	summaryFlag := flag.Bool("summary", false, "Show the summary of the campaign and change its players' state")
+	verifyAuditFlag := flag.String("verify-audit", "", "Check that the judge's audit log was not changed")
+	demoFlag := flag.Bool("demo", false, "Play a simulated game, e.g. as a screensaver")
+	kioskFlag := flag.Bool("kiosk", false, "Disable the options and about screens, ending the game and quitting")
+	startAtFlag := flag.String("start-at", "", "Start the game at a time like 19:30, or after a countdown like 5m")
+	var settings listFlag
+	flag.Var(&settings, "set", "Set an option of the options file for this run, as key=value")
+	var rosters listFlag
+	flag.Var(&rosters, "roster", "Import the players of the tournament event from a CSV roster, or a player's army list from a BattleScribe roster")
 	flag.Usage = func() {
 		//goland:noinspection GoUnhandledErrorResult
 		fmt.Fprintln(os.Stderr, cliUsage)
 	}
 	flag.Parse()
 
-	loadedOptions := options.LoadOptions(*optionsFileFlag)
+	if flag.Arg(0) == "update" {
+		if err := selfUpdate(); err != nil {
+			fmt.Printf("Error updating: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Keep files in the per-user directories, or next to the executable in portable mode
+	dirs, err := paths.Standard()
+	if *portableFlag {
+		dirs, err = paths.Portable()
+	}
+	if err == nil {
+		err = dirs.Create()
+	}
+	if err != nil {
+		fmt.Printf("Error preparing the application directories: %v, using the current directory\n", err)
+		dirs = paths.Dirs{}
+	}
+
+	// Diagnose the terminal and the directories for support questions
+	if flag.Arg(0) == "doctor" {
+		if !runDoctor(dirs) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Print the summary of a game of the log without starting the clock, on a clean stdout for scripts
+	if flag.Arg(0) == "report" {
+		logging.SetLogDir(dirs.Data)
+		if err := printGameReport(flag.Args()[1:], logging.LogFilePath()); err != nil {
+			fmt.Printf("Error reporting the game: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println("Hammerclock", hammerclockConfig.Version, "starting up...")
+	logging.SetLogDir(dirs.Data)
+	logging.Initialise()
+	fmt.Println("Logs will be written to", logging.LogFilePath())
+
+	// Options given as a URL are downloaded and loaded from a cached copy
+	optionsFile := resolveOptionsFile(*optionsFileFlag, *portableFlag, dirs)
+	if options.IsURL(optionsFile) {
+		cachedFile, err := options.FetchRemote(optionsFile)
+		if err != nil {
+			fmt.Printf("Error downloading options from '%s': %v\n", optionsFile, err)
+			cachedFile = dirs.OptionsFile()
+		}
+		optionsFile = cachedFile
+	}
+
+	loadedOptions := options.LoadOptions(optionsFile, dirs.OptionsFile())
+	fileOptions := options.Copy(loadedOptions)
+	// Environment variables override the options file, and --set overrides both
+	loadedOptions, err = options.ApplyEnv(loadedOptions, os.Environ())
+	if err != nil {
+		fmt.Printf("Ignoring invalid options in the environment: %v\n", err)
+	}
+	if loadedOptions, err = options.ApplySettings(loadedOptions, settings); err != nil {
+		fmt.Printf("Error in --set: %v\n", err)
+		os.Exit(1)
+	}
+	loadedOptions.Rules = options.MergeRulesDirs(loadedOptions.Rules, optionsFile)
+	if loadedOptions.Default >= len(loadedOptions.Rules) {
+		loadedOptions.Default = 0
+	}
+
+	// Check the audit log of the judge's interventions with the judge passphrase
+	if *verifyAuditFlag != "" {
+		count, last, err := audit.Verify(*verifyAuditFlag, loadedOptions.JudgePassphrase)
+		if err != nil {
+			fmt.Printf("The audit log is not intact after %d entries: %v\n", count, err)
+			os.Exit(1)
+		}
+		fmt.Printf("The audit log is intact, %d entries, ending with MAC %s\n", count, last)
+		return
+	}
+
+	// BattleScribe rosters are the army lists of the players in order, any other roster lists the players of an event
+	var (
+		armyRosters     []battlescribe.Roster
+		armyRosterFiles []string
+	)
+	eventRoster := ""
+	for _, file := range rosters {
+		if !battlescribe.IsRoster(file) {
+			eventRoster = file
+			continue
+		}
+		roster, err := battlescribe.ReadFile(file)
+		if err != nil {
+			fmt.Printf("Error importing the roster: %v\n", err)
+			os.Exit(1)
+		}
+		armyRosters = append(armyRosters, roster)
+		armyRosterFiles = append(armyRosterFiles, file)
+	}
+
+	// Import the players of a tournament event before its first round
+	if *eventFlag != "" && eventRoster != "" {
+		event, err := tournament.ImportRosterFile(*eventFlag, eventRoster)
+		if err != nil {
+			fmt.Printf("Error importing the roster: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%d players imported into %s\n", len(event.Players), *eventFlag)
+		return
+	}
+
+	// Play a table of a tournament event, or show the event record if no table is given
+	if *eventFlag != "" && *tableFlag == 0 {
+		event, err := tournament.Load(*eventFlag)
+		if err != nil {
+			fmt.Printf("Error loading the event: %v\n", err)
+			os.Exit(1)
+		}
+		if *standingsFlag {
+			if err := runStandings(*eventFlag, *roundFlag, hammerclock.OptionsColorPalette(loadedOptions, loadedOptions.ColorPalette)); err != nil {
+				fmt.Printf("Error running the standings: %v\n", err)
+			}
+			return
+		}
+		if *exportFlag != "" {
+			if err := event.Export(*exportFlag); err != nil {
+				fmt.Printf("Error exporting the event: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("Results exported to", *exportFlag)
+			return
+		}
+		fmt.Print(event.Summary())
+		return
+	}
+	// The campaign summary shows the campaign's players and log instead of playing a game
+	if *campaignFlag != "" && *summaryFlag {
+		if _, err := campaign.Load(*campaignFlag); err != nil {
+			fmt.Printf("Error loading the campaign: %v\n", err)
+			os.Exit(1)
+		}
+		if err := runCampaign(*campaignFlag, hammerclock.OptionsColorPalette(loadedOptions, loadedOptions.ColorPalette)); err != nil {
+			fmt.Printf("Error running the campaign summary: %v\n", err)
+		}
+		return
+	}
+	// A replay plays a game of the log back instead of playing a game
+	if flag.Arg(0) == "replay" {
+		replay, err := loadReplay(flag.Args()[1:], logging.LogFilePath())
+		if err != nil {
+			fmt.Printf("Error loading the replay: %v\n", err)
+			os.Exit(1)
+		}
+		if err := runReplay(replay, hammerclock.OptionsColorPalette(loadedOptions, loadedOptions.ColorPalette)); err != nil {
+			fmt.Printf("Error running the replay: %v\n", err)
+		}
+		return
+	}
+	// The organizer dashboard shows the other tables instead of playing a game
+	if *dashboardFlag {
+		credentials := link.Credentials{Secret: loadedOptions.LinkSecret, Organizer: loadedOptions.JudgePassphrase}
+		if err := runDashboard(flag.Args(), credentials, hammerclock.OptionsColorPalette(loadedOptions, loadedOptions.ColorPalette)); err != nil {
+			fmt.Printf("Error running the dashboard: %v\n", err)
+		}
+		logging.Cleanup()
+		return
+	}
+
+	if *eventFlag != "" {
+		if err := setupTableGame(*eventFlag, *roundFlag, *tableFlag, &loadedOptions); err != nil {
+			fmt.Printf("Error setting up the table: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var campaignGame *campaign.Campaign
+	if *campaignFlag != "" {
+		campaignGame, err = setupCampaignGame(*campaignFlag, &loadedOptions)
+		if err != nil {
+			fmt.Printf("Error setting up the campaign game: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *bannerFlag != "" {
+		loadedOptions.Banner = *bannerFlag
+	}
 
 	model := hammerclock.NewModel()
 	model.Options = loadedOptions
-	model.Phases = loadedOptions.Rules[loadedOptions.Default].Phases
-	model.CurrentColorPalette = palette.ColorPaletteByName(loadedOptions.ColorPalette)
+	model.OptionsFile = optionsFile
+	model.AuditFile = filepath.Join(dirs.Data, audit.FileName)
+	// Linked clients and the demo game have nothing of their own to save
+	if *joinFlag == "" && !*demoFlag {
+		model.AutosaveFile = filepath.Join(dirs.Data, hammerclock.AutosaveFileName)
+		model.SaveFile = filepath.Join(dirs.Data, hammerclock.SaveFileName)
+	}
+	model.SavedOptions = options.Copy(loadedOptions)
+	model.FileOptions = fileOptions
+	model.Phases = hammerclock.RulesetPhases(loadedOptions)
+	model.CurrentColorPalette = hammerclock.OptionsColorPalette(loadedOptions, loadedOptions.ColorPalette)
 
 	players := make([]*common.Player, loadedOptions.PlayerCount)
 	for i := 0; i < loadedOptions.PlayerCount; i++ {
@@ -63,70 +372,414 @@ func main() {
 			CurrentPhase: 0,
 			TurnCount:    0,
 			ActionLog:    []common.LogEntry{},
+			ArmyList:     hammerclock.ProfileArmy(loadedOptions, playerName),
+		}
+		if i < len(loadedOptions.PlayerFactions) {
+			players[i].Faction = loadedOptions.PlayerFactions[i]
+		}
+
+		// Load the player's ASCII art banner, if configured
+		if i < len(loadedOptions.PlayerBanners) && loadedOptions.PlayerBanners[i] != "" {
+			banner, err := options.LoadBanner(loadedOptions.PlayerBanners[i])
+			if err != nil {
+				fmt.Printf("Error loading banner for %s: %v\n", playerName, err)
+			} else {
+				players[i].Banner = banner
+			}
 		}
 	}
+	if campaignGame != nil {
+		applyCampaignRosters(players, campaignGame)
+	}
 	model.Players = players
+	if len(armyRosters) > len(players) {
+		fmt.Printf("Error: %d BattleScribe rosters for %d players\n", len(armyRosters), len(players))
+		os.Exit(1)
+	}
+	for i, roster := range armyRosters {
+		model, _ = hammerclock.Update(&common.RosterImportedMsg{
+			PlayerIndex: i,
+			File:        armyRosterFiles[i],
+			Faction:     roster.Faction,
+			Units:       roster.Units,
+		}, model)
+	}
+	model.Linked = *joinFlag != ""
+	model.Kiosk = *kioskFlag
+	if *demoFlag {
+		hammerclock.SetupDemo(&model)
+	}
+	// Count down to the start of the game, e.g. so all tables of a round start together
+	if *startAtFlag != "" {
+		at, ok := hammerclock.ParseStartTime(*startAtFlag, time.Now())
+		if !ok {
+			fmt.Printf("Error: '%s' is neither a time like 19:30 nor a countdown like 5m\n", *startAtFlag)
+			os.Exit(1)
+		}
+		model, _ = hammerclock.Update(&common.ScheduleStartMsg{At: at}, model)
+	}
+
+	// The session holds the games played in tabs, model is the game shown
+	session := hammerclock.NewSession(model)
 
 	msgChan := make(chan common.Message)
 	done := make(chan struct{})
 
 	view := hammerclock.NewView(&model, msgChan)
-	hammerclock.SetupInputCapture(view.App, msgChan)
+	hammerclock.SetupInputCapture(view.App, msgChan, view.VimBindings)
+	view.App.SetAfterDrawFunc(platform.SyncOnResize())
+
+	// Read switch events from an external footswitch or button, if configured
+	if loadedOptions.ExternalInput.Device != "" {
+		device, err := input.Open(loadedOptions.ExternalInput)
+		if err != nil {
+			fmt.Printf("Error opening external input device '%s': %v\n", loadedOptions.ExternalInput.Device, err)
+		} else {
+			go device.Run(msgChan, done)
+		}
+	}
+
+	// Share the clocks with linked terminals, or mirror the clocks of a host
+	var linkHost *link.Host
+	if *hostFlag != "" {
+		// The organizer proves to be one with the judge passphrase
+		credentials := link.Credentials{Secret: loadedOptions.LinkSecret, Organizer: loadedOptions.JudgePassphrase}
+		host, err := link.Listen(*hostFlag, credentials)
+		if errors.Is(err, link.ErrNoSecret) {
+			fmt.Println("Linked clocks need a linkSecret in the options, e.g. --set linkSecret=...")
+		} else if err != nil {
+			fmt.Printf("Error listening for linked clocks: %v\n", err)
+		} else {
+			fmt.Println("Linked clocks can join at", host.Addr())
+			linkHost = host
+			if *sharedFlag {
+				linkHost.Share(hammerclock.SharedKey)
+			}
+			go linkHost.Run(msgChan, done)
+			go func() {
+				if err := linkHost.Announce(done); err != nil {
+					fmt.Printf("Error announcing the clocks on the local network: %v\n", err)
+				}
+			}()
+		}
+	}
+	var linkClient *link.Client
+	if *joinFlag != "" {
+		if loadedOptions.LinkSecret == "" {
+			fmt.Println("Linked clocks need the linkSecret of the host in the options, e.g. --set linkSecret=...")
+		}
+		linkClient = link.Join(*joinFlag, link.Credentials{Secret: loadedOptions.LinkSecret})
+		go linkClient.Run(msgChan, done)
+	}
+
+	// Serve the game state to other tools, e.g. stream overlays
+	var apiServer *api.Server
+	if *apiFlag != "" {
+		server, err := api.Listen(*apiFlag)
+		if err != nil {
+			fmt.Printf("Error listening for game state requests: %v\n", err)
+		} else {
+			fmt.Printf("Web clock and game state API at http://%s/\n", server.Addr())
+			apiServer = server
+			apiServer.Publish(&model, hammerclock.ClocksRunning(&model), hammerclock.BattleRound(&model))
+			go func() {
+				if err := apiServer.Run(done); err != nil {
+					fmt.Printf("Error serving the game state: %v\n", err)
+				}
+			}()
+		}
+	}
+
+	// Let spectators watch over SSH, drawing a view of their own that takes no input
+	var spectators *spectate.Server
+	var spectatorView *hammerclock.View
+	if *spectateFlag != "" {
+		server, err := spectate.Listen(*spectateFlag, filepath.Join(dirs.Data, spectate.HostKeyFileName),
+			filepath.Join(dirs.Data, spectate.AuthorizedKeysFileName))
+		if err != nil {
+			fmt.Printf("Error listening for spectators: %v\n", err)
+		} else {
+			fmt.Printf("Spectators can watch with ssh at %s, host key %s\n", server.Addr(), server.Fingerprint())
+			spectators = server
+			spectatorView = hammerclock.NewView(&model, make(chan common.Message))
+			go spectators.Run(done)
+		}
+	}
+
+	// Post the events of the games to the Discord webhook, which can be turned on and off on the options screen
+	notifier := notify.New()
+	go notifier.Run(done)
+
+	// Listen for system-wide hotkeys, if enabled
+	if loadedOptions.GlobalHotkeys.Enabled {
+		if err := hotkey.Start(loadedOptions.GlobalHotkeys, msgChan, done); err != nil {
+			fmt.Printf("Error registering global hotkeys: %v\n", err)
+		}
+	}
+
+	// Drive GPIO buttons and player LEDs, if enabled
+	var gpioController *gpio.Controller
+	if loadedOptions.GPIO.Enabled {
+		controller, err := gpio.Open(loadedOptions.GPIO)
+		if err != nil {
+			fmt.Printf("Error setting up GPIO: %v\n", err)
+		} else {
+			gpioController = controller
+			go gpioController.Run(msgChan, done)
+		}
+	}
+
+	// The tick interval can be changed on the options screen, the ticker picks up changes on its next tick
+	var tickInterval atomic.Int64
+	tickInterval.Store(int64(options.TickDuration(loadedOptions)))
 
 	go func() {
-		ticker := time.NewTicker(1 * time.Second)
+		interval := time.Duration(tickInterval.Load())
+		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 
 		for {
 			select {
 			case <-ticker.C:
+				if newInterval := time.Duration(tickInterval.Load()); newInterval != interval {
+					interval = newInterval
+					ticker.Reset(interval)
+				}
+
 				// Always update the clock, regardless of game state
 				view.App.QueueUpdateDraw(func() {
 					view.UpdateClock(&model)
 				})
-				msgChan <- &common.TickMsg{}
+				// Wall clock time, as the monotonic clock stops while the system is suspended on some platforms
+				msgChan <- &common.TickMsg{Time: time.Now().Round(0)}
 			case <-done:
 				return
 			}
 		}
 	}()
 
+	// Errors recording tournament results and writing battle reports are reported once the application has stopped
+	var (
+		recordErrs []error
+		recordMu   sync.Mutex
+	)
+	// Failing autosaves are reported once; each game is autosaved at its own interval
+	var (
+		lastAutosave   = map[int]time.Time{}
+		autosaveFailed bool
+	)
+	// Keep the clocks in a file for the sources of streaming software, failing writes are reported once
+	var (
+		overlayFile   *api.OverlayFile
+		overlayFailed bool
+	)
+	if *overlayFlag != "" {
+		overlayFile = api.NewOverlayFile(*overlayFlag)
+	}
+
+	// recordGame keeps the records of a game after an update: the result of the tournament table, played in the first
+	// game, the campaign, the battle report, the autosave, the saved game and the Discord notifications
+	recordGame := func(id int, before, after *common.Model) {
+		ended := before.GameStarted && !after.GameStarted
+		recordErr := func(err error) {
+			recordMu.Lock()
+			recordErrs = append(recordErrs, err)
+			recordMu.Unlock()
+		}
+		// Roll the result of a tournament table up into the event record when its game ends
+		if *eventFlag != "" && id == 1 && ended {
+			result := tournament.ResultFromModel(before, *roundFlag, *tableFlag, time.Now())
+			if err := tournament.RecordResult(*eventFlag, result); err != nil {
+				recordErr(fmt.Errorf("recording the result in the event: %w", err))
+			}
+		}
+		// Carry the result of a campaign game over into the campaign
+		if *campaignFlag != "" && ended {
+			game := campaign.GameFromModel(before, time.Now())
+			if err := campaign.RecordGame(*campaignFlag, game); err != nil {
+				recordErr(fmt.Errorf("recording the game in the campaign: %w", err))
+			}
+		}
+		if before.Options.BattleReport && ended {
+			endedAt := time.Now()
+			if err := report.Write(filepath.Join(dirs.Data, report.FileName(endedAt)), before, endedAt); err != nil {
+				recordErr(fmt.Errorf("writing the battle report: %w", err))
+			}
+		}
+		// Keep the game in its autosave, so it can be continued after a crash or a closed terminal
+		if after.AutosaveFile != "" {
+			if after.GameStarted && (after.GameStatus != before.GameStatus ||
+				time.Since(lastAutosave[id]) >= hammerclock.AutosaveInterval) {
+				lastAutosave[id] = time.Now()
+				err := hammerclock.Autosave(after.AutosaveFile, after, lastAutosave[id])
+				if err != nil && !autosaveFailed {
+					autosaveFailed = true
+					recordErr(fmt.Errorf("autosaving the game: %w", err))
+				}
+			} else if ended {
+				_ = os.Remove(after.AutosaveFile)
+			}
+		}
+		// A game saved on demand is offered to be continued until it ends
+		if after.SaveFile != "" && ended {
+			_ = os.Remove(after.SaveFile)
+		}
+		notifier.Notify(before, after)
+	}
+
+	// dispatch handles the message returned by a command: dialogs and other view changes are shown, batches are
+	// dispatched in order and other messages go back to the update loop
+	var dispatch func(resultMsg common.Message)
+	dispatch = func(resultMsg common.Message) {
+		if resultMsg == nil {
+			return
+		}
+		if batch, ok := resultMsg.(*common.BatchMsg); ok {
+			for _, msg := range batch.Msgs {
+				dispatch(msg)
+			}
+			return
+		}
+		if showModal, ok := resultMsg.(*common.ShowModalMsg); ok {
+			view.App.QueueUpdateDraw(func() {
+				switch showModal.Type {
+				case "EndGameConfirm":
+					modal := hammerclock.CreateEndGameConfirmationModal(view, &model)
+					hammerclock.ShowConfirmationModal(view, modal)
+				case "ExitConfirm":
+					modal := hammerclock.CreateExitConfirmationModal(view)
+					hammerclock.ShowConfirmationModal(view, modal)
+				case "SavedGame":
+					modal := hammerclock.CreateSavedGameModal(view, &model)
+					hammerclock.ShowConfirmationModal(view, modal)
+				case "ResumeConfirm":
+					modal := hammerclock.CreateResumeConfirmationModal(view)
+					hammerclock.ShowConfirmationModal(view, modal)
+				case "GameOver":
+					if model.Options.FlagSound {
+						view.Beep()
+					}
+					modal := hammerclock.CreateGameOverModal(view, &model, showModal.PlayerIndex)
+					hammerclock.ShowConfirmationModal(view, modal)
+				case "SuspendResolve":
+					modal := hammerclock.CreateSuspendModal(view, &model)
+					hammerclock.ShowConfirmationModal(view, modal)
+				case "SecondaryObjectives":
+					form := hammerclock.CreateSecondaryObjectivesModal(view, &model, showModal.PlayerIndex)
+					hammerclock.ShowFormModal(view, form)
+				case hammerclock.PreGame, hammerclock.PostGame:
+					form := hammerclock.CreateSequenceStepModal(view, &model, showModal.Type, showModal.Step)
+					hammerclock.ShowFormModal(view, form)
+				case "CommandPalette":
+					view.ShowCommandPalette()
+				case "DiceRoller":
+					view.ShowDiceRoller(&model)
+				case "AddNote":
+					view.ShowNotePrompt()
+				case "AddTimer":
+					view.ShowTimerPrompt()
+				case "JudgeLogin":
+					view.ShowJudgeLogin()
+				case "JudgeAction":
+					view.ShowJudgePrompt()
+				case "OptionsPIN":
+					view.ShowOptionsPIN()
+				case "ArmyEditor":
+					view.ShowArmyEditor(&model, showModal.PlayerIndex)
+				case "UnitStatus":
+					view.ShowUnitStatus(&model, showModal.PlayerIndex)
+				case "Templates":
+					view.ShowTemplatePicker(&model)
+				case "OptionsDiff":
+					view.ShowOptionsDiff(&model)
+				}
+			})
+		} else if _, ok := resultMsg.(*common.RestoreMainUIMsg); ok {
+			view.App.QueueUpdateDraw(func() {
+				view.RestoreMainView()
+			})
+		} else if _, ok := resultMsg.(*common.ReloadOptionsScreenMsg); ok {
+			view.App.QueueUpdateDraw(func() {
+				view.ReloadOptionsScreen(&model)
+			})
+		} else if scrollMsg, ok := resultMsg.(*common.ScrollLogMsg); ok {
+			view.App.QueueUpdateDraw(func() {
+				view.ScrollLog(scrollMsg)
+			})
+		} else if _, ok := resultMsg.(*common.ScreenshotMsg); ok {
+			view.App.QueueUpdate(func() {
+				file, err := hammerclock.SaveScreenshot(view.Screen, dirs.Data, time.Now())
+				go func() { msgChan <- &common.ScreenshotSavedMsg{File: file, Err: err} }()
+			})
+		} else if _, ok := resultMsg.(*common.BellMsg); ok {
+			view.App.QueueUpdate(view.Beep)
+		} else if key, ok := resultMsg.(*common.ForwardKeyMsg); ok {
+			if linkClient != nil {
+				linkClient.SendKey(key.Key, key.Rune)
+			}
+		} else if exitMsg, ok := resultMsg.(*common.ExitConfirmMsg); ok && exitMsg.Confirmed {
+			// User confirmed exit, stop the application
+			view.App.Stop()
+		} else {
+			msgChan <- resultMsg
+		}
+	}
+
 	go func() {
 		for {
 			select {
 			case msg := <-msgChan:
-				updatedModel, cmd := hammerclock.Update(msg, model)
+				updatedSession, cmd := session.Update(msg)
+				updatedModel := *updatedSession.Current()
+				sameGame := updatedSession.Active == session.Active
+				tickInterval.Store(int64(options.TickDuration(updatedModel.Options)))
+				if sameGame && updatedModel.Warnings > model.Warnings {
+					platform.FlashTaskbar()
+				}
+				// Keep the records of every game that changed, shown or in the background
+				for i := range updatedSession.Games {
+					id := updatedSession.ID(i)
+					if before, ok := session.Game(id); ok {
+						recordGame(id, before, &updatedSession.Games[i])
+					}
+				}
+				session = updatedSession
 				model = updatedModel
 
+				if linkHost != nil {
+					linkHost.Broadcast(&model, hammerclock.ClocksRunning(&model), hammerclock.BattleRound(&model))
+				}
+				if apiServer != nil {
+					apiServer.Publish(&model, hammerclock.ClocksRunning(&model), hammerclock.BattleRound(&model))
+				}
+				if overlayFile != nil {
+					err := overlayFile.Write(&model, hammerclock.ClocksRunning(&model), hammerclock.BattleRound(&model))
+					if err != nil && !overlayFailed {
+						overlayFailed = true
+						recordMu.Lock()
+						recordErrs = append(recordErrs, fmt.Errorf("writing the overlay file: %w", err))
+						recordMu.Unlock()
+					}
+				}
+
+				if gpioController != nil {
+					gpioController.SetActivePlayer(gpio.ActivePlayerIndex(&model))
+				}
+
 				view.App.QueueUpdateDraw(func() {
-					view.Render(&model)
+					view.RenderSession(&session)
+					if spectators != nil && spectators.Spectators() > 0 {
+						spectatorView.RenderSession(&session)
+						spectatorView.UpdateClock(&model)
+						spectators.Show(func(width, height int) string {
+							return hammerclock.DrawANSI(spectatorView.MainView, width, height)
+						})
+					}
 				})
 
 				if cmd != nil {
 					go func() {
-						if resultMsg := cmd(); resultMsg != nil {
-							if showModal, ok := resultMsg.(*common.ShowModalMsg); ok {
-								view.App.QueueUpdateDraw(func() {
-									switch showModal.Type {
-									case "EndGameConfirm":
-										modal := hammerclock.CreateEndGameConfirmationModal(view)
-										hammerclock.ShowConfirmationModal(view, modal)
-									case "ExitConfirm":
-										modal := hammerclock.CreateExitConfirmationModal(view)
-										hammerclock.ShowConfirmationModal(view, modal)
-									}
-								})
-							} else if _, ok := resultMsg.(*common.RestoreMainUIMsg); ok {
-								view.App.QueueUpdateDraw(func() {
-									view.RestoreMainView()
-								})
-							} else if exitMsg, ok := resultMsg.(*common.ExitConfirmMsg); ok && exitMsg.Confirmed {
-								// User confirmed exit, stop the application
-								view.App.Stop()
-							} else {
-								msgChan <- resultMsg
-							}
-						}
+						dispatch(cmd())
 					}()
 				}
 			case <-done:
@@ -135,10 +788,52 @@ func main() {
 		}
 	}()
 
-	if err := view.App.SetRoot(view.MainView, true).EnableMouse(true).Run(); err != nil {
+	// A run that crashed or was killed leaves its marker behind, and its game is offered to be restored whatever the
+	// resumeGame option
+	var interrupted bool
+	var runningFile string
+	if model.AutosaveFile != "" {
+		runningFile = filepath.Join(dirs.Data, hammerclock.RunningFileName)
+		interrupted, _ = hammerclock.MarkRunning(runningFile)
+	}
+
+	// Offer to continue the game saved on demand or the unfinished game of the last run, whichever is newer, once the
+	// application is running
+	var offered *common.SavedGame
+	if model.SaveFile != "" {
+		offered, _ = hammerclock.LoadAutosave(model.SaveFile)
+	}
+	if (loadedOptions.ResumeGame || interrupted) && model.AutosaveFile != "" {
+		if saved, err := hammerclock.LoadAutosave(model.AutosaveFile); err == nil &&
+			(offered == nil || saved.SavedAt.After(offered.SavedAt)) {
+			saved.Interrupted = interrupted
+			offered = saved
+		}
+	}
+	if offered != nil {
+		go func() { msgChan <- &common.OfferSavedGameMsg{Game: offered} }()
+	}
+
+	screen, err := hammerclock.NewFocusScreen(msgChan)
+	if err == nil {
+		view.Screen = screen
+		err = view.App.SetScreen(screen).SetRoot(view.MainView, true).EnableMouse(true).EnablePaste(true).Run()
+	}
+	if err != nil {
 		fmt.Printf("Error running application: %v\n", err)
 	}
 
 	close(done)
+	if runningFile != "" {
+		_ = os.Remove(runningFile)
+	}
+	recordMu.Lock()
+	for _, err := range recordErrs {
+		fmt.Printf("Error %v\n", err)
+	}
+	recordMu.Unlock()
+	if err := notifier.Err(); err != nil {
+		fmt.Printf("Error posting to the Discord webhook: %v\n", err)
+	}
 	logging.Cleanup()
 }
@@ -2,17 +2,27 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"hammerclock/internal/hammerclock"
+	"hammerclock/internal/hammerclock/audit"
+	"hammerclock/internal/hammerclock/campaign"
 	"hammerclock/internal/hammerclock/common"
 	hammerclockConfig "hammerclock/internal/hammerclock/config"
+	"hammerclock/internal/hammerclock/dice"
+	"hammerclock/internal/hammerclock/logging"
 	"hammerclock/internal/hammerclock/options"
 	"hammerclock/internal/hammerclock/palette"
+	"hammerclock/internal/hammerclock/report"
+	"hammerclock/internal/hammerclock/rules"
+	"hammerclock/internal/hammerclock/ui"
 
 	"github.com/gdamore/tcell/v2"
 )
@@ -125,6 +135,12 @@ func TestUsageOutput(t *testing.T) {
 	if !strings.Contains(output.String(), "-o <file>") {
 		t.Errorf("Expected usage to contain options flag")
 	}
+	if !strings.Contains(output.String(), "-b <text>") {
+		t.Errorf("Expected usage to contain banner flag")
+	}
+	if !strings.Contains(output.String(), "--join <addr>") {
+		t.Errorf("Expected usage to contain link flags")
+	}
 }
 
 // TestModelCreation tests the initial model setup
@@ -256,6 +272,122 @@ func TestPhaseManagement(t *testing.T) {
 	}
 }
 
+// TestPhaseElapsedTime tests that the phase timer counts while the game runs and resets on phase changes
+func TestPhaseElapsedTime(t *testing.T) {
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+
+	if model.Players[0].PhaseElapsed != 2*time.Second {
+		t.Errorf("Expected 2s in the current phase, got %v", model.Players[0].PhaseElapsed)
+	}
+
+	model, _ = hammerclock.Update(&common.NextPhaseMsg{}, model)
+	if model.Players[0].PhaseElapsed != 0 {
+		t.Errorf("Expected phase time to reset on phase change, got %v", model.Players[0].PhaseElapsed)
+	}
+	if model.Players[0].TimeElapsed != 2*time.Second {
+		t.Errorf("Expected player time to be kept on phase change, got %v", model.Players[0].TimeElapsed)
+	}
+}
+
+// TestSaveAndRevertOptions tests that option changes are kept until saved or reverted
+func TestSaveAndRevertOptions(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.OptionsFile = filepath.Join(t.TempDir(), "options.json")
+
+	model, _ = hammerclock.Update(&common.SetTimeFormatMsg{Format: "24-hour"}, model)
+	if !hammerclock.OptionsChanged(&model) {
+		t.Fatalf("Expected the options to be marked as changed")
+	}
+
+	reverted, cmd := hammerclock.Update(&common.RevertOptionsMsg{}, model)
+	if hammerclock.OptionsChanged(&reverted) || reverted.Options.TimeFormat != "AMPM" {
+		t.Errorf("Expected the change to be reverted, got time format %q", reverted.Options.TimeFormat)
+	}
+	if _, ok := cmd().(*common.ReloadOptionsScreenMsg); !ok {
+		t.Errorf("Expected reverting to reload the options screen")
+	}
+
+	model, cmd = hammerclock.Update(&common.SaveOptionsMsg{}, model)
+	if !hammerclock.OptionsChanged(&model) {
+		t.Errorf("Expected the options to be marked as saved only once they were written")
+	}
+	saved, _ := hammerclock.Update(cmd(), model)
+	if hammerclock.OptionsChanged(&saved) || saved.OptionsError != "" {
+		t.Errorf("Expected the options to be saved, got error %q", saved.OptionsError)
+	}
+	if loaded := options.LoadOptions(model.OptionsFile, model.OptionsFile); loaded.TimeFormat != "24-hour" {
+		t.Errorf("Expected the saved file to contain the change, got %q", loaded.TimeFormat)
+	}
+}
+
+// TestRestoreOptionsBackup tests that the options file is restored from its backup by the command returned
+func TestRestoreOptionsBackup(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.OptionsFile = filepath.Join(t.TempDir(), "options.json")
+
+	model, cmd := hammerclock.Update(&common.RestoreOptionsBackupMsg{}, model)
+	model, _ = hammerclock.Update(cmd(), model)
+	if model.OptionsError == "" {
+		t.Error("Expected an error without a backup")
+	}
+
+	for _, format := range []string{"24-hour", "AMPM"} {
+		model, _ = hammerclock.Update(&common.SetTimeFormatMsg{Format: format}, model)
+		model, cmd = hammerclock.Update(&common.SaveOptionsMsg{}, model)
+		model, _ = hammerclock.Update(cmd(), model)
+	}
+	model, cmd = hammerclock.Update(&common.RestoreOptionsBackupMsg{}, model)
+	model, cmd = hammerclock.Update(cmd(), model)
+	if model.Options.TimeFormat != "24-hour" || hammerclock.OptionsChanged(&model) || model.OptionsError != "" {
+		t.Errorf("Expected the backup to be restored, got time format %q", model.Options.TimeFormat)
+	}
+	if _, ok := cmd().(*common.ReloadOptionsScreenMsg); !ok {
+		t.Errorf("Expected restoring to reload the options screen")
+	}
+}
+
+// TestGameTemplates tests saving the game setup as a template and starting a new game from it
+func TestGameTemplates(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Players[0].Name = "Alice"
+	model, _ = hammerclock.Update(&common.SetRulesetMsg{Index: 1}, model)
+	model, _ = hammerclock.Update(&common.SetTimeBudgetMsg{Minutes: 150}, model)
+	model, _ = hammerclock.Update(&common.SetFlagFallMsg{Action: "end"}, model)
+	model, _ = hammerclock.Update(&common.SetByoYomiMsg{Periods: 3, Seconds: 30}, model)
+	model, _ = hammerclock.Update(&common.SaveTemplateMsg{Name: "Tuesday 2000pt"}, model)
+
+	if len(model.Options.Templates) != 1 || model.Options.Templates[0].PlayerNames[0] != "Alice" {
+		t.Fatalf("Expected the template to be saved, got %+v", model.Options.Templates)
+	}
+
+	model, _ = hammerclock.Update(&common.SetRulesetMsg{Index: 0}, model)
+	model, _ = hammerclock.Update(&common.SetTimeBudgetMsg{Minutes: 0}, model)
+	model, _ = hammerclock.Update(&common.SetFlagFallMsg{Action: "continue"}, model)
+	model, _ = hammerclock.Update(&common.SetByoYomiMsg{}, model)
+	model.Players = model.Players[:1]
+	model, _ = hammerclock.Update(&common.ApplyTemplateMsg{Index: 0}, model)
+	if model.Options.TimeBudget != 150 || model.Options.FlagFall != "end" || model.Options.ByoYomiPeriods != 3 {
+		t.Errorf("Expected the clock setup of the template, got %d minutes, flag fall %q and %d byo-yomi periods",
+			model.Options.TimeBudget, model.Options.FlagFall, model.Options.ByoYomiPeriods)
+	}
+
+	if model.Options.Default != 1 || len(model.Players) != 2 || model.Players[0].Name != "Alice" {
+		t.Errorf("Expected the template setup to be applied, got ruleset %d and %d players",
+			model.Options.Default, len(model.Players))
+	}
+
+	// Templates are not applied while a game is running
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model.Players[0].Name = "Bob"
+	model, _ = hammerclock.Update(&common.ApplyTemplateMsg{Index: 0}, model)
+	if model.Players[0].Name != "Bob" {
+		t.Errorf("Expected templates to be ignored during a game")
+	}
+}
+
 // TestScreenNavigation tests navigation between different screens
 func TestScreenNavigation(t *testing.T) {
 	model := hammerclock.NewModel()
@@ -494,3 +626,1488 @@ func TestLoggingToggle(t *testing.T) {
 			initialLoggingState, !initialLoggingState)
 	}
 }
+
+// TestSecondaryObjectivesPrompt tests the end-of-turn secondary objective scoring
+func TestSecondaryObjectivesPrompt(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.PromptSecondaryObjectives = true
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+
+	// Ending the first player's turn should ask for their secondary objectives
+	updatedModel, cmd := hammerclock.Update(&common.SwitchTurnsMsg{}, model)
+	if cmd == nil {
+		t.Fatalf("Expected a command to show the secondary objectives prompt")
+	}
+	showModalMsg, ok := cmd().(*common.ShowModalMsg)
+	if !ok || showModalMsg.Type != "SecondaryObjectives" || showModalMsg.PlayerIndex != 0 {
+		t.Fatalf("Expected SecondaryObjectives modal for player 0, got %+v", showModalMsg)
+	}
+
+	// Scoring should add the victory points to the player
+	updatedModel, _ = hammerclock.Update(&common.ScoreSecondaryObjectivesMsg{PlayerIndex: 0, Scores: []int{3, 0, 2}}, updatedModel)
+	if updatedModel.Players[0].VictoryPoints != 5 {
+		t.Errorf("Expected 5 victory points, got %d", updatedModel.Players[0].VictoryPoints)
+	}
+}
+
+// TestVimBindings tests the vim-style log navigation and command palette
+func TestVimBindings(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.VimBindings = true
+
+	// 'l' moves the log focus to the next player
+	updatedModel, _ := hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'l'}, model)
+	if updatedModel.FocusedLog != 1 {
+		t.Errorf("Expected log focus on player 1, got %d", updatedModel.FocusedLog)
+	}
+
+	// 'gg' scrolls the focused log to the top
+	updatedModel, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'g'}, updatedModel)
+	_, cmd := hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'g'}, updatedModel)
+	scrollMsg, ok := cmd().(*common.ScrollLogMsg)
+	if !ok || !scrollMsg.ToTop || scrollMsg.PlayerIndex != 1 {
+		t.Errorf("Expected ScrollLogMsg to the top of player 1's log, got %+v", scrollMsg)
+	}
+
+	// Commands from the palette are dispatched by name
+	updatedModel, _ = hammerclock.Update(&common.RunCommandMsg{Name: "start"}, updatedModel)
+	if updatedModel.GameStatus != "Game In Progress" {
+		t.Errorf("Expected game status to be 'Game In Progress', got '%s'", updatedModel.GameStatus)
+	}
+}
+
+// TestMacroRecordingAndReplay tests recording game keys into a macro and replaying them
+func TestMacroRecordingAndReplay(t *testing.T) {
+	model := hammerclock.NewModel()
+
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'm'}, model)
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'p'}, model)
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'p'}, model)
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'm'}, model)
+
+	if len(model.Options.Macro) != 2 || model.Players[0].CurrentPhase != 2 {
+		t.Fatalf("Expected 2 recorded keys at phase 2, got %v at phase %d", model.Options.Macro, model.Players[0].CurrentPhase)
+	}
+
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: '@'}, model)
+	if model.Players[0].CurrentPhase != 4 {
+		t.Errorf("Expected replay to advance to phase 4, got %d", model.Players[0].CurrentPhase)
+	}
+}
+
+// TestMacroRecordsBookkeeping tests recording and replaying the bookkeeping steps of a ruleset: the next phase three
+// times, a note, a victory point and a timer
+func TestMacroRecordsBookkeeping(t *testing.T) {
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'm'}, model)
+	for range 3 {
+		model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'p'}, model)
+	}
+	model, cmd := hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'n'}, model)
+	if msg, ok := cmd().(*common.ShowModalMsg); !ok || msg.Type != "AddNote" {
+		t.Fatalf("Expected the note prompt while recording, got %v", msg)
+	}
+	model, _ = hammerclock.Update(&common.AddNoteMsg{Text: "Objective held"}, model)
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: '+'}, model)
+	model, _ = hammerclock.Update(&common.AddTimerMsg{Label: "Reinforcements", Duration: 5 * time.Minute}, model)
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'm'}, model)
+
+	expected := []string{"p", "p", "p", "note:Objective held", "+", "timer:5m0s Reinforcements"}
+	if !slices.Equal(model.Options.Macro, expected) {
+		t.Fatalf("Expected the macro %q, got %q", expected, model.Options.Macro)
+	}
+
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: ' '}, model)
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: '@'}, model)
+	player := model.Players[1]
+	if player.CurrentPhase != 3 || player.VictoryPoints != 1 {
+		t.Errorf("Expected the replay to reach phase 3 with a victory point, got phase %d and %d VP",
+			player.CurrentPhase, player.VictoryPoints)
+	}
+	notes := slices.IndexFunc(player.ActionLog, func(entry common.LogEntry) bool { return entry.Message == "Note: Objective held" })
+	if notes < 0 {
+		t.Errorf("Expected the note in the second player's log, got %+v", player.ActionLog)
+	}
+	if len(model.Timers) != 2 || model.Timers[1].Label != "Reinforcements" || model.Timers[1].Remaining != 5*time.Minute {
+		t.Errorf("Expected the replayed timer, got %+v", model.Timers)
+	}
+}
+
+// TestMacroReplayRunsCommands tests that the commands of replayed keys are run, e.g. to ask for the secondary
+// objectives at the end of a replayed turn
+func TestMacroReplayRunsCommands(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.Rules = slices.Clone(model.Options.Rules)
+	model.Options.Rules[model.Options.Default].SecondaryObjectives = []string{"Engage on All Fronts"}
+	model.Options.PromptSecondaryObjectives = true
+	model.Options.Macro = []string{"p", "SPACE"}
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+
+	model, cmd := hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: '@'}, model)
+	batch, ok := cmd().(*common.BatchMsg)
+	if !ok || len(batch.Msgs) != 1 {
+		t.Fatalf("Expected the messages of the replayed keys, got %#v", batch)
+	}
+	if msg, ok := batch.Msgs[0].(*common.ShowModalMsg); !ok || msg.Type != "SecondaryObjectives" || msg.PlayerIndex != 0 {
+		t.Errorf("Expected the secondary objectives of the first player to be asked for, got %#v", batch.Msgs[0])
+	}
+	if !model.Players[1].IsTurn {
+		t.Error("Expected the replayed keys to switch turns")
+	}
+}
+
+// TestRevertTurnSwitch tests reverting an accidental turn switch
+func TestRevertTurnSwitch(t *testing.T) {
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, _ = hammerclock.Update(&common.SwitchTurnsMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'r'}, model)
+
+	if !model.Players[0].IsTurn || model.Players[1].IsTurn {
+		t.Fatalf("Expected the first player to be active again after reverting")
+	}
+	if model.Players[0].TimeElapsed != 3*time.Second || model.Players[1].TimeElapsed != 0 {
+		t.Errorf("Expected the elapsed seconds to be credited back, got %v and %v",
+			model.Players[0].TimeElapsed, model.Players[1].TimeElapsed)
+	}
+}
+
+// TestTurnDurations tests that the durations of completed turns are recorded for the sparkline of each player
+func TestTurnDurations(t *testing.T) {
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, _ = hammerclock.Update(&common.SwitchTurnsMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, _ = hammerclock.Update(&common.SwitchTurnsMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, _ = hammerclock.Update(&common.SwitchTurnsMsg{}, model)
+
+	first, second := model.Players[0].TurnDurations, model.Players[1].TurnDurations
+	if !slices.Equal(first, []time.Duration{2 * time.Second, time.Second}) || !slices.Equal(second, []time.Duration{time.Second}) {
+		t.Errorf("Expected turns of 2s and 1s and a turn of 1s, got %v and %v", first, second)
+	}
+	if line := ui.Sparkline(first, 12); line != "█▄" {
+		t.Errorf("Expected the sparkline to scale to the longest turn, got %q", line)
+	}
+}
+
+// TestArmyListSavedWithProfile tests that an edited army list is saved with the player's profile and loaded for the
+// player in the next game
+func TestArmyListSavedWithProfile(t *testing.T) {
+	model := hammerclock.NewModel()
+	units := []common.Unit{{Name: "Intercessors", Points: 200}, {Name: "Captain", Points: 80}}
+	model, _ = hammerclock.Update(&common.SetArmyListMsg{PlayerIndex: 1, Units: units}, model)
+
+	if !slices.Equal(model.Players[1].ArmyList, units) {
+		t.Errorf("Expected the army list of the player to be set, got %v", model.Players[1].ArmyList)
+	}
+	name := model.Players[1].Name
+	if army := hammerclock.ProfileArmy(model.Options, name); !slices.Equal(army, units) {
+		t.Errorf("Expected the army list to be saved with the profile of %s, got %v", name, army)
+	}
+	if len(model.Players[0].ArmyList) != 0 || hammerclock.ProfileArmy(model.Options, model.Players[0].Name) != nil {
+		t.Error("Expected the other player to keep no army list")
+	}
+
+	model.Players[1].ArmyList[0].Status = "destroyed"
+	model, _ = hammerclock.Update(&common.SetArmyListMsg{PlayerIndex: 1, Units: units}, model)
+	if status := model.Players[1].ArmyList[0].Status; status != "destroyed" {
+		t.Errorf("Expected a unit kept in the list to keep its status, got %q", status)
+	}
+}
+
+// TestBattleScribeRosterImported tests that a BattleScribe roster becomes the player's army list and faction, and
+// that a roster that cannot be read is logged
+func TestBattleScribeRosterImported(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "list.ros")
+	roster := `<roster name="List"><forces><force catalogueName="Imperium - Space Marines"><selections>
+<selection name="Captain" type="model"><costs><cost name="pts" value="80.0"/></costs></selection>
+</selections></force></forces></roster>`
+	if err := os.WriteFile(file, []byte(roster), 0644); err != nil {
+		t.Fatalf("Failed to write the roster: %v", err)
+	}
+
+	model := hammerclock.NewModel()
+	model, cmd := hammerclock.Update(&common.ImportRosterMsg{PlayerIndex: 1, File: file}, model)
+	if cmd == nil {
+		t.Fatal("Expected the roster to be read")
+	}
+	model, _ = hammerclock.Update(cmd(), model)
+	player := model.Players[1]
+	if !slices.Equal(player.ArmyList, []common.Unit{{Name: "Captain", Points: 80}}) || player.Faction != "Imperium - Space Marines" {
+		t.Errorf("Expected the roster's army list and faction, got %v and %q", player.ArmyList, player.Faction)
+	}
+
+	model, cmd = hammerclock.Update(&common.ImportRosterMsg{PlayerIndex: 0, File: file + "z"}, model)
+	model, _ = hammerclock.Update(cmd(), model)
+	log := model.Players[0].ActionLog
+	if len(log) == 0 || log[len(log)-1].Type != common.LogTypeWarning || len(model.Players[0].ArmyList) != 0 {
+		t.Errorf("Expected a warning for the missing roster, got %v", log)
+	}
+}
+
+// TestVictoryPointHotkeys tests that + and - change the victory points of the active player during the game, logged
+// with the turn and phase
+func TestVictoryPointHotkeys(t *testing.T) {
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: '+'}, model)
+	if model.Players[0].VictoryPoints != 0 {
+		t.Fatalf("Expected no victory points before the game, got %d", model.Players[0].VictoryPoints)
+	}
+
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 's'}, model)
+	for _, key := range "+++-" {
+		model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: key}, model)
+	}
+	if model.Players[0].VictoryPoints != 2 || model.Players[1].VictoryPoints != 0 {
+		t.Errorf("Expected 2 VP for the active player, got %d and %d",
+			model.Players[0].VictoryPoints, model.Players[1].VictoryPoints)
+	}
+	last := model.Players[0].ActionLog[len(model.Players[0].ActionLog)-1]
+	expected := fmt.Sprintf("Lost 1 VP in turn %d, %s (total 2 VP)", last.Turn, last.Phase)
+	if last.Message != expected || last.Type != common.LogTypeScore || last.Phase == "" {
+		t.Errorf("Expected %q, got %+v", expected, last)
+	}
+
+	model.Options.ActionPIN = "1234"
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: '+'}, model)
+	if model.Players[0].VictoryPoints != 2 {
+		t.Errorf("Expected the hotkeys to be disabled with an action PIN, got %d VP", model.Players[0].VictoryPoints)
+	}
+}
+
+// TestUnitWoundsLogged tests that the damage and healing of a unit are kept within its wounds and logged
+func TestUnitWoundsLogged(t *testing.T) {
+	model := hammerclock.NewModel()
+	units := []common.Unit{{Name: "Captain", Points: 80, Wounds: 5}, {Name: "Intercessors", Points: 200}}
+	model, _ = hammerclock.Update(&common.SetArmyListMsg{PlayerIndex: 0, Units: units}, model)
+
+	model, _ = hammerclock.Update(&common.SetUnitWoundsMsg{PlayerIndex: 0, Unit: 0, WoundsLost: 3}, model)
+	model, _ = hammerclock.Update(&common.SetUnitWoundsMsg{PlayerIndex: 0, Unit: 0, WoundsLost: 9}, model)
+	model, _ = hammerclock.Update(&common.SetUnitWoundsMsg{PlayerIndex: 0, Unit: 0, WoundsLost: 4}, model)
+	model, _ = hammerclock.Update(&common.SetUnitWoundsMsg{PlayerIndex: 0, Unit: 1, WoundsLost: 1}, model)
+
+	if lost := model.Players[0].ArmyList[0].WoundsLost; lost != 4 {
+		t.Errorf("Expected the captain to have lost 4 wounds, got %d", lost)
+	}
+	if lost := model.Players[0].ArmyList[1].WoundsLost; lost != 0 {
+		t.Errorf("Expected a unit without wounds to take no damage, got %d", lost)
+	}
+	var messages []string
+	for _, entry := range model.Players[0].ActionLog[1:] {
+		messages = append(messages, entry.Message)
+	}
+	expected := []string{"Captain lost 3 W (2/5 W left)", "Captain lost 2 W (0/5 W left)", "Captain healed 1 W (1/5 W left)"}
+	if !slices.Equal(messages, expected) {
+		t.Errorf("Expected the log %q, got %q", expected, messages)
+	}
+}
+
+// TestArmyPointsCheckedAtGameStart tests that army lists over the game size, or well under it, are warned about when
+// the game starts
+func TestArmyPointsCheckedAtGameStart(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.GameSize = 2000
+	model.Players[0].ArmyList = []common.Unit{{Name: "Knight", Points: 1500}, {Name: "Armigers", Points: 600}}
+	model.Players[1].ArmyList = []common.Unit{{Name: "Boyz", Points: 1950}}
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	warnings := func(player *common.Player) int {
+		count := 0
+		for _, entry := range player.ActionLog {
+			if entry.Type == common.LogTypeWarning {
+				count++
+			}
+		}
+		return count
+	}
+
+	if warnings(model.Players[0]) != 1 {
+		t.Errorf("Expected a warning about the overspend, got %v", model.Players[0].ActionLog)
+	}
+	if warnings(model.Players[1]) != 0 {
+		t.Errorf("Expected no warning for a list within 10%% of the game size, got %v", model.Players[1].ActionLog)
+	}
+
+	model = hammerclock.NewModel()
+	model.Options.GameSize = 2000
+	model.Players[0].ArmyList = []common.Unit{{Name: "Boyz", Points: 1000}}
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	if model.Warnings != 1 {
+		t.Errorf("Expected a warning about the underspend only, got %v", model.Players[0].ActionLog)
+	}
+}
+
+// TestUnitStatusLogged tests that marking a unit is logged for the post-game review
+func TestUnitStatusLogged(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Players[0].ArmyList = []common.Unit{{Name: "Intercessors", Points: 200}}
+	before := model.Players[0].ArmyList
+	model, _ = hammerclock.Update(&common.SetUnitStatusMsg{PlayerIndex: 0, Unit: 0, Status: "destroyed"}, model)
+	model, _ = hammerclock.Update(&common.SetUnitStatusMsg{PlayerIndex: 0, Unit: 0, Status: ""}, model)
+	model, _ = hammerclock.Update(&common.SetUnitStatusMsg{PlayerIndex: 0, Unit: 3, Status: "shaken"}, model)
+
+	log := model.Players[0].ActionLog
+	if len(log) != 2 || log[0].Message != "Intercessors destroyed" || log[1].Message != "Intercessors no longer destroyed" {
+		t.Errorf("Expected the status changes to be logged, got %v", log)
+	}
+	if log[0].DateTime == "" {
+		t.Error("Expected the status changes to be timestamped")
+	}
+	if before[0].Status != "" {
+		t.Error("Expected the army list of the previous model to be left unchanged")
+	}
+}
+
+// TestUnitNoteLogged tests that the notes of units are set and logged, and kept when the army list is edited
+func TestUnitNoteLogged(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Players[0].ArmyList = []common.Unit{{Name: "Captain", Points: 80}}
+	model, _ = hammerclock.Update(&common.SetUnitNoteMsg{PlayerIndex: 0, Unit: 0, Note: "3 wounds left"}, model)
+	model, _ = hammerclock.Update(&common.SetArmyListMsg{PlayerIndex: 0, Units: []common.Unit{{Name: "Captain", Points: 90}}}, model)
+
+	if note := model.Players[0].ArmyList[0].Note; note != "3 wounds left" {
+		t.Errorf("Expected the note to be kept, got %q", note)
+	}
+	model, _ = hammerclock.Update(&common.SetUnitNoteMsg{PlayerIndex: 0, Unit: 0}, model)
+	log := model.Players[0].ActionLog
+	if len(log) != 3 || log[0].Message != "Note on Captain: 3 wounds left" || log[2].Message != "Note on Captain removed" {
+		t.Errorf("Expected the notes to be logged, got %v", log)
+	}
+}
+
+// TestCasualtySummaryAtGameEnd tests that the casualties of each army are summarized when the game ends, and the
+// units are fine again for the next game
+func TestCasualtySummaryAtGameEnd(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Players[0].ArmyList = []common.Unit{
+		{Name: "Intercessors", Points: 200, Status: "destroyed"},
+		{Name: "Captain", Points: 80, Status: "shaken", Note: "warlord"},
+	}
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.EndGameMsg{}, model)
+
+	log := model.Players[0].ActionLog
+	if last := log[len(log)-1].Message; last != "Casualties - 1 of 2 units destroyed, 200 of 280 pts lost" {
+		t.Errorf("Expected the casualties in the summary, got %q", last)
+	}
+	for _, entry := range model.Players[1].ActionLog {
+		if strings.HasPrefix(entry.Message, "Casualties") {
+			t.Error("Expected no casualties for a player without an army list")
+		}
+	}
+	if units := model.Players[0].ArmyList; units[0].Status != "" || units[1].Note != "" || units[1].Points != 80 {
+		t.Errorf("Expected the units to be reset for the next game, got %v", units)
+	}
+}
+
+// TestUnitDestroyedBy tests that the unit that destroyed a unit is recorded only while the unit is destroyed
+func TestUnitDestroyedBy(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Players[0].ArmyList = []common.Unit{{Name: "Intercessors", Points: 200}}
+	killed := &common.SetUnitDestroyedByMsg{PlayerIndex: 0, Unit: 0, DestroyedBy: "Player 2: Boyz"}
+	model, _ = hammerclock.Update(killed, model)
+	if model.Players[0].ArmyList[0].DestroyedBy != "" {
+		t.Error("Expected no attribution for a unit that is not destroyed")
+	}
+
+	model, _ = hammerclock.Update(&common.SetUnitStatusMsg{PlayerIndex: 0, Unit: 0, Status: "destroyed"}, model)
+	model, _ = hammerclock.Update(killed, model)
+	log := model.Players[0].ActionLog
+	if model.Players[0].ArmyList[0].DestroyedBy != "Player 2: Boyz" || log[len(log)-1].Message != "Intercessors destroyed by Player 2: Boyz" {
+		t.Errorf("Expected the attribution to be recorded and logged, got %v", log)
+	}
+
+	model, _ = hammerclock.Update(&common.SetUnitStatusMsg{PlayerIndex: 0, Unit: 0, Status: ""}, model)
+	if model.Players[0].ArmyList[0].DestroyedBy != "" {
+		t.Error("Expected the attribution to be cleared with the status")
+	}
+}
+
+// TestKillPointsScored tests that destroying a unit scores the victory points of its bracket for the opponent, and
+// that they are taken back when the unit is no longer destroyed or moved to the player of the recorded unit
+func TestKillPointsScored(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.Rules = []rules.Rules{{Name: "Kill Team", Phases: []string{"Firefight"}, KillPoints: []rules.KillBracket{
+		{MinPoints: 0, VictoryPoints: 1},
+		{MinPoints: 100, VictoryPoints: 2},
+	}}}
+	model.Options.Default = 0
+	model.Players[0].ArmyList = []common.Unit{{Name: "Intercessors", Points: 200}, {Name: "Scouts", Points: 70}}
+
+	destroy := func(unit int, status string) {
+		model, _ = hammerclock.Update(&common.SetUnitStatusMsg{PlayerIndex: 0, Unit: unit, Status: status}, model)
+	}
+	destroy(0, "destroyed")
+	destroy(1, "destroyed")
+	if vp := model.Players[1].VictoryPoints; vp != 3 {
+		t.Errorf("Expected 2 VP and 1 VP for the opponent, got %d", vp)
+	}
+	destroy(1, "")
+	if vp := model.Players[1].VictoryPoints; vp != 2 {
+		t.Errorf("Expected the VP to be taken back, got %d", vp)
+	}
+
+	model.Players = append(model.Players, &common.Player{Name: "Player 3"})
+	model, _ = hammerclock.Update(&common.SetUnitDestroyedByMsg{PlayerIndex: 0, Unit: 0, DestroyedBy: "Player 3: Boyz"}, model)
+	if model.Players[1].VictoryPoints != 0 || model.Players[2].VictoryPoints != 2 {
+		t.Errorf("Expected the VP to move to the player of the recorded unit, got %d and %d",
+			model.Players[1].VictoryPoints, model.Players[2].VictoryPoints)
+	}
+	if model.Players[0].VictoryPoints != 0 {
+		t.Error("Expected no VP for the player who lost the unit")
+	}
+}
+
+// TestDemoPlaysItself tests that the demo game advances phases and turns by itself and starts over after the last
+// battle round
+func TestDemoPlaysItself(t *testing.T) {
+	model := hammerclock.NewModel()
+	hammerclock.SetupDemo(&model)
+	if !model.GameStarted || model.Options.LoggingEnabled || len(model.Players[0].ArmyList) == 0 {
+		t.Fatal("Expected the demo game to start with army lists and without logging")
+	}
+
+	now := time.Now()
+	highestRound, restarted := 0, false
+	for i := 0; i < 2000 && !restarted; i++ {
+		now = now.Add(time.Second)
+		model, _ = hammerclock.Update(&common.TickMsg{Time: now}, model)
+		round := hammerclock.BattleRound(&model)
+		restarted = round < highestRound
+		highestRound = max(highestRound, round)
+	}
+
+	if highestRound <= 5 || !restarted {
+		t.Errorf("Expected the demo game to play 5 battle rounds and start over, got to round %d", highestRound)
+	}
+	if !model.GameStarted || model.Players[0].VictoryPoints != 0 {
+		t.Error("Expected the demo game to start over from scratch")
+	}
+}
+
+func TestScheduledStartCountsDown(t *testing.T) {
+	model := hammerclock.NewModel()
+	now := time.Date(2025, 6, 1, 13, 58, 0, 0, time.Local)
+	at, ok := hammerclock.ParseStartTime("14:00", now)
+	if !ok || !at.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("Expected the start at 14:00, got %v", at)
+	}
+	model, _ = hammerclock.Update(&common.ScheduleStartMsg{At: at}, model)
+
+	model, _ = hammerclock.Update(&common.TickMsg{Time: now.Add(time.Minute)}, model)
+	if model.GameStarted {
+		t.Fatal("Expected the game to wait for the scheduled start")
+	}
+	model, cmd := hammerclock.Update(&common.TickMsg{Time: at}, model)
+	if !model.GameStarted || !model.Players[0].IsTurn || !model.StartsAt.IsZero() {
+		t.Fatal("Expected the first player's clock to start at the scheduled start")
+	}
+	if _, ok := cmd().(*common.BellMsg); !ok {
+		t.Error("Expected the bell to ring at the scheduled start")
+	}
+}
+
+func TestAutosaveContinuesGame(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.AutosaveFile = filepath.Join(t.TempDir(), hammerclock.AutosaveFileName)
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 's'}, model)
+	model.Players[0].TimeElapsed = 5 * time.Minute
+	model.Players[0].VictoryPoints = 12
+	model.Players[0].TurnCount = 2
+	if err := hammerclock.Autosave(model.AutosaveFile, &model, time.Now()); err != nil {
+		t.Fatalf("Failed to autosave the game: %v", err)
+	}
+
+	saved, err := hammerclock.LoadAutosave(model.AutosaveFile)
+	if err != nil {
+		t.Fatalf("Failed to load the autosave: %v", err)
+	}
+	fresh := hammerclock.NewModel()
+	fresh.AutosaveFile = model.AutosaveFile
+	fresh, cmd := hammerclock.Update(&common.OfferSavedGameMsg{Game: saved}, fresh)
+	if msg, ok := cmd().(*common.ShowModalMsg); !ok || msg.Type != "SavedGame" {
+		t.Fatalf("Expected the saved game to be offered, got %v", msg)
+	}
+
+	resumed, _ := hammerclock.Update(&common.ResumeSavedGameMsg{Continue: true}, fresh)
+	player := resumed.Players[0]
+	if !resumed.GameStarted || resumed.GameStatus != "Game Paused" || player.TimeElapsed != 5*time.Minute ||
+		player.VictoryPoints != 12 || !player.IsTurn {
+		t.Errorf("Expected the saved game to continue paused, got %s with %+v", resumed.GameStatus, *player)
+	}
+
+	discarded, cmd := hammerclock.Update(&common.ResumeSavedGameMsg{Continue: false}, fresh)
+	if discarded.GameStarted || discarded.SavedGame != nil {
+		t.Error("Expected a fresh game")
+	}
+	cmd()
+	if _, err := os.Stat(model.AutosaveFile); !os.IsNotExist(err) {
+		t.Error("Expected the autosave to be removed when starting fresh")
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	undo := &common.KeyPressMsg{Key: tcell.KeyCtrlZ}
+	redo := &common.KeyPressMsg{Key: tcell.KeyCtrlR}
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.SwitchTurnsMsg{}, model)
+	model, _ = hammerclock.Update(&common.NextPhaseMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, _ = hammerclock.Update(&common.EndGameMsg{}, model)
+
+	// An accidental end of the game is undone with the state of the game before it
+	model, _ = hammerclock.Update(undo, model)
+	if !model.GameStarted || !model.Players[1].IsTurn || model.Players[1].CurrentPhase != 1 ||
+		model.Players[1].TimeElapsed != time.Second {
+		t.Fatalf("Expected the game to be back in the second player's turn, got %+v", *model.Players[1])
+	}
+
+	// The time since the phase change stays with the second player when it is undone
+	model, _ = hammerclock.Update(undo, model)
+	if model.Players[1].CurrentPhase != 0 || model.Players[1].TimeElapsed != time.Second {
+		t.Errorf("Expected the phase change to be undone, got %+v", *model.Players[1])
+	}
+	model, _ = hammerclock.Update(undo, model)
+	if !model.Players[0].IsTurn || model.Players[0].TimeElapsed != time.Second {
+		t.Errorf("Expected the turn switch to be undone, got %+v", *model.Players[0])
+	}
+	log := model.Players[0].ActionLog
+	if log[len(log)-1].Message != "Undid the turn switch" {
+		t.Errorf("Expected the undo to be logged, got %+v", log[len(log)-1])
+	}
+
+	model, _ = hammerclock.Update(redo, model)
+	if !model.Players[1].IsTurn || len(model.Redo) != 2 {
+		t.Errorf("Expected the turn switch to be redone, with two actions left to redo")
+	}
+
+	// The command palette undoes and redoes too, while other changes are not part of the history
+	model, _ = hammerclock.Update(&common.RunCommandMsg{Name: "undo"}, model)
+	model, _ = hammerclock.Update(&common.RunCommandMsg{Name: "redo"}, model)
+	model, _ = hammerclock.Update(&common.AddNoteMsg{Text: "Objective held"}, model)
+	if !model.Players[1].IsTurn || len(model.Redo) != 2 || len(model.History) != 1 {
+		t.Errorf("Expected the palette to undo and redo the turn switch and the note to stay out of the history, "+
+			"got %d to redo and %d to undo", len(model.Redo), len(model.History))
+	}
+
+	// A new action cannot be followed by a redo
+	model, _ = hammerclock.Update(&common.NextPhaseMsg{}, model)
+	model, _ = hammerclock.Update(redo, model)
+	if len(model.Redo) != 0 || len(model.History) != 2 || model.Players[1].CurrentPhase != 1 {
+		t.Errorf("Expected the redo to be forgotten, got %d to redo and %d to undo", len(model.Redo), len(model.History))
+	}
+
+	// The history is kept in the saved game
+	model.AutosaveFile = filepath.Join(t.TempDir(), hammerclock.AutosaveFileName)
+	if err := hammerclock.Autosave(model.AutosaveFile, &model, time.Now()); err != nil {
+		t.Fatalf("Failed to save the game: %v", err)
+	}
+	saved, _ := hammerclock.LoadAutosave(model.AutosaveFile)
+	fresh, _ := hammerclock.Update(&common.OfferSavedGameMsg{Game: saved}, hammerclock.NewModel())
+	fresh, _ = hammerclock.Update(&common.ResumeSavedGameMsg{Continue: true}, fresh)
+	fresh, _ = hammerclock.Update(undo, fresh)
+	if fresh.Players[1].CurrentPhase != 0 {
+		t.Errorf("Expected the phase change to be undone after loading the game, got %+v", *fresh.Players[1])
+	}
+}
+
+func TestMarkRunningDetectsUncleanShutdown(t *testing.T) {
+	running := filepath.Join(t.TempDir(), hammerclock.RunningFileName)
+	if interrupted, err := hammerclock.MarkRunning(running); err != nil || interrupted {
+		t.Fatalf("Expected a clean first start, got %v, %v", interrupted, err)
+	}
+	// The marker of the first run was not removed, as after a crash
+	if interrupted, _ := hammerclock.MarkRunning(running); !interrupted {
+		t.Error("Expected the unclean shutdown to be detected")
+	}
+	if err := os.Remove(running); err != nil {
+		t.Fatalf("Failed to remove the marker: %v", err)
+	}
+	if interrupted, _ := hammerclock.MarkRunning(running); interrupted {
+		t.Error("Expected a clean start after the marker was removed")
+	}
+}
+
+func TestSaveGameOnDemand(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.SaveFile = filepath.Join(t.TempDir(), hammerclock.SaveFileName)
+	model, cmd := hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyCtrlS}, model)
+	if cmd() != nil {
+		t.Fatal("Expected a game that has not started not to be saved")
+	}
+
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.NextPhaseMsg{}, model)
+	model.Players[0].TimeElapsed = 3 * time.Minute
+	model, cmd = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyCtrlS}, model)
+	model, _ = hammerclock.Update(cmd(), model)
+	log := model.Players[0].ActionLog
+	if log[len(log)-1].Message != "Game saved to "+model.SaveFile {
+		t.Errorf("Expected the save to be logged, got %+v", log[len(log)-1])
+	}
+
+	saved, err := hammerclock.LoadAutosave(model.SaveFile)
+	if err != nil {
+		t.Fatalf("Failed to load the saved game: %v", err)
+	}
+	fresh := hammerclock.NewModel()
+	fresh.SaveFile = model.SaveFile
+	fresh, _ = hammerclock.Update(&common.OfferSavedGameMsg{Game: saved}, fresh)
+	resumed, _ := hammerclock.Update(&common.ResumeSavedGameMsg{Continue: true}, fresh)
+	player := resumed.Players[0]
+	if player.TimeElapsed != 3*time.Minute || player.CurrentPhase != 1 || len(player.ActionLog) != len(log) {
+		t.Errorf("Expected the game to continue with its clocks, phases and logs, got %+v", *player)
+	}
+
+	fresh.AutosaveFile = filepath.Join(t.TempDir(), hammerclock.AutosaveFileName)
+	_, cmd = hammerclock.Update(&common.ResumeSavedGameMsg{Continue: false}, fresh)
+	cmd()
+	if _, err := os.Stat(model.SaveFile); !os.IsNotExist(err) {
+		t.Error("Expected the saved game to be removed when starting fresh")
+	}
+}
+
+func TestPhaseNamesInLocale(t *testing.T) {
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.SetLocaleMsg{Locale: "de_AT"}, model)
+	if model.Phases[1] != "Bewegungsphase" {
+		t.Errorf("Expected the German phase names for de_AT, got %v", model.Phases)
+	}
+
+	model, _ = hammerclock.Update(&common.SetLocaleMsg{Locale: "fr"}, model)
+	if model.Phases[1] != "Movement Phase" {
+		t.Errorf("Expected the phase names as defined without a translation, got %v", model.Phases)
+	}
+}
+
+// TestWarningCount tests that only warnings are counted, which decides when the taskbar is flashed
+func TestWarningCount(t *testing.T) {
+	model := hammerclock.NewModel()
+	logging.AddLogEntry(model.Players[0], &model, common.LogTypeTurn, "Turn started")
+	logging.AddLogEntry(model.Players[0], &model, common.LogTypeWarning, "Time is running out")
+	logging.AddLogEntry(model.Players[1], &model, common.LogTypeWarning, "Time is running out")
+
+	if model.Warnings != 2 {
+		t.Errorf("Expected 2 warnings, got %d", model.Warnings)
+	}
+}
+
+// TestPauseOnModal tests that the clocks are paused while a dialog is open and resumed afterwards
+func TestPauseOnModal(t *testing.T) {
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+
+	model, _ = hammerclock.Update(&common.ModalOpenedMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	if model.GameStatus != "Game Paused" || model.Players[0].TimeElapsed != 0 {
+		t.Fatalf("Expected the clocks to be paused while a dialog is open, got %q and %v",
+			model.GameStatus, model.Players[0].TimeElapsed)
+	}
+
+	model, _ = hammerclock.Update(&common.ModalClosedMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	if model.Players[0].TimeElapsed != time.Second {
+		t.Errorf("Expected the clocks to resume after the dialog was closed, got %v", model.Players[0].TimeElapsed)
+	}
+
+	// A game paused by the players stays paused
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.ModalOpenedMsg{}, model)
+	model, _ = hammerclock.Update(&common.ModalClosedMsg{}, model)
+	if model.GameStatus != "Game Paused" {
+		t.Errorf("Expected the game to stay paused, got %q", model.GameStatus)
+	}
+}
+
+// TestPauseOnFocusLoss tests that the game is paused when the terminal loses the focus and a resume prompt is shown
+// once it is back
+func TestPauseOnFocusLoss(t *testing.T) {
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+
+	// Disabled by default
+	model, _ = hammerclock.Update(&common.FocusChangedMsg{Focused: false}, model)
+	if model.GameStatus != "Game In Progress" {
+		t.Fatalf("Expected the game to keep running, got %q", model.GameStatus)
+	}
+
+	model, _ = hammerclock.Update(&common.SetPauseOnFocusLossMsg{Value: true}, model)
+	model, _ = hammerclock.Update(&common.FocusChangedMsg{Focused: false}, model)
+	if model.GameStatus != "Game Paused" {
+		t.Fatalf("Expected the game to be paused, got %q", model.GameStatus)
+	}
+
+	model, cmd := hammerclock.Update(&common.FocusChangedMsg{Focused: true}, model)
+	if msg, ok := cmd().(*common.ShowModalMsg); !ok || msg.Type != "ResumeConfirm" {
+		t.Errorf("Expected the resume prompt, got %v", msg)
+	}
+	if model.GameStatus != "Game Paused" {
+		t.Errorf("Expected the game to stay paused until resumed, got %q", model.GameStatus)
+	}
+}
+
+// TestSuspendDetection tests that a large gap between ticks pauses the game and the suspended time can be added
+func TestSuspendDetection(t *testing.T) {
+	start := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{Time: start}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{Time: start.Add(time.Second)}, model)
+
+	model, cmd := hammerclock.Update(&common.TickMsg{Time: start.Add(18*time.Minute + 2*time.Second)}, model)
+	if model.GameStatus != "Game Paused" || model.SuspendedFor != 18*time.Minute+time.Second {
+		t.Fatalf("Expected the game to be paused with 18m1s suspended, got %q and %v", model.GameStatus, model.SuspendedFor)
+	}
+	if msg, ok := cmd().(*common.ShowModalMsg); !ok || msg.Type != "SuspendResolve" {
+		t.Errorf("Expected the suspend prompt, got %v", msg)
+	}
+
+	model, _ = hammerclock.Update(&common.ResolveSuspendMsg{Action: "add"}, model)
+	if model.GameStatus != "Game In Progress" {
+		t.Errorf("Expected the game to resume, got %q", model.GameStatus)
+	}
+	if want := 18*time.Minute + 3*time.Second; model.Players[0].TimeElapsed != want {
+		t.Errorf("Expected %v on the active player's clock, got %v", want, model.Players[0].TimeElapsed)
+	}
+}
+
+// TestTickIntervalIndependence tests that the elapsed time is measured between ticks, whatever the tick interval
+func TestTickIntervalIndependence(t *testing.T) {
+	start := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.SetTickIntervalMsg{Milliseconds: 250}, model)
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+
+	// The first tick counts as one tick interval, the others as the time since the previous tick
+	for i := 0; i < 5; i++ {
+		model, _ = hammerclock.Update(&common.TickMsg{Time: start.Add(time.Duration(i) * 250 * time.Millisecond)}, model)
+	}
+
+	if model.Players[0].TimeElapsed != 1250*time.Millisecond {
+		t.Errorf("Expected 1.25s elapsed, got %v", model.Players[0].TimeElapsed)
+	}
+}
+
+// TestLinkedClientMirrorsHost tests that a linked client takes over the host's state and ignores game keys
+func TestLinkedClientMirrorsHost(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Linked = true
+
+	model, _ = hammerclock.Update(&common.LinkStateMsg{
+		Status:      "Game In Progress",
+		GameStarted: true,
+		Players: []common.LinkedPlayer{
+			{Name: "Alice", TimeElapsed: 90 * time.Second},
+			{Name: "Bob", TimeElapsed: 30 * time.Second, IsTurn: true},
+		},
+	}, model)
+	if model.Players[0].Name != "Alice" || !model.Players[1].IsTurn || model.Players[1].TimeElapsed != 30*time.Second {
+		t.Fatalf("Expected the host's players, got %+v and %+v", model.Players[0], model.Players[1])
+	}
+
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: ' '}, model)
+	if !model.Players[1].IsTurn {
+		t.Errorf("Expected the client to ignore switching turns")
+	}
+}
+
+// TestFlagFall tests that a player who runs out of time is flagged and the configured action is taken
+func TestFlagFall(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.TimeBudget = 1
+	model.Options.FlagFall = "pause"
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model.Players[0].TimeElapsed = time.Minute - time.Second
+
+	model, cmd := hammerclock.Update(&common.TickMsg{}, model)
+	if !model.Players[0].Flagged {
+		t.Fatal("Expected the player to be flagged")
+	}
+	if model.GameStatus != "Game Paused" {
+		t.Errorf("Expected the game to be paused, got %q", model.GameStatus)
+	}
+	if _, ok := cmd().(*common.BellMsg); !ok {
+		t.Errorf("Expected the bell to ring")
+	}
+
+	// Without an action, the clock keeps counting into negative time
+	model.Options.FlagFall = "continue"
+	model.Players[1].TimeElapsed = time.Minute - time.Second
+	model, _ = hammerclock.Update(&common.SwitchTurnsMsg{}, model)
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	if !model.Players[1].Flagged || model.GameStatus != "Game In Progress" {
+		t.Errorf("Expected the second player to be flagged with the game running, got %v and %q",
+			model.Players[1].Flagged, model.GameStatus)
+	}
+	if model.Players[1].TimeElapsed != time.Minute+time.Second {
+		t.Errorf("Expected the clock to keep counting, got %v", model.Players[1].TimeElapsed)
+	}
+}
+
+// TestFlagFallGameOver tests that a player running out of time loses the game when the flag fall ends it
+func TestFlagFallGameOver(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.TimeBudget = 1
+	model.Options.FlagFall = "over"
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model.Players[0].TimeElapsed = time.Minute - time.Second
+
+	model, cmd := hammerclock.Update(&common.TickMsg{}, model)
+	if model.GameStatus != "Game Over" {
+		t.Fatalf("Expected the game to be over, got %q", model.GameStatus)
+	}
+	if msg, ok := cmd().(*common.ShowModalMsg); !ok || msg.Type != "GameOver" || msg.PlayerIndex != 0 {
+		t.Errorf("Expected the game over dialog for the first player, got %+v", msg)
+	}
+	log := model.Players[1].ActionLog
+	if len(log) == 0 || log[len(log)-1].Message != "Game over - Player 1 lost on time, Player 2 wins" {
+		t.Errorf("Expected the result in the log, got %+v", log)
+	}
+
+	// The clocks stay stopped and the game can only be ended
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.SwitchTurnsMsg{}, model)
+	if model.Players[0].TimeElapsed != time.Minute || model.GameStatus != "Game Over" || !model.Players[0].IsTurn {
+		t.Errorf("Expected the game to stay over, got %v and %q", model.Players[0].TimeElapsed, model.GameStatus)
+	}
+	model, _ = hammerclock.Update(&common.EndGameMsg{}, model)
+	if model.GameStarted || model.GameStatus != "Game Not Started" {
+		t.Errorf("Expected the game to end, got %q", model.GameStatus)
+	}
+}
+
+// TestByoYomiPeriods tests that a player who used up the time budget plays on in byo-yomi periods, which are kept by
+// ending the turn in time, and that the flag falls after the last period
+func TestByoYomiPeriods(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.TimeBudget = 1
+	model.Options.ByoYomiPeriods = 2
+	model.Options.ByoYomiSeconds = 2
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model.Players[0].TimeElapsed = time.Minute - time.Second
+
+	// The budget runs out and the first period starts
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	player := model.Players[0]
+	if player.Flagged || player.ByoYomiPeriod != 1 || player.PeriodLeft != time.Second {
+		t.Fatalf("Expected 1s left in the first period, got %+v", player)
+	}
+
+	// Ending the turn within the period resets it
+	model, _ = hammerclock.Update(&common.SwitchTurnsMsg{}, model)
+	model, _ = hammerclock.Update(&common.SwitchTurnsMsg{}, model)
+	if player := model.Players[0]; player.ByoYomiPeriod != 1 || player.PeriodLeft != 2*time.Second {
+		t.Errorf("Expected the first period to be reset, got period %d with %v", player.ByoYomiPeriod, player.PeriodLeft)
+	}
+
+	// Using up a period moves on to the next one, the flag falls after the last
+	for i := 0; i < 2; i++ {
+		model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	}
+	if player := model.Players[0]; player.Flagged || player.ByoYomiPeriod != 2 {
+		t.Errorf("Expected the second period, got period %d", player.ByoYomiPeriod)
+	}
+	for i := 0; i < 2; i++ {
+		model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	}
+	if !model.Players[0].Flagged {
+		t.Error("Expected the flag to fall after the last period")
+	}
+
+	model, _ = hammerclock.Update(&common.EndGameMsg{}, model)
+	if model.Players[0].ByoYomiPeriod != 0 {
+		t.Errorf("Expected the periods to be reset with the game, got %d", model.Players[0].ByoYomiPeriod)
+	}
+}
+
+// TestPausedTimeTracking tests that the time spent paused is tracked separately and summarized at the end
+func TestPausedTimeTracking(t *testing.T) {
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+
+	if model.TotalGameTime != time.Second || model.PausedTime != 2*time.Second {
+		t.Fatalf("Expected 1s of play and 2s paused, got %v and %v", model.TotalGameTime, model.PausedTime)
+	}
+
+	model, _ = hammerclock.Update(&common.EndGameMsg{}, model)
+	if model.PausedTime != 0 {
+		t.Errorf("Expected the paused time to be reset, got %v", model.PausedTime)
+	}
+	log := model.Players[0].ActionLog
+	if len(log) == 0 || log[len(log)-1].Message != "Game summary - played 1s, paused 2s" {
+		t.Errorf("Expected a game summary in the log, got %+v", log)
+	}
+}
+
+func TestGracePeriod(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.GracePeriod = 2
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.SwitchTurnsMsg{}, model)
+
+	// The first two seconds after the switch are the grace period, the third counts for the player
+	for i := 0; i < 3; i++ {
+		model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	}
+	if model.Players[1].TimeElapsed != time.Second {
+		t.Errorf("Expected one second on the clock after the grace period, got %v", model.Players[1].TimeElapsed)
+	}
+	if model.TotalGameTime != 3*time.Second {
+		t.Errorf("Expected the grace period to count towards the game time, got %v", model.TotalGameTime)
+	}
+	if model.GraceRemaining != 0 {
+		t.Errorf("Expected the grace period to be used up, got %v", model.GraceRemaining)
+	}
+}
+
+func TestPhaseLimits(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.Rules = []rules.Rules{{
+		Name:   "Demo",
+		Phases: []string{"Movement", "Shooting"},
+		PhaseLimits: map[string]rules.PhaseLimit{
+			"Movement": {Soft: 2, Hard: 3},
+			"Shooting": {Hard: 1, Action: "pause"},
+		},
+	}}
+	model.Options.Default = 0
+	model.Phases = model.Options.Rules[0].Phases
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+
+	// The soft limit only warns the player
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	model, cmd := hammerclock.Update(&common.TickMsg{}, model)
+	if cmd() != nil || model.Players[0].CurrentPhase != 0 {
+		t.Fatalf("Expected the soft limit to only warn, got phase %d", model.Players[0].CurrentPhase)
+	}
+	log := model.Players[0].ActionLog
+	if last := log[len(log)-1]; last.Type != common.LogTypeWarning || !strings.Contains(last.Message, "Soft limit") {
+		t.Errorf("Expected a soft limit warning, got %+v", last)
+	}
+
+	// The hard limit calls the judge and advances to the next phase
+	model, cmd = hammerclock.Update(&common.TickMsg{}, model)
+	if _, ok := cmd().(*common.BellMsg); !ok {
+		t.Errorf("Expected the bell to ring at the hard limit")
+	}
+	if model.Players[0].CurrentPhase != 1 {
+		t.Fatalf("Expected the next phase after the hard limit, got %d", model.Players[0].CurrentPhase)
+	}
+
+	// Phases can pause the game instead
+	model, _ = hammerclock.Update(&common.TickMsg{}, model)
+	if model.GameStatus != "Game Paused" || model.Players[0].CurrentPhase != 1 {
+		t.Errorf("Expected the game to be paused in the same phase, got %q in phase %d",
+			model.GameStatus, model.Players[0].CurrentPhase)
+	}
+}
+
+func TestAuxiliaryTimers(t *testing.T) {
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.AddTimerMsg{Label: "Deployment", Duration: 2 * time.Second}, model)
+	model, _ = hammerclock.Update(&common.AddTimerMsg{Label: "Pizza", Duration: time.Minute}, model)
+
+	// Timers run before the game starts and ring the bell when they run out
+	model, cmd := hammerclock.Update(&common.TickMsg{}, model)
+	if cmd() != nil || model.Timers[0].Remaining != time.Second {
+		t.Fatalf("Expected the timer to count down quietly, got %v", model.Timers[0].Remaining)
+	}
+	model, cmd = hammerclock.Update(&common.TickMsg{}, model)
+	if _, ok := cmd().(*common.BellMsg); !ok || !model.Timers[0].Done {
+		t.Errorf("Expected the deployment timer to ring the bell when it runs out")
+	}
+	if model.Timers[1].Done || model.Timers[1].Remaining != 58*time.Second {
+		t.Errorf("Expected the other timer to keep running, got %+v", model.Timers[1])
+	}
+	if model.TotalGameTime != 0 {
+		t.Errorf("Expected the timers not to affect the game time, got %v", model.TotalGameTime)
+	}
+
+	model, _ = hammerclock.Update(&common.ClearTimersMsg{}, model)
+	if len(model.Timers) != 0 {
+		t.Errorf("Expected the timers to be cleared, got %d", len(model.Timers))
+	}
+}
+
+func TestRoundTimerReachesEveryGame(t *testing.T) {
+	session := hammerclock.NewSession(hammerclock.NewModel())
+	session, _ = session.Update(&common.KeyPressMsg{Key: tcell.KeyCtrlT})
+
+	ends := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	session, cmd := session.Update(&common.RoundTimerMsg{Ends: ends, Announcement: "Dice down in 15 minutes"})
+	if batch, ok := cmd().(*common.BatchMsg); !ok || len(batch.Msgs) == 0 {
+		t.Error("Expected a new announcement to ring the bell")
+	} else if _, ok := batch.Msgs[0].(*common.BellMsg); !ok {
+		t.Errorf("Expected a new announcement to ring the bell, got %T", batch.Msgs[0])
+	}
+	for i, game := range session.Games {
+		if !game.RoundEnds.Equal(ends) || game.Announcement != "Dice down in 15 minutes" {
+			t.Errorf("Expected game %d to show the round timer, got %v %q", i+1, game.RoundEnds, game.Announcement)
+		}
+	}
+
+	// The same announcement is pushed again when a table reconnects, it only rings once
+	session, cmd = session.Update(&common.RoundTimerMsg{Ends: ends, Announcement: "Dice down in 15 minutes"})
+	if cmd() != nil {
+		t.Error("Expected a repeated announcement not to ring the bell")
+	}
+	if text := ui.RoundTimerText(ends, "Dice down in 15 minutes", ends.Add(-14*time.Minute-32*time.Second)); text != "Round: 14:32 left | Dice down in 15 minutes" {
+		t.Errorf("Unexpected round timer text %q", text)
+	}
+}
+
+func TestJudgeModeIsAudited(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.JudgePassphrase = "secret"
+	model.AuditFile = filepath.Join(t.TempDir(), audit.FileName)
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 's'}, model)
+
+	// Judge actions need the passphrase
+	model, _ = hammerclock.Update(&common.JudgeActionMsg{Action: "pause"}, model)
+	if model.GameStatus != "Game In Progress" {
+		t.Fatal("Expected judge actions to be ignored before judge mode is unlocked")
+	}
+	model, cmd := hammerclock.Update(&common.JudgeLoginMsg{Passphrase: "guess"}, model)
+	if _, ok := cmd().(*common.BellMsg); !ok || model.JudgeMode {
+		t.Fatal("Expected a wrong passphrase to ring the bell and keep judge mode locked")
+	}
+	model, _ = hammerclock.Update(&common.JudgeLoginMsg{Passphrase: "secret"}, model)
+	if !model.JudgeMode {
+		t.Fatal("Expected the passphrase to unlock judge mode")
+	}
+
+	model, _ = hammerclock.Update(&common.JudgeActionMsg{Action: "penalty", Player: 1, Penalty: 2 * time.Minute, Reason: "slow play"}, model)
+	model, _ = hammerclock.Update(&common.JudgeActionMsg{Action: "score", Player: 0, Points: -3}, model)
+	model, _ = hammerclock.Update(&common.JudgeActionMsg{Action: "pause"}, model)
+	if model.Players[1].TimeElapsed != 2*time.Minute || model.Players[0].VictoryPoints != -3 {
+		t.Errorf("Expected the penalty and score adjustment, got %v and %d VP",
+			model.Players[1].TimeElapsed, model.Players[0].VictoryPoints)
+	}
+	if model.GameStatus != "Game Paused" {
+		t.Errorf("Expected the judge to pause the game, got %s", model.GameStatus)
+	}
+	model, _ = hammerclock.Update(&common.JudgeActionMsg{Action: "exit"}, model)
+	if model.JudgeMode {
+		t.Error("Expected judge mode to be left")
+	}
+
+	// Every intervention, including the failed login, is in the audit log
+	if count, _, err := audit.Verify(model.AuditFile, "secret"); err != nil || count != 6 {
+		t.Errorf("Expected 6 intact audit entries, got %d and %v", count, err)
+	}
+}
+
+func TestDiceRollsAreLoggedAndReported(t *testing.T) {
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+	model, _ = hammerclock.Update(&common.NextPhaseMsg{}, model)
+
+	expr, _ := dice.Parse("2d6+1")
+	roll := dice.Roll{Expr: expr, Results: []int{6, 4}}
+	model, _ = hammerclock.Update(&common.RollDiceMsg{Roll: roll}, model)
+
+	log := model.Players[0].ActionLog
+	expected := fmt.Sprintf("Rolled 2d6+1 (turn %d, %s): 6 4 +1 = 11", model.Players[0].TurnCount, model.Phases[1])
+	if last := log[len(log)-1]; last.Message != expected || last.Type != common.LogTypeDice {
+		t.Errorf("Expected %q in the active player's log, got %q", expected, last.Message)
+	}
+	if markdown := report.Markdown(&model, time.Now()); !strings.Contains(markdown, "- Dice: 1 roll, 2 dice, 43% above average") {
+		t.Errorf("Expected the dice in the battle report, got\n%s", markdown)
+	}
+}
+
+// TestRandomTableRollsLogged tests that a roll on a random table of the ruleset is logged with the entry rolled, and
+// that the entries are found by their results
+func TestRandomTableRollsLogged(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.Rules = slices.Clone(model.Options.Rules)
+	model.Options.Rules[model.Options.Default].RandomTables = []rules.RandomTable{
+		{Name: "Kick-off Events", Dice: "2d6", Entries: []rules.TableEntry{
+			{Min: 2, Max: 6, Text: "Blitz"}, {Min: 7, Text: "Changing Weather"}, {Min: 8, Max: 12, Text: "Riot"},
+		}},
+		{Name: "Warp Storm", Entries: []rules.TableEntry{{Text: "Calm"}, {Text: "Squall"}, {Text: "Tempest"}}},
+	}
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+
+	table, found := rules.FindRandomTable(model.Options.Rules[model.Options.Default].RandomTables, "kick-off events")
+	if !found {
+		t.Fatal("Expected the table to be found by its name in any case")
+	}
+	expr, _ := dice.Parse(table.DiceText())
+	model, _ = hammerclock.Update(&common.RollTableMsg{Table: table.Name, Roll: dice.Roll{Expr: expr, Results: []int{3, 4}}}, model)
+
+	log := model.Players[0].ActionLog
+	expected := fmt.Sprintf("Rolled on Kick-off Events (turn %d, %s): 3 4 = 7, Changing Weather",
+		model.Players[0].TurnCount, model.Phases[0])
+	if last := log[len(log)-1]; last.Message != expected || last.Type != common.LogTypeDice {
+		t.Errorf("Expected %q in the active player's log, got %q", expected, last.Message)
+	}
+	if markdown := report.Markdown(&model, time.Now()); strings.Contains(markdown, "- Dice:") {
+		t.Errorf("Expected the table rolls not to count as dice rolls, got\n%s", markdown)
+	}
+
+	storm, _ := rules.FindRandomTable(model.Options.Rules[model.Options.Default].RandomTables, "Warp Storm")
+	if storm.DiceText() != "d3" {
+		t.Errorf("Expected a die with a side per entry, got %q", storm.DiceText())
+	}
+	for result, text := range map[int]string{1: "Calm", 3: "Tempest", 4: ""} {
+		if entry, _ := storm.Entry(result); entry != text {
+			t.Errorf("Expected %q for a %d, got %q", text, result, entry)
+		}
+	}
+}
+
+func TestProtectedActionsNeedThePIN(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.ActionPIN = "4711"
+	model.Options.JudgePassphrase = "secret"
+	model.AuditFile = filepath.Join(t.TempDir(), audit.FileName)
+	model, _ = hammerclock.Update(&common.StartGameMsg{}, model)
+
+	model, cmd := hammerclock.Update(&common.ScoreSecondaryObjectivesMsg{PlayerIndex: 0, Scores: []int{3}, PIN: "1234"}, model)
+	if msg, ok := cmd().(*common.ShowModalMsg); !ok || msg.Type != "SecondaryObjectives" {
+		t.Errorf("Expected a wrong PIN to ask for the scores again, got %v", msg)
+	}
+	if model.Players[0].VictoryPoints != 0 {
+		t.Errorf("Expected no score with a wrong PIN, got %d VP", model.Players[0].VictoryPoints)
+	}
+	model, _ = hammerclock.Update(&common.ScoreSecondaryObjectivesMsg{PlayerIndex: 0, Scores: []int{3}, PIN: "4711"}, model)
+	if model.Players[0].VictoryPoints != 3 {
+		t.Errorf("Expected the score with the PIN, got %d VP", model.Players[0].VictoryPoints)
+	}
+
+	model, cmd = hammerclock.Update(&common.EndGameConfirmMsg{Confirmed: true}, model)
+	if _, ok := cmd().(*common.BellMsg); !ok || !model.GameStarted {
+		t.Fatal("Expected ending the game without the PIN to ring the bell and keep the game running")
+	}
+
+	// The judge does not need the PIN
+	model, _ = hammerclock.Update(&common.JudgeLoginMsg{Passphrase: "secret"}, model)
+	model, _ = hammerclock.Update(&common.EndGameConfirmMsg{Confirmed: true}, model)
+	if model.GameStarted {
+		t.Error("Expected the judge to end the game without the PIN")
+	}
+
+	// Every PIN attempt and the judge's login are audited
+	if count, _, err := audit.Verify(model.AuditFile, "secret"); err != nil || count != 4 {
+		t.Errorf("Expected 4 intact audit entries, got %d and %v", count, err)
+	}
+}
+
+func TestOptionsScreenNeedsThePIN(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.ActionPIN = "4711"
+	model.SavedOptions.ActionPIN = "4711"
+	model.OptionsFile = filepath.Join(t.TempDir(), "options.json")
+	model.AuditFile = filepath.Join(t.TempDir(), audit.FileName)
+
+	model, cmd := hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'o'}, model)
+	if msg, ok := cmd().(*common.ShowModalMsg); !ok || msg.Type != "OptionsPIN" || model.CurrentScreen != "main" {
+		t.Fatalf("Expected the options screen to ask for the PIN, got %v on %s", msg, model.CurrentScreen)
+	}
+
+	// Resetting and saving are refused until the options screen is opened with the PIN
+	model, _ = hammerclock.Update(&common.ResetOptionMsg{}, model)
+	model, _ = hammerclock.Update(&common.SaveOptionsMsg{}, model)
+	if _, err := os.Stat(model.OptionsFile); !os.IsNotExist(err) {
+		t.Errorf("Expected the options not to be saved without the PIN, got %v", err)
+	}
+	model, cmd = hammerclock.Update(&common.UnlockOptionsMsg{PIN: "1234"}, model)
+	if _, ok := cmd().(*common.BellMsg); !ok || model.CurrentScreen != "main" {
+		t.Fatalf("Expected a wrong PIN to ring the bell and keep the options closed, got %s", model.CurrentScreen)
+	}
+	model, _ = hammerclock.Update(&common.UnlockOptionsMsg{PIN: "4711"}, model)
+	if model.CurrentScreen != "options" {
+		t.Fatalf("Expected the PIN to open the options screen, got %s", model.CurrentScreen)
+	}
+
+	// Resetting all options keeps the PIN
+	model, _ = hammerclock.Update(&common.ResetOptionMsg{}, model)
+	if model.Options.ActionPIN != "4711" {
+		t.Errorf("Expected resetting the options to keep the PIN, got %q", model.Options.ActionPIN)
+	}
+
+	// Without a judge passphrase the attempts cannot be keyed, so they are not audited
+	if _, err := os.Stat(model.AuditFile); !os.IsNotExist(err) {
+		t.Errorf("Expected no audit log without a judge passphrase, got %v", err)
+	}
+}
+
+func TestPreAndPostGameSequences(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.Rules = []rules.Rules{{Name: "Blood Bowl", Phases: []string{"Team Turn"},
+		PreGame: []string{"Weather", "Coin toss"}, PostGame: []string{"Winnings", "Hire and fire"}}}
+	model.Options.Default = 0
+	model.Phases = hammerclock.RulesetPhases(model.Options)
+
+	model, cmd := hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 's'}, model)
+	if msg, ok := cmd().(*common.ShowModalMsg); !ok || msg.Type != hammerclock.PreGame || model.GameStarted {
+		t.Fatalf("Expected the pre-game sequence before the game starts, got %v", msg)
+	}
+	model, cmd = hammerclock.Update(&common.CompleteStepMsg{Sequence: hammerclock.PreGame, Result: "Blizzard"}, model)
+	if msg, ok := cmd().(*common.ShowModalMsg); !ok || msg.Step != 1 || model.GameStarted {
+		t.Fatalf("Expected the second step, got %v", msg)
+	}
+	model, _ = hammerclock.Update(&common.CompleteStepMsg{Sequence: hammerclock.PreGame, Step: 1}, model)
+	if !model.GameStarted || !slices.Equal(model.SequenceResults, []string{"Weather: Blizzard"}) {
+		t.Fatalf("Expected the game to start after the last step with the weather recorded, got %v", model.SequenceResults)
+	}
+
+	// The clocks stop during the post-game sequence, and the game ends after it
+	model, cmd = hammerclock.Update(&common.EndGameConfirmMsg{Confirmed: true}, model)
+	if msg, ok := cmd().(*common.ShowModalMsg); !ok || msg.Type != hammerclock.PostGame || model.GameStatus != "Game Paused" {
+		t.Fatalf("Expected the post-game sequence with the game paused, got %v and %s", msg, model.GameStatus)
+	}
+	model, cmd = hammerclock.Update(&common.CompleteStepMsg{Sequence: hammerclock.PostGame, Result: "40k", Skip: true}, model)
+	if !model.GameStarted || !slices.Equal(model.SequenceResults, []string{"Weather: Blizzard", "Winnings: 40k"}) {
+		t.Fatalf("Expected the winnings to be recorded before the game ends, got %v", model.SequenceResults)
+	}
+	log := model.Players[0].ActionLog
+	if log[0].Message != "Pre-game - Weather: Blizzard" || log[len(log)-1].Message != "Post-game - Winnings: 40k" {
+		t.Errorf("Expected the results in the first player's log, got %v", log)
+	}
+	ended, _ := hammerclock.Update(cmd(), model)
+	if ended.GameStarted || ended.PreGameDone || ended.SequenceResults != nil {
+		t.Error("Expected skipping the rest to end the game and reset the sequences")
+	}
+}
+
+func TestKioskDisablesScreensAndDestructiveActions(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Kiosk = true
+	model.Options.JudgePassphrase = "secret"
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 's'}, model)
+
+	for _, key := range []rune{'o', 'a'} {
+		model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: key}, model)
+		if model.CurrentScreen != "main" {
+			t.Errorf("Expected %c to be disabled in kiosk mode, got the %s screen", key, model.CurrentScreen)
+		}
+	}
+	for _, msg := range []common.Message{&common.ShowEndGameConfirmMsg{}, &common.ShowExitConfirmMsg{},
+		&common.RunCommandMsg{Name: "quit"}} {
+		if _, cmd := hammerclock.Update(msg, model); cmd() != nil {
+			t.Errorf("Expected %T to be disabled in kiosk mode", msg)
+		}
+	}
+
+	// The judge unlocks them with the passphrase
+	model, _ = hammerclock.Update(&common.JudgeLoginMsg{Passphrase: "secret"}, model)
+	if _, cmd := hammerclock.Update(&common.ShowExitConfirmMsg{}, model); cmd() == nil {
+		t.Error("Expected the judge to be able to quit in kiosk mode")
+	}
+}
+
+func TestScratchpadToggle(t *testing.T) {
+	model := hammerclock.NewModel()
+	model, _ = hammerclock.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: '='}, model)
+	if !model.ScratchpadShown {
+		t.Fatal("Expected = to show the scratchpad")
+	}
+
+	// The scratchpad is only a calculator, so it stays available while the input is locked
+	model.InputLocked = true
+	model, _ = hammerclock.Update(&common.RunCommandMsg{Name: "calc"}, model)
+	if model.ScratchpadShown {
+		t.Error("Expected the calc command to hide the scratchpad")
+	}
+}
+
+func TestCampaignGameSetup(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "ashes.json")
+	c := &campaign.Campaign{Name: "Ashes", Members: []campaign.Member{
+		{Name: "Alice", Faction: "Orks", Roster: []options.ArmyUnit{{Name: "Boyz", Points: 85}}},
+	}, Games: []campaign.Game{{Number: 1}}}
+	if err := c.Save(filename); err != nil {
+		t.Fatalf("Failed to save the campaign: %v", err)
+	}
+
+	opts := options.Options{PlayerCount: 2, PlayerNames: []string{"Alice", "Bob"}}
+	c, err := setupCampaignGame(filename, &opts)
+	if err != nil {
+		t.Fatalf("Failed to set up the campaign game: %v", err)
+	}
+	if opts.Banner != "Ashes - game 2" || !slices.Equal(opts.PlayerFactions, []string{"Orks", ""}) {
+		t.Errorf("Expected the game number in the banner and Alice's faction, got %q and %v", opts.Banner, opts.PlayerFactions)
+	}
+
+	players := []*common.Player{{Name: "Alice"}, {Name: "Bob", ArmyList: []common.Unit{{Name: "Gretchin"}}}}
+	applyCampaignRosters(players, c)
+	if len(players[0].ArmyList) != 1 || players[0].ArmyList[0].Name != "Boyz" || players[1].ArmyList[0].Name != "Gretchin" {
+		t.Errorf("Expected Alice to take her roster into the game and Bob to keep his list, got %v and %v",
+			players[0].ArmyList, players[1].ArmyList)
+	}
+}
+
+func TestSessionGamesInTabs(t *testing.T) {
+	model := hammerclock.NewModel()
+	dir := t.TempDir()
+	model.AutosaveFile = filepath.Join(dir, hammerclock.AutosaveFileName)
+	model.SaveFile = filepath.Join(dir, hammerclock.SaveFileName)
+	session := hammerclock.NewSession(model)
+	session, _ = session.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 's'})
+	session, _ = session.Update(&common.KeyPressMsg{Key: tcell.KeyCtrlT})
+	if len(session.Games) != 2 || session.Active != 1 {
+		t.Fatalf("Expected a second game to be shown, got %d games showing %d", len(session.Games), session.Active)
+	}
+	if session.Current().GameStarted {
+		t.Error("Expected the new game not to be started")
+	}
+	if game, ok := session.Game(session.ID(1)); !ok || game != session.Current() {
+		t.Error("Expected the second game to be found by its identifier")
+	}
+
+	// Each game has files of its own
+	if autosave := session.Current().AutosaveFile; autosave != filepath.Join(dir, "autosave-2.json") {
+		t.Errorf("Expected the second game to autosave to a file of its own, got %s", autosave)
+	}
+	if save := session.Current().SaveFile; save != filepath.Join(dir, "saved-game-2.json") {
+		t.Errorf("Expected the second game to be saved to a file of its own, got %s", save)
+	}
+
+	// Both games keep running, keys only go to the game shown
+	session, _ = session.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 's'})
+	session, _ = session.Update(&common.TickMsg{})
+	session, _ = session.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: ' '})
+	session, _ = session.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: '1'})
+	session, _ = session.Update(&common.TickMsg{})
+	first, second := session.Games[0], session.Games[1]
+	if first.TotalGameTime != 2*time.Second || first.Players[0].TimeElapsed != 2*time.Second {
+		t.Errorf("Expected the first game to keep running, got %v", first.TotalGameTime)
+	}
+	if second.TotalGameTime != 2*time.Second || !second.Players[1].IsTurn || second.Players[1].TimeElapsed != time.Second {
+		t.Errorf("Expected the turn switch in the second game only, got %v for the second player", second.Players[1].TimeElapsed)
+	}
+	if session.TabsText() != "[black:white] 1 [-:-] 2 " {
+		t.Errorf("Unexpected tabs text %q", session.TabsText())
+	}
+
+	// Running games are not closed
+	session, _ = session.Update(&common.KeyPressMsg{Key: tcell.KeyCtrlW})
+	if len(session.Games) != 2 {
+		t.Fatalf("Expected the running game to stay open, got %d games", len(session.Games))
+	}
+	session, _ = session.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'e'})
+	session, _ = session.Update(&common.EndGameConfirmMsg{Confirmed: true})
+	session, _ = session.Update(&common.KeyPressMsg{Key: tcell.KeyCtrlW})
+	if len(session.Games) != 1 || session.Active != 0 || !session.Current().GameStarted {
+		t.Errorf("Expected only the second game to be left, got %d games", len(session.Games))
+	}
+}
+
+// TestBackgroundGameMessagesWaitUntilShown tests that the dialog of a game over in the background is shown when
+// the game is, after ringing the bell
+func TestBackgroundGameMessagesWaitUntilShown(t *testing.T) {
+	model := hammerclock.NewModel()
+	model.Options.TimeBudget = 1
+	model.Options.FlagFall = "over"
+	session := hammerclock.NewSession(model)
+	session, _ = session.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 's'})
+	session, _ = session.Update(&common.KeyPressMsg{Key: tcell.KeyCtrlT})
+	session.Games[0].Players[0].TimeElapsed = time.Minute - time.Second
+
+	session, cmd := session.Update(&common.TickMsg{})
+	batch, ok := cmd().(*common.BatchMsg)
+	if !ok || len(batch.Msgs) != 1 {
+		t.Fatalf("Expected the message of the game in the background, got %#v", batch)
+	}
+	background, ok := batch.Msgs[0].(*common.BackgroundMsg)
+	if !ok {
+		t.Fatalf("Expected a BackgroundMsg, got %T", batch.Msgs[0])
+	}
+	session, cmd = session.Update(background)
+	if _, ok := cmd().(*common.BellMsg); !ok {
+		t.Error("Expected the game over in the background to ring the bell")
+	}
+
+	session, cmd = session.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: '1'})
+	batch, ok = cmd().(*common.BatchMsg)
+	if !ok || len(batch.Msgs) != 1 {
+		t.Fatalf("Expected the queued message when showing the game, got %#v", batch)
+	}
+	if msg, ok := batch.Msgs[0].(*common.ShowModalMsg); !ok || msg.Type != "GameOver" {
+		t.Errorf("Expected the game over dialog, got %#v", batch.Msgs[0])
+	}
+	session, _ = session.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: '2'})
+	if _, cmd = session.Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: '1'}); cmd() != nil {
+		t.Error("Expected the queued message to be delivered once")
+	}
+}
+
+func TestDashboardRows(t *testing.T) {
+	now := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	state := &common.LinkStateMsg{
+		Status:        "Game In Progress",
+		GameStarted:   true,
+		TotalGameTime: 30 * time.Minute,
+		Table:         "Table 2",
+		Round:         3,
+		TimeBudget:    time.Hour,
+		Players: []common.LinkedPlayer{
+			{Name: "Alice", TimeElapsed: 20 * time.Minute},
+			{Name: "Bob", TimeElapsed: 10 * time.Minute, IsTurn: true},
+		},
+	}
+
+	var dashboard hammerclock.Dashboard
+	dashboard = hammerclock.UpdateDashboard(&common.TableStateMsg{Addr: "10.0.0.2:7420", State: state}, dashboard, now)
+	dashboard = hammerclock.UpdateDashboard(&common.TableStateMsg{Addr: "10.0.0.1:7420", State: &common.LinkStateMsg{
+		Status: "Game Not Started", Table: "Table 1",
+	}}, dashboard, now.Add(-time.Minute))
+
+	rows := hammerclock.DashboardRows(dashboard, now)
+	if len(rows) != 2 || rows[0][0] != "Table 1" {
+		t.Fatalf("Expected the tables ordered by name, got %v", rows)
+	}
+	if strings.Join(rows[1], "|") != "Table 2|3|Bob|Game In Progress|30m0s|50m0s" {
+		t.Errorf("Unexpected row for table 2: %v", rows[1])
+	}
+	if rows[0][3] != "Connection lost" {
+		t.Errorf("Expected the silent table to be lost, got %q", rows[0][3])
+	}
+}
+
+func TestDoctorChecks(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to initialize the simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(60, 20)
+
+	checks := terminalChecks(screen)
+	if checks[0].ok || checks[0].result != "60x20" {
+		t.Errorf("Expected a terminal of 60x20 to be too small, got %+v", checks[0])
+	}
+	if !checks[2].ok {
+		t.Errorf("Expected a UTF-8 terminal to display the box-drawing characters, got %+v", checks[2])
+	}
+
+	if c := writeCheck("Data directory", t.TempDir()); !c.ok {
+		t.Errorf("Expected a temporary directory to be writable, got %+v", c)
+	}
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c := writeCheck("Data directory", filepath.Join(file, "dir")); c.ok || c.advice == "" {
+		t.Errorf("Expected a directory below a file to fail with advice, got %+v", c)
+	}
+}
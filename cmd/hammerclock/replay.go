@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"time"
+
+	"hammerclock/internal/hammerclock/palette"
+	"hammerclock/internal/hammerclock/report"
+	"hammerclock/internal/hammerclock/ui"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// replaySpeeds are the speeds a game can be played back at, as multiples of the time it took
+var replaySpeeds = []int{1, 10, 30, 60, 120, 300, 600}
+
+// replayInterval is the interval the replay advances in while it plays
+const replayInterval = 250 * time.Millisecond
+
+// loadReplay reads the game of a CSV log to play back, the last one unless another is picked with -game
+func loadReplay(args []string, defaultLog string) (*report.Replay, error) {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	number := flags.Int("game", 0, "Game of the log to play back, counting from 1 (default: the last game)")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	logFile := defaultLog
+	if flags.NArg() > 0 {
+		logFile = flags.Arg(0)
+	}
+
+	games, err := report.ReadLog(logFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(games) == 0 {
+		return nil, fmt.Errorf("no game in %s", logFile)
+	}
+	if *number == 0 {
+		*number = len(games)
+	}
+	if *number < 1 || *number > len(games) {
+		return nil, fmt.Errorf("there are %d games in %s", len(games), logFile)
+	}
+	return report.NewReplay(games[*number-1]), nil
+}
+
+// runReplay plays a game of the log back, with the players' turns, phases and clocks next to the log, until the
+// user quits
+func runReplay(replay *report.Replay, colors palette.ColorPalette) error {
+	app := tview.NewApplication()
+	table := ui.CreateReplayTable(colors)
+	log := ui.CreateReplayLog(colors)
+	help := tview.NewTextView().SetText(ui.ReplayHelp)
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, len(replay.Game.Players())+3, 0, false).
+		AddItem(log, 0, 1, false).
+		AddItem(help, 1, 0, false)
+
+	// The replay is only changed on the UI goroutine
+	speed, playing := slices.Index(replaySpeeds, 60), false
+	show := func() {
+		ui.UpdateReplayTable(table, replay, replaySpeeds[speed], playing, colors)
+		ui.UpdateReplayLog(log, replay)
+	}
+	show()
+
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyCtrlC || event.Rune() == 'q' || event.Rune() == 'Q':
+			app.Stop()
+			return nil
+		case event.Rune() == ' ':
+			if replay.Done() {
+				replay.Step(-replay.Position)
+			}
+			playing = !playing
+		case event.Key() == tcell.KeyRight:
+			replay.Step(1)
+		case event.Key() == tcell.KeyLeft:
+			replay.Step(-1)
+		case event.Key() == tcell.KeyPgDn:
+			replay.NextTurn()
+		case event.Key() == tcell.KeyPgUp:
+			replay.PrevTurn()
+		case event.Key() == tcell.KeyHome:
+			replay.Step(-replay.Position)
+		case event.Key() == tcell.KeyEnd:
+			replay.Step(len(replay.Game.Entries))
+		case event.Rune() == '+':
+			speed = min(speed+1, len(replaySpeeds)-1)
+		case event.Rune() == '-':
+			speed = max(speed-1, 0)
+		default:
+			return event
+		}
+		show()
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(replayInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				app.QueueUpdateDraw(func() {
+					if !playing {
+						return
+					}
+					replay.PlayTo(replay.At + replayInterval*time.Duration(replaySpeeds[speed]))
+					playing = !replay.Done()
+					show()
+				})
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := app.SetRoot(root, true).EnableMouse(true).Run()
+	close(done)
+	return err
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"time"
+
+	"hammerclock/internal/hammerclock/palette"
+	"hammerclock/internal/hammerclock/tournament"
+	"hammerclock/internal/hammerclock/ui"
+
+	"github.com/rivo/tview"
+)
+
+// standingsRefreshInterval is the interval the standings are reloaded in, as the tables record their results
+const standingsRefreshInterval = 5 * time.Second
+
+// runStandings shows the results entry form and the live standings of a tournament event until the user quits
+func runStandings(eventFile string, round int, colors palette.ColorPalette) error {
+	app := tview.NewApplication()
+	standings := ui.CreateStandingsTable(colors)
+	layout := tview.NewFlex()
+	status := tview.NewTextView().SetDynamicColors(true)
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(layout, 0, 1, true).
+		AddItem(status, 1, 0, false)
+
+	refresh := func() {
+		if event, err := tournament.Load(eventFile); err == nil {
+			ui.UpdateStandingsTable(standings, event.Standings(), colors)
+		}
+	}
+
+	var showForm func(round, table int)
+	showForm = func(round, table int) {
+		var event *tournament.Event
+		var pairing tournament.Pairing
+		err := tournament.Update(eventFile, func(e *tournament.Event) error {
+			var err error
+			event = e
+			pairing, err = e.Table(round, table)
+			return err
+		})
+		if err != nil {
+			status.SetText("[red]" + tview.Escape(err.Error()))
+			return
+		}
+
+		save := func(players []tournament.PlayerResult) {
+			// The file is read again, so results recorded by the tables in the meantime are kept
+			err := tournament.Update(eventFile, func(event *tournament.Event) error {
+				event.EnterResult(round, table, players)
+				return nil
+			})
+			if err != nil {
+				status.SetText("[red]" + tview.Escape(err.Error()))
+				return
+			}
+			status.SetText("[green]Result saved")
+			refresh()
+		}
+
+		form := ui.CreateResultsForm(event, round, table, pairing.Players, colors, showForm, save, app.Stop)
+		layout.Clear().
+			AddItem(form, 0, 1, true).
+			AddItem(standings, 0, 2, false)
+		app.SetFocus(form)
+		ui.UpdateStandingsTable(standings, event.Standings(), colors)
+	}
+	showForm(round, 1)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(standingsRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				app.QueueUpdateDraw(refresh)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return app.SetRoot(root, true).EnableMouse(true).Run()
+}
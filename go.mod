@@ -6,11 +6,16 @@ toolchain go1.24
 
 require (
 	github.com/gdamore/tcell/v2 v2.8.1
+	github.com/gofrs/flock v0.12.1
+	github.com/gorilla/websocket v1.5.3
 	github.com/mdp/qrterminal/v3 v3.2.1
 	github.com/rivo/tview v0.0.0-20250501113434-0c592cd31026
+	go.bug.st/serial v1.6.4
+	golang.org/x/crypto v0.38.0
 )
 
 require (
+	github.com/creack/goselect v0.1.2 // indirect
 	github.com/gdamore/encoding v1.0.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.16 // indirect
@@ -0,0 +1,197 @@
+// Package api serves the game state as JSON over HTTP, and streams its changes over a WebSocket, so other tools such
+// as stream overlays or scoreboards can follow the table, and serves the web clock and the stream overlay showing it
+// in a browser. The state is published by the update loop and the requests are answered from the latest copy, so
+// they never touch the model itself.
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+)
+
+// State is the state of the game served at /api/state
+type State struct {
+	Game    Game     `json:"game"`
+	Players []Player `json:"players"`
+}
+
+// Game is the state of the game without the players, served at /api/game
+type Game struct {
+	Status               string    `json:"status"`
+	Started              bool      `json:"started"`
+	Running              bool      `json:"running"` // Indicates if the active player's clock is running
+	Round                int       `json:"round"`   // Battle round, 0 before the game starts
+	Ruleset              string    `json:"ruleset"`
+	Phases               []string  `json:"phases"`
+	TotalGameTimeSeconds int64     `json:"totalGameTimeSeconds"`
+	PausedTimeSeconds    int64     `json:"pausedTimeSeconds"`
+	UpdatedAt            time.Time `json:"updatedAt"` // Time the state was published
+}
+
+// Player is the state of a player, served at /api/players
+type Player struct {
+	Name                 string `json:"name"`
+	Faction              string `json:"faction,omitempty"`
+	IsTurn               bool   `json:"isTurn"`
+	Turn                 int    `json:"turn"`
+	Phase                string `json:"phase"`
+	PhaseIndex           int    `json:"phaseIndex"`
+	TimeElapsedSeconds   int64  `json:"timeElapsedSeconds"`
+	PhaseElapsedSeconds  int64  `json:"phaseElapsedSeconds"`
+	TimeRemainingSeconds *int64 `json:"timeRemainingSeconds,omitempty"` // Negative once the player ran out of time, left out without a time budget
+	VictoryPoints        int    `json:"victoryPoints"`
+	Flagged              bool   `json:"flagged"`
+}
+
+// clockHTML is the web clock, a page showing the clocks in large type, e.g. on a tablet next to the table. It is
+// embedded so the server needs no files on disk.
+//
+//go:embed clock.html
+var clockHTML []byte
+
+// Server answers the requests for the game state, and streams its changes to the subscribers
+type Server struct {
+	listener    net.Listener
+	mu          sync.RWMutex
+	state       State
+	subscribers map[chan Event]struct{}
+	stop        chan struct{} // Closed when the server stops
+}
+
+// Listen starts listening for requests on the given address, e.g. ":8080"
+func Listen(addr string) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return newServer(listener), nil
+}
+
+// newServer returns a server for a listener, with an empty state
+func newServer(listener net.Listener) *Server {
+	return &Server{
+		listener:    listener,
+		state:       State{Players: []Player{}},
+		subscribers: map[chan Event]struct{}{},
+		stop:        make(chan struct{}),
+	}
+}
+
+// Addr returns the address the server is listening on
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Run answers requests until done is closed
+func (s *Server) Run(done <-chan struct{}) error {
+	server := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-done
+		_ = server.Close()
+		// Event streams were taken over from the HTTP server, which does not close them
+		close(s.stop)
+	}()
+	if err := server.Serve(s.listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Handler returns the handler of the endpoints /api/state, /api/game and /api/players, the event stream
+// /api/events, the web clock at / and the stream overlay at /overlay
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(clockHTML)
+	})
+	mux.HandleFunc("GET /overlay", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(overlayHTML)
+	})
+	mux.HandleFunc("GET /api/state", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.State())
+	})
+	mux.HandleFunc("GET /api/game", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.State().Game)
+	})
+	mux.HandleFunc("GET /api/players", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.State().Players)
+	})
+	mux.HandleFunc("GET /api/events", s.serveEvents)
+	return mux
+}
+
+// State returns the latest state published
+func (s *Server) State() State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+// Publish replaces the state served with the state of the model, and streams the changes. It is called by the
+// update loop, which owns the model; running tells whether the active player's clock is running and round is the
+// current battle round.
+func (s *Server) Publish(model *common.Model, running bool, round int) {
+	state := stateFromModel(model, running, round, time.Now())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notify(changes(s.state, state))
+	s.state = state
+}
+
+// stateFromModel copies the state of the model, so it can be served while the model changes
+func stateFromModel(model *common.Model, running bool, round int, now time.Time) State {
+	state := State{
+		Game: Game{
+			Status:               string(model.GameStatus),
+			Started:              model.GameStarted,
+			Running:              running,
+			Round:                round,
+			Phases:               append([]string{}, model.Phases...),
+			TotalGameTimeSeconds: int64(model.TotalGameTime / time.Second),
+			PausedTimeSeconds:    int64(model.PausedTime / time.Second),
+			UpdatedAt:            now,
+		},
+		Players: make([]Player, len(model.Players)),
+	}
+	if model.Options.Default >= 0 && model.Options.Default < len(model.Options.Rules) {
+		state.Game.Ruleset = model.Options.Rules[model.Options.Default].Name
+	}
+	for i, player := range model.Players {
+		state.Players[i] = Player{
+			Name:                player.Name,
+			Faction:             player.Faction,
+			IsTurn:              player.IsTurn,
+			Turn:                player.TurnCount,
+			PhaseIndex:          player.CurrentPhase,
+			TimeElapsedSeconds:  int64(player.TimeElapsed / time.Second),
+			PhaseElapsedSeconds: int64(player.PhaseElapsed / time.Second),
+			VictoryPoints:       player.VictoryPoints,
+			Flagged:             player.Flagged,
+		}
+		if player.CurrentPhase >= 0 && player.CurrentPhase < len(model.Phases) {
+			state.Players[i].Phase = model.Phases[player.CurrentPhase]
+		}
+		if budget := options.PlayerTimeBudget(model.Options, i); budget > 0 {
+			remaining := int64((budget - player.TimeElapsed) / time.Second)
+			state.Players[i].TimeRemainingSeconds = &remaining
+		}
+	}
+	return state
+}
+
+// writeJSON writes a value as the JSON response, readable by pages on other origins such as stream overlays
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestServerServesPublishedState(t *testing.T) {
+	server := newServer(nil)
+	model := &common.Model{
+		GameStarted: true,
+		GameStatus:  "Game in progress",
+		Phases:      []string{"Command", "Movement"},
+		Players: []*common.Player{
+			{Name: "Alice", IsTurn: true, TurnCount: 2, CurrentPhase: 1, TimeElapsed: 90 * time.Second},
+			{Name: "Bob", TurnCount: 1, TimeElapsed: 60 * time.Second},
+		},
+	}
+	server.Publish(model, true, 2)
+	model.Players[0].TimeElapsed = time.Hour
+
+	var players []Player
+	get(t, server, "/api/players", &players)
+	if len(players) != 2 || players[0].Name != "Alice" || players[0].Phase != "Movement" || !players[0].IsTurn {
+		t.Fatalf("Expected the published players, got %+v", players)
+	}
+	if players[0].TimeElapsedSeconds != 90 {
+		t.Errorf("Expected the time published, not the model's later time, got %d", players[0].TimeElapsedSeconds)
+	}
+
+	var game Game
+	get(t, server, "/api/game", &game)
+	if !game.Started || !game.Running || game.Round != 2 || game.Status != "Game in progress" {
+		t.Errorf("Expected the published game, got %+v", game)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "/api/events") {
+		t.Errorf("Expected the web clock at /, got %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/overlay", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "transparent") {
+		t.Errorf("Expected the stream overlay at /overlay, got %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/state", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected POST to be refused, got %d", recorder.Code)
+	}
+}
+
+func TestEventStreamPushesChanges(t *testing.T) {
+	server := newServer(nil)
+	model := &common.Model{
+		GameStarted: true,
+		Phases:      []string{"Command", "Movement"},
+		Players:     []*common.Player{{Name: "Alice", IsTurn: true, TurnCount: 1}, {Name: "Bob"}},
+	}
+	server.Publish(model, true, 1)
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+httpServer.Listener.Addr().String()+"/api/events", nil)
+	if err != nil {
+		t.Fatalf("Expected the WebSocket handshake, got %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	if event := readEvent(t, conn); event.Type != EventState || event.State == nil || len(event.State.Players) != 2 {
+		t.Fatalf("Expected the state first, got %+v", event)
+	}
+
+	model.Players[0].TimeElapsed = 1500 * time.Millisecond
+	model.TotalGameTime = 1500 * time.Millisecond
+	server.Publish(model, true, 1)
+	if event := readEvent(t, conn); event.Type != EventTick || event.Player != 0 || event.TimeElapsedSeconds != 1 {
+		t.Fatalf("Expected a tick of Alice's clock, got %+v", event)
+	}
+
+	model.Players = []*common.Player{{Name: "Alice", TurnCount: 1}, {Name: "Bob", IsTurn: true, TurnCount: 1}}
+	server.Publish(model, true, 1)
+	server.Publish(model, true, 1)
+	if event := readEvent(t, conn); event.Type != EventTurnSwitched || event.Player != 1 || event.Turn != 1 {
+		t.Fatalf("Expected Bob's turn, got %+v", event)
+	}
+
+	// A ping is answered before any further event, as nothing else changed
+	pong := make(chan string, 1)
+	conn.SetPongHandler(func(payload string) error {
+		pong <- payload
+		return nil
+	})
+	_ = conn.WriteControl(websocket.PingMessage, []byte("ping"), time.Now().Add(time.Second))
+	go func() {
+		_, _, _ = conn.ReadMessage()
+	}()
+	select {
+	case payload := <-pong:
+		if payload != "ping" {
+			t.Errorf("Expected the ping to be echoed, got %q", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Expected a pong")
+	}
+}
+
+func TestEventStreamRefusesOtherSitesAndVersions(t *testing.T) {
+	server := newServer(nil)
+	for _, header := range []map[string]string{
+		{"Sec-WebSocket-Version": "13", "Origin": "http://elsewhere.example"},
+		{"Sec-WebSocket-Version": "8"},
+	} {
+		request := httptest.NewRequest(http.MethodGet, "http://hammerclock/api/events", nil)
+		request.Header.Set("Upgrade", "websocket")
+		request.Header.Set("Connection", "Upgrade")
+		request.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		for key, value := range header {
+			request.Header.Set(key, value)
+		}
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, request)
+		if recorder.Code < http.StatusBadRequest {
+			t.Errorf("Expected the handshake with %v to be refused, got %d", header, recorder.Code)
+		}
+	}
+}
+
+// readEvent reads an event from the stream
+func readEvent(t *testing.T, conn *websocket.Conn) Event {
+	t.Helper()
+	messageType, payload, err := conn.ReadMessage()
+	if err != nil || messageType != websocket.TextMessage {
+		t.Fatalf("Expected an event, got %d %v", messageType, err)
+	}
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		t.Fatalf("Failed to decode the event: %v", err)
+	}
+	return event
+}
+
+// get requests a path of the server and decodes its JSON response
+func get(t *testing.T, server *Server, path string, value any) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected %s to answer 200, got %d", path, recorder.Code)
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(value); err != nil {
+		t.Fatalf("Failed to decode %s: %v", path, err)
+	}
+}
+
+func TestOverlayFileKeepsTheClocks(t *testing.T) {
+	model := &common.Model{
+		GameStarted: true,
+		GameStatus:  "Game In Progress",
+		Phases:      []string{"Command Phase", "Movement Phase"},
+		Players: []*common.Player{
+			{Name: "Alice", IsTurn: true, TurnCount: 2, CurrentPhase: 1, TimeElapsed: 12*time.Minute + 4*time.Second},
+			{Name: "Bob", TurnCount: 1, TimeElapsed: 10*time.Minute + 3*time.Second},
+		},
+		TotalGameTime: time.Hour + 2*time.Minute + 3*time.Second,
+	}
+	dir := t.TempDir()
+	text := filepath.Join(dir, "clock.txt")
+	if err := NewOverlayFile(text).Write(model, true, 2); err != nil {
+		t.Fatalf("Failed to write the overlay: %v", err)
+	}
+	expected := "Round 2 - Game In Progress - 1:02:03\n" +
+		"> Alice  12:04  Turn 2  Movement Phase\n" +
+		"  Bob    10:03  Turn 1  Command Phase\n"
+	if data, _ := os.ReadFile(text); string(data) != expected {
+		t.Errorf("Expected the overlay text %q, got %q", expected, data)
+	}
+
+	overlay := NewOverlayFile(filepath.Join(dir, "clock.json"))
+	if err := overlay.Write(model, true, 2); err != nil {
+		t.Fatalf("Failed to write the overlay: %v", err)
+	}
+	var state State
+	data, _ := os.ReadFile(filepath.Join(dir, "clock.json"))
+	if err := json.Unmarshal(data, &state); err != nil || len(state.Players) != 2 || state.Players[0].Phase != "Movement Phase" {
+		t.Errorf("Expected the state as JSON, got %s %v", data, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("Expected only the overlay files, got %d files", len(entries))
+	}
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// Types of the events streamed at /api/events
+const (
+	EventState         = "state"         // Full state, sent first and whenever something without an event of its own changes
+	EventGameStarted   = "gameStarted"   // The game started
+	EventGameEnded     = "gameEnded"     // The game ended
+	EventStatusChanged = "statusChanged" // The game was paused, resumed or is over
+	EventTurnSwitched  = "turnSwitched"  // A player's turn started
+	EventPhaseChanged  = "phaseChanged"  // A player moved to another phase
+	EventTick          = "tick"          // The clocks moved on by a second
+)
+
+// eventBuffer is the number of events kept for a client that is slow to take them, before it is dropped
+const eventBuffer = 64
+
+// Event is a change of the game streamed at /api/events
+type Event struct {
+	Type                 string    `json:"type"`
+	Time                 time.Time `json:"time"`   // Time the change was published
+	Player               int       `json:"player"` // Index of the player the event is about, or the active player; -1 for none
+	Status               string    `json:"status"`
+	Round                int       `json:"round"`
+	Turn                 int       `json:"turn"`
+	Phase                string    `json:"phase"`
+	PhaseIndex           int       `json:"phaseIndex"`
+	TimeElapsedSeconds   int64     `json:"timeElapsedSeconds"`
+	PhaseElapsedSeconds  int64     `json:"phaseElapsedSeconds"`
+	TotalGameTimeSeconds int64     `json:"totalGameTimeSeconds"`
+	State                *State    `json:"state,omitempty"` // Only in state events
+}
+
+// newEvent returns an event about a player of the state, or about no player if the index is -1
+func newEvent(eventType string, state State, player int) Event {
+	event := Event{
+		Type:                 eventType,
+		Time:                 state.Game.UpdatedAt,
+		Player:               player,
+		Status:               state.Game.Status,
+		Round:                state.Game.Round,
+		TotalGameTimeSeconds: state.Game.TotalGameTimeSeconds,
+	}
+	if player >= 0 && player < len(state.Players) {
+		p := state.Players[player]
+		event.Turn = p.Turn
+		event.Phase = p.Phase
+		event.PhaseIndex = p.PhaseIndex
+		event.TimeElapsedSeconds = p.TimeElapsedSeconds
+		event.PhaseElapsedSeconds = p.PhaseElapsedSeconds
+	}
+	if eventType == EventState {
+		event.State = &state
+	}
+	return event
+}
+
+// activePlayer returns the index of the player whose turn it is, or -1 if there is none
+func activePlayer(state State) int {
+	for i, player := range state.Players {
+		if player.IsTurn {
+			return i
+		}
+	}
+	return -1
+}
+
+// changes returns the events between two published states
+func changes(before, after State) []Event {
+	active := activePlayer(after)
+	switch {
+	case !before.Game.Started && after.Game.Started:
+		return []Event{newEvent(EventGameStarted, after, active)}
+	case before.Game.Started && !after.Game.Started:
+		return []Event{newEvent(EventGameEnded, after, -1)}
+	case len(before.Players) != len(after.Players):
+		return []Event{newEvent(EventState, after, active)}
+	}
+
+	var events []Event
+	if before.Game.Status != after.Game.Status {
+		events = append(events, newEvent(EventStatusChanged, after, active))
+	}
+	for i, player := range after.Players {
+		switch previous := before.Players[i]; {
+		case player.IsTurn && (!previous.IsTurn || player.Turn != previous.Turn):
+			events = append(events, newEvent(EventTurnSwitched, after, i))
+		case player.PhaseIndex != previous.PhaseIndex:
+			events = append(events, newEvent(EventPhaseChanged, after, i))
+		}
+	}
+	if len(events) > 0 {
+		return events
+	}
+	if !reflect.DeepEqual(withoutTimes(before), withoutTimes(after)) {
+		return []Event{newEvent(EventState, after, active)}
+	}
+	// Clocks are published more often than their seconds change
+	before.Game.UpdatedAt = after.Game.UpdatedAt
+	if !reflect.DeepEqual(before, after) {
+		return []Event{newEvent(EventTick, after, active)}
+	}
+	return nil
+}
+
+// withoutTimes returns a state with the times that change with every tick left out
+func withoutTimes(state State) State {
+	state.Game.TotalGameTimeSeconds = 0
+	state.Game.PausedTimeSeconds = 0
+	state.Game.UpdatedAt = time.Time{}
+	players := make([]Player, len(state.Players))
+	for i, player := range state.Players {
+		player.TimeElapsedSeconds = 0
+		player.PhaseElapsedSeconds = 0
+		player.TimeRemainingSeconds = nil
+		players[i] = player
+	}
+	state.Players = players
+	return state
+}
+
+// subscribe returns the latest state and a channel receiving the events published after it
+func (s *Server) subscribe() (State, chan Event) {
+	events := make(chan Event, eventBuffer)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers[events] = struct{}{}
+	return s.state, events
+}
+
+// unsubscribe stops sending events to a channel, unless it was dropped already
+func (s *Server) unsubscribe(events chan Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[events]; ok {
+		delete(s.subscribers, events)
+		close(events)
+	}
+}
+
+// notify sends events to all subscribers, dropping the ones whose buffer is full. It must be called with the lock
+// held.
+func (s *Server) notify(events []Event) {
+subscribers:
+	for subscriber := range s.subscribers {
+		for _, event := range events {
+			select {
+			case subscriber <- event:
+			default:
+				delete(s.subscribers, subscriber)
+				close(subscriber)
+				continue subscribers
+			}
+		}
+	}
+}
+
+// serveEvents streams the events over a WebSocket, starting with the latest state, until the client leaves or the
+// server stops
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	defer ws.conn.Close()
+	state, events := s.subscribe()
+	defer s.unsubscribe(events)
+
+	left := make(chan struct{})
+	go func() {
+		ws.readUntilClose()
+		close(left)
+	}()
+
+	if sendEvent(ws, newEvent(EventState, state, activePlayer(state))) != nil {
+		return
+	}
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok || sendEvent(ws, event) != nil {
+				return
+			}
+		case <-left:
+			return
+		case <-s.stop:
+			ws.close()
+			return
+		}
+	}
+}
+
+// sendEvent sends an event as a JSON text message
+func sendEvent(ws *webSocket, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return ws.write(data)
+}
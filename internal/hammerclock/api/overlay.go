@@ -0,0 +1,120 @@
+package api
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+)
+
+// overlayHTML is the stream overlay, a page on a transparent background showing the players' names, clocks, turns
+// and phases, sized for the browser sources of streaming software such as OBS
+//
+//go:embed overlay.html
+var overlayHTML []byte
+
+// OverlayFile is a file kept up to date with the state of the game for the text and browser sources of streaming
+// software: the state as JSON if the file name ends in .json, and a few lines of text otherwise
+type OverlayFile struct {
+	filename string
+	last     []byte // Content last written, so unchanged content is not written again
+}
+
+// NewOverlayFile returns the overlay file written to filename, e.g. "overlay.txt"
+func NewOverlayFile(filename string) *OverlayFile {
+	return &OverlayFile{filename: filename}
+}
+
+// Write writes the state of the model to the file if it changed. The file is replaced in one step, so a source
+// reading it never sees half of it. It is called by the update loop, which owns the model; running tells whether
+// the active player's clock is running and round is the current battle round.
+func (f *OverlayFile) Write(model *common.Model, running bool, round int) error {
+	state := stateFromModel(model, running, round, time.Now())
+	var data []byte
+	if strings.EqualFold(filepath.Ext(f.filename), ".json") {
+		// The time the state was published would change the file on every update
+		state.Game.UpdatedAt = time.Time{}
+		var err error
+		if data, err = json.MarshalIndent(state, "", "  "); err != nil {
+			return err
+		}
+	} else {
+		data = []byte(OverlayText(state))
+	}
+	if bytes.Equal(data, f.last) {
+		return nil
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(f.filename), ".overlay-*")
+	if err != nil {
+		return err
+	}
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), f.filename)
+	}
+	if err != nil {
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	f.last = data
+	return nil
+}
+
+// OverlayText returns the state as text for a text source, the game on the first line and a line per player with
+// the active player marked, e.g.
+//
+//	Round 2 - Game In Progress - 1:02:03
+//	> Alice  12:04  Turn 2  Movement Phase
+//	  Bob    10:03  Turn 1  Command Phase
+func OverlayText(state State) string {
+	var text strings.Builder
+	header := []string{state.Game.Status, formatTime(state.Game.TotalGameTimeSeconds)}
+	if state.Game.Round > 0 {
+		header = append([]string{fmt.Sprintf("Round %d", state.Game.Round)}, header...)
+	}
+	text.WriteString(strings.Join(header, " - ") + "\n")
+
+	width := 0
+	for _, player := range state.Players {
+		width = max(width, len([]rune(player.Name)))
+	}
+	for _, player := range state.Players {
+		marker := "  "
+		if player.IsTurn {
+			marker = "> "
+		}
+		// With a time budget the clock shows the time left, otherwise the time used
+		seconds := player.TimeElapsedSeconds
+		if player.TimeRemainingSeconds != nil {
+			seconds = *player.TimeRemainingSeconds
+		}
+		line := fmt.Sprintf("%s%-*s  %s", marker, width, player.Name, formatTime(seconds))
+		if state.Game.Started {
+			line += fmt.Sprintf("  Turn %d  %s", player.Turn, player.Phase)
+		}
+		text.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	return text.String()
+}
+
+// formatTime formats seconds as a clock, e.g. "12:04" or "1:02:03", with a minus sign once a player ran out of time
+func formatTime(seconds int64) string {
+	sign := ""
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+	if seconds >= 3600 {
+		return fmt.Sprintf("%s%d:%02d:%02d", sign, seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%s%d:%02d", sign, seconds/60, seconds%60)
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxMessageSize is the largest message accepted from a client, which only sends control frames
+const maxMessageSize = 1 << 16
+
+// writeTimeout is the time a client has to take a message before it is dropped
+const writeTimeout = 10 * time.Second
+
+// upgrader answers the WebSocket handshake. It only accepts version 13 of the protocol and, from browsers, pages
+// served by Hammerclock itself, so that a page on another site cannot follow the game through a player's browser.
+var upgrader = websocket.Upgrader{
+	HandshakeTimeout: writeTimeout,
+}
+
+// webSocket is the server side of a WebSocket connection. Messages can be written from several goroutines.
+type webSocket struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+// upgradeWebSocket answers the WebSocket handshake of a request, taking the connection over from the HTTP server.
+// Refused requests are answered with an HTTP error.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*webSocket, error) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetReadLimit(maxMessageSize)
+	return &webSocket{conn: conn}, nil
+}
+
+// write sends a text message
+func (ws *webSocket) write(data []byte) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	_ = ws.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return ws.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// close tells the client that the server goes away
+func (ws *webSocket) close() {
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+	_ = ws.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(writeTimeout))
+}
+
+// readUntilClose reads the client's messages, answering pings, until the client closes the connection or it breaks
+func (ws *webSocket) readUntilClose() {
+	for {
+		if _, _, err := ws.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,428 @@
+package hammerclock
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"hammerclock/internal/hammerclock/battlescribe"
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/logging"
+	"hammerclock/internal/hammerclock/options"
+	"hammerclock/internal/hammerclock/rules"
+)
+
+// armyPlayer returns the index of the player whose army list is shown: the player whose log has the keyboard focus,
+// or else the first active player. It returns -1 if there is no such player.
+func armyPlayer(model common.Model) int {
+	playerIndex := slices.IndexFunc(model.Players, func(player *common.Player) bool { return player.IsTurn })
+	if model.LogFocused || playerIndex < 0 {
+		playerIndex = model.FocusedLog
+	}
+	if playerIndex < 0 || playerIndex >= len(model.Players) {
+		return -1
+	}
+	return playerIndex
+}
+
+// handleShowArmyEditor opens the army list editor of a player, see armyPlayer
+func handleShowArmyEditor(model common.Model) (common.Model, Command) {
+	playerIndex := armyPlayer(model)
+	if playerIndex < 0 {
+		return model, noCommand
+	}
+	return model, func() common.Message {
+		return &common.ShowModalMsg{Type: "ArmyEditor", PlayerIndex: playerIndex}
+	}
+}
+
+// handleShowUnitStatus opens the unit status list of a player, see armyPlayer, if the player has an army list
+func handleShowUnitStatus(model common.Model) (common.Model, Command) {
+	playerIndex := armyPlayer(model)
+	if playerIndex < 0 || len(model.Players[playerIndex].ArmyList) == 0 {
+		return model, noCommand
+	}
+	return model, func() common.Message {
+		return &common.ShowModalMsg{Type: "UnitStatus", PlayerIndex: playerIndex}
+	}
+}
+
+// handleSetUnitStatus changes the status of a unit and logs the change, so the fate of the units can be reviewed
+// after the game
+func handleSetUnitStatus(msg *common.SetUnitStatusMsg, model common.Model) (common.Model, Command) {
+	if msg.PlayerIndex < 0 || msg.PlayerIndex >= len(model.Players) {
+		return model, noCommand
+	}
+	if msg.Unit < 0 || msg.Unit >= len(model.Players[msg.PlayerIndex].ArmyList) {
+		return model, noCommand
+	}
+
+	newModel := copyPlayers(model)
+	player := newModel.Players[msg.PlayerIndex]
+	player.ArmyList = slices.Clone(player.ArmyList)
+	unit := &player.ArmyList[msg.Unit]
+	if unit.Status == msg.Status {
+		return model, noCommand
+	}
+	if msg.Status == "" {
+		logging.AddLogEntry(player, &newModel, common.LogTypeGame, "%s no longer %s", unit.Name, unit.Status)
+	} else {
+		logging.AddLogEntry(player, &newModel, common.LogTypeGame, "%s %s", unit.Name, msg.Status)
+	}
+	wasDestroyed := unit.Status == "destroyed"
+	unit.Status = msg.Status
+	if unit.Status != "destroyed" {
+		unit.DestroyedBy = ""
+		scoreKill(&newModel, unit, -1)
+	} else if !wasDestroyed {
+		scoreKill(&newModel, unit, killScorer(newModel, msg.PlayerIndex))
+	}
+	return newModel, noCommand
+}
+
+// killScorer returns the index of the player who scores for destroying a unit of the given player when it is not
+// recorded who destroyed it: the opponent in a game of two, otherwise the active player, or -1 if that is the
+// unit's own player
+func killScorer(model common.Model, playerIndex int) int {
+	if len(model.Players) == 2 {
+		return 1 - playerIndex
+	}
+	scorer := slices.IndexFunc(model.Players, func(player *common.Player) bool { return player.IsTurn })
+	if scorer == playerIndex {
+		return -1
+	}
+	return scorer
+}
+
+// scoreKill gives the victory points the ruleset scores for destroying a unit to the player with the given index,
+// taking them back from the player who scored them before. A scorer of -1 takes them back only.
+func scoreKill(model *common.Model, unit *common.Unit, scorer int) {
+	if unit.KillScore > 0 && unit.ScoredBy != scorer {
+		player := model.Players[unit.ScoredBy]
+		player.VictoryPoints -= unit.KillScore
+		logging.AddLogEntry(player, model, common.LogTypeScore, "Lost %d VP for %s (total %d VP)",
+			unit.KillScore, unit.Name, player.VictoryPoints)
+		unit.KillScore = 0
+	}
+	if scorer < 0 || scorer >= len(model.Players) || unit.KillScore > 0 {
+		return
+	}
+
+	score := rules.KillScore(model.Options.Rules[model.Options.Default].KillPoints, unit.Points)
+	if score <= 0 {
+		return
+	}
+	player := model.Players[scorer]
+	player.VictoryPoints += score
+	logging.AddLogEntry(player, model, common.LogTypeScore, "Scored %d VP for destroying %s (total %d VP)",
+		score, unit.Name, player.VictoryPoints)
+	unit.KillScore, unit.ScoredBy = score, scorer
+}
+
+// handleSetUnitDestroyedBy records the opposing unit that destroyed a unit, for the MVP units of the battle report
+func handleSetUnitDestroyedBy(msg *common.SetUnitDestroyedByMsg, model common.Model) (common.Model, Command) {
+	if msg.PlayerIndex < 0 || msg.PlayerIndex >= len(model.Players) {
+		return model, noCommand
+	}
+	if msg.Unit < 0 || msg.Unit >= len(model.Players[msg.PlayerIndex].ArmyList) {
+		return model, noCommand
+	}
+	if model.Players[msg.PlayerIndex].ArmyList[msg.Unit].Status != "destroyed" || msg.DestroyedBy == "" {
+		return model, noCommand
+	}
+
+	newModel := copyPlayers(model)
+	player := newModel.Players[msg.PlayerIndex]
+	player.ArmyList = slices.Clone(player.ArmyList)
+	unit := &player.ArmyList[msg.Unit]
+	unit.DestroyedBy = msg.DestroyedBy
+	logging.AddLogEntry(player, &newModel, common.LogTypeGame, "%s destroyed by %s", unit.Name, unit.DestroyedBy)
+
+	// The player of the opposing unit scores for it
+	killer := slices.IndexFunc(newModel.Players, func(p *common.Player) bool {
+		return strings.HasPrefix(msg.DestroyedBy, p.Name+": ")
+	})
+	if killer >= 0 && killer != msg.PlayerIndex {
+		scoreKill(&newModel, unit, killer)
+	}
+	return newModel, noCommand
+}
+
+// opposingUnits returns the units of the other players that are not destroyed, as "player: unit", to pick the unit
+// that destroyed a unit of the player from
+func opposingUnits(model *common.Model, playerIndex int) []string {
+	var units []string
+	for i, player := range model.Players {
+		if i == playerIndex {
+			continue
+		}
+		for _, unit := range player.ArmyList {
+			if unit.Status != "destroyed" {
+				units = append(units, player.Name+": "+unit.Name)
+			}
+		}
+	}
+	return units
+}
+
+// handleSetUnitWounds changes the wounds a unit lost and logs the damage or healing with the wounds left. The wounds
+// lost are kept between none and all of the unit's wounds.
+func handleSetUnitWounds(msg *common.SetUnitWoundsMsg, model common.Model) (common.Model, Command) {
+	if msg.PlayerIndex < 0 || msg.PlayerIndex >= len(model.Players) {
+		return model, noCommand
+	}
+	if msg.Unit < 0 || msg.Unit >= len(model.Players[msg.PlayerIndex].ArmyList) {
+		return model, noCommand
+	}
+	unit := model.Players[msg.PlayerIndex].ArmyList[msg.Unit]
+	lost := min(max(msg.WoundsLost, 0), unit.Wounds)
+	if unit.Wounds == 0 || lost == unit.WoundsLost {
+		return model, noCommand
+	}
+
+	newModel := copyPlayers(model)
+	player := newModel.Players[msg.PlayerIndex]
+	player.ArmyList = slices.Clone(player.ArmyList)
+	changed := &player.ArmyList[msg.Unit]
+	action, wounds := "lost", lost-changed.WoundsLost
+	if wounds < 0 {
+		action, wounds = "healed", -wounds
+	}
+	changed.WoundsLost = lost
+	logging.AddLogEntry(player, &newModel, common.LogTypeGame, "%s %s %d W (%d/%d W left)", changed.Name, action,
+		wounds, changed.Wounds-lost, changed.Wounds)
+	return newModel, noCommand
+}
+
+// handleSetUnitNote changes the note of a unit and logs the new note
+func handleSetUnitNote(msg *common.SetUnitNoteMsg, model common.Model) (common.Model, Command) {
+	if msg.PlayerIndex < 0 || msg.PlayerIndex >= len(model.Players) {
+		return model, noCommand
+	}
+	if msg.Unit < 0 || msg.Unit >= len(model.Players[msg.PlayerIndex].ArmyList) {
+		return model, noCommand
+	}
+
+	newModel := copyPlayers(model)
+	player := newModel.Players[msg.PlayerIndex]
+	player.ArmyList = slices.Clone(player.ArmyList)
+	unit := &player.ArmyList[msg.Unit]
+	if unit.Note == msg.Note {
+		return model, noCommand
+	}
+	if msg.Note == "" {
+		logging.AddLogEntry(player, &newModel, common.LogTypeGame, "Note on %s removed", unit.Name)
+	} else {
+		logging.AddLogEntry(player, &newModel, common.LogTypeGame, "Note on %s: %s", unit.Name, msg.Note)
+	}
+	unit.Note = msg.Note
+	return newModel, noCommand
+}
+
+// handleSetArmyList replaces the army list of a player and saves it with the player's profile, creating the profile
+// if needed, and at an event as a new version of the player's list for the event. Units that keep their name keep
+// their status, note and kill. The options still need to be saved to keep the list.
+func handleSetArmyList(msg *common.SetArmyListMsg, model common.Model) (common.Model, Command) {
+	if msg.PlayerIndex < 0 || msg.PlayerIndex >= len(model.Players) {
+		return model, noCommand
+	}
+
+	newModel := copyPlayers(model)
+	player := newModel.Players[msg.PlayerIndex]
+	units := make([]common.Unit, len(msg.Units))
+	for i, unit := range msg.Units {
+		units[i] = common.Unit{Name: unit.Name, Points: unit.Points, Wounds: unit.Wounds}
+		if old := slices.IndexFunc(player.ArmyList, func(u common.Unit) bool { return u.Name == unit.Name }); old >= 0 {
+			units[i] = player.ArmyList[old]
+			units[i].Points, units[i].Wounds = unit.Points, unit.Wounds
+			units[i].WoundsLost = min(units[i].WoundsLost, unit.Wounds)
+		}
+	}
+	player.ArmyList = units
+
+	army := make([]options.ArmyUnit, len(units))
+	for i, unit := range units {
+		army[i] = options.ArmyUnit{Name: unit.Name, Points: unit.Points, Wounds: unit.Wounds}
+	}
+	version := options.SetProfileArmy(&newModel.Options, player.Name, army)
+	message := fmt.Sprintf("Army list set: %d units, %d pts", len(units), armyPoints(units))
+	if version > 0 {
+		message += fmt.Sprintf(" (version %d for %s)", version, newModel.Options.EventName)
+	}
+	logging.AddLogEntry(player, &newModel, common.LogTypeGame, "%s", message)
+	return newModel, noCommand
+}
+
+// handleImportRoster reads a BattleScribe roster for a player, without holding up the update loop
+func handleImportRoster(msg *common.ImportRosterMsg, model common.Model) (common.Model, Command) {
+	if msg.PlayerIndex < 0 || msg.PlayerIndex >= len(model.Players) || msg.File == "" {
+		return model, noCommand
+	}
+	return model, func() common.Message {
+		roster, err := battlescribe.ReadFile(msg.File)
+		return &common.RosterImportedMsg{
+			PlayerIndex: msg.PlayerIndex,
+			File:        msg.File,
+			Faction:     roster.Faction,
+			Units:       roster.Units,
+			Err:         err,
+		}
+	}
+}
+
+// handleRosterImported makes the units of a BattleScribe roster the player's army list, see handleSetArmyList, and
+// the roster's catalogue the player's faction if they have none. A roster that could not be read is logged as a
+// warning.
+func handleRosterImported(msg *common.RosterImportedMsg, model common.Model) (common.Model, Command) {
+	if msg.PlayerIndex < 0 || msg.PlayerIndex >= len(model.Players) {
+		return model, noCommand
+	}
+	if msg.Err != nil {
+		newModel := copyPlayers(model)
+		logging.AddLogEntry(newModel.Players[msg.PlayerIndex], &newModel, common.LogTypeWarning,
+			"Roster not imported: %v", msg.Err)
+		return newModel, noCommand
+	}
+
+	newModel, cmd := handleSetArmyList(&common.SetArmyListMsg{PlayerIndex: msg.PlayerIndex, Units: msg.Units}, model)
+	player := newModel.Players[msg.PlayerIndex]
+	if player.Faction == "" && msg.Faction != "" {
+		player.Faction = msg.Faction
+		logging.AddLogEntry(player, &newModel, common.LogTypeGame, "Faction set to %s from the roster %s",
+			msg.Faction, filepath.Base(msg.File))
+	}
+	return newModel, cmd
+}
+
+// ProfileArmy returns the army list saved with the profile of the named player, for the event of the options if the
+// player has one for it, or none if the player has no profile. It is loaded when a player takes a seat.
+func ProfileArmy(opts options.Options, name string) []common.Unit {
+	army := options.ProfileArmy(opts, name)
+	if army == nil {
+		return nil
+	}
+	units := make([]common.Unit, len(army))
+	for i, unit := range army {
+		units[i] = common.Unit{Name: unit.Name, Points: unit.Points, Wounds: unit.Wounds}
+	}
+	return units
+}
+
+// resetArmyList returns a copy of an army list without the status, damage and notes of the units, for a new game
+func resetArmyList(units []common.Unit) []common.Unit {
+	if units == nil {
+		return nil
+	}
+	reset := make([]common.Unit, len(units))
+	for i, unit := range units {
+		reset[i] = common.Unit{Name: unit.Name, Points: unit.Points, Wounds: unit.Wounds}
+	}
+	return reset
+}
+
+// armyUnderspend is the share of the game size an army list can fall short of without a warning
+const armyUnderspend = 0.1
+
+// checkArmyPoints warns the players whose army list exceeds the game size, or falls well short of it, when the game
+// starts. Players without an army list are not checked.
+func checkArmyPoints(model *common.Model) {
+	size := model.Options.GameSize
+	if size <= 0 {
+		return
+	}
+	for _, player := range model.Players {
+		if len(player.ArmyList) == 0 {
+			continue
+		}
+		points := armyPoints(player.ArmyList)
+		switch {
+		case points > size:
+			logging.AddLogEntry(player, model, common.LogTypeWarning,
+				"Army list of %d pts is %d pts over the game size of %d pts", points, points-size, size)
+		case float64(size-points) > float64(size)*armyUnderspend:
+			logging.AddLogEntry(player, model, common.LogTypeWarning,
+				"Army list of %d pts is %d pts under the game size of %d pts", points, size-points, size)
+		}
+	}
+}
+
+// armyPoints returns the points total of an army list
+func armyPoints(units []common.Unit) int {
+	points := 0
+	for _, unit := range units {
+		points += unit.Points
+	}
+	return points
+}
+
+// armyLinePoints matches a unit with its points at the end of a line, e.g. "Intercessors, 200",
+// "Intercessors - 200 pts" or "Intercessors [200 points]"
+var armyLinePoints = regexp.MustCompile(`(?i)^(.*?)[\s,:–-]*[(\[]?\s*(\d+)\s*(?:pts?|points?)?\s*[)\]]?$`)
+
+// armyLineWounds matches the wounds of a unit at the end of a line, after its points, e.g. ", 10W" or " (5 wounds)"
+var armyLineWounds = regexp.MustCompile(`(?i)[\s,;]+[(\[]?\s*(\d+)\s*(?:w|wounds?)\s*[)\]]?$`)
+
+// listMarker matches the marker of a Markdown list item, e.g. "- ", "* " or "1. "
+var listMarker = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+`)
+
+// armyListText returns the text of an army list in the editor, a unit per line
+func armyListText(units []common.Unit) string {
+	lines := make([]string, len(units))
+	for i, unit := range units {
+		lines[i] = fmt.Sprintf("%s, %d", unit.Name, unit.Points)
+		if unit.Wounds > 0 {
+			lines[i] += fmt.Sprintf(", %dW", unit.Wounds)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseArmyList parses the units of an army list typed or pasted as plain text or Markdown, one unit per line with
+// its points at the end, e.g. "Intercessors, 200", "- Intercessors (200 pts)" or a table row "| Intercessors | 200 |".
+// The points may be followed by the unit's wounds, e.g. "Intercessors, 200, 10W". Units without points cost none. Empty lines, headings, rules and table rows without points are skipped.
+func parseArmyList(text string) []common.Unit {
+	var units []common.Unit
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.Trim(line, "-=*_`|: ") == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "|") {
+			if unit, found := parseArmyTableRow(line); found {
+				units = append(units, unit)
+			}
+			continue
+		}
+
+		line = strings.ReplaceAll(listMarker.ReplaceAllString(line, ""), "**", "")
+		wounds := 0
+		if match := armyLineWounds.FindStringSubmatch(line); match != nil {
+			wounds, _ = strconv.Atoi(match[1])
+			line = strings.TrimSuffix(line, match[0])
+		}
+		unit := common.Unit{Name: line, Wounds: wounds}
+		if match := armyLinePoints.FindStringSubmatch(line); match != nil && strings.TrimSpace(match[1]) != "" {
+			points, _ := strconv.Atoi(match[2])
+			unit = common.Unit{Name: strings.TrimSpace(match[1]), Points: points, Wounds: wounds}
+		}
+		units = append(units, unit)
+	}
+	return units
+}
+
+// parseArmyTableRow parses a row of a Markdown table as a unit: the first cell is the name, and the first cell after
+// it holding a number the points. Rows without points, such as the header, are not units.
+func parseArmyTableRow(line string) (common.Unit, bool) {
+	cells := strings.Split(strings.Trim(line, "|"), "|")
+	name := strings.TrimSpace(strings.ReplaceAll(cells[0], "**", ""))
+	for _, cell := range cells[1:] {
+		cell = strings.TrimSpace(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(cell)), "pts"))
+		if points, err := strconv.Atoi(cell); err == nil && points >= 0 && name != "" {
+			return common.Unit{Name: name, Points: points}, true
+		}
+	}
+	return common.Unit{}, false
+}
@@ -0,0 +1,187 @@
+// Package audit keeps a tamper-evident record of the interventions of a judge. Each line of the audit log carries an
+// HMAC of its own content and of the HMAC of the line before it, keyed with the judge passphrase, so a line that is
+// edited, inserted or removed breaks the chain from there on. The HMAC of the last line is sealed in a file next to
+// the log, so a log cut short is detected as well. Without the passphrase the chain cannot be recomputed to hide
+// the change.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the audit log, written next to the CSV log
+const FileName = "audit.log"
+
+// SealSuffix is added to the name of the audit log for the file sealing its last line
+const SealSuffix = ".seal"
+
+// Entry is a judge intervention
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Table   string    `json:"table,omitempty"`  // Event and table the intervention was made at
+	Action  string    `json:"action"`           // e.g. "login", "penalty", "pause" or "score"
+	Player  string    `json:"player,omitempty"` // Player the intervention concerns, if any
+	Details string    `json:"details,omitempty"`
+	Prev    string    `json:"prev"` // MAC of the previous entry, empty for the first entry
+	MAC     string    `json:"mac"`  // HMAC of the entry without its MAC, chained to the previous entry through Prev
+}
+
+// mu serializes appends, so concurrent interventions do not link to the same previous line
+var mu sync.Mutex
+
+// Append adds an entry to the audit log in filename, chaining it to the last entry with the key, and seals the log
+// with the new entry
+func Append(filename, key string, entry Entry) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	count, last, err := tail(filename)
+	if err != nil {
+		return err
+	}
+	entry.Prev = last
+	entry.MAC = ""
+	if entry.MAC, err = entryMAC(key, entry); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return writeSeal(filename, seal(key, count+1, entry.MAC))
+}
+
+// Verify checks the chain of the audit log in filename and its seal with the key. It returns the number of entries
+// and the MAC of the last one, which can be noted down to check later that no entries were removed along with the
+// seal. The error names the first line that does not match the line before it.
+func Verify(filename, key string) (int, string, error) {
+	count, last, err := readChain(filename, key)
+	if err != nil {
+		return count, last, err
+	}
+
+	sealed, err := os.ReadFile(filename + SealSuffix)
+	if err != nil {
+		return count, last, fmt.Errorf("the seal of the log cannot be read: %w", err)
+	}
+	if !hmac.Equal(sealed, []byte(seal(key, count, last))) {
+		return count, last, errors.New("the log does not end with the sealed entry, entries were removed")
+	}
+	return count, last, nil
+}
+
+// readChain reads the audit log in filename and checks the chain of its entries with the key. It returns the number
+// of entries and the MAC of the last one, or of the last one that is intact if the chain is broken.
+func readChain(filename, key string) (int, string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+
+	last := ""
+	count := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return count, last, fmt.Errorf("line %d is not an audit entry", count+1)
+		}
+		expected, err := entryMAC(key, entry)
+		if err != nil {
+			return count, last, err
+		}
+		if entry.Prev != last || !hmac.Equal([]byte(entry.MAC), []byte(expected)) {
+			return count, last, fmt.Errorf("line %d does not follow the line before it, the log was changed", count+1)
+		}
+		last = entry.MAC
+		count++
+	}
+	return count, last, scanner.Err()
+}
+
+// tail returns the number of entries of the audit log in filename and the MAC of the last one, without checking the
+// chain. A missing log has none.
+func tail(filename string) (int, string, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return 0, "", nil
+	} else if err != nil {
+		return 0, "", err
+	}
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	if len(lines[0]) == 0 {
+		return 0, "", nil
+	}
+	var last Entry
+	if err := json.Unmarshal(lines[len(lines)-1], &last); err != nil {
+		return 0, "", fmt.Errorf("the last line of the audit log is not an audit entry")
+	}
+	return len(lines), last.MAC, nil
+}
+
+// entryMAC returns the HMAC of an entry without its MAC with the key, hex encoded
+func entryMAC(key string, entry Entry) (string, error) {
+	entry.MAC = ""
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	return sign(key, content), nil
+}
+
+// seal returns the seal of a log with count entries whose last entry has the given MAC
+func seal(key string, count int, last string) string {
+	return sign(key, []byte("seal:"+strconv.Itoa(count)+":"+last))
+}
+
+// writeSeal replaces the seal of the audit log in filename at once, so it is never seen half written
+func writeSeal(filename, seal string) error {
+	temp, err := os.CreateTemp(filepath.Dir(filename), ".audit-*")
+	if err != nil {
+		return err
+	}
+	_, err = temp.WriteString(seal)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), filename+SealSuffix)
+	}
+	if err != nil {
+		_ = os.Remove(temp.Name())
+	}
+	return err
+}
+
+// sign returns the HMAC of data with the key, hex encoded
+func sign(key string, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyDetectsChangedEntries(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), FileName)
+	at := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	for _, entry := range []Entry{
+		{Time: at, Action: "login"},
+		{Time: at.Add(time.Minute), Action: "penalty", Player: "Alice", Details: "2m0s - slow play"},
+		{Time: at.Add(2 * time.Minute), Action: "score", Player: "Bob", Details: "-3 VP"},
+	} {
+		if err := Append(filename, "secret", entry); err != nil {
+			t.Fatalf("Failed to append an entry: %v", err)
+		}
+	}
+
+	if count, _, err := Verify(filename, "secret"); err != nil || count != 3 {
+		t.Fatalf("Expected an intact log of 3 entries, got %d and %v", count, err)
+	}
+	if _, _, err := Verify(filename, "guess"); err == nil {
+		t.Error("Expected the log not to verify with another passphrase")
+	}
+
+	data, _ := os.ReadFile(filename)
+	for _, change := range []struct {
+		name, log string
+		count     int
+	}{
+		{"changed penalty", strings.Replace(string(data), "2m0s", "1m0s", 1), 1},
+		{"changed last entry", strings.Replace(string(data), "-3 VP", "-1 VP", 1), 2},
+		{"removed last entry", string(data[:bytes.LastIndexByte(data[:len(data)-1], '\n')+1]), 2},
+	} {
+		if err := os.WriteFile(filename, []byte(change.log), 0644); err != nil {
+			t.Fatalf("Failed to change the log: %v", err)
+		}
+		if count, _, err := Verify(filename, "secret"); err == nil || count != change.count {
+			t.Errorf("Expected the %s to be detected after %d entries, got %d and %v", change.name, change.count, count, err)
+		}
+	}
+}
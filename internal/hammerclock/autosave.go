@@ -0,0 +1,204 @@
+package hammerclock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/logging"
+	"hammerclock/internal/hammerclock/rules"
+)
+
+// AutosaveFileName is the name of the autosave of the game shown, written to the data directory
+const AutosaveFileName = "autosave.json"
+
+// SaveFileName is the name of the file the game shown is saved to on demand, written to the data directory
+const SaveFileName = "saved-game.json"
+
+// RunningFileName is the name of the file marking a running Hammerclock in the data directory. It is removed when
+// Hammerclock quits, so finding it at the next start means the run crashed or was killed.
+const RunningFileName = "running"
+
+// AutosaveInterval is the time between two autosaves of a running game
+const AutosaveInterval = 10 * time.Second
+
+// Autosave writes the game to the autosave file. The file is replaced in one step, so a crash while writing keeps
+// the previous save.
+func Autosave(filename string, model *common.Model, now time.Time) error {
+	saved := common.SavedGame{
+		SavedAt:       now,
+		GameStatus:    model.GameStatus,
+		TotalGameTime: model.TotalGameTime,
+		PausedTime:    model.PausedTime,
+		Players:       make([]common.Player, len(model.Players)),
+		Timers:        model.Timers,
+		Sequence:      model.SequenceResults,
+		History:       model.History,
+	}
+	if model.Options.Default < len(model.Options.Rules) {
+		saved.Ruleset = model.Options.Rules[model.Options.Default].Name
+	}
+	for i, player := range model.Players {
+		saved.Players[i] = *player
+	}
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(filename), ".autosave-*")
+	if err != nil {
+		return err
+	}
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), filename)
+	}
+	if err != nil {
+		_ = os.Remove(temp.Name())
+	}
+	return err
+}
+
+// MarkRunning creates the file marking a running Hammerclock, and reports whether it was left behind by a run that did
+// not shut down cleanly
+func MarkRunning(filename string) (bool, error) {
+	_, err := os.Stat(filename)
+	interrupted := err == nil
+	return interrupted, os.WriteFile(filename, []byte(strconv.Itoa(os.Getpid())), 0644)
+}
+
+// LoadAutosave reads the game of the autosave file, or of the file the game was saved to on demand
+func LoadAutosave(filename string) (*common.SavedGame, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var saved common.SavedGame
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	saved.File = filename
+	return &saved, nil
+}
+
+// SavedGameText describes a saved game for the offer to continue it, e.g. "Alice vs Bob, battle round 3, saved
+// 2025-06-01 14:32"
+func SavedGameText(saved *common.SavedGame) string {
+	names := make([]string, len(saved.Players))
+	round := 0
+	for i, player := range saved.Players {
+		names[i] = player.Name
+		if i == 0 || player.TurnCount < round {
+			round = player.TurnCount
+		}
+	}
+	return fmt.Sprintf("%s, battle round %d, saved %s", strings.Join(names, " vs "), round+1,
+		saved.SavedAt.Format("2006-01-02 15:04"))
+}
+
+// handleSaveGame saves the game to the save file on demand. The game is offered to be continued at the next start
+// until it ends, whatever the resumeGame option.
+func handleSaveGame(model common.Model) (common.Model, Command) {
+	if !model.GameStarted || model.SaveFile == "" {
+		return model, noCommand
+	}
+	game := copyPlayers(model)
+	return model, func() common.Message {
+		err := Autosave(game.SaveFile, &game, time.Now())
+		return &common.GameSavedMsg{File: game.SaveFile, Err: err}
+	}
+}
+
+// handleGameSaved logs for the active player whether the game was saved
+func handleGameSaved(msg *common.GameSavedMsg, model common.Model) (common.Model, Command) {
+	newModel := copyPlayers(model)
+	for _, player := range newModel.Players {
+		if !player.IsTurn {
+			continue
+		}
+		if msg.Err != nil {
+			logging.AddLogEntry(player, &newModel, common.LogTypeWarning, "Saving the game failed: %v", msg.Err)
+		} else {
+			logging.AddLogEntry(player, &newModel, common.LogTypeGame, "Game saved to %s", msg.File)
+		}
+		break
+	}
+	return newModel, noCommand
+}
+
+// handleOfferSavedGame asks whether to continue the unfinished game of the autosave
+func handleOfferSavedGame(msg *common.OfferSavedGameMsg, model common.Model) (common.Model, Command) {
+	if msg.Game == nil || len(msg.Game.Players) == 0 || model.GameStarted {
+		return model, noCommand
+	}
+	newModel := model
+	newModel.SavedGame = msg.Game
+	return newModel, func() common.Message {
+		return &common.ShowModalMsg{Type: "SavedGame"}
+	}
+}
+
+// handleResumeSavedGame continues the saved game, paused, with its ruleset, players, logs and timers; or discards the
+// file it was saved to, to start fresh
+func handleResumeSavedGame(msg *common.ResumeSavedGameMsg, model common.Model) (common.Model, Command) {
+	saved := model.SavedGame
+	newModel := model
+	newModel.SavedGame = nil
+	if saved == nil {
+		return newModel, noCommand
+	}
+	if !msg.Continue {
+		file := saved.File
+		if file == "" {
+			file = newModel.AutosaveFile
+		}
+		if file == "" {
+			return newModel, noCommand
+		}
+		return newModel, func() common.Message {
+			_ = os.Remove(file)
+			return nil
+		}
+	}
+
+	if i := slices.IndexFunc(model.Options.Rules, func(r rules.Rules) bool { return r.Name == saved.Ruleset }); i >= 0 {
+		newModel.Options.Default = i
+		newModel.Phases = RulesetPhases(newModel.Options)
+	}
+	newModel.Players = make([]*common.Player, len(saved.Players))
+	for i := range saved.Players {
+		player := saved.Players[i]
+		newModel.Players[i] = &player
+	}
+	newModel.GameStarted = true
+	newModel.GameStatus = gamePaused
+	newModel.TotalGameTime = saved.TotalGameTime
+	newModel.PausedTime = saved.PausedTime
+	newModel.Timers = saved.Timers
+	newModel.SequenceResults = saved.Sequence
+	newModel.History = saved.History
+	newModel.Redo = nil
+	newModel.LastTurnSwitch = nil
+
+	source := "autosave"
+	if saved.File != "" && saved.File == model.SaveFile {
+		source = "save"
+	}
+	for _, player := range newModel.Players {
+		if player.IsTurn {
+			logging.AddLogEntry(player, &newModel, common.LogTypeGame, "Game continued from the %s of %s", source,
+				saved.SavedAt.Format("2006-01-02 15:04:05"))
+		}
+	}
+	return newModel, noCommand
+}
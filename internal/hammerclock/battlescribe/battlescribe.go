@@ -0,0 +1,145 @@
+// Package battlescribe imports the army lists of BattleScribe rosters, the .ros files and their zipped .rosz form,
+// which other roster builders such as New Recruit export too. Each unit chosen in the roster becomes a unit of the
+// army list, with the points of the unit and everything chosen for it.
+package battlescribe
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"hammerclock/internal/hammerclock/common"
+)
+
+// maxRosterSize is the largest roster read, far above the size of any real army
+const maxRosterSize = 16 << 20
+
+// Roster is the army list of a roster
+type Roster struct {
+	Name    string // Name the roster was saved under, e.g. "Spring Cup list"
+	Faction string // Catalogue of the first force, e.g. "Imperium - Space Marines"
+	Units   []common.Unit
+}
+
+// rosterXML is the root element of a .ros file
+type rosterXML struct {
+	Name   string     `xml:"name,attr"`
+	Forces []forceXML `xml:"forces>force"`
+}
+
+// forceXML is a detachment of the roster, which may hold forces of its own
+type forceXML struct {
+	CatalogueName string         `xml:"catalogueName,attr"`
+	Selections    []selectionXML `xml:"selections>selection"`
+	Forces        []forceXML     `xml:"forces>force"`
+}
+
+// selectionXML is an entry chosen in the roster: a unit, a model or an upgrade, with the entries chosen for it
+type selectionXML struct {
+	Name       string         `xml:"name,attr"`
+	Type       string         `xml:"type,attr"`
+	Costs      []costXML      `xml:"costs>cost"`
+	Selections []selectionXML `xml:"selections>selection"`
+}
+
+// costXML is a cost of a selection, e.g. its points or power level
+type costXML struct {
+	Name   string  `xml:"name,attr"`
+	TypeID string  `xml:"typeId,attr"`
+	Value  float64 `xml:"value,attr"`
+}
+
+// IsRoster reports whether the file name is one of a BattleScribe roster, ending in .ros or .rosz
+func IsRoster(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".ros" || ext == ".rosz"
+}
+
+// ReadFile reads the roster of a .ros or .rosz file
+func ReadFile(filename string) (Roster, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return Roster{}, err
+	}
+	if strings.EqualFold(filepath.Ext(filename), ".rosz") {
+		if data, err = unzip(data); err != nil {
+			return Roster{}, fmt.Errorf("reading %s: %w", filename, err)
+		}
+	}
+	roster, err := Parse(data)
+	if err != nil {
+		return Roster{}, fmt.Errorf("reading %s: %w", filename, err)
+	}
+	return roster, nil
+}
+
+// unzip returns the .ros file zipped in a .rosz file
+func unzip(data []byte) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range archive.File {
+		if !strings.EqualFold(filepath.Ext(file.Name), ".ros") {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return io.ReadAll(io.LimitReader(reader, maxRosterSize))
+	}
+	return nil, errors.New("no .ros file in the archive")
+}
+
+// Parse reads the roster of the XML of a .ros file. The units are the units and models chosen in the forces, and any
+// other entry with points, in roster order.
+func Parse(data []byte) (Roster, error) {
+	var parsed rosterXML
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return Roster{}, err
+	}
+	roster := Roster{Name: parsed.Name}
+	var addForces func(forces []forceXML)
+	addForces = func(forces []forceXML) {
+		for _, force := range forces {
+			if roster.Faction == "" {
+				roster.Faction = force.CatalogueName
+			}
+			for _, selection := range force.Selections {
+				points := selection.points()
+				if selection.Type == "unit" || selection.Type == "model" || points > 0 {
+					roster.Units = append(roster.Units, common.Unit{Name: selection.Name, Points: int(math.Round(points))})
+				}
+			}
+			addForces(force.Forces)
+		}
+	}
+	addForces(parsed.Forces)
+	if len(roster.Units) == 0 {
+		return Roster{}, errors.New("no units in the roster")
+	}
+	return roster, nil
+}
+
+// points returns the points of a selection with the entries chosen for it
+func (s selectionXML) points() float64 {
+	points := 0.0
+	for _, cost := range s.Costs {
+		if strings.EqualFold(cost.Name, "pts") || strings.EqualFold(cost.Name, "points") || cost.TypeID == "points" {
+			points += cost.Value
+		}
+	}
+	for _, selection := range s.Selections {
+		points += selection.points()
+	}
+	return points
+}
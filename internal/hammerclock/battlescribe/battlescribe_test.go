@@ -0,0 +1,119 @@
+package battlescribe
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// roster is a shortened roster as saved by BattleScribe, with the points of the upgrades on the entries chosen for
+// the units
+const roster = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<roster id="1" name="Spring Cup list" battleScribeVersion="2.03" gameSystemName="Warhammer 40,000 10th Edition" xmlns="http://www.battlescribe.net/schema/rosterSchema">
+  <costs>
+    <cost name="pts" typeId="51b2-306e-1021-d207" value="360.0"/>
+  </costs>
+  <forces>
+    <force id="2" name="Army Roster" catalogueName="Imperium - Space Marines">
+      <selections>
+        <selection id="3" name="Detachment Choice" type="upgrade" number="1">
+          <costs>
+            <cost name="pts" typeId="51b2-306e-1021-d207" value="0.0"/>
+          </costs>
+        </selection>
+        <selection id="4" name="Captain" type="model" number="1">
+          <costs>
+            <cost name="pts" typeId="51b2-306e-1021-d207" value="80.0"/>
+            <cost name=" PL" typeId="e356-c769-5920-6e14" value="5.0"/>
+          </costs>
+          <selections>
+            <selection id="5" name="Relic Shield" type="upgrade" number="1">
+              <costs>
+                <cost name="pts" typeId="51b2-306e-1021-d207" value="0.0"/>
+              </costs>
+            </selection>
+          </selections>
+        </selection>
+        <selection id="6" name="Intercessor Squad" type="unit" number="1">
+          <costs>
+            <cost name="pts" typeId="51b2-306e-1021-d207" value="0.0"/>
+          </costs>
+          <selections>
+            <selection id="7" name="Intercessor Sergeant" type="model" number="1">
+              <costs>
+                <cost name="pts" typeId="51b2-306e-1021-d207" value="16.0"/>
+              </costs>
+            </selection>
+            <selection id="8" name="Intercessor" type="model" number="9">
+              <costs>
+                <cost name="pts" typeId="51b2-306e-1021-d207" value="144.0"/>
+              </costs>
+            </selection>
+          </selections>
+        </selection>
+      </selections>
+      <forces>
+        <force id="9" name="Allies" catalogueName="Imperium - Imperial Knights">
+          <selections>
+            <selection id="10" name="Armiger Warglaive" type="model" number="1">
+              <costs>
+                <cost name="pts" typeId="51b2-306e-1021-d207" value="120.0"/>
+              </costs>
+            </selection>
+          </selections>
+        </force>
+      </forces>
+    </force>
+  </forces>
+</roster>`
+
+func TestParseReadsUnitsWithTheirPoints(t *testing.T) {
+	parsed, err := Parse([]byte(roster))
+	if err != nil {
+		t.Fatalf("Failed to parse the roster: %v", err)
+	}
+	if parsed.Name != "Spring Cup list" || parsed.Faction != "Imperium - Space Marines" {
+		t.Errorf("Expected the roster's name and faction, got %q and %q", parsed.Name, parsed.Faction)
+	}
+	expected := []struct {
+		name   string
+		points int
+	}{{"Captain", 80}, {"Intercessor Squad", 160}, {"Armiger Warglaive", 120}}
+	if len(parsed.Units) != len(expected) {
+		t.Fatalf("Expected %d units, got %+v", len(expected), parsed.Units)
+	}
+	for i, unit := range parsed.Units {
+		if unit.Name != expected[i].name || unit.Points != expected[i].points {
+			t.Errorf("Expected %s with %d pts, got %s with %d pts", expected[i].name, expected[i].points, unit.Name, unit.Points)
+		}
+	}
+
+	if _, err := Parse([]byte(`<roster name="Empty"><forces/></roster>`)); err == nil {
+		t.Error("Expected an error for a roster without units")
+	}
+}
+
+func TestReadFileUnzipsRosz(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "list.rosz")
+	file, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("Failed to create the roster: %v", err)
+	}
+	archive := zip.NewWriter(file)
+	writer, _ := archive.Create("list.ros")
+	_, _ = writer.Write([]byte(roster))
+	_ = archive.Close()
+	_ = file.Close()
+
+	if !IsRoster(filename) || IsRoster("players.csv") {
+		t.Error("Expected only .ros and .rosz files to be rosters")
+	}
+	parsed, err := ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read the roster: %v", err)
+	}
+	if len(parsed.Units) != 3 {
+		t.Errorf("Expected the units of the zipped roster, got %+v", parsed.Units)
+	}
+}
@@ -0,0 +1,141 @@
+// Package calc evaluates the arithmetic of the scratchpad calculator, e.g. "17*6+45" for points totals, with dice
+// terms like "10d6" for probability checks.
+package calc
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"hammerclock/internal/hammerclock/dice"
+)
+
+// Modes give the value of the dice terms of an expression, by the word the expression starts with. Without one, dice
+// count as their average.
+var Modes = []string{"avg", "min", "max"}
+
+// tokenPattern matches the tokens of an expression: dice terms, numbers, operators and parentheses
+var tokenPattern = regexp.MustCompile(`^\s*(\d*[dD]\d+|\d+(?:\.\d+)?|\.\d+|[-+*/()])`)
+
+// Eval evaluates an expression like "17*6+45", "(3+2)*4" or "avg 10d6" and returns the result, without decimals
+// for whole numbers
+func Eval(text string) (string, error) {
+	mode := "avg"
+	if word, rest, found := strings.Cut(strings.TrimSpace(text), " "); found && slices.Contains(Modes, word) {
+		mode, text = word, rest
+	}
+
+	var tokens []string
+	for rest := text; strings.TrimSpace(rest) != ""; {
+		match := tokenPattern.FindStringSubmatch(rest)
+		if match == nil {
+			return "", fmt.Errorf("cannot read '%s'", strings.TrimSpace(rest))
+		}
+		tokens = append(tokens, match[1])
+		rest = rest[len(match[0]):]
+	}
+	if len(tokens) == 0 {
+		return "", fmt.Errorf("nothing to calculate")
+	}
+
+	p := parser{tokens: tokens, mode: mode}
+	value, err := p.expr()
+	if err != nil {
+		return "", err
+	}
+	if p.pos < len(tokens) {
+		return "", fmt.Errorf("unexpected '%s'", tokens[p.pos])
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64), nil
+}
+
+// parser evaluates the tokens of an expression by recursive descent, multiplying and dividing before adding
+type parser struct {
+	tokens []string
+	pos    int
+	mode   string // Value of the dice terms: avg, min or max
+}
+
+// peek returns the next token, or an empty string at the end
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// expr evaluates a sum or difference of terms
+func (p *parser) expr() (float64, error) {
+	value, err := p.term()
+	for err == nil && (p.peek() == "+" || p.peek() == "-") {
+		operator := p.peek()
+		p.pos++
+		var right float64
+		if right, err = p.term(); operator == "+" {
+			value += right
+		} else {
+			value -= right
+		}
+	}
+	return value, err
+}
+
+// term evaluates a product or quotient of factors
+func (p *parser) term() (float64, error) {
+	value, err := p.factor()
+	for err == nil && (p.peek() == "*" || p.peek() == "/") {
+		operator := p.peek()
+		p.pos++
+		var right float64
+		if right, err = p.factor(); err != nil {
+			break
+		}
+		if operator == "*" {
+			value *= right
+		} else if right == 0 {
+			err = fmt.Errorf("division by zero")
+		} else {
+			value /= right
+		}
+	}
+	return value, err
+}
+
+// factor evaluates a number, a dice term, a negated factor or an expression in parentheses
+func (p *parser) factor() (float64, error) {
+	token := p.peek()
+	p.pos++
+	switch {
+	case token == "":
+		return 0, fmt.Errorf("the expression is incomplete")
+	case token == "-":
+		value, err := p.factor()
+		return -value, err
+	case token == "(":
+		value, err := p.expr()
+		if err == nil && p.peek() != ")" {
+			err = fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return value, err
+	case strings.ContainsAny(token, "dD"):
+		expr, err := dice.Parse(token)
+		if err != nil {
+			return 0, err
+		}
+		switch p.mode {
+		case "min":
+			return float64(expr.Count), nil
+		case "max":
+			return float64(expr.Count * expr.Sides), nil
+		}
+		return expr.Average(), nil
+	}
+	value, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected '%s'", token)
+	}
+	return value, nil
+}
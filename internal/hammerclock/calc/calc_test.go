@@ -0,0 +1,30 @@
+package calc
+
+import "testing"
+
+func TestEval(t *testing.T) {
+	tests := map[string]string{
+		"17*6+45":       "147",
+		"(3 + 2) * 4":   "20",
+		"10 - 2 - 3":    "5",
+		"-4 + 1":        "-3",
+		"7/2":           "3.5",
+		"avg 10d6":      "35",
+		"2d6":           "7",
+		"min 2d6+1":     "3",
+		"max 3d6 * 2":   "36",
+		"1000 - 2*d3 ":  "996",
+		"avg (d6+d3)/2": "2.75",
+	}
+	for expression, expected := range tests {
+		if result, err := Eval(expression); err != nil || result != expected {
+			t.Errorf("Expected %s = %s, got %s and %v", expression, expected, result, err)
+		}
+	}
+
+	for _, expression := range []string{"", "1/0", "2*", "(1+2", "1 2", "three", "avg", "0d6"} {
+		if result, err := Eval(expression); err == nil {
+			t.Errorf("Expected %q not to evaluate, got %s", expression, result)
+		}
+	}
+}
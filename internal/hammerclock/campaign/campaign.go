@@ -0,0 +1,286 @@
+// Package campaign links games into a narrative campaign. The players' rosters, experience, injuries and territory
+// are kept in the campaign file between the games, together with the results of the games and a campaign log.
+package campaign
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+)
+
+// Experience earned in a game of the campaign
+const (
+	playedXP = 1 // For each player who took part
+	winXP    = 1 // On top for the winner
+)
+
+// Campaign is a named series of games played by its members
+type Campaign struct {
+	Name    string   `json:"name"`
+	Members []Member `json:"members"`
+	Games   []Game   `json:"games,omitempty"`
+	Log     []Entry  `json:"log,omitempty"`
+}
+
+// Member is a player of the campaign with the state carried from game to game
+type Member struct {
+	Name      string             `json:"name"`
+	Faction   string             `json:"faction,omitempty"`
+	Roster    []options.ArmyUnit `json:"roster,omitempty"` // Units the player fields, taken into each game
+	XP        int                `json:"xp"`
+	Territory int                `json:"territory"`          // Territories the player holds
+	Injuries  []string           `json:"injuries,omitempty"` // Lasting injuries, e.g. "Lost an eye"
+}
+
+// Game is the result of a game of the campaign
+type Game struct {
+	Number   int            `json:"number"`
+	PlayedAt time.Time      `json:"playedAt"`
+	GameTime time.Duration  `json:"gameTime"`
+	Players  []PlayerResult `json:"players"`
+	Sequence []string       `json:"sequence,omitempty"` // Results of the pre- and post-game steps, e.g. "Weather: Blizzard"
+}
+
+// PlayerResult is the result of a player in a game of the campaign
+type PlayerResult struct {
+	Name          string             `json:"name"`
+	VictoryPoints int                `json:"victoryPoints"`
+	TimeElapsed   time.Duration      `json:"timeElapsed"`
+	Army          []options.ArmyUnit `json:"army,omitempty"`       // Units the player fielded
+	Casualties    []string           `json:"casualties,omitempty"` // Units destroyed in the game
+}
+
+// Entry is a line of the campaign log
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// Change is a change to the state of a member between the games, e.g. experience spent or territory lost
+type Change struct {
+	XP        int
+	Territory int
+	Injury    string // New lasting injury, empty for none
+	Note      string // Reason for the change, e.g. "Raided the mine"
+}
+
+// Stats are a member's totals over the games of the campaign
+type Stats struct {
+	Games         int
+	Wins          int
+	Losses        int
+	Draws         int
+	VictoryPoints int
+}
+
+// New returns an empty campaign named after its file, e.g. "ashes" for ashes.json
+func New(filename string) *Campaign {
+	return &Campaign{Name: strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))}
+}
+
+// Load reads a campaign from a file
+func Load(filename string) (*Campaign, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var campaign Campaign
+	if err := json.Unmarshal(data, &campaign); err != nil {
+		return nil, fmt.Errorf("invalid campaign file '%s': %w", filename, err)
+	}
+	if campaign.Name == "" {
+		return nil, fmt.Errorf("invalid campaign file '%s': a campaign needs a name", filename)
+	}
+	return &campaign, nil
+}
+
+// LoadOrNew reads a campaign from a file, or returns a new campaign if the file does not exist yet
+func LoadOrNew(filename string) (*Campaign, error) {
+	campaign, err := Load(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(filename), nil
+	}
+	return campaign, err
+}
+
+// Save writes the campaign to a file
+func (c *Campaign) Save(filename string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// Member returns the named member, or nil if the player is not part of the campaign
+func (c *Campaign) Member(name string) *Member {
+	for i := range c.Members {
+		if c.Members[i].Name == name {
+			return &c.Members[i]
+		}
+	}
+	return nil
+}
+
+// join returns the named member, adding the player to the campaign first if needed
+func (c *Campaign) join(name string) *Member {
+	if member := c.Member(name); member != nil {
+		return member
+	}
+	c.Members = append(c.Members, Member{Name: name})
+	return &c.Members[len(c.Members)-1]
+}
+
+// Record adds a game to the campaign. Its players join the campaign if they are new, earn their experience and take
+// the army they fielded as their roster into the next game. The game is written to the campaign log.
+func (c *Campaign) Record(game Game) {
+	game.Number = len(c.Games) + 1
+	winner := game.Winner()
+
+	results := make([]string, len(game.Players))
+	var casualties []string
+	for i, player := range game.Players {
+		member := c.join(player.Name)
+		member.XP += playedXP
+		if player.Name == winner {
+			member.XP += winXP
+		}
+		if len(player.Army) > 0 {
+			member.Roster = slices.Clone(player.Army)
+		}
+		results[i] = fmt.Sprintf("%s %d VP", player.Name, player.VictoryPoints)
+		if len(player.Casualties) > 0 {
+			casualties = append(casualties, fmt.Sprintf("%s lost %s", player.Name, strings.Join(player.Casualties, ", ")))
+		}
+	}
+	c.Games = append(c.Games, game)
+
+	outcome := "drawn"
+	if winner != "" {
+		outcome = "won by " + winner
+	}
+	message := fmt.Sprintf("Game %d: %s, %s", game.Number, strings.Join(results, " vs "), outcome)
+	if len(casualties) > 0 {
+		message += ". " + strings.Join(casualties, "; ")
+	}
+	if len(game.Sequence) > 0 {
+		message += ". " + strings.Join(game.Sequence, "; ")
+	}
+	c.Log = append(c.Log, Entry{Time: game.PlayedAt, Message: message})
+}
+
+// Adjust changes the state of a member between the games and writes the change to the campaign log
+func (c *Campaign) Adjust(name string, change Change, at time.Time) error {
+	member := c.Member(name)
+	if member == nil {
+		return fmt.Errorf("%s is not part of the campaign", name)
+	}
+
+	var changes []string
+	if change.XP != 0 {
+		member.XP += change.XP
+		changes = append(changes, fmt.Sprintf("%+d XP", change.XP))
+	}
+	if change.Territory != 0 {
+		member.Territory += change.Territory
+		changes = append(changes, fmt.Sprintf("%+d territory", change.Territory))
+	}
+	if injury := strings.TrimSpace(change.Injury); injury != "" {
+		member.Injuries = append(member.Injuries, injury)
+		changes = append(changes, "injury: "+injury)
+	}
+	note := strings.TrimSpace(change.Note)
+	if len(changes) == 0 && note == "" {
+		return nil
+	}
+
+	message := name
+	if len(changes) > 0 {
+		message += ": " + strings.Join(changes, ", ")
+	}
+	if note != "" {
+		message += " - " + note
+	}
+	c.Log = append(c.Log, Entry{Time: at, Message: message})
+	return nil
+}
+
+// Stats returns the totals of the named member over the games of the campaign
+func (c *Campaign) Stats(name string) Stats {
+	var stats Stats
+	for _, game := range c.Games {
+		i := slices.IndexFunc(game.Players, func(player PlayerResult) bool { return player.Name == name })
+		if i < 0 {
+			continue
+		}
+		stats.Games++
+		stats.VictoryPoints += game.Players[i].VictoryPoints
+		switch game.Winner() {
+		case name:
+			stats.Wins++
+		case "":
+			stats.Draws++
+		default:
+			stats.Losses++
+		}
+	}
+	return stats
+}
+
+// Winner returns the name of the player with the most victory points, or an empty string for a draw
+func (g Game) Winner() string {
+	winner, best := "", 0
+	for i, player := range g.Players {
+		switch {
+		case i == 0 || player.VictoryPoints > best:
+			winner, best = player.Name, player.VictoryPoints
+		case player.VictoryPoints == best:
+			winner = ""
+		}
+	}
+	return winner
+}
+
+// GameFromModel captures the result of the game of a model, before the game is reset
+func GameFromModel(model *common.Model, playedAt time.Time) Game {
+	game := Game{
+		PlayedAt: playedAt,
+		GameTime: model.TotalGameTime,
+		Players:  make([]PlayerResult, len(model.Players)),
+		Sequence: slices.Clone(model.SequenceResults),
+	}
+	for i, player := range model.Players {
+		result := PlayerResult{
+			Name:          player.Name,
+			VictoryPoints: player.VictoryPoints,
+			TimeElapsed:   player.TimeElapsed,
+		}
+		for _, unit := range player.ArmyList {
+			result.Army = append(result.Army, options.ArmyUnit{Name: unit.Name, Points: unit.Points, Wounds: unit.Wounds})
+			if unit.Status == "destroyed" {
+				result.Casualties = append(result.Casualties, unit.Name)
+			}
+		}
+		game.Players[i] = result
+	}
+	return game
+}
+
+// RecordGame adds a game to the campaign file, creating the file for the first game of a new campaign. The file is
+// read again first, so changes made on the campaign screen in the meantime are kept.
+func RecordGame(filename string, game Game) error {
+	campaign, err := LoadOrNew(filename)
+	if err != nil {
+		return err
+	}
+	campaign.Record(game)
+	return campaign.Save(filename)
+}
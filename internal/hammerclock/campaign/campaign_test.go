@@ -0,0 +1,77 @@
+package campaign
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"hammerclock/internal/hammerclock/options"
+)
+
+func TestRecordCarriesStateBetweenGames(t *testing.T) {
+	campaign := &Campaign{Name: "Ashes", Members: []Member{{Name: "Alice", Faction: "Orks", XP: 4}}}
+	army := []options.ArmyUnit{{Name: "Boyz", Points: 85}, {Name: "Nobz", Points: 110}}
+	campaign.Record(Game{Players: []PlayerResult{
+		{Name: "Alice", VictoryPoints: 12, Army: army, Casualties: []string{"Nobz"}},
+		{Name: "Bob", VictoryPoints: 8},
+	}})
+
+	alice, bob := campaign.Member("Alice"), campaign.Member("Bob")
+	if alice == nil || alice.XP != 4+playedXP+winXP || !slices.Equal(alice.Roster, army) {
+		t.Errorf("Expected Alice to earn the winner's experience and keep her army, got %+v", alice)
+	}
+	if bob == nil || bob.XP != playedXP {
+		t.Errorf("Expected Bob to join the campaign with the experience of a game, got %+v", bob)
+	}
+	if expected := "Game 1: Alice 12 VP vs Bob 8 VP, won by Alice. Alice lost Nobz"; campaign.Log[0].Message != expected {
+		t.Errorf("Expected %q in the log, got %q", expected, campaign.Log[0].Message)
+	}
+
+	campaign.Record(Game{Players: []PlayerResult{{Name: "Alice", VictoryPoints: 5}, {Name: "Bob", VictoryPoints: 5}}})
+	if stats := campaign.Stats("Alice"); stats != (Stats{Games: 2, Wins: 1, Draws: 1, VictoryPoints: 17}) {
+		t.Errorf("Expected a win and a draw for Alice, got %+v", stats)
+	}
+	if campaign.Games[1].Number != 2 || !slices.Equal(campaign.Member("Alice").Roster, army) {
+		t.Error("Expected the second game to be numbered and to keep the roster of the first")
+	}
+}
+
+func TestAdjustLogsTheChange(t *testing.T) {
+	campaign := &Campaign{Name: "Ashes", Members: []Member{{Name: "Alice", XP: 5, Territory: 1}}}
+	change := Change{XP: -3, Territory: 1, Injury: "Lost an eye", Note: "Raided the mine"}
+	if err := campaign.Adjust("Alice", change, time.Now()); err != nil {
+		t.Fatalf("Failed to adjust: %v", err)
+	}
+
+	alice := campaign.Member("Alice")
+	if alice.XP != 2 || alice.Territory != 2 || !slices.Equal(alice.Injuries, []string{"Lost an eye"}) {
+		t.Errorf("Expected the change to be applied, got %+v", alice)
+	}
+	if expected := "Alice: -3 XP, +1 territory, injury: Lost an eye - Raided the mine"; campaign.Log[0].Message != expected {
+		t.Errorf("Expected %q in the log, got %q", expected, campaign.Log[0].Message)
+	}
+
+	if err := campaign.Adjust("Carol", change, time.Now()); err == nil {
+		t.Error("Expected an error for a player who is not part of the campaign")
+	}
+}
+
+func TestRecordGameCreatesTheCampaignFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "ashes.json")
+	game := Game{GameTime: time.Hour, Players: []PlayerResult{{Name: "Alice", VictoryPoints: 3}}}
+	if err := RecordGame(filename, game); err != nil {
+		t.Fatalf("Failed to record the game: %v", err)
+	}
+	if err := RecordGame(filename, game); err != nil {
+		t.Fatalf("Failed to record the second game: %v", err)
+	}
+
+	campaign, err := Load(filename)
+	if err != nil {
+		t.Fatalf("Failed to load the campaign: %v", err)
+	}
+	if campaign.Name != "ashes" || len(campaign.Games) != 2 || campaign.Member("Alice").XP != 2*(playedXP+winXP) {
+		t.Errorf("Expected both games in the campaign named after its file, got %+v", campaign)
+	}
+}
@@ -1,6 +1,12 @@
 package common
 
-import "github.com/gdamore/tcell/v2"
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"hammerclock/internal/hammerclock/dice"
+	"hammerclock/internal/hammerclock/options"
+)
 
 // PrevPhaseMsg is sent when the user wants to move to the previous phase
 type PrevPhaseMsg struct{}
@@ -8,14 +14,79 @@ type PrevPhaseMsg struct{}
 // ShowOptionsMsg is sent when the user wants to show the options screen
 type ShowOptionsMsg struct{}
 
+// UnlockOptionsMsg is sent with the action PIN entered to open the options screen, if the options ask for one
+type UnlockOptionsMsg struct {
+	PIN string
+}
+
 // ShowAboutMsg is sent when the user wants to show the about screen
 type ShowAboutMsg struct{}
 
+// ShowFeedMsg is sent when the user wants to show the combined game feed screen
+type ShowFeedMsg struct{}
+
+// ShowScratchpadMsg is sent when the user shows or hides the scratchpad calculator
+type ShowScratchpadMsg struct{}
+
 // ShowMainScreenMsg is sent when the user wants to return to the main screen
 type ShowMainScreenMsg struct{}
 
-// TickMsg is sent every second to update the clock and player times
-type TickMsg struct{}
+// TickMsg is sent every tick interval (one second by default) to update the clock and player times
+type TickMsg struct {
+	Time time.Time // Wall clock time of the tick, used to notice that the system was suspended; zero if unknown
+}
+
+// LinkStateMsg is sent when a linked client receives the game state from its host, with the running clocks
+// already corrected for the transfer delay
+type LinkStateMsg struct {
+	Status        GameStatus
+	GameStarted   bool
+	TotalGameTime time.Duration
+	PausedTime    time.Duration
+	Players       []LinkedPlayer
+	Table         string        // Event and table of the host, or its banner or ruleset name without them
+	Round         int           // Battle round, 0 if the players do not alternate turns
+	TimeBudget    time.Duration // Time on each player's clock, 0 for clocks without a limit
+	Shared        bool          // Indicates if the host plays the game keys pressed on its linked terminals
+}
+
+// ForwardKeyMsg is sent when a game key is pressed on a linked terminal of a shared game, to be played on the host
+type ForwardKeyMsg struct {
+	Key  tcell.Key
+	Rune rune
+}
+
+// TableStateMsg is sent when the organizer dashboard receives the game state of a table
+type TableStateMsg struct {
+	Addr  string
+	State *LinkStateMsg
+}
+
+// RoundTimerMsg is sent when the organizer sets the round countdown or an announcement for every table
+type RoundTimerMsg struct {
+	Ends         time.Time // End of the round, zero without a countdown
+	Announcement string    // Announcement shown on every table, e.g. "Dice down in 15 minutes", empty if none
+}
+
+// BellMsg is sent to ring the terminal bell
+type BellMsg struct{}
+
+// BatchMsg is returned by a command that ran several commands, with their messages in order
+type BatchMsg struct {
+	Msgs []Message
+}
+
+// BackgroundMsg carries a message of a command of a game in the background, delivered when the game is shown
+type BackgroundMsg struct {
+	Game int // Identifier of the game in its session
+	Msg  Message
+}
+
+// ResolveSuspendMsg is sent when the user decides what to do with the time the system was suspended
+type ResolveSuspendMsg struct {
+	Action string // "add" to credit the time to the active player, "discard" to drop it or "pause"
+	PIN    string // PIN entered to add the time, if the options ask for one
+}
 
 // KeyPressMsg is sent when a key is pressed
 type KeyPressMsg struct {
@@ -29,6 +100,7 @@ type EndGameMsg struct{}
 // EndGameConfirmMsg is sent when the user confirms or cancels ending the game
 type EndGameConfirmMsg struct {
 	Confirmed bool
+	PIN       string // PIN entered to end the game, if the options ask for one
 }
 
 // ShowEndGameConfirmMsg is sent to show the end game confirmation dialog
@@ -44,12 +116,150 @@ type ExitConfirmMsg struct {
 
 // ShowModalMsg is sent to show a modal dialog
 type ShowModalMsg struct {
-	Type string
+	Type        string
+	PlayerIndex int // Player the dialog refers to, if any
+	Step        int // Step of the pre- or post-game sequence the dialog shows, if any
 }
 
 // RestoreMainUIMsg is sent to restore the main UI after a modal dialog
 type RestoreMainUIMsg struct{}
 
+// ModalOpenedMsg is sent when a modal dialog is displayed over the main UI
+type ModalOpenedMsg struct{}
+
+// ModalClosedMsg is sent when the last modal dialog is closed and the main UI has the focus again
+type ModalClosedMsg struct{}
+
+// FocusChangedMsg is sent when the terminal window gains or loses the focus, if the terminal reports it
+type FocusChangedMsg struct {
+	Focused bool
+}
+
+// ScrollLogMsg is sent to scroll the action log of a player panel
+type ScrollLogMsg struct {
+	PlayerIndex int
+	Lines       int  // Number of lines to scroll, negative to scroll up
+	ToTop       bool // Scroll to the first log entry
+	ToBottom    bool // Scroll to the latest log entry
+}
+
+// ScreenshotMsg is sent to save the screen as shown to a file, with its colors as ANSI escape sequences
+type ScreenshotMsg struct{}
+
+// ScreenshotSavedMsg is sent when the screenshot was saved to a file, or could not be
+type ScreenshotSavedMsg struct {
+	File string
+	Err  error
+}
+
+// ScheduleStartMsg is sent when the user schedules the start of the game, a zero time cancels the countdown
+type ScheduleStartMsg struct {
+	At time.Time
+}
+
+// OfferSavedGameMsg is sent at startup when the autosave holds an unfinished game, to ask whether to continue it
+type OfferSavedGameMsg struct {
+	Game *SavedGame
+}
+
+// ResumeSavedGameMsg is sent when the user chooses to continue the saved game, or to start fresh
+type ResumeSavedGameMsg struct {
+	Continue bool
+}
+
+// GameSavedMsg is sent when the game was saved to a file on demand, or could not be
+type GameSavedMsg struct {
+	File string
+	Err  error
+}
+
+// AddTimerMsg is sent when the user starts an auxiliary countdown timer
+type AddTimerMsg struct {
+	Label    string
+	Duration time.Duration
+}
+
+// ClearTimersMsg is sent when the user removes all auxiliary timers
+type ClearTimersMsg struct{}
+
+// JudgeLoginMsg is sent when someone enters the passphrase to unlock judge mode
+type JudgeLoginMsg struct {
+	Passphrase string
+}
+
+// JudgeActionMsg is sent when the judge intervenes in the game
+type JudgeActionMsg struct {
+	Action  string        // "penalty", "pause", "score" or "exit" to leave judge mode
+	Player  int           // Index of the player a penalty or score adjustment applies to
+	Penalty time.Duration // Time added to the player's clock
+	Points  int           // Victory points added to the player, negative to take points away
+	Reason  string
+}
+
+// AddNoteMsg is sent when the user adds a note to the active player's action log
+type AddNoteMsg struct {
+	Text string
+}
+
+// RollDiceMsg is sent when the user rolls dice with the dice roller
+type RollDiceMsg struct {
+	Roll dice.Roll
+}
+
+// RollTableMsg is sent when the user rolls on a random table of the ruleset with the dice roller
+type RollTableMsg struct {
+	Table string
+	Roll  dice.Roll
+}
+
+// SaveTemplateMsg is sent when the user saves the current game setup as a template
+type SaveTemplateMsg struct {
+	Name string
+}
+
+// ApplyTemplateMsg is sent when the user sets up a new game from a template
+type ApplyTemplateMsg struct {
+	Index int
+}
+
+// RunCommandMsg is sent when the user runs a command from the command palette
+type RunCommandMsg struct {
+	Name string
+}
+
+// SaveOptionsMsg is sent when the user saves the options to the options file
+type SaveOptionsMsg struct{}
+
+// OptionsSavedMsg is sent when the options were written to the options file, or could not be
+type OptionsSavedMsg struct {
+	Options     options.Options // The options as shown when they were saved
+	FileOptions options.Options // The options as written to the file
+	Err         error
+}
+
+// RevertOptionsMsg is sent when the user discards the unsaved changes to the options
+type RevertOptionsMsg struct{}
+
+// RestoreOptionsBackupMsg is sent when the user restores the options file from its most recent backup
+type RestoreOptionsBackupMsg struct{}
+
+// OptionsBackupRestoredMsg is sent when the options file was restored from its backup, or could not be
+type OptionsBackupRestoredMsg struct {
+	Options options.Options
+	Err     error
+}
+
+// ResetOptionMsg is sent when the user resets an option to its default, an empty Field resets all options
+type ResetOptionMsg struct {
+	Field string
+}
+
+// ShowOptionsDiffMsg is sent when the user wants to see which options differ from the defaults and the options file
+type ShowOptionsDiffMsg struct{}
+
+// ReloadOptionsScreenMsg is sent when the options screen must be rebuilt from the model
+type ReloadOptionsScreenMsg struct{}
+
 // SetRulesetMsg is sent when the user selects a different ruleset
 type SetRulesetMsg struct {
 	Index int
@@ -76,16 +286,212 @@ type SetTimeFormatMsg struct {
 	Format string
 }
 
+// SetLogTimestampsMsg is sent when the timestamp display of the action logs is changed
+type SetLogTimestampsMsg struct {
+	Format string
+}
+
+// SetTransparentBackgroundMsg is sent when the terminal's background is used instead of painting the palette's black
+type SetTransparentBackgroundMsg struct {
+	Value bool
+}
+
+// SetClockShowDateMsg is sent when the date display next to the clock is toggled
+type SetClockShowDateMsg struct {
+	Value bool
+}
+
+// SetClockShowGameTimeMsg is sent when the game time display next to the clock is toggled
+type SetClockShowGameTimeMsg struct {
+	Value bool
+}
+
+// SetBannerMsg is sent when the custom banner text is changed
+type SetBannerMsg struct {
+	Text string
+}
+
+// SetLayoutMsg is sent when the user changes how the player panels are arranged
+type SetLayoutMsg struct {
+	Layout string
+}
+
+// SetTurnCueMsg is sent when the user changes the cue shown after a turn switch
+type SetTurnCueMsg struct {
+	Cue string
+}
+
+// SetBorderStyleMsg is sent when the user changes the style borders are drawn in
+type SetBorderStyleMsg struct {
+	Style string
+}
+
+// SetPanelWidgetsMsg is sent when the user changes the widgets shown in the player panels
+type SetPanelWidgetsMsg struct {
+	Widgets []string
+}
+
+// SetEventNameMsg is sent when the event name is changed
+type SetEventNameMsg struct {
+	Text string
+}
+
+// SetTableNumberMsg is sent when the table number is changed
+type SetTableNumberMsg struct {
+	Number int
+}
+
 // SetOneTurnForAllPlayersMsg is sent when the "One Turn For All Players" option is toggled
 type SetOneTurnForAllPlayersMsg struct {
 	Value bool
 }
 
+// SetPromptSecondaryObjectivesMsg is sent when the user toggles the end-of-turn secondary objective prompt
+type SetPromptSecondaryObjectivesMsg struct {
+	Value bool
+}
+
+// SetUnitStatusMsg is sent when the user marks a unit of a player's army list, e.g. as destroyed
+type SetUnitStatusMsg struct {
+	PlayerIndex int
+	Unit        int    // Index of the unit in the army list
+	Status      string // New status of the unit, empty when it is fine again
+}
+
+// SetUnitDestroyedByMsg is sent when the user records which opposing unit destroyed a unit
+type SetUnitDestroyedByMsg struct {
+	PlayerIndex int
+	Unit        int    // Index of the destroyed unit in the army list
+	DestroyedBy string // Opposing unit, as "player: unit"
+}
+
+// SetUnitWoundsMsg is sent when the user marks damage on a unit of a player's army list, or heals it
+type SetUnitWoundsMsg struct {
+	PlayerIndex int
+	Unit        int // Index of the unit in the army list
+	WoundsLost  int // Wounds the unit lost in total
+}
+
+// SetUnitNoteMsg is sent when the user edits the note of a unit of a player's army list
+type SetUnitNoteMsg struct {
+	PlayerIndex int
+	Unit        int    // Index of the unit in the army list
+	Note        string // New note of the unit, empty to remove it
+}
+
+// SetArmyListMsg is sent when the user saves a player's army list in the army list editor
+type SetArmyListMsg struct {
+	PlayerIndex int
+	Units       []Unit
+}
+
+// ImportRosterMsg is sent when the user imports a BattleScribe roster file as a player's army list
+type ImportRosterMsg struct {
+	PlayerIndex int
+	File        string
+}
+
+// RosterImportedMsg is sent when a BattleScribe roster was read for a player, or could not be
+type RosterImportedMsg struct {
+	PlayerIndex int
+	File        string
+	Faction     string // Catalogue of the roster, e.g. "Imperium - Space Marines"
+	Units       []Unit
+	Err         error
+}
+
+// CompleteStepMsg is sent when the user completes a step of the ruleset's pre- or post-game sequence, or skips the
+// rest of the sequence
+type CompleteStepMsg struct {
+	Sequence string // PreGame or PostGame
+	Step     int
+	Result   string // Result to record, e.g. the weather rolled, empty for none
+	Skip     bool
+}
+
+// ScoreSecondaryObjectivesMsg is sent when the user submits the secondary objective scores for a player
+type ScoreSecondaryObjectivesMsg struct {
+	PlayerIndex int
+	Scores      []int  // Victory points per objective, in the order defined by the ruleset
+	PIN         string // PIN entered to score, if the options ask for one
+}
+
+// SetPauseOnModalMsg is sent when the user toggles pausing the clocks while a dialog is open
+type SetPauseOnModalMsg struct {
+	Value bool
+}
+
+// SetDiscordNotificationsMsg is sent when the user toggles posting the events of the game to the Discord webhook
+type SetDiscordNotificationsMsg struct {
+	Value bool
+}
+
+// SetPauseOnFocusLossMsg is sent when the user toggles pausing the game when the terminal loses the focus
+type SetPauseOnFocusLossMsg struct {
+	Value bool
+}
+
+// SetTickIntervalMsg is sent when the user changes the interval between clock updates
+type SetTickIntervalMsg struct {
+	Milliseconds int
+}
+
+// SetTimeBudgetMsg is sent when the user changes the minutes on each player's clock
+type SetTimeBudgetMsg struct {
+	Minutes int
+}
+
+// SetByoYomiMsg is sent when the user changes the byo-yomi periods a player gets once the time budget is used up
+type SetByoYomiMsg struct {
+	Periods int
+	Seconds int
+}
+
+// SetGameSizeMsg is sent when the user changes the points of each army in the game
+type SetGameSizeMsg struct {
+	Points int
+}
+
+// SetGracePeriodMsg is sent when the user changes the grace period after a turn switch
+type SetGracePeriodMsg struct {
+	Seconds int
+}
+
+// SetLocaleMsg is sent when the user changes the language of the phase names
+type SetLocaleMsg struct {
+	Locale string
+}
+
+// SetTimeOddsMsg is sent when the user changes the time odds the first player gives the others
+type SetTimeOddsMsg struct {
+	Odds string
+}
+
+// SetFlagFallMsg is sent when the user changes what happens when a player runs out of time
+type SetFlagFallMsg struct {
+	Action string
+}
+
+// SetVimBindingsMsg is sent when the user toggles the vim-style key bindings
+type SetVimBindingsMsg struct {
+	Value bool
+}
+
 // SetEnableLogMsg is sent when the user toggles CSV logging
 type SetEnableLogMsg struct {
 	Value bool
 }
 
+// SetResumeGameMsg is sent when the user toggles the offer to continue an unfinished game at startup
+type SetResumeGameMsg struct {
+	Value bool
+}
+
+// SetBattleReportMsg is sent when the user toggles the battle reports written when a game ends
+type SetBattleReportMsg struct {
+	Value bool
+}
+
 // StartGameMsg is sent when the user wants to start/pause/resume the game
 type StartGameMsg struct{}
 
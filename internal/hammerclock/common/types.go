@@ -13,28 +13,139 @@ type Model struct {
 	Players             []*Player
 	Phases              []string
 	GameStatus          GameStatus
-	CurrentScreen       string // Can be "main", "options", or "about"
+	CurrentScreen       string // Can be "main", "options", "about" or "feed"
+	ArmyExpanded        bool   // Shows the units of the army lists in the player panels, not just their totals
+	ScratchpadShown     bool   // Shows the scratchpad calculator below the player panels
 	GameStarted         bool
 	Options             options.Options
 	CurrentColorPalette palette.ColorPalette
 	TotalGameTime       time.Duration // Total elapsed time for the entire game
+	Warnings            int           // Number of warnings logged in the game, so new warnings can be noticed
+	PausedTime          time.Duration // Time the game spent paused, not included in TotalGameTime
+	FocusedLog          int           // Index of the player whose action log receives keyboard navigation
+	LogFocused          bool          // Indicates if the focused action log receives j/k scrolling
+	PendingKey          rune          // First key of a multi-key binding (e.g. "gg"), 0 if none
+	RecordingMacro      bool          // Indicates if game keys are being recorded into the macro
+	InputLocked         bool          // Indicates if game-mutating keys are ignored
+	LastTurnSwitch      *TurnSwitch   // State before the most recent turn switch, nil if it cannot be reverted
+	History             []Snapshot    // States before the latest actions that can be undone, oldest first
+	Redo                []Snapshot    // States before the latest undos, most recent last
+	ModalPaused         bool          // Indicates if the game was paused because a dialog is open
+	FocusPaused         bool          // Indicates if the game was paused because the terminal lost the focus
+	LastTick            time.Time     // Wall clock time of the last tick, zero if unknown
+	SuspendedFor        time.Duration // Time the system was suspended during the game, until the user resolves it
+	Linked              bool          // Indicates if the clocks mirror a linked host instead of being played locally
+	LinkShared          bool          // Indicates if the linked host plays the game keys pressed on this terminal
+	GraceRemaining      time.Duration // Grace period left before the active player's clock starts counting
+	Timers              []Timer       // Auxiliary countdown timers, independent of the player clocks
+	RoundEnds           time.Time     // End of the tournament round set by the organizer, zero if none
+	StartsAt            time.Time     // Scheduled start of the game, zero without a countdown
+	PreGameDone         bool          // Indicates if the pre-game sequence of the ruleset was worked through
+	SequenceResults     []string      // Results of the pre- and post-game steps, e.g. "Weather: Sweltering heat"
+	Announcement        string        // Latest announcement of the organizer, empty if none
+	JudgeMode           bool          // Indicates if the judge unlocked the judge actions with the passphrase
+	Demo                bool          // Indicates if the game is the demo game, which plays itself
+	Kiosk               bool          // Indicates if the clock runs unattended, without its screens and destructive actions
+
+	// Options persistence
+	OptionsFile  string          // File the options are saved to
+	SavedOptions options.Options // Options as last loaded from or saved to OptionsFile
+	FileOptions  options.Options // Options as in OptionsFile, without the overrides of the environment and command line
+	OptionsError string          // Error of the last attempt to save the options, empty if none
+
+	AuditFile string // File the judge interventions are recorded in, empty to not record them
+
+	AutosaveFile string     // File the game shown is autosaved to, empty to not save it
+	SaveFile     string     // File the game shown is saved to on demand, empty to not save it
+	SavedGame    *SavedGame // Unfinished game of the autosave or the save file offered to be continued, nil if none
+}
+
+// SavedGame is the state of a game kept in the autosave, so an unfinished game can be continued at the next start
+type SavedGame struct {
+	SavedAt       time.Time     `json:"savedAt"`
+	Ruleset       string        `json:"ruleset"` // Name of the ruleset
+	GameStatus    GameStatus    `json:"gameStatus"`
+	TotalGameTime time.Duration `json:"totalGameTime"`
+	PausedTime    time.Duration `json:"pausedTime"`
+	Players       []Player      `json:"players"`
+	Timers        []Timer       `json:"timers,omitempty"`
+	Sequence      []string      `json:"sequence,omitempty"` // Results of the pre-game steps
+	History       []Snapshot    `json:"history,omitempty"`  // States before the latest actions that can be undone
+	File          string        `json:"-"`                  // File the game was read from
+	Interrupted   bool          `json:"-"`                  // Indicates if the run that saved the game did not shut down cleanly
+}
+
+// Snapshot is the state of a game before an action, kept so the action can be undone or redone
+type Snapshot struct {
+	Action        string        `json:"action"` // Action the snapshot was taken before, e.g. "turn switch"
+	GameStarted   bool          `json:"gameStarted"`
+	GameStatus    GameStatus    `json:"gameStatus"`
+	TotalGameTime time.Duration `json:"totalGameTime"`
+	PausedTime    time.Duration `json:"pausedTime"`
+	Players       []Player      `json:"players"`
+}
+
+// TurnSwitch records the state of the players before a turn switch, so the switch can be reverted
+type TurnSwitch struct {
+	Players       []Player      // Copies of the players before the switch
+	TotalGameTime time.Duration // Total game time at the moment of the switch
 }
 
 // Player represents a player in the game
 type Player struct {
-	Name         string
-	TimeElapsed  time.Duration // Time elapsed for the player
-	IsTurn       bool          // Indicates if it's this player's turn
-	CurrentPhase int           // Current phase of the game for this player
-	TurnCount    int           // Counter to track number of turns completed
-	ArmyList     []unit
-	ActionLog    []LogEntry // Log of player actions during the game
+	Name          string
+	TimeElapsed   time.Duration // Time elapsed for the player
+	IsTurn        bool          // Indicates if it's this player's turn
+	CurrentPhase  int           // Current phase of the game for this player
+	PhaseElapsed  time.Duration // Time spent in the current phase
+	TurnCount     int           // Counter to track number of turns completed
+	TurnStart     time.Duration // Time elapsed for the player when their current turn started
+	VictoryPoints int           // Victory points scored by the player
+	Banner        string        // ASCII art banner shown at the top of the player's panel
+	Faction       string        // Faction played, shown next to the name, empty if unknown
+	Flagged       bool          // Indicates if the player ran out of their time budget
+	ByoYomiPeriod int           // Byo-yomi period the player is in, counting from 1, 0 while the time budget lasts
+	PeriodLeft    time.Duration // Time left in the byo-yomi period, reset when the player's turn ends
+	ArmyList      []Unit
+	ActionLog     []LogEntry      // Log of player actions during the game
+	TurnDurations []time.Duration // Durations of the player's completed turns, oldest first
 }
 
-// unit represents a unit in a player's army
-type unit struct {
+// Timer is an auxiliary countdown timer, e.g. for deployment or a rules lookup, that runs independently of the game
+type Timer struct {
+	Label     string
+	Remaining time.Duration
+	Done      bool // Indicates if the timer ran out and its alert was given
+}
+
+// LinkedPlayer is the state of a player received from a linked host
+type LinkedPlayer struct {
+	Name          string
+	TimeElapsed   time.Duration
+	PhaseElapsed  time.Duration
+	IsTurn        bool
+	CurrentPhase  int
+	TurnCount     int
+	VictoryPoints int
+	Flagged       bool
+	ByoYomiPeriod int
+	PeriodLeft    time.Duration
+	TimeBudget    time.Duration // Time on the player's clock with the time odds, 0 without a limit
+}
+
+// Unit represents a unit in a player's army
+type Unit struct {
 	Name   string
 	Points int
+	Status string // e.g. "destroyed", empty while the unit is fine
+	Note   string // e.g. "3 wounds left" or "carries the relic"
+
+	Wounds     int // Wounds of the unit at full strength, 0 if they are not tracked
+	WoundsLost int // Wounds the unit lost in the game
+
+	DestroyedBy string // Opposing unit that destroyed the unit, as "player: unit", empty if not recorded
+	KillScore   int    // Victory points scored for destroying the unit, by the player with the index ScoredBy
+	ScoredBy    int
 }
 
 // GameStatus represents the current state of the game
@@ -47,8 +158,24 @@ type LogEntry struct {
 	Turn       int
 	Phase      string
 	Message    string
+	Type       LogEntryType
+	Event      string // Event name of the table, so logs collected from many tables can be told apart
+	Table      int    // Table number, 0 for none
 }
 
+// LogEntryType categorizes log entries, so they can be told apart in the log display
+type LogEntryType string
+
+const (
+	LogTypeGame    LogEntryType = "game"    // Game started, paused, resumed or ended
+	LogTypeTurn    LogEntryType = "turn"    // Turn started, ended or reverted
+	LogTypePhase   LogEntryType = "phase"   // Phase changes
+	LogTypeScore   LogEntryType = "score"   // Victory points scored
+	LogTypeWarning LogEntryType = "warning" // Time warnings and other alerts
+	LogTypeNote    LogEntryType = "note"    // Notes added manually by the players
+	LogTypeDice    LogEntryType = "dice"    // Dice rolled with the dice roller
+)
+
 // Message represents a message that can be sent to the Update function
 type Message interface {
 }
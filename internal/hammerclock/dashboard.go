@@ -0,0 +1,93 @@
+package hammerclock
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+)
+
+// tableTimeout is the time without a state after which a table is shown as lost on the dashboard
+const tableTimeout = 10 * time.Second
+
+// Dashboard is the state of the organizer dashboard, which shows the tables linked to it on one screen and pushes
+// the round countdown and announcements to them
+type Dashboard struct {
+	Tables       map[string]DashboardTable // Tables by address
+	RoundEnds    time.Time                 // End of the round, zero without a countdown
+	Announcement string                    // Announcement shown on every table, empty if none
+}
+
+// DashboardTable is the last state received from a table
+type DashboardTable struct {
+	State    *common.LinkStateMsg
+	Received time.Time
+}
+
+// UpdateDashboard processes a message of the organizer dashboard and returns the updated dashboard
+func UpdateDashboard(msg common.Message, dashboard Dashboard, now time.Time) Dashboard {
+	switch msg := msg.(type) {
+	case *common.TableStateMsg:
+		tables := make(map[string]DashboardTable, len(dashboard.Tables)+1)
+		for addr, table := range dashboard.Tables {
+			tables[addr] = table
+		}
+		tables[msg.Addr] = DashboardTable{State: msg.State, Received: now}
+		dashboard.Tables = tables
+	case *common.RoundTimerMsg:
+		dashboard.RoundEnds = msg.Ends
+		dashboard.Announcement = msg.Announcement
+	}
+	return dashboard
+}
+
+// DashboardRows returns a row for each table, ordered by table name: the table, battle round, active player, game
+// status, game time and the active player's remaining time. Tables not heard from for a while are marked as lost.
+func DashboardRows(dashboard Dashboard, now time.Time) [][]string {
+	addrs := make([]string, 0, len(dashboard.Tables))
+	for addr := range dashboard.Tables {
+		addrs = append(addrs, addr)
+	}
+	slices.SortFunc(addrs, func(a, b string) int {
+		return cmp.Or(cmp.Compare(dashboard.Tables[a].State.Table, dashboard.Tables[b].State.Table), cmp.Compare(a, b))
+	})
+
+	rows := make([][]string, len(addrs))
+	for i, addr := range addrs {
+		table := dashboard.Tables[addr]
+		state := table.State
+
+		round := "-"
+		if state.Round > 0 {
+			round = fmt.Sprint(state.Round)
+		}
+		active, remaining := "-", "-"
+		for _, player := range state.Players {
+			if player.IsTurn && state.GameStarted {
+				active = player.Name
+				budget := player.TimeBudget
+				// Hosts of older versions only send the time on all clocks
+				if budget == 0 {
+					budget = state.TimeBudget
+				}
+				if budget > 0 {
+					remaining = (budget - player.TimeElapsed).Truncate(time.Second).String()
+				}
+				break
+			}
+		}
+		status := string(state.Status)
+		if now.Sub(table.Received) > tableTimeout {
+			status = "Connection lost"
+		}
+
+		name := state.Table
+		if name == "" {
+			name = addr
+		}
+		rows[i] = []string{name, round, active, status, state.TotalGameTime.Truncate(time.Second).String(), remaining}
+	}
+	return rows
+}
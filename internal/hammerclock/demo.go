@@ -0,0 +1,106 @@
+package hammerclock
+
+import (
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/logging"
+)
+
+// demoPhaseTime is how long the simulated players of the demo game spend in a phase, give or take a few seconds
+const demoPhaseTime = 4 * time.Second
+
+// demoRounds is the number of battle rounds after which the demo game starts over
+const demoRounds = 5
+
+// demoPlayer is a simulated player of the demo game
+type demoPlayer struct {
+	name    string
+	faction string
+	army    []common.Unit
+}
+
+// demoPlayers are the simulated players of the demo game
+var demoPlayers = []demoPlayer{
+	{name: "Uriel", faction: "Space Marines", army: []common.Unit{
+		{Name: "Captain", Points: 80}, {Name: "Intercessors", Points: 160}, {Name: "Hellblasters", Points: 230},
+		{Name: "Redemptor Dreadnought", Points: 210}, {Name: "Eliminators", Points: 85},
+	}},
+	{name: "Trazyn", faction: "Necrons", army: []common.Unit{
+		{Name: "Overlord", Points: 85}, {Name: "Necron Warriors", Points: 200}, {Name: "Immortals", Points: 150},
+		{Name: "Canoptek Doomstalker", Points: 140}, {Name: "Lokhust Destroyers", Points: 180},
+	}},
+}
+
+// SetupDemo turns a model into the demo game, e.g. for a screensaver on the club display or to show the color
+// palettes in screenshots: simulated players with army lists, and a game that starts right away and plays itself.
+// Nothing of the demo game is written to the logs or battle reports.
+func SetupDemo(model *common.Model) {
+	model.Demo = true
+	model.Options.LoggingEnabled = false
+	model.Options.BattleReport = false
+	model.Options.PromptSecondaryObjectives = false
+	model.Players = make([]*common.Player, len(demoPlayers))
+	for i, demo := range demoPlayers {
+		model.Players[i] = &common.Player{
+			Name:      demo.name,
+			Faction:   demo.faction,
+			IsTurn:    i == 0,
+			ActionLog: []common.LogEntry{},
+			ArmyList:  resetArmyList(demo.army),
+		}
+	}
+	*model, _ = handleStartGame(*model)
+}
+
+// advanceDemo plays the demo game: the active player moves on to the next phase once its time is up, scores a few
+// victory points at the end of the turn and now and then destroys a unit of the opponent. The game starts over after
+// demoRounds battle rounds.
+func advanceDemo(model common.Model) common.Model {
+	if !model.GameStarted || model.GameStatus != gameInProgress {
+		return model
+	}
+	if BattleRound(&model) > demoRounds {
+		model, _ = handleEndGame(model)
+		SetupDemo(&model)
+		return model
+	}
+
+	active := -1
+	for i, player := range model.Players {
+		if player.IsTurn {
+			active = i
+		}
+	}
+	if active < 0 {
+		return model
+	}
+	player := model.Players[active]
+	// Vary the time of the phases a little, so the turns of the sparklines differ
+	phaseTime := demoPhaseTime + time.Duration((player.TurnCount*3+player.CurrentPhase*5)%4)*time.Second
+	if player.PhaseElapsed < phaseTime {
+		return model
+	}
+
+	if player.CurrentPhase < len(model.Phases)-1 {
+		model, _ = handleNextPhase(model)
+		return model
+	}
+
+	model = copyPlayers(model)
+	player = model.Players[active]
+	score := (player.TurnCount+active)%3 + 2
+	player.VictoryPoints += score
+	logging.AddLogEntry(player, &model, common.LogTypeScore, "Scored %d VP (total %d VP)", score, player.VictoryPoints)
+	if player.TurnCount%2 == 1 {
+		opponent := (active + 1) % len(model.Players)
+		for i, unit := range model.Players[opponent].ArmyList {
+			if unit.Status != "destroyed" {
+				model, _ = handleSetUnitStatus(&common.SetUnitStatusMsg{PlayerIndex: opponent, Unit: i, Status: "destroyed"}, model)
+				break
+			}
+		}
+	}
+	model, _ = handleSwitchTurns(model)
+	return model
+}
@@ -0,0 +1,99 @@
+// Package dice parses and rolls dice expressions like "2d6" or "d3+1", as written in tabletop rulebooks.
+package dice
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Limits of an expression, so a typo like "1000d6" does not flood the action log
+const (
+	MaxDice  = 100
+	MaxSides = 1000
+)
+
+// Expr is a dice expression: a number of dice with the same number of sides and a modifier added to their total
+type Expr struct {
+	Count    int
+	Sides    int
+	Modifier int
+}
+
+// exprPattern matches expressions like "d6", "2D6" or "3d6+1"
+var exprPattern = regexp.MustCompile(`^(\d*)[dD](\d+)\s*(?:([+-])\s*(\d+))?$`)
+
+// Parse parses a dice expression like "2d6", "d3" or "3d6+1". The number of dice defaults to one.
+func Parse(text string) (Expr, error) {
+	match := exprPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return Expr{}, fmt.Errorf("'%s' is not a dice expression like 2d6 or d3+1", text)
+	}
+	expr := Expr{Count: 1}
+	if match[1] != "" {
+		expr.Count, _ = strconv.Atoi(match[1])
+	}
+	expr.Sides, _ = strconv.Atoi(match[2])
+	if match[4] != "" {
+		expr.Modifier, _ = strconv.Atoi(match[4])
+		if match[3] == "-" {
+			expr.Modifier = -expr.Modifier
+		}
+	}
+	if expr.Count < 1 || expr.Count > MaxDice || expr.Sides < 2 || expr.Sides > MaxSides {
+		return Expr{}, fmt.Errorf("'%s' needs 1 to %d dice of 2 to %d sides", text, MaxDice, MaxSides)
+	}
+	return expr, nil
+}
+
+// String returns the expression as it is written, e.g. "3d6+1"
+func (e Expr) String() string {
+	text := fmt.Sprintf("%dd%d", e.Count, e.Sides)
+	if e.Modifier != 0 {
+		text += fmt.Sprintf("%+d", e.Modifier)
+	}
+	return text
+}
+
+// Average returns the average total of the expression
+func (e Expr) Average() float64 {
+	return float64(e.Count)*float64(e.Sides+1)/2 + float64(e.Modifier)
+}
+
+// Roll rolls the dice of the expression with intN, which returns a random number in [0, n) like rand.IntN
+func (e Expr) Roll(intN func(n int) int) Roll {
+	roll := Roll{Expr: e, Results: make([]int, e.Count)}
+	for i := range roll.Results {
+		roll.Results[i] = intN(e.Sides) + 1
+	}
+	return roll
+}
+
+// Roll is a roll of the dice of an expression
+type Roll struct {
+	Expr    Expr
+	Results []int // Result of each die, before the modifier
+}
+
+// Total returns the total of the dice with the modifier
+func (r Roll) Total() int {
+	total := r.Expr.Modifier
+	for _, result := range r.Results {
+		total += result
+	}
+	return total
+}
+
+// String returns the results and the total of the roll, e.g. "4 2 6 +1 = 13"
+func (r Roll) String() string {
+	results := make([]string, len(r.Results))
+	for i, result := range r.Results {
+		results[i] = strconv.Itoa(result)
+	}
+	text := strings.Join(results, " ")
+	if r.Expr.Modifier != 0 {
+		text += fmt.Sprintf(" %+d", r.Expr.Modifier)
+	}
+	return fmt.Sprintf("%s = %d", text, r.Total())
+}
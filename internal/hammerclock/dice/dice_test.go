@@ -0,0 +1,35 @@
+package dice
+
+import "testing"
+
+func TestParseAndRoll(t *testing.T) {
+	expr, err := Parse("3D6 + 1")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if expr != (Expr{Count: 3, Sides: 6, Modifier: 1}) || expr.String() != "3d6+1" {
+		t.Errorf("Expected 3d6+1, got %+v", expr)
+	}
+	if expr.Average() != 11.5 {
+		t.Errorf("Expected an average of 11.5, got %v", expr.Average())
+	}
+
+	results := []int{3, 1, 5}
+	roll := expr.Roll(func(n int) int {
+		result := results[0]
+		results = results[1:]
+		return result
+	})
+	if roll.Total() != 13 || roll.String() != "4 2 6 +1 = 13" {
+		t.Errorf("Expected 4 2 6 +1 = 13, got %s", roll)
+	}
+
+	if expr, err := Parse("d3-1"); err != nil || expr != (Expr{Count: 1, Sides: 3, Modifier: -1}) {
+		t.Errorf("Expected d3-1 to be one die, got %+v and %v", expr, err)
+	}
+	for _, text := range []string{"", "6", "2d", "0d6", "2d1", "1000d6", "2d6*2"} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Expected '%s' not to parse", text)
+		}
+	}
+}
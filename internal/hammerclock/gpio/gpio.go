@@ -0,0 +1,53 @@
+// Package gpio connects physical buttons and LEDs on a Raspberry Pi (or any Linux board exposing sysfs GPIO)
+// to the game, turning hammerclock into a dedicated table clock appliance.
+//
+// The hardware implementation is only compiled with the "gpio" build tag:
+//
+//	go build -tags gpio -o hammerclock cmd/hammerclock/main.go
+package gpio
+
+import (
+	"errors"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+)
+
+// ErrNotSupported is returned by Open when hammerclock was built without GPIO support
+var ErrNotSupported = errors.New("GPIO support is not available in this build, rebuild with -tags gpio on Linux")
+
+// pollInterval is how often the button pins are sampled
+const pollInterval = 20 * time.Millisecond
+
+// debounceTime is the minimum time between two accepted presses of the same button
+const debounceTime = 250 * time.Millisecond
+
+// button tracks the state of an input pin to detect debounced presses
+type button struct {
+	msg         func() common.Message
+	wasPressed  bool
+	lastPressed time.Time
+}
+
+// update records the current state of the button and reports whether it has just been pressed
+func (b *button) update(pressed bool, now time.Time) bool {
+	justPressed := pressed && !b.wasPressed && now.Sub(b.lastPressed) >= debounceTime
+	if justPressed {
+		b.lastPressed = now
+	}
+	b.wasPressed = pressed
+	return justPressed
+}
+
+// ActivePlayerIndex returns the index of the first player whose turn it is, or -1 if none
+func ActivePlayerIndex(model *common.Model) int {
+	if !model.GameStarted {
+		return -1
+	}
+	for i, player := range model.Players {
+		if player.IsTurn {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,151 @@
+//go:build gpio && linux
+
+package gpio
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+)
+
+// sysfsRoot is the sysfs GPIO interface directory
+const sysfsRoot = "/sys/class/gpio"
+
+// Controller drives the configured GPIO buttons and LEDs
+type Controller struct {
+	opts         options.GPIOOptions
+	buttons      map[int]*button
+	activePlayer int
+}
+
+// Open exports and configures the pins from the options
+func Open(opts options.GPIOOptions) (*Controller, error) {
+	if err := options.ValidateGPIO(opts); err != nil {
+		return nil, err
+	}
+	c := &Controller{opts: opts, buttons: map[int]*button{}, activePlayer: -2}
+
+	inputs := map[int]func() common.Message{
+		opts.SwitchTurnPin: func() common.Message { return &common.SwitchTurnsMsg{} },
+		opts.PausePin:      func() common.Message { return &common.StartGameMsg{} },
+	}
+	for pin, msg := range inputs {
+		if pin <= 0 {
+			continue
+		}
+		if err := setupPin(pin, "in", opts.ActiveLow); err != nil {
+			return nil, err
+		}
+		c.buttons[pin] = &button{msg: msg}
+	}
+
+	for _, pin := range opts.PlayerLEDPins {
+		if err := setupPin(pin, "out", false); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Run polls the buttons and sends their messages to msgChan until done is closed
+func (c *Controller) Run(msgChan chan<- common.Message, done <-chan struct{}) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			for pin, b := range c.buttons {
+				pressed, err := readPin(pin)
+				if err == nil && b.update(pressed, now) {
+					select {
+					case msgChan <- b.msg():
+					case <-done:
+						return
+					}
+				}
+			}
+		case <-done:
+			c.SetActivePlayer(-1)
+			return
+		}
+	}
+}
+
+// SetActivePlayer lights the LED of the active player and turns off all others
+func (c *Controller) SetActivePlayer(index int) {
+	if index == c.activePlayer {
+		return
+	}
+	c.activePlayer = index
+	for i, pin := range c.opts.PlayerLEDPins {
+		value := "0"
+		if i == index {
+			value = "1"
+		}
+		_ = writePinFile(pin, "value", value)
+	}
+}
+
+// setupPin exports a pin and sets its direction and polarity
+func setupPin(pin int, direction string, activeLow bool) error {
+	if _, err := os.Stat(pinPath(pin, "")); os.IsNotExist(err) {
+		if err := os.WriteFile(sysfsRoot+"/export", []byte(strconv.Itoa(pin)), 0); err != nil {
+			return fmt.Errorf("exporting GPIO %d: %w", pin, err)
+		}
+	}
+
+	// udev may need a moment to grant access to a freshly exported pin
+	var err error
+	for attempt := 0; attempt < 10; attempt++ {
+		if err = writePinFile(pin, "direction", direction); err == nil {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err != nil {
+		return fmt.Errorf("configuring GPIO %d: %w", pin, err)
+	}
+
+	if activeLow {
+		return writePinFile(pin, "active_low", "1")
+	}
+	return writePinFile(pin, "active_low", "0")
+}
+
+// readPin reports whether the pin is active
+func readPin(pin int) (bool, error) {
+	value, err := os.ReadFile(pinPath(pin, "value"))
+	if err != nil {
+		return false, err
+	}
+	switch strings.TrimSpace(string(value)) {
+	case "1":
+		return true, nil
+	case "0":
+		return false, nil
+	default:
+		return false, errors.New("unexpected GPIO value")
+	}
+}
+
+// writePinFile writes a value to one of the sysfs attribute files of a pin
+func writePinFile(pin int, name string, value string) error {
+	return os.WriteFile(pinPath(pin, name), []byte(value), 0)
+}
+
+// pinPath returns the sysfs path of a pin, or of one of its attribute files
+func pinPath(pin int, name string) string {
+	path := fmt.Sprintf("%s/gpio%d", sysfsRoot, pin)
+	if name != "" {
+		path += "/" + name
+	}
+	return path
+}
@@ -0,0 +1,22 @@
+//go:build !gpio || !linux
+
+package gpio
+
+import (
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+)
+
+// Controller drives the configured GPIO buttons and LEDs
+type Controller struct{}
+
+// Open always fails in builds without GPIO support
+func Open(options.GPIOOptions) (*Controller, error) {
+	return nil, ErrNotSupported
+}
+
+// Run does nothing in builds without GPIO support
+func (c *Controller) Run(chan<- common.Message, <-chan struct{}) {}
+
+// SetActivePlayer does nothing in builds without GPIO support
+func (c *Controller) SetActivePlayer(int) {}
@@ -0,0 +1,34 @@
+package gpio
+
+import (
+	"testing"
+	"time"
+)
+
+func TestButtonDebounce(t *testing.T) {
+	start := time.Date(2026, 4, 12, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		samples  []bool        // Pin state at each poll
+		interval time.Duration // Time between the polls
+		expected []bool        // Whether each poll reports a press
+	}{
+		{"single press", []bool{false, true, true, false}, pollInterval, []bool{false, true, false, false}},
+		{"bounce within the debounce time", []bool{true, false, true, false, true}, pollInterval,
+			[]bool{true, false, false, false, false}},
+		{"presses after the debounce time", []bool{true, false, true}, debounceTime, []bool{true, false, true}},
+		{"held down", []bool{true, true, true}, debounceTime, []bool{true, false, false}},
+		{"released", []bool{false, false}, pollInterval, []bool{false, false}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := &button{}
+			for i, pressed := range test.samples {
+				now := start.Add(time.Duration(i) * test.interval)
+				if got := b.update(pressed, now); got != test.expected[i] {
+					t.Errorf("Expected poll %d to report %v, got %v", i, test.expected[i], got)
+				}
+			}
+		})
+	}
+}
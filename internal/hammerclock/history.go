@@ -0,0 +1,142 @@
+package hammerclock
+
+import (
+	"slices"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/logging"
+)
+
+// The history keeps a snapshot of a game before each turn switch, phase change and end of the game, the actions players
+// take by mistake. It does not record the messages themselves, so other changes cannot be undone.
+
+// historySize is the number of actions that can be undone
+const historySize = 20
+
+// Actions recorded in the history
+const (
+	actionTurnSwitch  = "turn switch"
+	actionPhaseChange = "phase change"
+	actionEndGame     = "end of the game"
+)
+
+// recordHistory keeps the state of a game before an action that can be undone, and forgets the undone actions that
+// could be redone. Updates that change the history themselves, e.g. an undo, are not recorded.
+func recordHistory(before, after common.Model) common.Model {
+	if len(before.History) != len(after.History) || len(before.Redo) != len(after.Redo) {
+		return after
+	}
+	action := undoableAction(before, after)
+	if action == "" {
+		return after
+	}
+	history := append(slices.Clip(before.History), takeSnapshot(action, before))
+	after.History = history[max(len(history)-historySize, 0):]
+	after.Redo = nil
+	return after
+}
+
+// undoableAction returns the action between two states of a game that can be undone, or an empty string if there is
+// none
+func undoableAction(before, after common.Model) string {
+	if before.GameStarted && !after.GameStarted {
+		return actionEndGame
+	}
+	if !before.GameStarted || len(before.Players) != len(after.Players) {
+		return ""
+	}
+	action := ""
+	for i, player := range before.Players {
+		switch changed := after.Players[i]; {
+		case player.IsTurn != changed.IsTurn || player.TurnCount != changed.TurnCount:
+			return actionTurnSwitch
+		case player.CurrentPhase != changed.CurrentPhase:
+			action = actionPhaseChange
+		}
+	}
+	return action
+}
+
+// takeSnapshot returns the state of a game before an action
+func takeSnapshot(action string, model common.Model) common.Snapshot {
+	snapshot := common.Snapshot{
+		Action:        action,
+		GameStarted:   model.GameStarted,
+		GameStatus:    model.GameStatus,
+		TotalGameTime: model.TotalGameTime,
+		PausedTime:    model.PausedTime,
+		Players:       make([]common.Player, len(model.Players)),
+	}
+	for i, player := range model.Players {
+		snapshot.Players[i] = *player
+	}
+	return snapshot
+}
+
+// restoreSnapshot returns the game in the state of a snapshot. The time played since the snapshot was taken is
+// given to the player who was active then, as when a turn switch is reverted.
+func restoreSnapshot(snapshot common.Snapshot, model common.Model) common.Model {
+	var played time.Duration
+	if model.GameStarted && snapshot.GameStarted {
+		played = max(model.TotalGameTime-snapshot.TotalGameTime, 0)
+	}
+
+	newModel := model
+	newModel.GameStarted = snapshot.GameStarted
+	newModel.GameStatus = snapshot.GameStatus
+	newModel.TotalGameTime = snapshot.TotalGameTime + played
+	newModel.PausedTime = max(snapshot.PausedTime, model.PausedTime)
+	newModel.LastTurnSwitch = nil
+	newModel.GraceRemaining = 0
+	newModel.Players = make([]*common.Player, len(snapshot.Players))
+	for i := range snapshot.Players {
+		player := snapshot.Players[i]
+		// The slices are clipped, so the snapshots sharing them are not changed when they grow
+		player.ActionLog = slices.Clip(player.ActionLog)
+		player.TurnDurations = slices.Clip(player.TurnDurations)
+		if player.IsTurn {
+			player.TimeElapsed += played
+			player.PhaseElapsed += played
+		}
+		newModel.Players[i] = &player
+	}
+	return newModel
+}
+
+// handleUndo undoes the latest turn switch, phase change or end of the game, and logs it for the active player
+func handleUndo(model common.Model) (common.Model, Command) {
+	if len(model.History) == 0 {
+		return model, noCommand
+	}
+	snapshot := model.History[len(model.History)-1]
+	newModel := restoreSnapshot(snapshot, model)
+	newModel.History = slices.Clip(model.History[:len(model.History)-1])
+	newModel.Redo = append(slices.Clip(model.Redo), takeSnapshot(snapshot.Action, model))
+	logActive(&newModel, "Undid the %s", snapshot.Action)
+	return newModel, noCommand
+}
+
+// handleRedo redoes the latest undone action, and logs it for the active player
+func handleRedo(model common.Model) (common.Model, Command) {
+	if len(model.Redo) == 0 {
+		return model, noCommand
+	}
+	snapshot := model.Redo[len(model.Redo)-1]
+	newModel := restoreSnapshot(snapshot, model)
+	newModel.Redo = slices.Clip(model.Redo[:len(model.Redo)-1])
+	newModel.History = append(slices.Clip(model.History), takeSnapshot(snapshot.Action, model))
+	logActive(&newModel, "Redid the %s", snapshot.Action)
+	return newModel, noCommand
+}
+
+// logActive adds an entry to the action log of the active player, whose player must already be a copy owned by the
+// model
+func logActive(model *common.Model, format string, args ...any) {
+	for _, player := range model.Players {
+		if player.IsTurn {
+			logging.AddLogEntry(player, model, common.LogTypeGame, format, args...)
+			return
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package hotkey registers system-wide hotkeys, so the active player can be switched even when the
+// hammerclock terminal is not focused. The hotkeys are captured through a platform hook: the evdev
+// input devices on Linux and RegisterHotKey on Windows.
+package hotkey
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+)
+
+// ErrNotSupported is returned by Start on platforms without a global hotkey hook
+var ErrNotSupported = errors.New("global hotkeys are not supported on this platform")
+
+// key identifies a hotkey independently of the platform
+type key int
+
+// Supported hotkeys. Function keys are used because they are unlikely to clash with other applications.
+const (
+	keyF1 key = iota + 1
+	keyF2
+	keyF3
+	keyF4
+	keyF5
+	keyF6
+	keyF7
+	keyF8
+	keyF9
+	keyF10
+	keyF11
+	keyF12
+	keyPause
+	keyScrollLock
+)
+
+// keyNames maps the key names used in the options to the supported hotkeys
+var keyNames = map[string]key{
+	"F1": keyF1, "F2": keyF2, "F3": keyF3, "F4": keyF4, "F5": keyF5, "F6": keyF6,
+	"F7": keyF7, "F8": keyF8, "F9": keyF9, "F10": keyF10, "F11": keyF11, "F12": keyF12,
+	"PAUSE": keyPause, "SCROLLLOCK": keyScrollLock,
+}
+
+// bindings returns the hotkeys configured in the options and the messages they send
+func bindings(opts options.GlobalHotkeyOptions) (map[key]func() common.Message, error) {
+	configured := map[string]func() common.Message{
+		opts.SwitchTurn: func() common.Message { return &common.SwitchTurnsMsg{} },
+		opts.Pause:      func() common.Message { return &common.StartGameMsg{} },
+	}
+
+	result := map[key]func() common.Message{}
+	for name, msg := range configured {
+		if name == "" {
+			continue
+		}
+		k, ok := keyNames[strings.ToUpper(name)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported hotkey '%s'", name)
+		}
+		result[k] = msg
+	}
+	return result, nil
+}
+
+// send delivers a hotkey message unless the application is shutting down
+func send(msgChan chan<- common.Message, msg common.Message, done <-chan struct{}) {
+	select {
+	case msgChan <- msg:
+	case <-done:
+	}
+}
@@ -0,0 +1,83 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+)
+
+// inputEvent mirrors struct input_event from linux/input.h
+type inputEvent struct {
+	Time  syscall.Timeval
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+// evKey is the event type of key presses, keyPressed the value of a key press (not a release or repeat)
+const (
+	evKey      = 0x01
+	keyPressed = 1
+)
+
+// evdevCodes maps the supported hotkeys to Linux key codes
+var evdevCodes = map[key]uint16{
+	keyF1: 59, keyF2: 60, keyF3: 61, keyF4: 62, keyF5: 63, keyF6: 64, keyF7: 65, keyF8: 66,
+	keyF9: 67, keyF10: 68, keyF11: 87, keyF12: 88, keyPause: 119, keyScrollLock: 70,
+}
+
+// Start opens the keyboard input device and sends the messages of the configured hotkeys to msgChan
+// until done is closed. Reading input devices usually requires membership of the "input" group.
+func Start(opts options.GlobalHotkeyOptions, msgChan chan<- common.Message, done <-chan struct{}) error {
+	keys, err := bindings(opts)
+	if err != nil {
+		return err
+	}
+	if opts.Device == "" {
+		return fmt.Errorf("no keyboard device configured, set globalHotkeys.device (e.g. /dev/input/event3)")
+	}
+
+	device, err := os.Open(opts.Device)
+	if err != nil {
+		return err
+	}
+
+	codes := map[uint16]func() common.Message{}
+	for k, msg := range keys {
+		codes[evdevCodes[k]] = msg
+	}
+
+	go func() {
+		<-done
+		_ = device.Close()
+	}()
+
+	go func() {
+		buffer := make([]byte, unsafe.Sizeof(inputEvent{}))
+		for {
+			if _, err := io.ReadFull(device, buffer); err != nil {
+				return
+			}
+			var event inputEvent
+			if _, err := binary.Decode(buffer, binary.NativeEndian, &event); err != nil {
+				continue
+			}
+			if event.Type != evKey || event.Value != keyPressed {
+				continue
+			}
+			if msg, ok := codes[event.Code]; ok {
+				send(msgChan, msg(), done)
+			}
+		}
+	}()
+
+	return nil
+}
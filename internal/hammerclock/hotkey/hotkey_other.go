@@ -0,0 +1,13 @@
+//go:build !linux && !windows
+
+package hotkey
+
+import (
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+)
+
+// Start always fails on platforms without a global hotkey hook
+func Start(options.GlobalHotkeyOptions, chan<- common.Message, <-chan struct{}) error {
+	return ErrNotSupported
+}
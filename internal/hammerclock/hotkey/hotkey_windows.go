@@ -0,0 +1,97 @@
+//go:build windows
+
+package hotkey
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+)
+
+var (
+	user32                = syscall.NewLazyDLL("user32.dll")
+	kernel32              = syscall.NewLazyDLL("kernel32.dll")
+	procRegisterHotKey    = user32.NewProc("RegisterHotKey")
+	procUnregisterHotKey  = user32.NewProc("UnregisterHotKey")
+	procGetMessage        = user32.NewProc("GetMessageW")
+	procPostThreadMessage = user32.NewProc("PostThreadMessageW")
+	procGetCurrentThread  = kernel32.NewProc("GetCurrentThreadId")
+)
+
+// Windows message identifiers
+const (
+	wmHotkey = 0x0312
+	wmQuit   = 0x0012
+)
+
+// msg mirrors the Win32 MSG structure
+type msg struct {
+	Hwnd    uintptr
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	Pt      struct{ X, Y int32 }
+}
+
+// virtualKeys maps the supported hotkeys to Windows virtual-key codes
+var virtualKeys = map[key]uintptr{
+	keyF1: 0x70, keyF2: 0x71, keyF3: 0x72, keyF4: 0x73, keyF5: 0x74, keyF6: 0x75, keyF7: 0x76, keyF8: 0x77,
+	keyF9: 0x78, keyF10: 0x79, keyF11: 0x7A, keyF12: 0x7B, keyPause: 0x13, keyScrollLock: 0x91,
+}
+
+// Start registers the configured hotkeys and sends their messages to msgChan until done is closed
+func Start(opts options.GlobalHotkeyOptions, msgChan chan<- common.Message, done <-chan struct{}) error {
+	keys, err := bindings(opts)
+	if err != nil {
+		return err
+	}
+
+	started := make(chan error)
+	go func() {
+		// Hotkey messages are delivered to the thread that registered them
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		ids := map[uintptr]func() common.Message{}
+		for k, message := range keys {
+			id := uintptr(len(ids) + 1)
+			if r, _, err := procRegisterHotKey.Call(0, id, 0, virtualKeys[k]); r == 0 {
+				started <- fmt.Errorf("registering hotkey: %v", err)
+				return
+			}
+			ids[id] = message
+		}
+		defer func() {
+			for id := range ids {
+				_, _, _ = procUnregisterHotKey.Call(0, id)
+			}
+		}()
+
+		threadID, _, _ := procGetCurrentThread.Call()
+		go func() {
+			<-done
+			_, _, _ = procPostThreadMessage.Call(threadID, wmQuit, 0, 0)
+		}()
+		started <- nil
+
+		var m msg
+		for {
+			r, _, _ := procGetMessage.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+			if int32(r) <= 0 {
+				return
+			}
+			if m.Message == wmHotkey {
+				if message, ok := ids[m.WParam]; ok {
+					send(msgChan, message(), done)
+				}
+			}
+		}
+	}()
+
+	return <-started
+}
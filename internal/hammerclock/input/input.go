@@ -0,0 +1,107 @@
+// Package input reads switch events from external devices, such as footswitches or arcade buttons
+// connected through a serial port or a raw HID device, and turns them into application messages.
+package input
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+
+	"go.bug.st/serial"
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+)
+
+// Device is an opened external input device
+type Device struct {
+	file io.ReadCloser
+	opts options.ExternalInputOptions
+}
+
+// Open opens the external input device configured in the options. Serial ports are set to the configured baud
+// rate with 8 data bits, no parity and 1 stop bit in raw mode, so the events are read as they are sent; COM ports of
+// any number are opened on Windows. HID devices are read as files.
+func Open(opts options.ExternalInputOptions) (*Device, error) {
+	if opts.Mode == "hid" {
+		file, err := os.Open(opts.Device)
+		if err != nil {
+			return nil, err
+		}
+		return &Device{file: file, opts: opts}, nil
+	}
+
+	baudRate := opts.BaudRate
+	if baudRate <= 0 {
+		baudRate = options.DefaultOptions.ExternalInput.BaudRate
+	}
+	port, err := serial.Open(opts.Device, &serial.Mode{
+		BaudRate: baudRate,
+		DataBits: 8,
+		Parity:   serial.NoParity,
+		StopBits: serial.OneStopBit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Device{file: port, opts: opts}, nil
+}
+
+// Run reads switch events from the device and sends the mapped messages to msgChan until done is closed
+// or the device is disconnected.
+func (d *Device) Run(msgChan chan<- common.Message, done <-chan struct{}) {
+	// Closing the file unblocks the pending read when the application shuts down
+	go func() {
+		<-done
+		_ = d.file.Close()
+	}()
+
+	readEvents(d.file, d.opts, func(msg common.Message) {
+		select {
+		case msgChan <- msg:
+		case <-done:
+		}
+	})
+}
+
+// readEvents reads events from the reader and calls send for every event mapped to a message.
+// In "hid" mode every read is a raw report compared as a hex string, otherwise the input is read line by line.
+func readEvents(r io.Reader, opts options.ExternalInputOptions, send func(common.Message)) {
+	if opts.Mode == "hid" {
+		report := make([]byte, 64)
+		for {
+			n, err := r.Read(report)
+			if n > 0 {
+				if msg := messageFor(hex.EncodeToString(report[:n]), opts); msg != nil {
+					send(msg)
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if msg := messageFor(scanner.Text(), opts); msg != nil {
+			send(msg)
+		}
+	}
+}
+
+// messageFor returns the message mapped to the given event, or nil if the event is not mapped
+func messageFor(event string, opts options.ExternalInputOptions) common.Message {
+	event = strings.TrimSpace(event)
+	switch {
+	case event == "":
+		return nil
+	case strings.EqualFold(event, opts.SwitchTurn):
+		return &common.SwitchTurnsMsg{}
+	case strings.EqualFold(event, opts.Pause):
+		return &common.StartGameMsg{}
+	default:
+		return nil
+	}
+}
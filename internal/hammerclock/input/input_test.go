@@ -0,0 +1,41 @@
+package input
+
+import (
+	"strings"
+	"testing"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+)
+
+func TestReadEventsMapsSerialLines(t *testing.T) {
+	opts := options.ExternalInputOptions{SwitchTurn: "SWITCH", Pause: "PAUSE"}
+	var received []common.Message
+
+	readEvents(strings.NewReader("switch\r\nnoise\nPAUSE\n"), opts, func(msg common.Message) {
+		received = append(received, msg)
+	})
+
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(received))
+	}
+	if _, ok := received[0].(*common.SwitchTurnsMsg); !ok {
+		t.Errorf("Expected SwitchTurnsMsg, got %T", received[0])
+	}
+	if _, ok := received[1].(*common.StartGameMsg); !ok {
+		t.Errorf("Expected StartGameMsg, got %T", received[1])
+	}
+}
+
+func TestReadEventsMapsHIDReports(t *testing.T) {
+	opts := options.ExternalInputOptions{Mode: "hid", SwitchTurn: "0102"}
+	var received []common.Message
+
+	readEvents(strings.NewReader("\x01\x02"), opts, func(msg common.Message) {
+		received = append(received, msg)
+	})
+
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(received))
+	}
+}
@@ -0,0 +1,142 @@
+package hammerclock
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"hammerclock/internal/hammerclock/audit"
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/logging"
+	"hammerclock/internal/hammerclock/options"
+)
+
+// handleShowJudge asks for the judge passphrase, or for the judge's action once judge mode is unlocked. Judge mode
+// is only available with a passphrase in the options.
+func handleShowJudge(model common.Model) (common.Model, Command) {
+	if model.Options.JudgePassphrase == "" || model.Linked {
+		return model, noCommand
+	}
+	modalType := "JudgeLogin"
+	if model.JudgeMode {
+		modalType = "JudgeAction"
+	}
+	return model, func() common.Message {
+		return &common.ShowModalMsg{Type: modalType}
+	}
+}
+
+// handleJudgeLogin unlocks judge mode if the passphrase matches. Failed attempts are audited too and ring the bell.
+func handleJudgeLogin(msg *common.JudgeLoginMsg, model common.Model) (common.Model, Command) {
+	if model.Options.JudgePassphrase == "" {
+		return model, noCommand
+	}
+	newModel := copyPlayers(model)
+	if subtle.ConstantTimeCompare([]byte(msg.Passphrase), []byte(model.Options.JudgePassphrase)) != 1 {
+		recordIntervention(&newModel, audit.Entry{Action: "login failed"})
+		return newModel, func() common.Message {
+			return &common.BellMsg{}
+		}
+	}
+	newModel.JudgeMode = true
+	recordIntervention(&newModel, audit.Entry{Action: "login"})
+	return newModel, noCommand
+}
+
+// handleJudgeAction applies an intervention of the judge, writing it to the affected player's action log and to
+// the audit log
+func handleJudgeAction(msg *common.JudgeActionMsg, model common.Model) (common.Model, Command) {
+	if !model.JudgeMode {
+		return model, noCommand
+	}
+	needsPlayer := msg.Action == "penalty" || msg.Action == "score"
+	if needsPlayer && (msg.Player < 0 || msg.Player >= len(model.Players)) {
+		return model, noCommand
+	}
+
+	newModel := copyPlayers(model)
+	switch msg.Action {
+	case "penalty":
+		player := newModel.Players[msg.Player]
+		player.TimeElapsed += msg.Penalty
+		logging.AddLogEntry(player, &newModel, common.LogTypeWarning, "Judge: %v time penalty %s", msg.Penalty, reasonText(msg.Reason))
+		recordIntervention(&newModel, audit.Entry{Action: "penalty", Player: player.Name,
+			Details: fmt.Sprintf("%v %s", msg.Penalty, reasonText(msg.Reason))})
+	case "score":
+		player := newModel.Players[msg.Player]
+		player.VictoryPoints += msg.Points
+		logging.AddLogEntry(player, &newModel, common.LogTypeScore, "Judge: %+d VP %s", msg.Points, reasonText(msg.Reason))
+		recordIntervention(&newModel, audit.Entry{Action: "score", Player: player.Name,
+			Details: fmt.Sprintf("%+d VP %s", msg.Points, reasonText(msg.Reason))})
+	case "pause":
+		if model.GameStatus != gameInProgress {
+			return model, noCommand
+		}
+		newModel.GameStatus = gamePaused
+		for _, player := range newModel.Players {
+			if player.IsTurn {
+				logging.AddLogEntry(player, &newModel, common.LogTypeGame, "Game paused by the judge %s", reasonText(msg.Reason))
+			}
+		}
+		recordIntervention(&newModel, audit.Entry{Action: "pause", Details: reasonText(msg.Reason)})
+	case "exit":
+		newModel.JudgeMode = false
+		recordIntervention(&newModel, audit.Entry{Action: "logout"})
+	default:
+		return model, noCommand
+	}
+	return newModel, noCommand
+}
+
+// pinRequired reports whether ending the game, adding suspended time and scoring secondary objectives need the
+// action PIN of the options. The judge does not need it in judge mode.
+func pinRequired(model common.Model) bool {
+	return model.Options.ActionPIN != "" && !model.JudgeMode
+}
+
+// checkPIN reports whether a protected action may be done with the PIN entered for it. Attempts are audited like the
+// interventions of the judge, so the players' copies must already be owned by the model.
+func checkPIN(model *common.Model, action, pin string) bool {
+	if !pinRequired(*model) {
+		return true
+	}
+	if subtle.ConstantTimeCompare([]byte(pin), []byte(model.Options.ActionPIN)) != 1 {
+		recordIntervention(model, audit.Entry{Action: "pin failed", Details: action})
+		return false
+	}
+	recordIntervention(model, audit.Entry{Action: "pin", Details: action})
+	return true
+}
+
+// copyPlayers returns a copy of the model with copies of its players, so their logs can be changed
+func copyPlayers(model common.Model) common.Model {
+	newModel := model
+	newModel.Players = make([]*common.Player, len(model.Players))
+	for i, player := range model.Players {
+		newPlayer := *player
+		newModel.Players[i] = &newPlayer
+	}
+	return newModel
+}
+
+// reasonText formats the reason the judge gave for an intervention, e.g. "(slow play)"
+func reasonText(reason string) string {
+	if reason == "" {
+		return "(no reason given)"
+	}
+	return "(" + reason + ")"
+}
+
+// recordIntervention appends an intervention to the audit log, keyed with the judge passphrase. Without a
+// passphrase nothing is audited, as anyone could forge the entries. The players' copies must already be owned by the
+// model, as a failure to write the log is noted in the first player's log.
+func recordIntervention(model *common.Model, entry audit.Entry) {
+	if model.AuditFile == "" || model.Options.JudgePassphrase == "" {
+		return
+	}
+	entry.Time = time.Now()
+	entry.Table = options.Identity(model.Options)
+	if err := audit.Append(model.AuditFile, model.Options.JudgePassphrase, entry); err != nil && len(model.Players) > 0 {
+		logging.AddLogEntry(model.Players[0], model, common.LogTypeWarning, "Judge intervention not audited: %v", err)
+	}
+}
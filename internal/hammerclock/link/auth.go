@@ -0,0 +1,87 @@
+package link
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+)
+
+// handshakeTimeout is how long the host waits for a client to answer its challenge
+const handshakeTimeout = 5 * time.Second
+
+// ErrNoSecret is returned when linking clocks without a link secret
+var ErrNoSecret = errors.New("linked clocks need a link secret")
+
+// Credentials authenticate the terminals linked to a host
+type Credentials struct {
+	Secret    string // Link secret shared by the host and its clients
+	Organizer string // Passphrase of the organizer, whose clients may push the round timer; empty for none
+}
+
+// proof returns the answer to a challenge of the host, which shows the secret is known without sending it
+func proof(secret, nonce string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(nonce))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// organizerNonce returns the challenge the organizer's passphrase answers, which differs from the one of the link
+// secret so one proof cannot stand in for the other
+func organizerNonce(nonce string) string {
+	return "organizer:" + nonce
+}
+
+// challenge sends a new challenge to a client and reports whether its answer proves it knows the link secret, and
+// whether it also proves it is the organizer. Clients that do not answer in time are refused.
+func (h *Host) challenge(conn net.Conn, scanner *bufio.Scanner) (ok, organizer bool) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return false, false
+	}
+	nonce := hex.EncodeToString(random)
+	line, _ := json.Marshal(message{Type: "challenge", Nonce: nonce})
+	if _, err := conn.Write(append(line, '\n')); err != nil {
+		return false, false
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(handshakeTimeout))
+	defer func() { _ = conn.SetReadDeadline(time.Time{}) }()
+	if !scanner.Scan() {
+		return false, false
+	}
+	var answer message
+	if err := json.Unmarshal(scanner.Bytes(), &answer); err != nil || answer.Type != "hello" {
+		return false, false
+	}
+	if !hmac.Equal([]byte(answer.Proof), []byte(proof(h.credentials.Secret, nonce))) {
+		return false, false
+	}
+	organizer = h.credentials.Organizer != "" &&
+		hmac.Equal([]byte(answer.Organizer), []byte(proof(h.credentials.Organizer, organizerNonce(nonce))))
+	return true, organizer
+}
+
+// answer waits for the host's challenge and answers it with the proof of the link secret, and of the organizer's
+// passphrase if the client has one
+func (c *Client) answer(conn net.Conn, scanner *bufio.Scanner) error {
+	_ = conn.SetReadDeadline(time.Now().Add(handshakeTimeout))
+	defer func() { _ = conn.SetReadDeadline(time.Time{}) }()
+	if !scanner.Scan() {
+		return errors.New("no challenge from the host")
+	}
+	var challenge message
+	if err := json.Unmarshal(scanner.Bytes(), &challenge); err != nil || challenge.Type != "challenge" {
+		return errors.New("invalid challenge from the host")
+	}
+	hello := message{Type: "hello", Proof: proof(c.credentials.Secret, challenge.Nonce)}
+	if c.credentials.Organizer != "" {
+		hello.Organizer = proof(c.credentials.Organizer, organizerNonce(challenge.Nonce))
+	}
+	return c.write(conn, hello)
+}
@@ -0,0 +1,175 @@
+package link
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Intervals of the client's handshakes and reconnection attempts
+const (
+	pingInterval      = 2 * time.Second
+	reconnectInterval = 2 * time.Second
+)
+
+// Client mirrors the game state of a host
+type Client struct {
+	addr        string
+	credentials Credentials
+	mu          sync.Mutex
+	filter      clockFilter
+
+	// The round timer is sent again whenever the client reconnects, so tables that restart catch up
+	writeMu      sync.Mutex
+	conn         net.Conn  // Current connection to the host, nil while disconnected
+	roundEnds    time.Time // End of the round, zero without a countdown
+	announcement string
+	roundSet     bool // Indicates if a round timer was set
+}
+
+// Join creates a client for the host at the given address, e.g. "192.168.1.20:7420", with the host's link secret
+func Join(addr string, credentials Credentials) *Client {
+	return &Client{addr: withDefaultPort(addr), credentials: credentials}
+}
+
+// Run connects to the host and sends the received game states to msgChan until done is closed. Lost connections
+// are reestablished; the clock offset estimated so far is kept, as the clocks do not drift apart quickly.
+func (c *Client) Run(msgChan chan<- common.Message, done <-chan struct{}) {
+	for {
+		conn, err := net.DialTimeout("tcp", c.addr, reconnectInterval)
+		if err == nil {
+			c.serve(conn, msgChan, done)
+		}
+
+		select {
+		case <-done:
+			return
+		case <-time.After(reconnectInterval):
+		}
+	}
+}
+
+// SetRound sends the end of the round and an announcement to the host, now and whenever the client reconnects.
+// A zero end clears the countdown and an empty announcement clears the announcement.
+func (c *Client) SetRound(ends time.Time, announcement string) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.roundEnds, c.announcement, c.roundSet = ends, announcement, true
+	if c.conn != nil {
+		_ = c.writeRound(c.conn)
+	}
+}
+
+// SendKey sends a game key pressed on the client to the host of a shared game. Keys pressed while disconnected are
+// dropped, as the game may have moved on by the time the connection is back.
+func (c *Client) SendKey(key tcell.Key, r rune) {
+	c.writeMu.Lock()
+	conn := c.conn
+	c.writeMu.Unlock()
+	if conn != nil {
+		_ = c.write(conn, message{Type: "key", Key: &keyPress{Key: key, Rune: r}})
+	}
+}
+
+// writeRound sends the round timer to the host, the caller must hold writeMu
+func (c *Client) writeRound(conn net.Conn) error {
+	round := &roundTimer{Announcement: c.announcement}
+	if !c.roundEnds.IsZero() {
+		// A round that is already over is sent as just over, so the host does not take it for no countdown
+		round.Remaining = max(time.Until(c.roundEnds), time.Nanosecond)
+	}
+	line, _ := json.Marshal(message{Type: "round", Round: round})
+	_, err := conn.Write(append(line, '\n'))
+	return err
+}
+
+// write sends a message to the host
+func (c *Client) write(conn net.Conn, msg message) error {
+	line, _ := json.Marshal(msg)
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := conn.Write(append(line, '\n'))
+	return err
+}
+
+// offset returns the estimated host clock minus client clock
+func (c *Client) offset() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.filter.offset()
+}
+
+// serve answers the host's challenge, then pings the host regularly and handles its messages until the connection
+// is lost or done is closed
+func (c *Client) serve(conn net.Conn, msgChan chan<- common.Message, done <-chan struct{}) {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(nil, 1<<20)
+	if err := c.answer(conn, scanner); err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	closed := make(chan struct{})
+	defer close(closed)
+
+	c.writeMu.Lock()
+	c.conn = conn
+	if c.roundSet {
+		_ = c.writeRound(conn)
+	}
+	c.writeMu.Unlock()
+	defer func() {
+		c.writeMu.Lock()
+		c.conn = nil
+		c.writeMu.Unlock()
+	}()
+
+	go func() {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		for {
+			if err := c.write(conn, message{Type: "ping", T0: time.Now().UnixNano()}); err != nil {
+				return
+			}
+			select {
+			case <-ticker.C:
+			case <-done:
+				_ = conn.Close()
+				return
+			case <-closed:
+				return
+			}
+		}
+	}()
+
+	for scanner.Scan() {
+		received := time.Now()
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+
+		switch msg.Type {
+		case "pong":
+			c.mu.Lock()
+			c.filter.add(newSample(time.Unix(0, msg.T0), time.Unix(0, msg.T1), time.Unix(0, msg.T2), received))
+			c.mu.Unlock()
+		case "state":
+			if msg.State == nil {
+				continue
+			}
+			select {
+			case msgChan <- msg.State.toMessage(received.Add(c.offset())):
+			case <-done:
+				return
+			}
+		}
+	}
+	_ = conn.Close()
+}
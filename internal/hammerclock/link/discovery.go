@@ -0,0 +1,95 @@
+package link
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"time"
+)
+
+// DiscoveryPort is the UDP port hosts announce themselves on, so dashboards on the local network find them
+const DiscoveryPort = 7421
+
+// announceInterval is the interval of a host's announcements
+const announceInterval = 2 * time.Second
+
+// announcement is the message a host broadcasts on the local network
+type announcement struct {
+	Type string `json:"type"` // "announce"
+	Port int    `json:"port"` // TCP port the host is listening on for clients
+}
+
+// Announce broadcasts the host on the local network until done is closed, so organizer dashboards can link to it
+func (h *Host) Announce(done <-chan struct{}) error {
+	return h.announceTo(&net.UDPAddr{IP: net.IPv4bcast, Port: DiscoveryPort}, done)
+}
+
+// announceTo sends the host's announcements to the given address until done is closed
+func (h *Host) announceTo(addr *net.UDPAddr, done <-chan struct{}) error {
+	conn, err := net.DialUDP("udp4", nil, addr)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	port := 0
+	if tcpAddr, ok := h.Addr().(*net.TCPAddr); ok {
+		port = tcpAddr.Port
+	}
+	payload, _ := json.Marshal(announcement{Type: "announce", Port: port})
+
+	ticker := time.NewTicker(announceInterval)
+	defer ticker.Stop()
+	for {
+		// Announcements are best effort, a network without broadcasts only loses the discovery
+		_, _ = conn.Write(payload)
+		select {
+		case <-ticker.C:
+		case <-done:
+			return nil
+		}
+	}
+}
+
+// Discover listens for the announcements of hosts on the local network and sends the address of every newly
+// found host to found, until done is closed
+func Discover(found chan<- string, done <-chan struct{}) error {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: DiscoveryPort})
+	if err != nil {
+		return err
+	}
+	go discover(conn, found, done)
+	return nil
+}
+
+// discover reads announcements from the connection until done is closed
+func discover(conn *net.UDPConn, found chan<- string, done <-chan struct{}) {
+	go func() {
+		<-done
+		_ = conn.Close()
+	}()
+
+	known := map[string]bool{}
+	buffer := make([]byte, 512)
+	for {
+		n, from, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			return
+		}
+		var msg announcement
+		if err := json.Unmarshal(buffer[:n], &msg); err != nil || msg.Type != "announce" || msg.Port == 0 {
+			continue
+		}
+
+		addr := net.JoinHostPort(from.IP.String(), strconv.Itoa(msg.Port))
+		if known[addr] {
+			continue
+		}
+		known[addr] = true
+		select {
+		case found <- addr:
+		case <-done:
+			return
+		}
+	}
+}
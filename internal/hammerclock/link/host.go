@@ -0,0 +1,163 @@
+package link
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+)
+
+// clientQueueSize is the number of messages queued for a slow client before further states are dropped
+const clientQueueSize = 16
+
+// Host shares the game state with the clients linked to it
+type Host struct {
+	listener    net.Listener
+	credentials Credentials
+	mu          sync.Mutex
+	clients     map[net.Conn]chan []byte
+	playable    func(*common.KeyPressMsg) bool // Game keys the clients may play, nil unless the game is shared
+}
+
+// Listen starts listening on the given address, e.g. ":7420", for clients with the link secret of the credentials
+func Listen(addr string, credentials Credentials) (*Host, error) {
+	if credentials.Secret == "" {
+		return nil, ErrNoSecret
+	}
+	listener, err := net.Listen("tcp", withDefaultPort(addr))
+	if err != nil {
+		return nil, err
+	}
+	return &Host{listener: listener, credentials: credentials, clients: map[net.Conn]chan []byte{}}, nil
+}
+
+// Addr returns the address the host is listening on
+func (h *Host) Addr() net.Addr {
+	return h.listener.Addr()
+}
+
+// Share lets the clients play the game, e.g. so each player keeps the clocks on their own laptop. The game keys
+// pressed on a client that playable accepts are passed on to msgChan as if pressed on the host. It must be called
+// before Run.
+func (h *Host) Share(playable func(*common.KeyPressMsg) bool) {
+	h.playable = playable
+}
+
+// Run accepts clients until done is closed. Round timers sent by the organizer, and the game keys of a shared game,
+// are passed on to msgChan.
+func (h *Host) Run(msgChan chan<- common.Message, done <-chan struct{}) {
+	// Closing the listener unblocks the pending accept when the application shuts down
+	go func() {
+		<-done
+		_ = h.listener.Close()
+		h.mu.Lock()
+		for conn := range h.clients {
+			_ = conn.Close()
+		}
+		h.mu.Unlock()
+	}()
+
+	for {
+		conn, err := h.listener.Accept()
+		if err != nil {
+			return
+		}
+		go h.serve(conn, msgChan, done)
+	}
+}
+
+// Broadcast sends the game state to all clients. running tells whether the active player's clock is running and
+// round is the current battle round. Clients that cannot keep up miss states rather than delaying the host.
+func (h *Host) Broadcast(model *common.Model, running bool, round int) {
+	s := stateFromModel(model, running, round, time.Now())
+	s.Shared = h.playable != nil
+	line, err := json.Marshal(message{Type: "state", State: s})
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, queue := range h.clients {
+		select {
+		case queue <- line:
+		default:
+		}
+	}
+}
+
+// serve challenges a client for the link secret, then answers its pings, passes on its game keys, and its round
+// timers if it is the organizer, and writes its queued messages until the connection is closed. Clients without the
+// secret are disconnected.
+func (h *Host) serve(conn net.Conn, msgChan chan<- common.Message, done <-chan struct{}) {
+	scanner := bufio.NewScanner(conn)
+	ok, organizer := h.challenge(conn, scanner)
+	if !ok {
+		_ = conn.Close()
+		return
+	}
+
+	queue := make(chan []byte, clientQueueSize)
+	h.mu.Lock()
+	h.clients[conn] = queue
+	h.mu.Unlock()
+
+	// The queue is only closed once Broadcast can no longer send to it
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, conn)
+		h.mu.Unlock()
+		close(queue)
+		_ = conn.Close()
+	}()
+
+	go func() {
+		for line := range queue {
+			if _, err := conn.Write(append(line, '\n')); err != nil {
+				_ = conn.Close()
+				return
+			}
+		}
+	}()
+
+	for scanner.Scan() {
+		received := time.Now()
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if msg.Type == "round" && msg.Round != nil && organizer {
+			select {
+			case msgChan <- msg.Round.toMessage(received):
+			case <-done:
+				return
+			}
+			continue
+		}
+		if msg.Type == "key" && msg.Key != nil && h.playable != nil {
+			key := &common.KeyPressMsg{Key: msg.Key.Key, Rune: msg.Key.Rune}
+			if !h.playable(key) {
+				continue
+			}
+			select {
+			case msgChan <- key:
+			case <-done:
+				return
+			}
+			continue
+		}
+		if msg.Type != "ping" {
+			continue
+		}
+
+		// The pong is timestamped when it is queued, which is as close to sending it as the host gets
+		pong, _ := json.Marshal(message{Type: "pong", T0: msg.T0, T1: received.UnixNano(), T2: time.Now().UnixNano()})
+		select {
+		case queue <- pong:
+		default:
+		}
+	}
+}
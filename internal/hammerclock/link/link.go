@@ -0,0 +1,179 @@
+// Package link connects hammerclock instances over the network, so a second terminal, e.g. on the other side of
+// the table, shows the same clocks as the host. The host sends the game state whenever it changes. Clients estimate
+// the offset between their clock and the host's with an NTP-style handshake and correct the received times for the
+// transfer delay, so both displays agree within a fraction of a second even over flaky Wi-Fi. An organizer linked
+// to the tables can push the round countdown and announcements to them. Clients prove they know the link secret
+// when they connect, by answering a random challenge of the host with an HMAC of it.
+package link
+
+import (
+	"net"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// DefaultPort is the TCP port used when an address has no port
+const DefaultPort = "7420"
+
+// message is a line of the JSON protocol spoken between the host and its clients
+type message struct {
+	Type      string      `json:"type"`                // "challenge", "hello", "ping", "pong", "state", "round" or "key"
+	Nonce     string      `json:"nonce,omitempty"`     // Random challenge the host sends a new client
+	Proof     string      `json:"proof,omitempty"`     // Answer of the client to the challenge, for "hello" messages
+	Organizer string      `json:"organizer,omitempty"` // Answer with the organizer's passphrase, for "hello" messages
+	T0        int64       `json:"t0,omitempty"`        // Client time the ping was sent, in Unix nanoseconds
+	T1        int64       `json:"t1,omitempty"`        // Host time the ping was received
+	T2        int64       `json:"t2,omitempty"`        // Host time the pong was sent
+	State     *state      `json:"state,omitempty"`     // Game state, for "state" messages
+	Round     *roundTimer `json:"round,omitempty"`     // Round countdown and announcement, for "round" messages
+	Key       *keyPress   `json:"key,omitempty"`       // Game key pressed on a client, for "key" messages
+}
+
+// keyPress is a game key pressed on a client of a shared game, played on the host
+type keyPress struct {
+	Key  tcell.Key `json:"key"`
+	Rune rune      `json:"rune,omitempty"`
+}
+
+// roundTimer is the round countdown and announcement an organizer sends to the tables. The countdown is sent as the
+// time left rather than the end of the round, so it does not depend on the clocks of the two machines agreeing.
+type roundTimer struct {
+	Remaining    time.Duration `json:"remaining"` // Time left in the round, 0 without a countdown
+	Announcement string        `json:"announcement"`
+}
+
+// toMessage converts a received round timer to a RoundTimerMsg, counting down from the time it was received
+func (r *roundTimer) toMessage(received time.Time) *common.RoundTimerMsg {
+	msg := &common.RoundTimerMsg{Announcement: r.Announcement}
+	if r.Remaining > 0 {
+		msg.Ends = received.Add(r.Remaining)
+	}
+	return msg
+}
+
+// state is the game state shared with the clients
+type state struct {
+	SentAt        int64             `json:"sentAt"` // Host time the state was sent, in Unix nanoseconds
+	Status        common.GameStatus `json:"status"`
+	GameStarted   bool              `json:"gameStarted"`
+	Running       bool              `json:"running"` // The active player's clock is running
+	TotalGameTime time.Duration     `json:"totalGameTime"`
+	PausedTime    time.Duration     `json:"pausedTime"`
+	Players       []playerState     `json:"players"`
+	Table         string            `json:"table"`            // Event and table of the host, its banner or its ruleset name
+	Round         int               `json:"round"`            // Battle round, 0 if the players do not alternate turns
+	TimeBudget    time.Duration     `json:"timeBudget"`       // Time on the clocks without time odds, 0 without a limit
+	Shared        bool              `json:"shared,omitempty"` // The host plays the game keys pressed on the clients
+}
+
+// playerState is the state of a player shared with the clients
+type playerState struct {
+	Name          string        `json:"name"`
+	TimeElapsed   time.Duration `json:"timeElapsed"`
+	PhaseElapsed  time.Duration `json:"phaseElapsed"`
+	IsTurn        bool          `json:"isTurn"`
+	CurrentPhase  int           `json:"currentPhase"`
+	TurnCount     int           `json:"turnCount"`
+	VictoryPoints int           `json:"victoryPoints"`
+	Flagged       bool          `json:"flagged"`
+	ByoYomiPeriod int           `json:"byoYomiPeriod,omitempty"`
+	PeriodLeft    time.Duration `json:"periodLeft,omitempty"`
+	TimeBudget    time.Duration `json:"timeBudget"` // Time on the player's clock with the time odds, 0 without a limit
+}
+
+// stateFromModel captures the game state of the model in the given battle round at the given time
+func stateFromModel(model *common.Model, running bool, round int, now time.Time) *state {
+	table := options.Identity(model.Options)
+	if table == "" {
+		table = model.Options.Banner
+	}
+	if table == "" && model.Options.Default < len(model.Options.Rules) {
+		table = model.Options.Rules[model.Options.Default].Name
+	}
+
+	s := &state{
+		SentAt:        now.UnixNano(),
+		Status:        model.GameStatus,
+		GameStarted:   model.GameStarted,
+		Running:       running,
+		TotalGameTime: model.TotalGameTime,
+		PausedTime:    model.PausedTime,
+		Players:       make([]playerState, len(model.Players)),
+		Table:         table,
+		Round:         round,
+		TimeBudget:    options.GameTimeBudget(model.Options),
+	}
+	for i, player := range model.Players {
+		s.Players[i] = playerState{
+			Name:          player.Name,
+			TimeElapsed:   player.TimeElapsed,
+			PhaseElapsed:  player.PhaseElapsed,
+			IsTurn:        player.IsTurn,
+			CurrentPhase:  player.CurrentPhase,
+			TurnCount:     player.TurnCount,
+			VictoryPoints: player.VictoryPoints,
+			Flagged:       player.Flagged,
+			ByoYomiPeriod: player.ByoYomiPeriod,
+			PeriodLeft:    player.PeriodLeft,
+			TimeBudget:    options.PlayerTimeBudget(model.Options, i),
+		}
+	}
+	return s
+}
+
+// toMessage converts a received state to a LinkStateMsg. The time the state spent in transit, measured on the
+// host's clock, is added to the running clocks.
+func (s *state) toMessage(hostNow time.Time) *common.LinkStateMsg {
+	var transit time.Duration
+	if s.Running {
+		transit = max(hostNow.Sub(time.Unix(0, s.SentAt)), 0)
+	}
+
+	msg := &common.LinkStateMsg{
+		Status:        s.Status,
+		GameStarted:   s.GameStarted,
+		TotalGameTime: s.TotalGameTime + transit,
+		PausedTime:    s.PausedTime,
+		Players:       make([]common.LinkedPlayer, len(s.Players)),
+		Table:         s.Table,
+		Round:         s.Round,
+		TimeBudget:    s.TimeBudget,
+		Shared:        s.Shared,
+	}
+	for i, player := range s.Players {
+		linked := common.LinkedPlayer{
+			Name:          player.Name,
+			TimeElapsed:   player.TimeElapsed,
+			PhaseElapsed:  player.PhaseElapsed,
+			IsTurn:        player.IsTurn,
+			CurrentPhase:  player.CurrentPhase,
+			TurnCount:     player.TurnCount,
+			VictoryPoints: player.VictoryPoints,
+			Flagged:       player.Flagged,
+			ByoYomiPeriod: player.ByoYomiPeriod,
+			PeriodLeft:    player.PeriodLeft,
+			TimeBudget:    player.TimeBudget,
+		}
+		if player.IsTurn {
+			linked.TimeElapsed += transit
+			linked.PhaseElapsed += transit
+			if linked.ByoYomiPeriod > 0 {
+				linked.PeriodLeft -= transit
+			}
+		}
+		msg.Players[i] = linked
+	}
+	return msg
+}
+
+// withDefaultPort adds DefaultPort to addresses without a port, e.g. "192.168.1.20"
+func withDefaultPort(addr string) string {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return net.JoinHostPort(addr, DefaultPort)
+	}
+	return addr
+}
@@ -0,0 +1,250 @@
+package link
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// testCredentials are the credentials of the hosts and clients of the tests
+var testCredentials = Credentials{Secret: "club night"}
+
+func TestNewSampleComputesOffsetAndDelay(t *testing.T) {
+	// The host clock is 5s ahead, the network takes 100ms each way and the host answers after 10ms
+	t0 := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	t1 := t0.Add(5*time.Second + 100*time.Millisecond)
+	t2 := t1.Add(10 * time.Millisecond)
+	t3 := t0.Add(210 * time.Millisecond)
+
+	s := newSample(t0, t1, t2, t3)
+	if s.offset != 5*time.Second {
+		t.Errorf("Expected an offset of 5s, got %v", s.offset)
+	}
+	if s.delay != 200*time.Millisecond {
+		t.Errorf("Expected a delay of 200ms, got %v", s.delay)
+	}
+}
+
+func TestClockFilterTrustsTheShortestRoundTrip(t *testing.T) {
+	var filter clockFilter
+	if filter.offset() != 0 {
+		t.Errorf("Expected no offset before the first handshake, got %v", filter.offset())
+	}
+
+	filter.add(sample{offset: 900 * time.Millisecond, delay: 800 * time.Millisecond})
+	filter.add(sample{offset: 1000 * time.Millisecond, delay: 20 * time.Millisecond})
+	filter.add(sample{offset: 1400 * time.Millisecond, delay: 1500 * time.Millisecond})
+	if filter.offset() != time.Second {
+		t.Errorf("Expected the offset of the fastest handshake, got %v", filter.offset())
+	}
+
+	// Old handshakes are forgotten
+	for i := 0; i < maxSamples; i++ {
+		filter.add(sample{offset: 2 * time.Second, delay: 100 * time.Millisecond})
+	}
+	if filter.offset() != 2*time.Second {
+		t.Errorf("Expected the old handshakes to be forgotten, got %v", filter.offset())
+	}
+}
+
+func TestStateAddsTransitTimeToRunningClock(t *testing.T) {
+	sent := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	model := &common.Model{
+		GameStatus:    "Game In Progress",
+		GameStarted:   true,
+		TotalGameTime: time.Minute,
+		Players: []*common.Player{
+			{Name: "Alice", TimeElapsed: 40 * time.Second, IsTurn: true},
+			{Name: "Bob", TimeElapsed: 20 * time.Second},
+		},
+	}
+
+	msg := stateFromModel(model, true, 1, sent).toMessage(sent.Add(300 * time.Millisecond))
+	if msg.Players[0].TimeElapsed != 40*time.Second+300*time.Millisecond {
+		t.Errorf("Expected the transit time on the active player's clock, got %v", msg.Players[0].TimeElapsed)
+	}
+	if msg.Players[1].TimeElapsed != 20*time.Second {
+		t.Errorf("Expected the other player's clock to be unchanged, got %v", msg.Players[1].TimeElapsed)
+	}
+
+	paused := stateFromModel(model, false, 1, sent).toMessage(sent.Add(300 * time.Millisecond))
+	if paused.Players[0].TimeElapsed != 40*time.Second {
+		t.Errorf("Expected no transit time while the clocks are stopped, got %v", paused.Players[0].TimeElapsed)
+	}
+}
+
+func TestClientReceivesHostState(t *testing.T) {
+	host, err := Listen("127.0.0.1:0", testCredentials)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go host.Run(make(chan common.Message), done)
+
+	msgChan := make(chan common.Message, 10)
+	go Join(host.Addr().String(), testCredentials).Run(msgChan, done)
+
+	model := &common.Model{Players: []*common.Player{{Name: "Alice", IsTurn: true}, {Name: "Bob"}}}
+	deadline := time.After(5 * time.Second)
+	for {
+		host.Broadcast(model, false, 0)
+		select {
+		case msg := <-msgChan:
+			state, ok := msg.(*common.LinkStateMsg)
+			if !ok || len(state.Players) != 2 || state.Players[0].Name != "Alice" {
+				t.Fatalf("Expected the host's state, got %+v", msg)
+			}
+			return
+		case <-deadline:
+			t.Fatal("Expected a state from the host, got none")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func TestHostRefusesClientsWithoutTheSecret(t *testing.T) {
+	if _, err := Listen("127.0.0.1:0", Credentials{}); err != ErrNoSecret {
+		t.Errorf("Expected hosting without a link secret to fail, got %v", err)
+	}
+
+	host, err := Listen("127.0.0.1:0", testCredentials)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	host.Share(func(*common.KeyPressMsg) bool { return true })
+	hostChan := make(chan common.Message, 10)
+	done := make(chan struct{})
+	defer close(done)
+	go host.Run(hostChan, done)
+
+	client := Join(host.Addr().String(), Credentials{Secret: "guess"})
+	clientChan := make(chan common.Message, 10)
+	go client.Run(clientChan, done)
+
+	model := &common.Model{Players: []*common.Player{{Name: "Alice", IsTurn: true}, {Name: "Bob"}}}
+	for range 10 {
+		host.Broadcast(model, false, 0)
+		client.SendKey(tcell.KeyRune, ' ')
+		time.Sleep(50 * time.Millisecond)
+	}
+	select {
+	case msg := <-clientChan:
+		t.Errorf("Expected no state for a client with the wrong secret, got %+v", msg)
+	case msg := <-hostChan:
+		t.Errorf("Expected no keys from a client with the wrong secret, got %+v", msg)
+	default:
+	}
+}
+
+func TestSharedHostPlaysClientKeys(t *testing.T) {
+	host, err := Listen("127.0.0.1:0", testCredentials)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	host.Share(func(key *common.KeyPressMsg) bool { return key.Rune == ' ' })
+	hostChan := make(chan common.Message, 10)
+	done := make(chan struct{})
+	defer close(done)
+	go host.Run(hostChan, done)
+
+	client := Join(host.Addr().String(), testCredentials)
+	clientChan := make(chan common.Message, 10)
+	go client.Run(clientChan, done)
+
+	model := &common.Model{Players: []*common.Player{{Name: "Alice", IsTurn: true}, {Name: "Bob"}}}
+	deadline := time.After(5 * time.Second)
+	for {
+		host.Broadcast(model, false, 0)
+		client.SendKey(tcell.KeyRune, 'q')
+		client.SendKey(tcell.KeyRune, ' ')
+		select {
+		case msg := <-hostChan:
+			key, ok := msg.(*common.KeyPressMsg)
+			if !ok || key.Rune != ' ' {
+				t.Fatalf("Expected only the playable key on the host, got %+v", msg)
+			}
+			host.Broadcast(model, false, 0)
+			select {
+			case msg := <-clientChan:
+				if state, ok := msg.(*common.LinkStateMsg); !ok || !state.Shared {
+					t.Errorf("Expected the client to be told the game is shared, got %+v", msg)
+				}
+			case <-time.After(5 * time.Second):
+				t.Error("Expected a state on the client, got none")
+			}
+			return
+		case <-deadline:
+			t.Fatal("Expected the client's key on the host, got none")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func TestDiscoverFindsAnnouncingHost(t *testing.T) {
+	host, err := Listen("127.0.0.1:0", testCredentials)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen for announcements: %v", err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+
+	found := make(chan string, 1)
+	go discover(conn, found, done)
+	go func() { _ = host.announceTo(conn.LocalAddr().(*net.UDPAddr), done) }()
+
+	select {
+	case addr := <-found:
+		if addr != host.Addr().String() {
+			t.Errorf("Expected the host's address %s, got %s", host.Addr(), addr)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the host to be discovered")
+	}
+}
+
+func TestHostReceivesRoundTimer(t *testing.T) {
+	organizer := Credentials{Secret: testCredentials.Secret, Organizer: "judge"}
+	host, err := Listen("127.0.0.1:0", organizer)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	msgChan := make(chan common.Message, 10)
+	go host.Run(msgChan, done)
+
+	// Only the organizer sets the round timer
+	player := Join(host.Addr().String(), testCredentials)
+	player.SetRound(time.Now().Add(time.Minute), "Dice down now")
+	go player.Run(make(chan common.Message, 10), done)
+	impostor := Join(host.Addr().String(), Credentials{Secret: testCredentials.Secret, Organizer: "guess"})
+	impostor.SetRound(time.Now().Add(time.Minute), "Dice down now")
+	go impostor.Run(make(chan common.Message, 10), done)
+
+	// The round timer set before connecting is sent once the client is linked
+	client := Join(host.Addr().String(), organizer)
+	client.SetRound(time.Now().Add(15*time.Minute), "Dice down in 15 minutes")
+	go client.Run(make(chan common.Message, 10), done)
+
+	select {
+	case msg := <-msgChan:
+		round, ok := msg.(*common.RoundTimerMsg)
+		if !ok || round.Announcement != "Dice down in 15 minutes" {
+			t.Fatalf("Expected the organizer's round timer, got %+v", msg)
+		}
+		if left := time.Until(round.Ends); left < 14*time.Minute || left > 15*time.Minute {
+			t.Errorf("Expected about 15 minutes left in the round, got %v", left)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a round timer from the organizer, got none")
+	}
+}
@@ -0,0 +1,50 @@
+package link
+
+import "time"
+
+// maxSamples is the number of recent handshakes the clock offset is estimated from
+const maxSamples = 8
+
+// sample is the result of a single ping/pong handshake
+type sample struct {
+	offset time.Duration // Host clock minus client clock
+	delay  time.Duration // Round trip time, without the time the host took to answer
+}
+
+// newSample computes the clock offset and round trip delay of a handshake from the client send time t0, the host
+// receive time t1, the host send time t2 and the client receive time t3
+func newSample(t0, t1, t2, t3 time.Time) sample {
+	return sample{
+		offset: (t1.Sub(t0) + t2.Sub(t3)) / 2,
+		delay:  t3.Sub(t0) - t2.Sub(t1),
+	}
+}
+
+// clockFilter estimates the offset between the client's and the host's clock from recent handshakes.
+// Delayed packets, e.g. on congested Wi-Fi, make the round trip asymmetric and the offset inaccurate, so the
+// sample with the shortest round trip is trusted most.
+type clockFilter struct {
+	samples []sample
+}
+
+// add records a handshake, forgetting the oldest one once maxSamples are recorded
+func (f *clockFilter) add(s sample) {
+	f.samples = append(f.samples, s)
+	if len(f.samples) > maxSamples {
+		f.samples = f.samples[1:]
+	}
+}
+
+// offset returns the estimated host clock minus client clock, or 0 before the first handshake
+func (f *clockFilter) offset() time.Duration {
+	if len(f.samples) == 0 {
+		return 0
+	}
+	best := f.samples[0]
+	for _, s := range f.samples[1:] {
+		if s.delay < best.delay {
+			best = s
+		}
+	}
+	return best.offset
+}
@@ -19,6 +19,21 @@ var logInitialized bool
 var logWg sync.WaitGroup
 var logMutex sync.Mutex
 
+// logDir is the directory logs.csv is written to, the current working directory by default
+var logDir = hammerclockConfig.DefaultLogFilePath
+
+// SetLogDir sets the directory logs.csv is written to
+func SetLogDir(dir string) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	logDir = dir
+}
+
+// LogFilePath returns the path of the CSV log file
+func LogFilePath() string {
+	return filepath.Join(logDir, hammerclockConfig.DefaultLogFileName)
+}
+
 // Initialise sets up the background log writer
 func Initialise() {
 	logMutex.Lock()
@@ -79,11 +94,7 @@ func sendLogEntry(entry common.LogEntry) {
 
 // writeLogEntry appends a LogEntry to logs.csv in CSV format.
 func writeLogEntry(entry common.LogEntry) {
-	// Use default log directory (current working directory)
-	logDir := hammerclockConfig.DefaultLogFilePath
-	fileName := hammerclockConfig.DefaultLogFileName
-
-	filePath := filepath.Join(logDir, fileName)
+	filePath := LogFilePath()
 
 	fileExists := false
 
@@ -110,7 +121,7 @@ func writeLogEntry(entry common.LogEntry) {
 
 	// Write header if it's a new file
 	if !fileExists {
-		if err := writer.Write([]string{"DateTime", "PlayerName", "Turn", "Phase", "Message"}); err != nil {
+		if err := writer.Write([]string{"DateTime", "PlayerName", "Turn", "Phase", "Message", "Event", "Table"}); err != nil {
 			fmt.Printf("Error writing CSV header: %v\n", err)
 			return
 		}
@@ -123,16 +134,18 @@ func writeLogEntry(entry common.LogEntry) {
 		fmt.Sprintf("%d", entry.Turn),
 		entry.Phase,
 		entry.Message,
+		entry.Event,
+		fmt.Sprintf("%d", entry.Table),
 	}); err != nil {
 		fmt.Printf("Error writing CSV entry: %v\n", err)
 	}
 }
 
-// AddLogEntry adds a log entry to a player's action log
-func AddLogEntry(player *common.Player, model *common.Model, format string, args ...any) {
+// AddLogEntry adds a log entry of the given type to a player's action log
+func AddLogEntry(player *common.Player, model *common.Model, entryType common.LogEntryType, format string, args ...any) {
 	currentPhase := ""
-	if player.CurrentPhase < len(model.Options.Rules[model.Options.Default].Phases) && player.CurrentPhase >= 0 {
-		currentPhase = model.Options.Rules[model.Options.Default].Phases[player.CurrentPhase]
+	if player.CurrentPhase < len(model.Phases) && player.CurrentPhase >= 0 {
+		currentPhase = model.Phases[player.CurrentPhase]
 	}
 
 	logEntry := common.LogEntry{
@@ -141,10 +154,16 @@ func AddLogEntry(player *common.Player, model *common.Model, format string, args
 		Turn:       player.TurnCount,
 		Phase:      currentPhase,
 		Message:    fmt.Sprintf(format, args...),
+		Type:       entryType,
+		Event:      model.Options.EventName,
+		Table:      model.Options.TableNumber,
 	}
 
 	// Add to in-memory player action log for UI
 	player.ActionLog = append(player.ActionLog, logEntry)
+	if entryType == common.LogTypeWarning {
+		model.Warnings++
+	}
 
 	// Send log entry to the logging channel
 	sendLogEntry(logEntry)
@@ -26,7 +26,9 @@ var testModel = &common.Model{
 				OneTurnForAllPlayers: true,
 			},
 		},
-		Default: 0,
+		Default:     0,
+		EventName:   "Spring Cup",
+		TableNumber: 4,
 	},
 	TotalGameTime: 0,
 }
@@ -72,11 +74,14 @@ func TestAddLogEntryAppendsToPlayerActionLog(t *testing.T) {
 	player := &common.Player{Name: "Player 1"}
 	model := testModel
 
-	AddLogEntry(player, model, "Test message")
+	AddLogEntry(player, model, common.LogTypeNote, "Test message")
 	if len(player.ActionLog) != 1 {
 		t.Errorf("Expected player.ActionLog to have 1 entry, got %d", len(player.ActionLog))
 	}
 	if player.ActionLog[0].Message != "Test message" {
 		t.Errorf("Expected log message to be 'Test message', got '%s'", player.ActionLog[0].Message)
 	}
+	if player.ActionLog[0].Event != model.Options.EventName || player.ActionLog[0].Table != model.Options.TableNumber {
+		t.Errorf("Expected the log entry to name the event and table, got %q and %d", player.ActionLog[0].Event, player.ActionLog[0].Table)
+	}
 }
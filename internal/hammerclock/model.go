@@ -2,18 +2,72 @@ package hammerclock
 
 import (
 	"fmt"
+	"strings"
 
 	"hammerclock/internal/hammerclock/common"
+	hammerclockConfig "hammerclock/internal/hammerclock/config"
 	"hammerclock/internal/hammerclock/options"
 	"hammerclock/internal/hammerclock/palette"
+	"hammerclock/internal/hammerclock/platform"
+	"hammerclock/internal/hammerclock/rules"
 )
 
 const (
 	gameNotStarted common.GameStatus = "Game Not Started"
 	gameInProgress common.GameStatus = "Game In Progress"
 	gamePaused     common.GameStatus = "Game Paused"
+	gameOver       common.GameStatus = "Game Over"
 )
 
+// statusKeys are the keys of the game statuses in the statusLabels option
+var statusKeys = map[common.GameStatus]string{
+	gameNotStarted: "notStarted",
+	gameInProgress: "inProgress",
+	gamePaused:     "paused",
+	gameOver:       "over",
+}
+
+// statusTranslations are the built-in texts of the game statuses in other languages, by language and status key
+var statusTranslations = map[string]map[string]string{
+	"de": {"notStarted": "Spiel nicht begonnen", "inProgress": "Spiel läuft", "paused": "Spiel pausiert", "over": "Spiel vorbei"},
+}
+
+// OptionsColorPalette returns the named color palette, with the terminal's default background in place of its black
+// if the options ask for a transparent background. The legacy Windows console only has the basic colors, the other
+// palettes would look washed out, so it always gets the basic palette.
+func OptionsColorPalette(opts options.Options, name string) palette.ColorPalette {
+	if platform.LegacyConsole() {
+		name = palette.BasicPaletteName
+	}
+	colors := palette.ColorPaletteByName(name)
+	if opts.TransparentBackground {
+		return colors.WithDefaultBackground()
+	}
+	return colors
+}
+
+// StatusLabel returns the text shown for a game status: its label in the options, e.g. "Dice down" for paused, the
+// built-in translation for the locale option, or the status as it is
+func StatusLabel(opts options.Options, status common.GameStatus) string {
+	key := statusKeys[status]
+	if label := opts.StatusLabels[key]; label != "" {
+		return label
+	}
+	language, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(opts.Locale, "_", "-")), "-")
+	if label := statusTranslations[language][key]; label != "" {
+		return label
+	}
+	return string(status)
+}
+
+// RulesetPhases returns the phases of the selected ruleset in the language of the locale option
+func RulesetPhases(opts options.Options) []string {
+	if opts.Default < 0 || opts.Default >= len(opts.Rules) {
+		return nil
+	}
+	return rules.LocalizedPhases(opts.Rules[opts.Default], opts.Locale)
+}
+
 // NewModel creates a new model with default values
 func NewModel() common.Model {
 	// Initialize with default options
@@ -23,13 +77,16 @@ func NewModel() common.Model {
 	players := make([]*common.Player, opts.PlayerCount)
 	model := common.Model{
 		Players:             players,
-		Phases:              opts.Rules[opts.Default].Phases,
+		Phases:              RulesetPhases(opts),
 		GameStatus:          gameNotStarted,
 		CurrentScreen:       "main",
 		GameStarted:         false,
 		Options:             opts,
 		CurrentColorPalette: palette.K9sPalette,
 		TotalGameTime:       0,
+		OptionsFile:         hammerclockConfig.DefaultOptionsFilename,
+		SavedOptions:        options.Copy(opts),
+		FileOptions:         options.Copy(opts),
 	}
 
 	for i := 0; i < opts.PlayerCount; i++ {
@@ -43,6 +100,10 @@ func NewModel() common.Model {
 			IsTurn:       i == 0,
 			CurrentPhase: 0,
 			ActionLog:    []common.LogEntry{}, // Initialize empty action log
+			ArmyList:     ProfileArmy(opts, playerName),
+		}
+		if i < len(opts.PlayerFactions) {
+			players[i].Faction = opts.PlayerFactions[i]
 		}
 	}
 
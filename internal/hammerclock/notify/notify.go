@@ -0,0 +1,183 @@
+// Package notify posts the significant events of a game, its start, the turn switches, players running low on time and
+// its end, to a Discord webhook, so a club's channel can follow the games on its tables. The events are found by
+// comparing the model before and after each update, and are posted in order by a goroutine of their own, so a slow
+// webhook never holds up the clocks.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+)
+
+// Username is the name the messages are posted under
+const Username = "Hammerclock"
+
+// queueSize is the number of messages waiting to be posted, further messages are dropped while the webhook is slow
+const queueSize = 32
+
+// postTimeout is the time a post may take, including the webhook's answer
+const postTimeout = 10 * time.Second
+
+// post is a message waiting to be posted to a webhook
+type post struct {
+	url     string
+	content string
+}
+
+// Notifier posts the events of the games to the Discord webhook of the options
+type Notifier struct {
+	client *http.Client
+	posts  chan post
+	mu     sync.Mutex
+	err    error // First failed post
+}
+
+// New returns a notifier; its posts are sent once Run is started
+func New() *Notifier {
+	return &Notifier{client: &http.Client{Timeout: postTimeout}, posts: make(chan post, queueSize)}
+}
+
+// Run posts the queued messages until done is closed
+func (n *Notifier) Run(done <-chan struct{}) {
+	for {
+		select {
+		case p := <-n.posts:
+			if err := n.send(p); err != nil {
+				n.mu.Lock()
+				if n.err == nil {
+					n.err = err
+				}
+				n.mu.Unlock()
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// Err returns the error of the first post that failed, if any
+func (n *Notifier) Err() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.err
+}
+
+// Notify queues the events between the model before and after an update, if the notifications are enabled. Terminals
+// linked to a host leave the notifications to the host, so the events are not posted twice.
+func (n *Notifier) Notify(before, after *common.Model) {
+	discord := after.Options.Discord
+	if !discord.Enabled || discord.WebhookURL == "" || after.Linked {
+		return
+	}
+	for _, content := range Messages(before, after) {
+		select {
+		case n.posts <- post{url: discord.WebhookURL, content: content}:
+		default:
+		}
+	}
+}
+
+// send posts a message to the webhook
+func (n *Notifier) send(p post) error {
+	body, err := json.Marshal(map[string]string{"username": Username, "content": p.content})
+	if err != nil {
+		return err
+	}
+	response, err := n.client.Post(p.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", response.Status)
+	}
+	return nil
+}
+
+// Messages returns the messages for the events between the model before and after an update: the game starting, the
+// turn switching to another player, a player's remaining time falling to the low time of the options, and the game
+// ending
+func Messages(before, after *common.Model) []string {
+	var messages []string
+	switch {
+	case !before.GameStarted && after.GameStarted:
+		names := make([]string, len(after.Players))
+		for i, player := range after.Players {
+			names[i] = playerName(player)
+		}
+		messages = append(messages, withIdentity(after, "Game started: "+strings.Join(names, " vs ")))
+	case before.GameStarted && !after.GameStarted:
+		return append(messages, withIdentity(before, fmt.Sprintf("Game over after %v. %s",
+			before.TotalGameTime.Truncate(time.Second), scores(before))))
+	case !after.GameStarted:
+		return nil
+	}
+
+	if active := activePlayer(after); active >= 0 && (!before.GameStarted || active != activePlayer(before)) {
+		player := after.Players[active]
+		messages = append(messages, fmt.Sprintf("Turn %d: %s is up. %s", player.TurnCount, player.Name, clocks(after)))
+	}
+
+	lowTime := time.Duration(after.Options.Discord.LowTime) * time.Minute
+	for i, player := range after.Players {
+		budget := options.PlayerTimeBudget(after.Options, i)
+		if lowTime <= 0 || budget <= lowTime || i >= len(before.Players) {
+			continue
+		}
+		remaining := budget - player.TimeElapsed
+		if budget-before.Players[i].TimeElapsed > lowTime && remaining <= lowTime {
+			messages = append(messages, fmt.Sprintf("%s is low on time: %v left", player.Name,
+				max(remaining, 0).Truncate(time.Second)))
+		}
+	}
+	return messages
+}
+
+// activePlayer returns the index of the player whose turn it is, or -1
+func activePlayer(model *common.Model) int {
+	return slices.IndexFunc(model.Players, func(player *common.Player) bool { return player.IsTurn })
+}
+
+// playerName returns the name of a player with their faction, e.g. "Alice (Ultramarines)"
+func playerName(player *common.Player) string {
+	if player.Faction == "" {
+		return player.Name
+	}
+	return fmt.Sprintf("%s (%s)", player.Name, player.Faction)
+}
+
+// clocks returns the time on the players' clocks, e.g. "Clocks: Alice 12m4s, Bob 10m3s"
+func clocks(model *common.Model) string {
+	times := make([]string, len(model.Players))
+	for i, player := range model.Players {
+		times[i] = fmt.Sprintf("%s %v", player.Name, player.TimeElapsed.Truncate(time.Second))
+	}
+	return "Clocks: " + strings.Join(times, ", ")
+}
+
+// scores returns the players' victory points and clocks at the end of a game, e.g. "Alice 35 VP (52m1s), Bob 28 VP
+// (49m40s)"
+func scores(model *common.Model) string {
+	results := make([]string, len(model.Players))
+	for i, player := range model.Players {
+		results[i] = fmt.Sprintf("%s %d VP (%v)", player.Name, player.VictoryPoints, player.TimeElapsed.Truncate(time.Second))
+	}
+	return strings.Join(results, ", ")
+}
+
+// withIdentity prefixes a message with the event and table of the game, if any, e.g. "Spring Cup, Table 4: "
+func withIdentity(model *common.Model, message string) string {
+	if identity := options.Identity(model.Options); identity != "" {
+		return identity + ": " + message
+	}
+	return message
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+)
+
+func TestMessagesFollowTheGame(t *testing.T) {
+	opts := options.Copy(options.DefaultOptions)
+	opts.TimeBudget = 60
+	opts.EventName = "Spring Cup"
+	before := &common.Model{Options: opts, Players: []*common.Player{{Name: "Alice", Faction: "Orks"}, {Name: "Bob"}}}
+	after := &common.Model{Options: opts, GameStarted: true, Players: []*common.Player{
+		{Name: "Alice", Faction: "Orks", IsTurn: true, TurnCount: 1}, {Name: "Bob"},
+	}}
+	expected := []string{"Spring Cup: Game started: Alice (Orks) vs Bob", "Turn 1: Alice is up. Clocks: Alice 0s, Bob 0s"}
+	if messages := Messages(before, after); strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %q, got %q", expected, messages)
+	}
+
+	before, after = after, &common.Model{Options: opts, GameStarted: true, Players: []*common.Player{
+		{Name: "Alice", TurnCount: 1, TimeElapsed: 55*time.Minute + 30*time.Second},
+		{Name: "Bob", IsTurn: true, TurnCount: 1},
+	}}
+	expected = []string{"Turn 1: Bob is up. Clocks: Alice 55m30s, Bob 0s", "Alice is low on time: 4m30s left"}
+	if messages := Messages(before, after); strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %q, got %q", expected, messages)
+	}
+	if messages := Messages(after, after); len(messages) != 0 {
+		t.Errorf("Expected no messages without changes, got %q", messages)
+	}
+
+	after.TotalGameTime = time.Hour
+	after.Players[0].VictoryPoints = 35
+	ended := &common.Model{Options: opts}
+	expected = []string{"Spring Cup: Game over after 1h0m0s. Alice 35 VP (55m30s), Bob 0 VP (0s)"}
+	if messages := Messages(after, ended); strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %q, got %q", expected, messages)
+	}
+}
+
+func TestNotifierPostsToWebhook(t *testing.T) {
+	posted := make(chan map[string]string, 4)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		posted <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhook.Close()
+
+	notifier := New()
+	done := make(chan struct{})
+	defer close(done)
+	go notifier.Run(done)
+
+	before := &common.Model{Players: []*common.Player{{Name: "Alice"}}}
+	after := &common.Model{GameStarted: true, Players: []*common.Player{{Name: "Alice"}}}
+	notifier.Notify(before, after)
+	after.Options.Discord = options.DiscordOptions{Enabled: true, WebhookURL: webhook.URL}
+	notifier.Notify(before, after)
+
+	select {
+	case body := <-posted:
+		if body["content"] != "Game started: Alice" || body["username"] != Username {
+			t.Errorf("Expected the start of the game, got %q", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a post to the webhook")
+	}
+	select {
+	case body := <-posted:
+		t.Errorf("Expected a single post while the notifications were enabled, got %q", body)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if err := notifier.Err(); err != nil {
+		t.Errorf("Expected the post to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package options
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// MaxBackups is the number of options file backups that are kept
+const MaxBackups = 5
+
+// backupTimeFormat is the timestamp format used in backup file names, sortable by time
+const backupTimeFormat = "20060102-150405.000000000"
+
+// backupOptionsFile copies the options file to a timestamped backup next to it, e.g.
+// default.json.20250102-150405.000000000.bak, and removes the oldest backups beyond MaxBackups.
+// Nothing is done if the file does not exist yet.
+func backupOptionsFile(filename string) error {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// Move the timestamp forward if a backup was already made at the same instant
+	backupTime := time.Now()
+	backupName := filename + "." + backupTime.Format(backupTimeFormat) + ".bak"
+	for _, err := os.Stat(backupName); err == nil; _, err = os.Stat(backupName) {
+		backupTime = backupTime.Add(time.Nanosecond)
+		backupName = filename + "." + backupTime.Format(backupTimeFormat) + ".bak"
+	}
+	if err := os.WriteFile(backupName, data, 0644); err != nil {
+		return err
+	}
+
+	backups, err := listBackups(filename)
+	if err != nil {
+		return err
+	}
+	for len(backups) > MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// listBackups returns the backups of the options file, oldest first
+func listBackups(filename string) ([]string, error) {
+	backups, err := filepath.Glob(filepath.Join(filepath.Dir(filename), filepath.Base(filename)) + ".*.bak")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// RestoreBackup replaces the options file with its most recent backup and returns the restored options.
+// The restored backup is removed, so restoring again goes back one more step.
+func RestoreBackup(filename string) (Options, error) {
+	backups, err := listBackups(filename)
+	if err != nil {
+		return Options{}, err
+	}
+	if len(backups) == 0 {
+		return Options{}, fmt.Errorf("no backups of '%s' found", filename)
+	}
+	latest := backups[len(backups)-1]
+
+	data, err := os.ReadFile(latest)
+	if err != nil {
+		return Options{}, err
+	}
+	opts := Copy(DefaultOptions)
+	if err := json.Unmarshal(data, &opts); err != nil {
+		return Options{}, fmt.Errorf("backup '%s' is invalid: %w", latest, err)
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return Options{}, err
+	}
+	return opts, os.Remove(latest)
+}
@@ -0,0 +1,41 @@
+package options
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveOptionsKeepsBackupsAndRestores(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "options.json")
+
+	for count := 1; count <= MaxBackups+3; count++ {
+		opts := Copy(DefaultOptions)
+		opts.PlayerCount = count
+		if err := SaveOptions(opts, filename, true); err != nil {
+			t.Fatalf("Failed to save options: %v", err)
+		}
+	}
+
+	backups, err := listBackups(filename)
+	if err != nil || len(backups) != MaxBackups {
+		t.Fatalf("Expected %d backups, got %d (%v)", MaxBackups, len(backups), err)
+	}
+
+	restored, err := RestoreBackup(filename)
+	if err != nil {
+		t.Fatalf("Failed to restore backup: %v", err)
+	}
+	if restored.PlayerCount != MaxBackups+2 {
+		t.Errorf("Expected the previous options to be restored, got player count %d", restored.PlayerCount)
+	}
+	if loaded := LoadOptions(filename, filename); loaded.PlayerCount != restored.PlayerCount {
+		t.Errorf("Expected the options file to contain the restored options, got player count %d", loaded.PlayerCount)
+	}
+	if backups, _ := listBackups(filename); len(backups) != MaxBackups-1 {
+		t.Errorf("Expected the restored backup to be removed, got %d backups", len(backups))
+	}
+
+	if _, err := RestoreBackup(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("Expected an error when no backups exist")
+	}
+}
@@ -3,8 +3,14 @@ package options
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"hammerclock/internal/hammerclock/config"
 	"hammerclock/internal/hammerclock/rules"
@@ -18,7 +24,208 @@ type Options struct {
 	PlayerNames    []string      `json:"playerNames"`
 	ColorPalette   string        `json:"colorPalette"`
 	TimeFormat     string        `json:"timeFormat"`     // AMPM or 24h
+	Locale         string        `json:"locale"`         // Language of the phase and status names, e.g. de
 	LoggingEnabled bool          `json:"loggingEnabled"` // Enable/disable CSV logging
+	BattleReport   bool          `json:"battleReport"`   // Write a Markdown battle report when a game ends
+	ResumeGame     bool          `json:"resumeGame"`     // Offer to continue the unfinished game of the last run at startup
+
+	StatusLabels map[string]string `json:"statusLabels,omitempty"` // Texts of the game statuses notStarted, inProgress, paused and over
+
+	PromptSecondaryObjectives bool `json:"promptSecondaryObjectives"` // Ask for secondary objective scores at the end of each turn
+	VimBindings               bool `json:"vimBindings"`               // Enable hjkl, gg/G and : key bindings
+	PauseOnModal              bool `json:"pauseOnModal"`              // Pause the clocks while a dialog is open
+	PauseOnFocusLoss          bool `json:"pauseOnFocusLoss"`          // Pause the game when the terminal loses the focus
+	RevertWindow              int  `json:"revertWindow"`              // Seconds during which a turn switch can be reverted
+	TickInterval              int  `json:"tickInterval"`              // Milliseconds between clock updates, 250 to 2000
+	GracePeriod               int  `json:"gracePeriod"`               // Seconds after a turn switch before the new player's clock starts
+
+	TimeBudget     int    `json:"timeBudget"`     // Minutes on each player's clock, 0 for clocks without a limit
+	TimeOdds       string `json:"timeOdds"`       // Time odds the first player gives the others: a ratio like 2:1, a handicap like -10m, or none
+	FlagFall       string `json:"flagFall"`       // What happens when a player runs out of time: continue, pause, over or end
+	FlagSound      bool   `json:"flagSound"`      // Ring the terminal bell when a player runs out of time
+	ByoYomiPeriods int    `json:"byoYomiPeriods"` // Byo-yomi periods a player gets once the time budget is used up, 0 for none
+	ByoYomiSeconds int    `json:"byoYomiSeconds"` // Seconds of each byo-yomi period
+	GameSize       int    `json:"gameSize"`       // Points of each army, e.g. 2000, checked against the army lists; 0 for none
+
+	LogTimestamps         string `json:"logTimestamps"`         // Timestamps shown in the action log panels: full, time or none
+	Layout                string `json:"layout"`                // Player panels side by side (horizontal), stacked (vertical) or auto
+	TurnCue               string `json:"turnCue"`               // Cue on the new player's panel after a turn switch: flash, invert or none
+	BorderStyle           string `json:"borderStyle"`           // Borders of panels and dialogs: single, double, rounded, ascii or none
+	TransparentBackground bool   `json:"transparentBackground"` // Leave the terminal's background instead of painting the palette's black
+	ClockShowDate         bool   `json:"clockShowDate"`         // Show the date next to the clock in the top bar
+	ClockShowGameTime     bool   `json:"clockShowGameTime"`     // Show the total elapsed game time next to the clock
+	Banner                string `json:"banner"`                // Custom text shown in the top bar, e.g. event name or table number
+
+	EventName   string `json:"eventName"`   // Event the table plays in, shown in the top bar and written to logs and exports
+	TableNumber int    `json:"tableNumber"` // Table number within the event, 0 for none
+
+	Macro          []string `json:"macro,omitempty"`          // Keys replayed by the macro key, e.g. ["p", "p", "SPACE"]
+	PlayerBanners  []string `json:"playerBanners,omitempty"`  // Text files with ASCII art shown at the top of each player's panel
+	PlayerFactions []string `json:"playerFactions,omitempty"` // Factions shown next to the player names, e.g. from an event roster
+	PanelWidgets   []string `json:"panelWidgets,omitempty"`   // Widgets of the player panels in order, e.g. ["clock", "log"]; all if empty
+
+	Templates []GameTemplate  `json:"templates,omitempty"` // Saved game setups to start new games from
+	Profiles  []PlayerProfile `json:"profiles,omitempty"`  // Saved players with their army lists, matched to the players by name
+
+	JudgePassphrase string `json:"judgePassphrase,omitempty"` // Passphrase that unlocks judge mode, empty to disable it
+	ActionPIN       string `json:"actionPin,omitempty"`       // PIN for ending the game, adding suspended time and scoring, empty to not ask
+	LinkSecret      string `json:"linkSecret,omitempty"`      // Secret shared by linked clocks, required to host or join them
+
+	ExternalInput ExternalInputOptions `json:"externalInput"` // Footswitch or button connected as a serial or HID device
+	GPIO          GPIOOptions          `json:"gpio"`          // Buttons and LEDs wired to GPIO pins (builds with -tags gpio)
+	GlobalHotkeys GlobalHotkeyOptions  `json:"globalHotkeys"` // Hotkeys that work while the terminal is not focused
+	Discord       DiscordOptions       `json:"discord"`       // Discord webhook the events of the games are posted to
+}
+
+// GameTemplate is a named game setup, e.g. "Tuesday 2000pt 40K", that new games can be started from
+type GameTemplate struct {
+	Name         string   `json:"name"`
+	Ruleset      string   `json:"ruleset"` // Name of the ruleset
+	PlayerCount  int      `json:"playerCount"`
+	PlayerNames  []string `json:"playerNames"`
+	ColorPalette string   `json:"colorPalette"`
+
+	// Clock setup, see the options of the same names. Templates saved without a flag-fall action keep the clock setup.
+	TimeBudget     int    `json:"timeBudget"`
+	TimeOdds       string `json:"timeOdds"`
+	FlagFall       string `json:"flagFall,omitempty"`
+	ByoYomiPeriods int    `json:"byoYomiPeriods"`
+	ByoYomiSeconds int    `json:"byoYomiSeconds"`
+	GracePeriod    int    `json:"gracePeriod"`
+	GameSize       int    `json:"gameSize"`
+}
+
+// PlayerProfile is a saved player, e.g. a regular opponent, with their army list
+type PlayerProfile struct {
+	Name   string      `json:"name"`
+	Army   []ArmyUnit  `json:"army,omitempty"`   // Latest army list of the player
+	Events []EventArmy `json:"events,omitempty"` // Army lists the player brought to events
+}
+
+// EventArmy is the army list a player brought to an event
+type EventArmy struct {
+	Event   string     `json:"event"`
+	Version int        `json:"version"` // Counts the changes to the list for the event, starting at 1
+	Army    []ArmyUnit `json:"army"`
+}
+
+// ArmyUnit is a unit of an army list saved with a player profile
+type ArmyUnit struct {
+	Name   string `json:"name"`
+	Points int    `json:"points"`
+	Wounds int    `json:"wounds,omitempty"` // Wounds of the unit at full strength, 0 if they are not tracked
+}
+
+// ProfileIndex returns the index of the profile of the named player in the options, or -1 if there is none
+func ProfileIndex(opts Options, name string) int {
+	return slices.IndexFunc(opts.Profiles, func(profile PlayerProfile) bool { return profile.Name == name })
+}
+
+// ProfileArmy returns the army list of the named player for the event of the options, or the player's latest list
+// outside of events and at events the player has no list for yet
+func ProfileArmy(opts Options, name string) []ArmyUnit {
+	i := ProfileIndex(opts, name)
+	if i < 0 {
+		return nil
+	}
+	profile := opts.Profiles[i]
+	if j := eventArmyIndex(profile, opts.EventName); j >= 0 {
+		return profile.Events[j].Army
+	}
+	return profile.Army
+}
+
+// SetProfileArmy saves the army list of the named player with their profile, creating the profile if needed. At an
+// event the list is also saved as the player's list for the event, whose version goes up each time it changes. It
+// returns the version of the list for the event, or 0 outside of events.
+func SetProfileArmy(opts *Options, name string, army []ArmyUnit) int {
+	opts.Profiles = slices.Clone(opts.Profiles)
+	i := ProfileIndex(*opts, name)
+	if i < 0 {
+		opts.Profiles = append(opts.Profiles, PlayerProfile{Name: name})
+		i = len(opts.Profiles) - 1
+	}
+	profile := &opts.Profiles[i]
+	profile.Army = army
+	if opts.EventName == "" {
+		return 0
+	}
+
+	profile.Events = slices.Clone(profile.Events)
+	j := eventArmyIndex(*profile, opts.EventName)
+	if j < 0 {
+		profile.Events = append(profile.Events, EventArmy{Event: opts.EventName, Version: 1, Army: army})
+		return 1
+	}
+	if !slices.Equal(profile.Events[j].Army, army) {
+		profile.Events[j].Version++
+		profile.Events[j].Army = army
+	}
+	return profile.Events[j].Version
+}
+
+// eventArmyIndex returns the index of the army list of a profile for the event, or -1 if there is none
+func eventArmyIndex(profile PlayerProfile, event string) int {
+	if event == "" {
+		return -1
+	}
+	return slices.IndexFunc(profile.Events, func(list EventArmy) bool { return list.Event == event })
+}
+
+// GlobalHotkeyOptions configures the system-wide hotkeys. Keys are named F1-F12, Pause or ScrollLock.
+type GlobalHotkeyOptions struct {
+	Enabled    bool   `json:"enabled"`
+	SwitchTurn string `json:"switchTurn"` // Hotkey that switches turns
+	Pause      string `json:"pause"`      // Hotkey that starts, pauses or resumes the game
+	Device     string `json:"device"`     // Keyboard input device on Linux, e.g. /dev/input/event3
+}
+
+// DiscordOptions configures the notifications posted to a Discord webhook when a game starts, the turn switches, a
+// player runs low on time and the game ends.
+type DiscordOptions struct {
+	Enabled    bool   `json:"enabled"`
+	WebhookURL string `json:"webhookUrl"` // URL of the channel's webhook, e.g. https://discord.com/api/webhooks/...
+	LowTime    int    `json:"lowTime"`    // Minutes left on a player's clock that are posted as low time, 0 to not post it
+}
+
+// GPIOOptions configures the GPIO pins used for buttons and player LEDs. Pin numbers are sysfs GPIO numbers,
+// 0 disables a button.
+type GPIOOptions struct {
+	Enabled       bool  `json:"enabled"`
+	SwitchTurnPin int   `json:"switchTurnPin"` // Button that switches turns
+	PausePin      int   `json:"pausePin"`      // Button that starts, pauses or resumes the game
+	ActiveLow     bool  `json:"activeLow"`     // Buttons pull the pin low when pressed
+	PlayerLEDPins []int `json:"playerLedPins"` // LED lit while the corresponding player is active
+}
+
+// ValidateGPIO checks that no GPIO pin is used for more than one button or LED, as a pin wired to one button would
+// otherwise trigger two actions
+func ValidateGPIO(opts GPIOOptions) error {
+	pins := append([]int{opts.SwitchTurnPin, opts.PausePin}, opts.PlayerLEDPins...)
+	names := []string{"switchTurnPin", "pausePin"}
+	for i := range opts.PlayerLEDPins {
+		names = append(names, fmt.Sprintf("playerLedPins[%d]", i))
+	}
+	used := map[int]string{}
+	for i, pin := range pins {
+		if pin <= 0 {
+			continue
+		}
+		if other, found := used[pin]; found {
+			return fmt.Errorf("GPIO pin %d is used for both %s and %s", pin, other, names[i])
+		}
+		used[pin] = names[i]
+	}
+	return nil
+}
+
+// ExternalInputOptions configures an external switch device and the events mapped to game actions.
+type ExternalInputOptions struct {
+	Device     string `json:"device"`     // Path of the device, e.g. /dev/ttyUSB0, /dev/hidraw0 or COM3; empty to disable
+	Mode       string `json:"mode"`       // "serial" for newline-terminated text events, "hid" for raw reports
+	BaudRate   int    `json:"baudRate"`   // Speed of the serial port, with 8 data bits, no parity and 1 stop bit
+	SwitchTurn string `json:"switchTurn"` // Event that switches turns (text line, or hex-encoded HID report)
+	Pause      string `json:"pause"`      // Event that starts, pauses or resumes the game
 }
 
 // defaultPlayerNames Generate default player names
@@ -40,45 +247,188 @@ var DefaultOptions = Options{
 	ColorPalette:   hammerclockConfig.DefaultColorPalette,
 	TimeFormat:     "AMPM",
 	LoggingEnabled: true, // CSV logging enabled by default
+	RevertWindow:   30,
+	TickInterval:   1000,
+	TimeOdds:       "none",
+	FlagFall:       "continue",
+	FlagSound:      true,
+	LogTimestamps:  "time",
+	Layout:         "horizontal",
+	TurnCue:        "flash",
+	BorderStyle:    "single",
+	PauseOnModal:   true,
+	ExternalInput: ExternalInputOptions{
+		Mode:       "serial",
+		BaudRate:   9600,
+		SwitchTurn: "SWITCH",
+		Pause:      "PAUSE",
+	},
+	GPIO: GPIOOptions{
+		SwitchTurnPin: 17,
+		PausePin:      27,
+		ActiveLow:     true,
+		PlayerLEDPins: []int{22, 23},
+	},
+	GlobalHotkeys: GlobalHotkeyOptions{
+		SwitchTurn: "F9",
+		Pause:      "F10",
+	},
+	Discord: DiscordOptions{
+		LowTime: 5,
+	},
+}
+
+// ResettableFields lists the options that can be reset to their defaults from the options screen, in display order
+var ResettableFields = []string{
+	"Rules",
+	"Players",
+	"Color palette",
+	"Time format",
+	"Locale",
+	"Log timestamps",
+	"Layout",
+	"Turn cue",
+	"Border style",
+	"Panel widgets",
+	"Clock",
+	"Banner",
+	"Event and table",
+	"CSV logging",
+	"Battle report",
+	"Resume game",
+	"Secondary objectives prompt",
+	"Vim key bindings",
+	"Tick interval",
+	"Time budget",
+	"Grace period",
+	"Game size",
+	"Pause on dialogs",
+	"Pause on focus loss",
+	"Discord notifications",
+}
+
+// ResetField resets a single option, named as in ResettableFields, to its default value.
+// An empty field name resets all presentation and game settings, keeping the user's data, see keepUserData.
+// It returns false if the field is unknown.
+func ResetField(opts *Options, field string) bool {
+	defaults := Copy(DefaultOptions)
+	switch field {
+	case "":
+		keepUserData(&defaults, *opts)
+		*opts = defaults
+	case "Rules":
+		opts.Rules = defaults.Rules
+		opts.Default = defaults.Default
+	case "Players":
+		opts.PlayerCount = defaults.PlayerCount
+		opts.PlayerNames = defaults.PlayerNames
+	case "Color palette":
+		opts.ColorPalette = defaults.ColorPalette
+		opts.TransparentBackground = defaults.TransparentBackground
+	case "Time format":
+		opts.TimeFormat = defaults.TimeFormat
+	case "Locale":
+		opts.Locale = defaults.Locale
+	case "Log timestamps":
+		opts.LogTimestamps = defaults.LogTimestamps
+	case "Layout":
+		opts.Layout = defaults.Layout
+	case "Turn cue":
+		opts.TurnCue = defaults.TurnCue
+	case "Border style":
+		opts.BorderStyle = defaults.BorderStyle
+	case "Panel widgets":
+		opts.PanelWidgets = defaults.PanelWidgets
+	case "Clock":
+		opts.ClockShowDate = defaults.ClockShowDate
+		opts.ClockShowGameTime = defaults.ClockShowGameTime
+	case "Banner":
+		opts.Banner = defaults.Banner
+	case "Event and table":
+		opts.EventName = defaults.EventName
+		opts.TableNumber = defaults.TableNumber
+	case "CSV logging":
+		opts.LoggingEnabled = defaults.LoggingEnabled
+	case "Battle report":
+		opts.BattleReport = defaults.BattleReport
+	case "Resume game":
+		opts.ResumeGame = defaults.ResumeGame
+	case "Secondary objectives prompt":
+		opts.PromptSecondaryObjectives = defaults.PromptSecondaryObjectives
+	case "Vim key bindings":
+		opts.VimBindings = defaults.VimBindings
+	case "Tick interval":
+		opts.TickInterval = defaults.TickInterval
+	case "Time budget":
+		opts.TimeBudget = defaults.TimeBudget
+		opts.TimeOdds = defaults.TimeOdds
+		opts.FlagFall = defaults.FlagFall
+		opts.FlagSound = defaults.FlagSound
+		opts.ByoYomiPeriods = defaults.ByoYomiPeriods
+		opts.ByoYomiSeconds = defaults.ByoYomiSeconds
+	case "Grace period":
+		opts.GracePeriod = defaults.GracePeriod
+	case "Game size":
+		opts.GameSize = defaults.GameSize
+	case "Pause on dialogs":
+		opts.PauseOnModal = defaults.PauseOnModal
+	case "Pause on focus loss":
+		opts.PauseOnFocusLoss = defaults.PauseOnFocusLoss
+	case "Discord notifications":
+		opts.Discord = defaults.Discord
+	default:
+		return false
+	}
+	return true
+}
+
+// keepUserData copies what the user saved or set up, rather than chose for the game, to the reset options: the
+// templates, player profiles and macro, the secrets, so resetting does not lift the protection of the game, and the
+// connected devices and the Discord webhook
+func keepUserData(reset *Options, opts Options) {
+	kept := Copy(opts)
+	reset.Templates = kept.Templates
+	reset.Profiles = kept.Profiles
+	reset.Macro = kept.Macro
+	reset.JudgePassphrase = kept.JudgePassphrase
+	reset.ActionPIN = kept.ActionPIN
+	reset.LinkSecret = kept.LinkSecret
+	reset.ExternalInput = kept.ExternalInput
+	reset.GPIO = kept.GPIO
+	reset.GlobalHotkeys = kept.GlobalHotkeys
+	reset.Discord.WebhookURL = kept.Discord.WebhookURL
 }
 
-// LoadOptions loads the options from a file
-func LoadOptions(filename string) Options {
+// isDefaultOptionsFile reports whether the file is the default options file. Missing default files fall back to
+// the built-in defaults.
+func isDefaultOptionsFile(filename string, defaultFilename string) bool {
+	return filepath.Clean(filename) == filepath.Clean(defaultFilename)
+}
+
+// LoadOptions loads the options from a file. Files that cannot be loaded fall back to the default options file,
+// e.g. default.json in the config directory, and that one to the built-in defaults.
+func LoadOptions(filename string, defaultFilename string) Options {
 	var opts Options
 
 	// Check if the options file exists
 	_, err := os.Stat(filename)
 	if os.IsNotExist(err) {
 		// If the requested file is not the default one, inform the user and use default
-		if filename != hammerclockConfig.DefaultOptionsFilename {
+		if !isDefaultOptionsFile(filename, defaultFilename) {
 			fmt.Printf("options file '%s' not found, using default options file\n", filename)
-			return LoadOptions(hammerclockConfig.DefaultOptionsFilename)
+			return LoadOptions(defaultFilename, defaultFilename)
 		}
 
-		// Default file doesn't exist, create it from defaultOptions
-		fmt.Println("Default options file not found, creating it")
-
-		// Convert default options to JSON
-		jsonData, err := json.MarshalIndent(DefaultOptions, "", "  ")
-		if err != nil {
-			fmt.Println("Error creating default options file:", err)
-			return DefaultOptions
-		}
-
-		// Write the JSON data to the file
-		err = os.WriteFile(hammerclockConfig.DefaultOptionsFilename, jsonData, 0644)
-		if err != nil {
-			fmt.Println("Error writing default options file:", err)
-			return DefaultOptions
-		}
-
-		return DefaultOptions
+		// Default file doesn't exist, use the built-in defaults. The file is only written when the options are
+		// saved, so read-only installs work without touching the working directory.
+		fmt.Println("Default options file not found, using built-in defaults")
+		return Copy(DefaultOptions)
 	} else if err != nil {
 		// Some other error occurred
 		fmt.Printf("Error checking options file '%s': %v\n", filename, err)
-		if filename != hammerclockConfig.DefaultOptionsFilename {
+		if !isDefaultOptionsFile(filename, defaultFilename) {
 			fmt.Println("Falling back to default options")
-			return LoadOptions(hammerclockConfig.DefaultOptionsFilename)
+			return LoadOptions(defaultFilename, defaultFilename)
 		}
 		return DefaultOptions
 	}
@@ -87,27 +437,128 @@ func LoadOptions(filename string) Options {
 	byteValue, err := os.ReadFile(filename)
 	if err != nil {
 		fmt.Printf("Error reading options file '%s': %v\n", filename, err)
-		if filename != hammerclockConfig.DefaultOptionsFilename {
+		if !isDefaultOptionsFile(filename, defaultFilename) {
 			fmt.Println("Falling back to default options")
-			return LoadOptions(hammerclockConfig.DefaultOptionsFilename)
+			return LoadOptions(defaultFilename, defaultFilename)
 		}
 		return DefaultOptions
 	}
 
-	// Unmarshal the JSON data into the options struct
+	// Unmarshal the JSON data over the defaults, so options missing from older files keep their default values
+	opts = Copy(DefaultOptions)
 	err = json.Unmarshal(byteValue, &opts)
 	if err != nil {
 		fmt.Printf("Error parsing options file '%s': %v\n", filename, err)
-		if filename != hammerclockConfig.DefaultOptionsFilename {
+		if !isDefaultOptionsFile(filename, defaultFilename) {
 			fmt.Println("Falling back to default options")
-			return LoadOptions(hammerclockConfig.DefaultOptionsFilename)
+			return LoadOptions(defaultFilename, defaultFilename)
 		}
 		return DefaultOptions
 	}
 
+	if len(opts.Rules) == 0 {
+		opts.Rules = DefaultOptions.Rules
+	}
+	if err := ValidateGPIO(opts.GPIO); opts.GPIO.Enabled && err != nil {
+		fmt.Printf("Error in options file '%s': %v, GPIO is disabled\n", filename, err)
+		opts.GPIO.Enabled = false
+	}
+
 	return opts
 }
 
+// Identity returns the event name and table number that identify the table, e.g. "Spring Cup, Table 4", or an
+// empty string if neither is set
+func Identity(opts Options) string {
+	var parts []string
+	if opts.EventName != "" {
+		parts = append(parts, opts.EventName)
+	}
+	if opts.TableNumber > 0 {
+		parts = append(parts, fmt.Sprintf("Table %d", opts.TableNumber))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Copy returns a copy of the options that does not share slices with the original
+func Copy(opts Options) Options {
+	newOpts := opts
+	newOpts.Rules = slices.Clone(opts.Rules)
+	newOpts.PlayerNames = slices.Clone(opts.PlayerNames)
+	newOpts.Macro = slices.Clone(opts.Macro)
+	newOpts.PlayerBanners = slices.Clone(opts.PlayerBanners)
+	newOpts.PlayerFactions = slices.Clone(opts.PlayerFactions)
+	newOpts.PanelWidgets = slices.Clone(opts.PanelWidgets)
+	newOpts.StatusLabels = maps.Clone(opts.StatusLabels)
+	newOpts.Templates = slices.Clone(opts.Templates)
+	newOpts.Profiles = slices.Clone(opts.Profiles)
+	newOpts.GPIO.PlayerLEDPins = slices.Clone(opts.GPIO.PlayerLEDPins)
+	return newOpts
+}
+
+// Bounds of the tick interval in milliseconds
+const (
+	MinTickInterval = 250
+	MaxTickInterval = 2000
+)
+
+// GameTimeBudget returns the time on each player's clock before time odds, 0 without a limit: the time budget of the
+// selected ruleset if it has one, e.g. 90 minutes for 2000 point games, or else the timeBudget option
+func GameTimeBudget(opts Options) time.Duration {
+	if opts.Default >= 0 && opts.Default < len(opts.Rules) && opts.Rules[opts.Default].TimeBudget > 0 {
+		return time.Duration(opts.Rules[opts.Default].TimeBudget) * time.Minute
+	}
+	return time.Duration(opts.TimeBudget) * time.Minute
+}
+
+// PlayerTimeBudget returns the time on a player's clock, 0 without a limit, see GameTimeBudget. With time odds, the
+// first player gives the others odds: at 2:1 the first player has half the time budget, at 3:2 two thirds, and at
+// -10m ten minutes less, but at least a minute. Odds that cannot be read are ignored.
+func PlayerTimeBudget(opts Options, player int) time.Duration {
+	budget := GameTimeBudget(opts)
+	if budget <= 0 || player != 0 {
+		return budget
+	}
+	var others, first int
+	if n, _ := fmt.Sscanf(opts.TimeOdds, "%d:%d", &others, &first); n == 2 && others > 0 && first > 0 {
+		return budget * time.Duration(first) / time.Duration(others)
+	}
+	if handicap, found := strings.CutPrefix(opts.TimeOdds, "-"); found {
+		if d, err := time.ParseDuration(handicap); err == nil {
+			return max(budget-d, time.Minute)
+		}
+	}
+	return budget
+}
+
+// TickDuration returns the interval between clock updates, limited to MinTickInterval and MaxTickInterval.
+// An unset interval uses the default.
+func TickDuration(opts Options) time.Duration {
+	interval := opts.TickInterval
+	if interval == 0 {
+		interval = DefaultOptions.TickInterval
+	}
+	return time.Duration(min(max(interval, MinTickInterval), MaxTickInterval)) * time.Millisecond
+}
+
+// MaxBannerLines is the maximum number of lines of a player banner
+const MaxBannerLines = 8
+
+// LoadBanner reads a player banner from a text file. Trailing blank lines are removed and
+// the banner is cut to MaxBannerLines lines.
+func LoadBanner(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n "), "\n")
+	if len(lines) > MaxBannerLines {
+		lines = lines[:MaxBannerLines]
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 // SaveOptions saves the options to a file
 func SaveOptions(opts Options, filename string, silent bool) error {
 	// If no filename is specified, use the default
@@ -115,6 +566,9 @@ func SaveOptions(opts Options, filename string, silent bool) error {
 		filename = hammerclockConfig.DefaultOptionsFilename
 	}
 
+	// Rules merged from rules.d directories stay in their own files
+	opts.Rules = withoutMergedRules(opts.Rules)
+
 	// Convert options to JSON
 	jsonData, err := json.MarshalIndent(opts, "", "  ")
 	if err != nil {
@@ -124,6 +578,11 @@ func SaveOptions(opts Options, filename string, silent bool) error {
 		return err
 	}
 
+	// Keep a backup of the file that is about to be overwritten
+	if err := backupOptionsFile(filename); err != nil && !silent {
+		fmt.Printf("Error backing up options file '%s': %v\n", filename, err)
+	}
+
 	// Write the JSON data to the file
 	err = os.WriteFile(filename, jsonData, 0644)
 	if err != nil && !silent {
@@ -132,3 +591,78 @@ func SaveOptions(opts Options, filename string, silent bool) error {
 
 	return err
 }
+
+// Diff lists the options that differ between base and opts, one line per changed value in the form
+// "path: base value -> new value". Paths use the JSON names, e.g. "playerNames[1]" or "rules[0].phases[2]".
+func Diff(base Options, opts Options) []string {
+	baseValues := flattenOptions(base)
+	values := flattenOptions(opts)
+
+	paths := make([]string, 0, len(values))
+	for path := range values {
+		paths = append(paths, path)
+	}
+	for path := range baseValues {
+		if _, ok := values[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var diff []string
+	for _, path := range paths {
+		baseValue, inBase := baseValues[path]
+		value, inOpts := values[path]
+		switch {
+		case !inBase:
+			diff = append(diff, fmt.Sprintf("%s: (none) -> %s", path, value))
+		case !inOpts:
+			diff = append(diff, fmt.Sprintf("%s: %s -> (none)", path, baseValue))
+		case baseValue != value:
+			diff = append(diff, fmt.Sprintf("%s: %s -> %s", path, baseValue, value))
+		}
+	}
+	return diff
+}
+
+// secretOptions are the paths of the options that are hidden in the diff
+var secretOptions = []string{"judgePassphrase", "actionPin", "linkSecret", "discord.webhookUrl"}
+
+// flattenOptions converts the options to a map from JSON paths to JSON-encoded leaf values
+func flattenOptions(opts Options) map[string]string {
+	var tree any
+	data, _ := json.Marshal(opts)
+	_ = json.Unmarshal(data, &tree)
+
+	values := map[string]string{}
+	flattenValue("", tree, values)
+
+	// The secrets are not shown to the players looking at the diff
+	for _, path := range secretOptions {
+		if _, ok := values[path]; ok {
+			values[path] = `"(hidden)"`
+		}
+	}
+	return values
+}
+
+// flattenValue adds the leaf values below the given path to the map
+func flattenValue(path string, value any, values map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenValue(childPath, child, values)
+		}
+	case []any:
+		for i, child := range v {
+			flattenValue(fmt.Sprintf("%s[%d]", path, i), child, values)
+		}
+	default:
+		encoded, _ := json.Marshal(v)
+		values[path] = string(encoded)
+	}
+}
@@ -3,20 +3,36 @@ package options
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"hammerclock/internal/hammerclock/config"
+	"hammerclock/internal/hammerclock/rules"
 )
 
 func TestLoadOptionsFromNonExistentFileUsesDefaultOptions(t *testing.T) {
 	filename := "nonexistent.json"
-	opts := LoadOptions(filename)
+	opts := LoadOptions(filename, filepath.Join(t.TempDir(), hammerclockConfig.DefaultOptionsFilename))
 
 	if opts.Default != DefaultOptions.Default {
 		t.Errorf("Expected default options, got %+v", opts)
 	}
 }
 
+func TestLoadOptionsFallsBackToTheDefaultOptionsFile(t *testing.T) {
+	defaultFilename := filepath.Join(t.TempDir(), hammerclockConfig.DefaultOptionsFilename)
+	if err := os.WriteFile(defaultFilename, []byte(`{"playerCount": 3}`), 0644); err != nil {
+		t.Fatalf("Failed to write the default options: %v", err)
+	}
+
+	opts := LoadOptions("nonexistent.json", defaultFilename)
+	if opts.PlayerCount != 3 {
+		t.Errorf("Expected the options of the default options file, got player count %d", opts.PlayerCount)
+	}
+}
+
 func TestLoadOptionsFromInvalidFileFallsBackToDefault(t *testing.T) {
 	filename := "invalid.json"
 	err := os.WriteFile(filename, []byte("invalid json"), 0644)
@@ -25,7 +41,7 @@ func TestLoadOptionsFromInvalidFileFallsBackToDefault(t *testing.T) {
 	}
 	defer os.Remove(filename)
 
-	opts := LoadOptions(filename)
+	opts := LoadOptions(filename, filename)
 	if opts.Default != DefaultOptions.Default {
 		t.Errorf("Expected default options, got %+v", opts)
 	}
@@ -57,6 +73,13 @@ func TestSaveOptionsCreatesFileWithCorrectContent(t *testing.T) {
 }
 
 func TestSaveOptionsHandlesEmptyFilenameGracefully(t *testing.T) {
+	t.Cleanup(func() {
+		backups, _ := listBackups(hammerclockConfig.DefaultOptionsFilename)
+		for _, backup := range backups {
+			_ = os.Remove(backup)
+		}
+	})
+
 	err := SaveOptions(DefaultOptions, "", false)
 	if err != nil {
 		t.Errorf("Expected no error when saving with empty filename, got %v", err)
@@ -71,8 +94,236 @@ func TestLoadOptionsHandlesCorruptedDefaultFileGracefully(t *testing.T) {
 	}
 	defer os.Remove(filename)
 
-	opts := LoadOptions(filename)
+	opts := LoadOptions(filename, filename)
 	if opts.Default != DefaultOptions.Default {
 		t.Errorf("Expected fallback to default options, got %+v", opts)
 	}
 }
+
+func TestLoadOptionsKeepsDefaultsForMissingFields(t *testing.T) {
+	filename := "partial.json"
+	err := os.WriteFile(filename, []byte(`{"playerCount": 3, "playerNames": ["A", "B", "C"]}`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create partial file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	opts := LoadOptions(filename, filename)
+	if opts.PlayerCount != 3 {
+		t.Errorf("Expected player count from file, got %d", opts.PlayerCount)
+	}
+	if opts.RevertWindow != DefaultOptions.RevertWindow || len(opts.Rules) != len(DefaultOptions.Rules) {
+		t.Errorf("Expected missing options to keep their defaults, got %+v", opts)
+	}
+	if DefaultOptions.PlayerNames[0] == "A" {
+		t.Errorf("Expected loading options to leave the defaults unchanged")
+	}
+}
+
+func TestLoadBannerTrimsAndLimitsLines(t *testing.T) {
+	filename := "banner.txt"
+	err := os.WriteFile(filename, []byte(" /\\\n/  \\\n1\n2\n3\n4\n5\n6\n7\n\n"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create banner file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	banner, err := LoadBanner(filename)
+	if err != nil {
+		t.Fatalf("Expected banner to load, got error: %v", err)
+	}
+	if lines := strings.Split(banner, "\n"); len(lines) != MaxBannerLines || lines[0] != " /\\" {
+		t.Errorf("Expected %d lines starting with the art, got %q", MaxBannerLines, banner)
+	}
+
+	if _, err := LoadBanner("missing.txt"); err == nil {
+		t.Errorf("Expected an error for a missing banner file")
+	}
+}
+
+func TestResetFieldRestoresDefaults(t *testing.T) {
+	opts := Copy(DefaultOptions)
+	opts.TimeFormat = "24-hour"
+	opts.VimBindings = true
+	opts.Discord = DiscordOptions{Enabled: true, WebhookURL: "https://discord.com/api/webhooks/1/token"}
+
+	if !ResetField(&opts, "Discord notifications") || opts.Discord != DefaultOptions.Discord {
+		t.Errorf("Expected the Discord webhook to be reset, got %+v", opts.Discord)
+	}
+	if !ResetField(&opts, "Time format") || opts.TimeFormat != DefaultOptions.TimeFormat {
+		t.Errorf("Expected time format to be reset, got %q", opts.TimeFormat)
+	}
+	if !opts.VimBindings {
+		t.Errorf("Expected other options to be kept when resetting a single field")
+	}
+
+	opts.ActionPIN, opts.JudgePassphrase, opts.LinkSecret = "4711", "secret", "krak3n"
+	opts.Discord.WebhookURL = "https://discord.com/api/webhooks/1/token"
+	opts.Templates = []GameTemplate{{Name: "Tuesday 2000pt"}}
+	opts.Profiles = []PlayerProfile{{Name: "Alice", Army: []ArmyUnit{{Name: "Intercessors"}}}}
+	if !ResetField(&opts, "") || opts.VimBindings != DefaultOptions.VimBindings {
+		t.Errorf("Expected all options to be reset")
+	}
+	if opts.ActionPIN != "4711" || opts.JudgePassphrase != "secret" || opts.LinkSecret != "krak3n" ||
+		opts.Discord.WebhookURL == "" {
+		t.Errorf("Expected the secrets to be kept, got %q, %q, %q and %q",
+			opts.ActionPIN, opts.JudgePassphrase, opts.LinkSecret, opts.Discord.WebhookURL)
+	}
+	if len(opts.Templates) != 1 || len(opts.Profiles) != 1 || len(opts.Profiles[0].Army) != 1 {
+		t.Errorf("Expected the templates and profiles to be kept, got %+v and %+v", opts.Templates, opts.Profiles)
+	}
+	if ResetField(&opts, "Unknown") {
+		t.Errorf("Expected unknown fields to be rejected")
+	}
+}
+
+func TestDiffListsChangedOptions(t *testing.T) {
+	opts := Copy(DefaultOptions)
+	opts.TimeFormat = "24-hour"
+	opts.PlayerNames = append(opts.PlayerNames, "Player 3")
+	opts.Discord.WebhookURL = "https://discord.com/api/webhooks/1/token"
+	opts.ActionPIN = "1234"
+
+	diff := Diff(DefaultOptions, opts)
+	expected := []string{
+		`actionPin: (none) -> "(hidden)"`,
+		`playerNames[2]: (none) -> "Player 3"`,
+		`timeFormat: "AMPM" -> "24-hour"`,
+	}
+	if strings.Join(diff, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected diff %q, got %q", expected, diff)
+	}
+
+	if diff := Diff(DefaultOptions, Copy(DefaultOptions)); len(diff) != 0 {
+		t.Errorf("Expected no differences for identical options, got %q", diff)
+	}
+}
+
+func TestTickDurationIsLimited(t *testing.T) {
+	tests := map[int]time.Duration{
+		0:     time.Second,
+		100:   250 * time.Millisecond,
+		500:   500 * time.Millisecond,
+		10000: 2 * time.Second,
+	}
+	for interval, expected := range tests {
+		if got := TickDuration(Options{TickInterval: interval}); got != expected {
+			t.Errorf("Expected %v for a tick interval of %d, got %v", expected, interval, got)
+		}
+	}
+}
+
+func TestIdentityNamesEventAndTable(t *testing.T) {
+	tests := []struct {
+		eventName   string
+		tableNumber int
+		expected    string
+	}{
+		{"Spring Cup", 4, "Spring Cup, Table 4"},
+		{"Spring Cup", 0, "Spring Cup"},
+		{"", 12, "Table 12"},
+		{"", 0, ""},
+	}
+	for _, test := range tests {
+		if got := Identity(Options{EventName: test.eventName, TableNumber: test.tableNumber}); got != test.expected {
+			t.Errorf("Expected %q for %q and table %d, got %q", test.expected, test.eventName, test.tableNumber, got)
+		}
+	}
+}
+
+func TestProfileArmyVersionsPerEvent(t *testing.T) {
+	opts := Options{}
+	first := []ArmyUnit{{Name: "Intercessors", Points: 200}}
+	second := []ArmyUnit{{Name: "Intercessors", Points: 200}, {Name: "Captain", Points: 80}}
+
+	if version := SetProfileArmy(&opts, "Alice", first); version != 0 {
+		t.Errorf("Expected no version outside of events, got %d", version)
+	}
+	opts.EventName = "Spring Cup"
+	if army := ProfileArmy(opts, "Alice"); len(army) != 1 {
+		t.Errorf("Expected the latest list at an event without a list for it, got %v", army)
+	}
+	versions := []int{
+		SetProfileArmy(&opts, "Alice", first),
+		SetProfileArmy(&opts, "Alice", first),
+		SetProfileArmy(&opts, "Alice", second),
+	}
+	if versions[0] != 1 || versions[1] != 1 || versions[2] != 2 {
+		t.Errorf("Expected the version to go up only when the list changes, got %v", versions)
+	}
+
+	opts.EventName = "Autumn Cup"
+	SetProfileArmy(&opts, "Alice", first)
+	opts.EventName = "Spring Cup"
+	if army := ProfileArmy(opts, "Alice"); len(army) != 2 {
+		t.Errorf("Expected the list for the event, got %v", army)
+	}
+	opts.EventName = ""
+	if army := ProfileArmy(opts, "Alice"); len(army) != 1 {
+		t.Errorf("Expected the latest list outside of events, got %v", army)
+	}
+	if len(opts.Profiles) != 1 || ProfileArmy(opts, "Bob") != nil {
+		t.Errorf("Expected a single profile, got %v", opts.Profiles)
+	}
+}
+
+func TestPlayerTimeBudgetWithOdds(t *testing.T) {
+	tests := []struct {
+		odds     string
+		expected time.Duration
+	}{
+		{"none", 90 * time.Minute},
+		{"2:1", 45 * time.Minute},
+		{"3:2", 60 * time.Minute},
+		{"-20m", 70 * time.Minute},
+		{"-2h", time.Minute},
+		{"2:0", 90 * time.Minute},
+	}
+	for _, test := range tests {
+		opts := Options{TimeBudget: 90, TimeOdds: test.odds}
+		if budget := PlayerTimeBudget(opts, 0); budget != test.expected {
+			t.Errorf("Expected %v on the first clock with odds %q, got %v", test.expected, test.odds, budget)
+		}
+		if budget := PlayerTimeBudget(opts, 1); budget != 90*time.Minute {
+			t.Errorf("Expected the full budget on the other clocks with odds %q, got %v", test.odds, budget)
+		}
+	}
+	if budget := PlayerTimeBudget(Options{TimeOdds: "2:1"}, 0); budget != 0 {
+		t.Errorf("Expected no limit without a time budget, got %v", budget)
+	}
+}
+
+func TestGameTimeBudgetOfRuleset(t *testing.T) {
+	opts := Options{TimeBudget: 90, Rules: []rules.Rules{{Name: "Combat Patrol", TimeBudget: 45}, {Name: "Chess"}}}
+	if budget := GameTimeBudget(opts); budget != 45*time.Minute {
+		t.Errorf("Expected the time budget of the ruleset, got %v", budget)
+	}
+	opts.TimeOdds = "-15m"
+	if budget := PlayerTimeBudget(opts, 0); budget != 30*time.Minute {
+		t.Errorf("Expected the odds to apply to the time budget of the ruleset, got %v", budget)
+	}
+
+	opts.Default = 1
+	if budget := GameTimeBudget(opts); budget != 90*time.Minute {
+		t.Errorf("Expected the timeBudget option for a ruleset without a time budget, got %v", budget)
+	}
+}
+
+func TestLoadOptionsRejectsDuplicateGPIOPins(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "gpio.json")
+	data := `{"gpio": {"enabled": true, "switchTurnPin": 17, "pausePin": 27, "playerLedPins": [22, 17]}}`
+	if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write the options: %v", err)
+	}
+
+	opts := LoadOptions(filename, filename)
+	if opts.GPIO.Enabled {
+		t.Error("Expected GPIO to be disabled with a pin used twice")
+	}
+	if err := ValidateGPIO(opts.GPIO); err == nil || err.Error() != "GPIO pin 17 is used for both switchTurnPin and playerLedPins[1]" {
+		t.Errorf("Expected the pin used twice to be named, got %v", err)
+	}
+	if err := ValidateGPIO(DefaultOptions.GPIO); err != nil {
+		t.Errorf("Expected the default pins to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package options
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// EnvPrefix starts the names of the environment variables that override options, e.g. HAMMERCLOCK_PLAYER_COUNT
+const EnvPrefix = "HAMMERCLOCK_"
+
+// EnvName returns the environment variable overriding the option with the given key, e.g. HAMMERCLOCK_TIME_BUDGET
+// for timeBudget
+func EnvName(key string) string {
+	var name strings.Builder
+	name.WriteString(EnvPrefix)
+	for i, r := range key {
+		if unicode.IsUpper(r) && i > 0 {
+			name.WriteRune('_')
+		}
+		name.WriteRune(unicode.ToUpper(r))
+	}
+	return name.String()
+}
+
+// Set sets the option with the given key of the options file to a value. Text options take the value as it is,
+// other options take it as JSON, e.g. true, 90 or ["Alice", "Bob"]; lists of text also take comma-separated values.
+func Set(opts *Options, key, value string) error {
+	field, found := optionField(opts, key)
+	if !found {
+		return fmt.Errorf("unknown option '%s'", key)
+	}
+	if field.Kind() == reflect.String {
+		field.SetString(value)
+		return nil
+	}
+
+	parsed := reflect.New(field.Type())
+	if err := json.Unmarshal([]byte(value), parsed.Interface()); err != nil {
+		if field.Type() != reflect.TypeOf([]string(nil)) {
+			return fmt.Errorf("invalid value '%s' for option '%s'", value, key)
+		}
+		parsed.Elem().Set(reflect.ValueOf(strings.Split(value, ",")))
+	}
+	field.Set(parsed.Elem())
+	return nil
+}
+
+// ApplyEnv returns the options with the options named by the environment variables in environ, given as KEY=value
+// like os.Environ, set to their values. Variables with invalid values are skipped and reported in the error.
+func ApplyEnv(opts Options, environ []string) (Options, error) {
+	opts = Copy(opts)
+	keys := make(map[string]string)
+	for _, key := range Keys() {
+		keys[EnvName(key)] = key
+	}
+
+	var errs []error
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+		key, found := keys[name]
+		if !found {
+			continue
+		}
+		if err := Set(&opts, key, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return opts, errors.Join(errs...)
+}
+
+// ApplySettings returns the options with the settings, given as key=value, applied in order
+func ApplySettings(opts Options, settings []string) (Options, error) {
+	opts = Copy(opts)
+	for _, setting := range settings {
+		key, value, found := strings.Cut(setting, "=")
+		if !found {
+			return opts, fmt.Errorf("setting '%s' is not of the form key=value", setting)
+		}
+		if err := Set(&opts, key, value); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// Keys returns the keys of the options file, in the order of the Options fields
+func Keys() []string {
+	optionsType := reflect.TypeOf(Options{})
+	keys := make([]string, 0, optionsType.NumField())
+	for i := range optionsType.NumField() {
+		keys = append(keys, jsonKey(optionsType.Field(i)))
+	}
+	return keys
+}
+
+// optionField returns the field of the options with the given key of the options file
+func optionField(opts *Options, key string) (reflect.Value, bool) {
+	value := reflect.ValueOf(opts).Elem()
+	for i := range value.NumField() {
+		if jsonKey(value.Type().Field(i)) == key {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// jsonKey returns the key of a field of the options in the options file
+func jsonKey(field reflect.StructField) string {
+	key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return key
+}
+
+// WithoutOverrides returns the options to save to the options file: opts with the options that were overridden when
+// loading and kept since set back to their value in the file. loaded are the options as loaded, with the overrides,
+// and file the options as in the file.
+func WithoutOverrides(opts, loaded, file Options) Options {
+	opts = Copy(opts)
+	value := reflect.ValueOf(&opts).Elem()
+	loadedValue := reflect.ValueOf(loaded)
+	fileValue := reflect.ValueOf(Copy(file))
+	for i := range value.NumField() {
+		if reflect.DeepEqual(value.Field(i).Interface(), loadedValue.Field(i).Interface()) {
+			value.Field(i).Set(fileValue.Field(i))
+		}
+	}
+	return opts
+}
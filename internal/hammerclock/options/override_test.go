@@ -0,0 +1,68 @@
+package options
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOverridesTakePrecedenceOverTheFile(t *testing.T) {
+	if name := EnvName("timeBudget"); name != "HAMMERCLOCK_TIME_BUDGET" {
+		t.Errorf("Expected HAMMERCLOCK_TIME_BUDGET, got %s", name)
+	}
+
+	opts := Copy(DefaultOptions)
+	opts.TimeBudget = 60
+	opts.Banner = "From the file"
+
+	environ := []string{
+		"HOME=/home/player",
+		"HAMMERCLOCK_TIME_BUDGET=90",
+		"HAMMERCLOCK_PLAYER_NAMES=Alice,Bob",
+		"HAMMERCLOCK_BANNER=From the environment",
+		"HAMMERCLOCK_FLAG_SOUND=loud",
+	}
+	opts, err := ApplyEnv(opts, environ)
+	if err == nil {
+		t.Error("Expected an error for the invalid flagSound")
+	}
+	if opts.TimeBudget != 90 || !slices.Equal(opts.PlayerNames, []string{"Alice", "Bob"}) {
+		t.Errorf("Expected the environment to override the file, got %d and %v", opts.TimeBudget, opts.PlayerNames)
+	}
+
+	opts, err = ApplySettings(opts, []string{"banner=Table 4", "vimBindings=true"})
+	if err != nil {
+		t.Fatalf("Failed to apply the settings: %v", err)
+	}
+	if opts.Banner != "Table 4" || !opts.VimBindings {
+		t.Errorf("Expected the settings to override the environment, got %q and %v", opts.Banner, opts.VimBindings)
+	}
+	if opts.TimeBudget != 90 {
+		t.Errorf("Expected the other options to be kept, got a time budget of %d", opts.TimeBudget)
+	}
+
+	if _, err := ApplySettings(opts, []string{"noSuchOption=1"}); err == nil {
+		t.Error("Expected an error for an unknown option")
+	}
+}
+
+func TestOverridesAreNotSaved(t *testing.T) {
+	file := Copy(DefaultOptions)
+	file.TimeBudget = 60
+	file.Banner = "From the file"
+
+	loaded, err := ApplySettings(file, []string{"timeBudget=90", "banner=From the command line"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	opts := Copy(loaded)
+	opts.TimeBudget = 120
+	opts.TimeFormat = "24-hour"
+
+	saved := WithoutOverrides(opts, loaded, file)
+	if saved.Banner != "From the file" {
+		t.Errorf("Expected the overridden banner to keep its value in the file, got %q", saved.Banner)
+	}
+	if saved.TimeBudget != 120 || saved.TimeFormat != "24-hour" {
+		t.Errorf("Expected the changed options to be saved, got %d and %q", saved.TimeBudget, saved.TimeFormat)
+	}
+}
@@ -0,0 +1,68 @@
+package options
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// remoteTimeout limits how long downloading a remote options file may take
+const remoteTimeout = 10 * time.Second
+
+// IsURL reports whether the options file name is an http or https URL
+func IsURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// FetchRemote downloads an options file from a URL into the user's cache directory and returns the path
+// of the cached copy. If the download fails, the previously cached copy is used when available.
+func FetchRemote(url string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(url))
+	cachePath := filepath.Join(cacheDir, "hammerclock", "options-"+hex.EncodeToString(sum[:8])+".json")
+
+	downloadErr := download(url, cachePath)
+	if downloadErr == nil {
+		return cachePath, nil
+	}
+
+	if _, err := os.Stat(cachePath); err == nil {
+		fmt.Printf("Could not download options from '%s' (%v), using cached copy\n", url, downloadErr)
+		return cachePath, nil
+	}
+	return "", downloadErr
+}
+
+// download fetches the URL and writes the response body to the given file
+func download(url string, filename string) error {
+	client := http.Client{Timeout: remoteTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
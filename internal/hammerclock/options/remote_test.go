@@ -0,0 +1,41 @@
+package options
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchRemoteCachesOptions(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	available := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"playerCount": 4}`))
+	}))
+	defer server.Close()
+
+	if !IsURL(server.URL) || IsURL("default.json") {
+		t.Fatalf("Expected only URLs to be detected as remote options")
+	}
+
+	cachePath, err := FetchRemote(server.URL + "/club.json")
+	if err != nil {
+		t.Fatalf("Expected download to succeed, got error: %v", err)
+	}
+	if opts := LoadOptions(cachePath, cachePath); opts.PlayerCount != 4 {
+		t.Errorf("Expected cached options to be loaded, got player count %d", opts.PlayerCount)
+	}
+
+	// The cached copy is used when the server is unavailable
+	available = false
+	if fallbackPath, err := FetchRemote(server.URL + "/club.json"); err != nil || fallbackPath != cachePath {
+		t.Errorf("Expected cached copy to be used, got %q, %v", fallbackPath, err)
+	}
+	if _, err := FetchRemote(server.URL + "/other.json"); err == nil {
+		t.Errorf("Expected an error for an unavailable URL without a cached copy")
+	}
+}
@@ -0,0 +1,103 @@
+package options
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"hammerclock/internal/hammerclock/rules"
+)
+
+// RulesDirName is the name of the directory with rules fragments, looked up next to the options file
+const RulesDirName = "rules.d"
+
+// SystemRulesDirs are the directories where packages install rules fragments, merged before the user's rules.d
+var SystemRulesDirs = []string{"/usr/share/hammerclock/rules.d", "/etc/hammerclock/rules.d"}
+
+// MergeRulesDirs merges the rules fragments of the system directories and of the rules.d directory next to the
+// options file into the rules. Rulesets of the options file always win; a fragment replaces an earlier fragment
+// with the same name.
+func MergeRulesDirs(base []rules.Rules, optionsFile string) []rules.Rules {
+	dirs := append(append([]string{}, SystemRulesDirs...), filepath.Join(filepath.Dir(optionsFile), RulesDirName))
+
+	merged := base
+	for _, dir := range dirs {
+		fragments, err := LoadRulesDir(dir)
+		if err != nil {
+			fmt.Printf("Error loading rules from '%s': %v\n", dir, err)
+		}
+		merged = MergeRules(merged, fragments)
+	}
+	return merged
+}
+
+// LoadRulesDir loads the *.json rules fragments of a directory in file name order. A fragment contains a
+// single ruleset or an array of rulesets. A missing directory has no rules.
+func LoadRulesDir(dir string) ([]rules.Rules, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var loaded []rules.Rules
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return loaded, err
+		}
+
+		var fragment []rules.Rules
+		if err := json.Unmarshal(data, &fragment); err != nil {
+			var single rules.Rules
+			if err := json.Unmarshal(data, &single); err != nil {
+				return loaded, fmt.Errorf("invalid rules fragment '%s': %w", file, err)
+			}
+			fragment = []rules.Rules{single}
+		}
+
+		for _, rule := range fragment {
+			if rule.Name == "" || len(rule.Phases) == 0 {
+				return loaded, fmt.Errorf("rules fragment '%s' has a ruleset without name or phases", file)
+			}
+			rule.Source = file
+			loaded = append(loaded, rule)
+		}
+	}
+	return loaded, nil
+}
+
+// MergeRules adds the rules fragments to the base rules. Fragments named like a ruleset of the options file
+// are skipped, fragments named like an earlier fragment replace it.
+func MergeRules(base []rules.Rules, fragments []rules.Rules) []rules.Rules {
+	merged := append([]rules.Rules{}, base...)
+	for _, fragment := range fragments {
+		replaced := false
+		for i, rule := range merged {
+			if rule.Name == fragment.Name {
+				if rule.Source != "" {
+					merged[i] = fragment
+				}
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, fragment)
+		}
+	}
+	return merged
+}
+
+// withoutMergedRules returns the rules that belong to the options file, leaving out merged fragments
+func withoutMergedRules(all []rules.Rules) []rules.Rules {
+	own := make([]rules.Rules, 0, len(all))
+	for _, rule := range all {
+		if rule.Source == "" {
+			own = append(own, rule)
+		}
+	}
+	return own
+}
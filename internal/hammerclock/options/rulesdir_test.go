@@ -0,0 +1,54 @@
+package options
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"hammerclock/internal/hammerclock/rules"
+)
+
+func TestMergeRulesDirsAddsFragments(t *testing.T) {
+	systemRulesDirs := SystemRulesDirs
+	SystemRulesDirs = nil
+	defer func() { SystemRulesDirs = systemRulesDirs }()
+
+	dir := t.TempDir()
+	rulesDir := filepath.Join(dir, RulesDirName)
+	if err := os.Mkdir(rulesDir, 0755); err != nil {
+		t.Fatalf("Failed to create rules.d: %v", err)
+	}
+	fragments := map[string]string{
+		"10-skirmish.json": `{"name": "Skirmish", "phases": ["Move", "Fight"]}`,
+		"20-more.json":     `[{"name": "Skirmish", "phases": ["Move", "Shoot", "Fight"]}, {"name": "Chess", "phases": ["Move"]}]`,
+	}
+	for name, content := range fragments {
+		if err := os.WriteFile(filepath.Join(rulesDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write fragment: %v", err)
+		}
+	}
+
+	base := []rules.Rules{{Name: "Chess", Phases: []string{"Play"}}}
+	merged := MergeRulesDirs(base, filepath.Join(dir, "default.json"))
+
+	if len(merged) != 2 {
+		t.Fatalf("Expected 2 rulesets, got %+v", merged)
+	}
+	if len(merged[0].Phases) != 1 || merged[0].Source != "" {
+		t.Errorf("Expected the ruleset of the options file to win, got %+v", merged[0])
+	}
+	if merged[1].Name != "Skirmish" || len(merged[1].Phases) != 3 {
+		t.Errorf("Expected the later fragment to replace the earlier one, got %+v", merged[1])
+	}
+
+	// Merged rulesets are not written to the options file
+	filename := filepath.Join(dir, "default.json")
+	opts := Copy(DefaultOptions)
+	opts.Rules = merged
+	if err := SaveOptions(opts, filename, true); err != nil {
+		t.Fatalf("Failed to save options: %v", err)
+	}
+	if saved := LoadOptions(filename, filename); len(saved.Rules) != 1 {
+		t.Errorf("Expected only the rulesets of the options file to be saved, got %d", len(saved.Rules))
+	}
+}
@@ -1,6 +1,9 @@
 package palette
 
 import (
+	_ "embed"
+	"encoding/json"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -17,93 +20,79 @@ type ColorPalette struct {
 	Black    tcell.Color
 }
 
-// K9sPalette K9s color palette
-var K9sPalette = ColorPalette{
-	Blue:     tcell.NewRGBColor(36, 96, 146),   // Dark blue for backgrounds
-	Cyan:     tcell.NewRGBColor(0, 183, 235),   // Cyan for highlights
-	White:    tcell.NewRGBColor(255, 255, 255), // White for primary text
-	DimWhite: tcell.NewRGBColor(180, 180, 180), // Dimmed white for inactive panels
-	Yellow:   tcell.NewRGBColor(253, 185, 19),  // Yellow for warnings
-	Green:    tcell.NewRGBColor(0, 200, 83),    // Green for success/active states
-	Red:      tcell.NewRGBColor(255, 0, 0),     // Red for errors/critical states
-	Black:    tcell.NewRGBColor(0, 0, 0),       // Black for default backgrounds
+// paletteJSON describes a bundled color palette with hex colors, e.g. "#246092", or basic color names, e.g. "navy"
+type paletteJSON struct {
+	Name     string `json:"name"`
+	Blue     string `json:"blue"`
+	Cyan     string `json:"cyan"`
+	White    string `json:"white"`
+	DimWhite string `json:"dimWhite"`
+	Yellow   string `json:"yellow"`
+	Green    string `json:"green"`
+	Red      string `json:"red"`
+	Black    string `json:"black"`
 }
 
-// draculaPalette Dracula color palette
-var draculaPalette = ColorPalette{
-	Blue:     tcell.NewRGBColor(189, 147, 249), // Purple
-	Cyan:     tcell.NewRGBColor(139, 233, 253), // Cyan
-	White:    tcell.NewRGBColor(248, 248, 242), // Foreground
-	DimWhite: tcell.NewRGBColor(174, 174, 169), // Dimmed foreground
-	Yellow:   tcell.NewRGBColor(241, 250, 140), // Yellow
-	Green:    tcell.NewRGBColor(80, 250, 123),  // Green
-	Red:      tcell.NewRGBColor(255, 85, 85),   // Red
-	Black:    tcell.NewRGBColor(40, 42, 54),    // Background
-}
+// palettesJSON holds the bundled color palettes, embedded so they are available without any files on disk
+//
+//go:embed palettes.json
+var palettesJSON []byte
 
-// monokaiPalette Monokai color palette
-var monokaiPalette = ColorPalette{
-	Blue:     tcell.NewRGBColor(102, 217, 239), // Blue
-	Cyan:     tcell.NewRGBColor(102, 217, 239), // Blue (same as Blue for Monokai)
-	White:    tcell.NewRGBColor(248, 248, 242), // Foreground
-	DimWhite: tcell.NewRGBColor(174, 174, 169), // Dimmed foreground
-	Yellow:   tcell.NewRGBColor(230, 219, 116), // Yellow
-	Green:    tcell.NewRGBColor(166, 226, 46),  // Green
-	Red:      tcell.NewRGBColor(249, 38, 114),  // Red
-	Black:    tcell.NewRGBColor(39, 40, 34),    // Background
-}
+// bundledPalettes contains the bundled color palettes in display order
+var bundledPalettes = mustParsePalettes(palettesJSON)
 
-// warhammerPalette represents the color theme for Warhammer 40K
-var warhammerPalette = ColorPalette{
-	Blue:     tcell.NewRGBColor(38, 57, 132),   // Ultramarine Blue
-	Cyan:     tcell.NewRGBColor(23, 155, 215),  // Tyranid Blue
-	White:    tcell.NewRGBColor(255, 250, 240), // Imperial White
-	DimWhite: tcell.NewRGBColor(180, 170, 150), // Bone Color
-	Yellow:   tcell.NewRGBColor(245, 180, 26),  // Imperial Gold
-	Green:    tcell.NewRGBColor(0, 120, 50),    // Dark Angels Green
-	Red:      tcell.NewRGBColor(190, 0, 0),     // Blood Angels Red
-	Black:    tcell.NewRGBColor(10, 10, 10),    // Abaddon Black
-}
+// K9sPalette K9s color palette, the fallback for unknown palette names
+var K9sPalette = ColorPaletteByName("k9s")
+
+// BasicPaletteName is the palette made of the 16 basic console colors, used in consoles without true color
+const BasicPaletteName = "basic"
 
-// killTeamPalette represents the color theme for Kill Team
-var killTeamPalette = ColorPalette{
-	Blue:     tcell.NewRGBColor(63, 81, 153),   // Night Lords Blue
-	Cyan:     tcell.NewRGBColor(0, 169, 157),   // Tactical Turquoise
-	White:    tcell.NewRGBColor(230, 230, 230), // Tactical White
-	DimWhite: tcell.NewRGBColor(150, 150, 150), // Urban Gray
-	Yellow:   tcell.NewRGBColor(255, 193, 0),   // Warning Yellow
-	Green:    tcell.NewRGBColor(76, 99, 25),    // Camo Green
-	Red:      tcell.NewRGBColor(200, 40, 40),   // Target Red
-	Black:    tcell.NewRGBColor(5, 5, 5),       // Shadow Black
+// mustParsePalettes parses the bundled color palettes, panicking if the embedded file is invalid
+func mustParsePalettes(data []byte) []paletteJSON {
+	var palettes []paletteJSON
+	if err := json.Unmarshal(data, &palettes); err != nil {
+		panic("invalid bundled color palettes: " + err.Error())
+	}
+	return palettes
 }
 
 // ColorPalettes returns a list of available color palettes
 func ColorPalettes() []string {
-	return []string{
-		"k9s",
-		"dracula",
-		"monokai",
-		"warhammer",
-		"killteam",
+	names := make([]string, len(bundledPalettes))
+	for i, p := range bundledPalettes {
+		names[i] = p.Name
 	}
+	return names
 }
 
-// ColorPaletteByName returns the color palette for the given name
+// ColorPaletteByName returns the color palette for the given name. Unknown names return the k9s palette.
 func ColorPaletteByName(name string) ColorPalette {
-	switch name {
-	case "dracula":
-		return draculaPalette
-	case "monokai":
-		return monokaiPalette
-	case "warhammer":
-		return warhammerPalette
-	case "killteam":
-		return killTeamPalette
-	default: // "k9s" or any other value defaults to k9s
-		return K9sPalette
+	selected := bundledPalettes[0]
+	for _, p := range bundledPalettes {
+		if p.Name == name {
+			selected = p
+			break
+		}
+	}
+	return ColorPalette{
+		Blue:     tcell.GetColor(selected.Blue),
+		Cyan:     tcell.GetColor(selected.Cyan),
+		White:    tcell.GetColor(selected.White),
+		DimWhite: tcell.GetColor(selected.DimWhite),
+		Yellow:   tcell.GetColor(selected.Yellow),
+		Green:    tcell.GetColor(selected.Green),
+		Red:      tcell.GetColor(selected.Red),
+		Black:    tcell.GetColor(selected.Black),
 	}
 }
 
+// WithDefaultBackground returns the palette with the terminal's default background in place of its black, so the
+// panels are not painted over translucent terminal themes
+func (p ColorPalette) WithDefaultBackground() ColorPalette {
+	p.Black = tcell.ColorDefault
+	return p
+}
+
 // ApplyColorPalette applies the color palette to tview styles
 func ApplyColorPalette(palette ColorPalette) {
 	tview.Styles.PrimitiveBackgroundColor = palette.Black
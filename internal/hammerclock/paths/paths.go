@@ -0,0 +1,81 @@
+// Package paths locates the directories Hammerclock keeps its options, logs and game history in
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"hammerclock/internal/hammerclock/config"
+)
+
+// appDirName is the name of the per-user application directories
+const appDirName = "hammerclock"
+
+// PortableDirName is the directory next to the executable that holds all files in portable mode
+const PortableDirName = "hammerclock-data"
+
+// Dirs are the directories Hammerclock keeps its files in
+type Dirs struct {
+	Config string // Options file, its backups and rules.d
+	Data   string // CSV logs and game history
+}
+
+// Standard returns the per-user directories, e.g. ~/.config/hammerclock and ~/.local/share/hammerclock on Linux
+func Standard() (Dirs, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return Dirs{}, err
+	}
+	dataDir, err := userDataDir()
+	if err != nil {
+		return Dirs{}, err
+	}
+	return Dirs{
+		Config: filepath.Join(configDir, appDirName),
+		Data:   filepath.Join(dataDir, appDirName),
+	}, nil
+}
+
+// Portable returns a single directory next to the executable for all files, for running from a USB stick
+func Portable() (Dirs, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return Dirs{}, err
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	dir := filepath.Join(filepath.Dir(executable), PortableDirName)
+	return Dirs{Config: dir, Data: dir}, nil
+}
+
+// Create creates the directories if they don't exist yet
+func (d Dirs) Create() error {
+	if err := os.MkdirAll(d.Config, 0755); err != nil {
+		return err
+	}
+	return os.MkdirAll(d.Data, 0755)
+}
+
+// OptionsFile returns the path of the default options file in the config directory
+func (d Dirs) OptionsFile() string {
+	return filepath.Join(d.Config, hammerclockConfig.DefaultOptionsFilename)
+}
+
+// userDataDir returns the base directory for user data. On Linux and other Unix systems this follows
+// $XDG_DATA_HOME, on Windows and macOS it is the same as the config directory.
+func userDataDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows", "darwin", "ios", "plan9":
+		return os.UserConfigDir()
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
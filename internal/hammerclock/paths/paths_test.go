@@ -0,0 +1,41 @@
+package paths
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestStandardUsesUserDirectories(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG directories are only used on Linux")
+	}
+	base := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(base, "config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(base, "data"))
+
+	dirs, err := Standard()
+	if err != nil {
+		t.Fatalf("Expected standard directories, got error: %v", err)
+	}
+	if dirs.Config != filepath.Join(base, "config", "hammerclock") || dirs.Data != filepath.Join(base, "data", "hammerclock") {
+		t.Errorf("Unexpected standard directories: %+v", dirs)
+	}
+	if dirs.OptionsFile() != filepath.Join(dirs.Config, "default.json") {
+		t.Errorf("Expected the options file in the config directory, got %s", dirs.OptionsFile())
+	}
+
+	if err := dirs.Create(); err != nil {
+		t.Errorf("Expected the directories to be created, got error: %v", err)
+	}
+}
+
+func TestPortableKeepsFilesNextToExecutable(t *testing.T) {
+	dirs, err := Portable()
+	if err != nil {
+		t.Fatalf("Expected portable directories, got error: %v", err)
+	}
+	if dirs.Config != dirs.Data || filepath.Base(dirs.Config) != PortableDirName {
+		t.Errorf("Expected a single portable directory, got %+v", dirs)
+	}
+}
@@ -0,0 +1,19 @@
+// Package platform hides the differences between the consoles hammerclock runs in. Most of it only matters
+// on Windows, where the legacy console host is limited to 16 colors, leaves stale cells behind when its
+// window is resized and has no bell that players notice, so the taskbar button is flashed instead.
+package platform
+
+import "github.com/gdamore/tcell/v2"
+
+// SyncOnResize returns a function for tview's SetAfterDrawFunc that repaints the whole screen after the
+// console was resized. It only does so where the console needs it, elsewhere it does nothing.
+func SyncOnResize() func(screen tcell.Screen) {
+	var width, height int
+	return func(screen tcell.Screen) {
+		w, h := screen.Size()
+		if resyncOnResize && width != 0 && (w != width || h != height) {
+			screen.Sync()
+		}
+		width, height = w, h
+	}
+}
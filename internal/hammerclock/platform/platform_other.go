@@ -0,0 +1,14 @@
+//go:build !windows
+
+package platform
+
+// resyncOnResize is false because other terminals repaint themselves after a resize
+const resyncOnResize = false
+
+// LegacyConsole always returns false, as terminals on other platforms support at least 256 colors
+func LegacyConsole() bool {
+	return false
+}
+
+// FlashTaskbar does nothing on platforms without a taskbar to flash
+func FlashTaskbar() {}
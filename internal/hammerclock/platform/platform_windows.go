@@ -0,0 +1,77 @@
+//go:build windows
+
+package platform
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32             = syscall.NewLazyDLL("kernel32.dll")
+	user32               = syscall.NewLazyDLL("user32.dll")
+	procSetConsoleMode   = kernel32.NewProc("SetConsoleMode")
+	procGetConsoleWindow = kernel32.NewProc("GetConsoleWindow")
+	procFlashWindowEx    = user32.NewProc("FlashWindowEx")
+)
+
+// resyncOnResize is true because the Windows console keeps stale cells around after its window is resized
+const resyncOnResize = true
+
+// Windows console and window flags
+const (
+	enableVirtualTerminalProcessing = 0x0004
+	flashwAll                       = 0x0003 // Flash both the window caption and the taskbar button
+	flashwTimerNoFG                 = 0x000C // Keep flashing until the window comes to the foreground
+)
+
+// flashWInfo mirrors the Win32 FLASHWINFO structure
+type flashWInfo struct {
+	CbSize    uint32
+	Hwnd      uintptr
+	DwFlags   uint32
+	UCount    uint32
+	DwTimeout uint32
+}
+
+// LegacyConsole reports whether hammerclock runs in the legacy console host, which cannot process virtual
+// terminal sequences and is therefore limited to the 16 basic console colors
+func LegacyConsole() bool {
+	// Windows Terminal always supports true color
+	if os.Getenv("WT_SESSION") != "" {
+		return false
+	}
+
+	var mode uint32
+	if err := syscall.GetConsoleMode(syscall.Stdout, &mode); err != nil {
+		// Not a console, e.g. mintty, which handles colors itself
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return false
+	}
+
+	// Consoles that refuse virtual terminal processing are the legacy ones. The original mode is restored,
+	// tcell enables the mode itself when the screen is initialized.
+	if ok, _, _ := procSetConsoleMode.Call(uintptr(syscall.Stdout), uintptr(mode|enableVirtualTerminalProcessing)); ok == 0 {
+		return true
+	}
+	procSetConsoleMode.Call(uintptr(syscall.Stdout), uintptr(mode))
+	return false
+}
+
+// FlashTaskbar flashes the console window's taskbar button until the window is brought to the foreground
+func FlashTaskbar() {
+	hwnd, _, _ := procGetConsoleWindow.Call()
+	if hwnd == 0 {
+		return
+	}
+
+	info := flashWInfo{
+		Hwnd:    hwnd,
+		DwFlags: flashwAll | flashwTimerNoFG,
+	}
+	info.CbSize = uint32(unsafe.Sizeof(info))
+	procFlashWindowEx.Call(uintptr(unsafe.Pointer(&info)))
+}
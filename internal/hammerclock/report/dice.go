@@ -0,0 +1,70 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"hammerclock/internal/hammerclock/common"
+)
+
+// DiceStats are the dice a player rolled with the dice roller in a game, read from the action log
+type DiceStats struct {
+	Rolls    int
+	Dice     int
+	Sum      int     // Total of the dice, without the modifiers
+	Expected float64 // Average total of the dice
+}
+
+// diceRoll matches the log message of a roll, e.g. "Rolled 3d6+1 (turn 2, Shooting): 4 2 6 +1 = 13"
+var diceRoll = regexp.MustCompile(`^Rolled \d+d(\d+)(?:[+-]\d+)? \(.*\): ([\d ]+?)(?: [+-]\d+)? = -?\d+$`)
+
+// add counts the roll of a log message, if it is one
+func (s *DiceStats) add(message string) {
+	match := diceRoll.FindStringSubmatch(message)
+	if match == nil {
+		return
+	}
+	sides, _ := strconv.Atoi(match[1])
+	s.Rolls++
+	for _, field := range strings.Fields(match[2]) {
+		result, _ := strconv.Atoi(field)
+		s.Dice++
+		s.Sum += result
+		s.Expected += float64(sides+1) / 2
+	}
+}
+
+// PlayerDice returns the dice a player rolled, from their action log
+func PlayerDice(player *common.Player) DiceStats {
+	var stats DiceStats
+	for _, entry := range player.ActionLog {
+		stats.add(entry.Message)
+	}
+	return stats
+}
+
+// String summarizes the rolls, e.g. "12 rolls, 47 dice, 4% above average", or returns an empty string without any
+func (s DiceStats) String() string {
+	if s.Rolls == 0 {
+		return ""
+	}
+	luck := math.Round((float64(s.Sum)/s.Expected - 1) * 100)
+	average := "on average"
+	switch {
+	case luck > 0:
+		average = fmt.Sprintf("%.0f%% above average", luck)
+	case luck < 0:
+		average = fmt.Sprintf("%.0f%% below average", -luck)
+	}
+	rolls, dice := "rolls", "dice"
+	if s.Rolls == 1 {
+		rolls = "roll"
+	}
+	if s.Dice == 1 {
+		dice = "die"
+	}
+	return fmt.Sprintf("%d %s, %d %s, %s", s.Rolls, rolls, s.Dice, dice, average)
+}
@@ -0,0 +1,217 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"hammerclock/internal/hammerclock/common"
+)
+
+// Game is a game read back from the CSV log, with the entries of all its players in the order they were logged
+type Game struct {
+	Entries []common.LogEntry
+}
+
+// LogPlayer is what the log tells of a player's game
+type LogPlayer struct {
+	Name          string
+	Turns         int
+	VictoryPoints int
+	Casualties    string // e.g. "3 of 8 units destroyed, 450 of 2000 pts lost", empty without an army list
+	Dice          DiceStats
+}
+
+// Patterns of the log messages a game is summarized from
+var (
+	totalVP       = regexp.MustCompile(`\(total (-?\d+) VP\)$`)
+	gameSummary   = regexp.MustCompile(`^Game summary - played (.*)$`)
+	casualtiesMsg = regexp.MustCompile(`^Casualties - (.*)$`)
+	destroyedBy   = regexp.MustCompile(`^(.*) destroyed by (.*)$`)
+)
+
+// ReadLog reads the games of a CSV log in the order they were played. A game starts with its "Game started" entries,
+// so entries before the first game started are left out.
+func ReadLog(filename string) ([]Game, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	// Logs written before the event and table columns were added have fewer fields
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var games []Game
+	previous := ""
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && record[0] == "DateTime" {
+			continue
+		}
+		entry := logEntry(record)
+		// Each player logs the start, a game starts with the first of them
+		if entry.Message == "Game started" && previous != "Game started" {
+			games = append(games, Game{})
+		}
+		previous = entry.Message
+		if len(games) > 0 {
+			games[len(games)-1].Entries = append(games[len(games)-1].Entries, entry)
+		}
+	}
+	return games, nil
+}
+
+// logEntry converts a record of the CSV log into a log entry
+func logEntry(record []string) common.LogEntry {
+	field := func(i int) string {
+		if i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	turn, _ := strconv.Atoi(field(2))
+	table, _ := strconv.Atoi(field(6))
+	return common.LogEntry{DateTime: field(0), PlayerName: field(1), Turn: turn, Phase: field(3), Message: field(4),
+		Event: field(5), Table: table}
+}
+
+// Ended returns whether the game was ended, rather than the log stopping while it was played
+func (g Game) Ended() bool {
+	for _, entry := range g.Entries {
+		if strings.HasPrefix(entry.Message, "Game ended") {
+			return true
+		}
+	}
+	return false
+}
+
+// Players returns the players of the game in the order they appear in the log
+func (g Game) Players() []LogPlayer {
+	var players []LogPlayer
+	index := map[string]int{}
+	for _, entry := range g.Entries {
+		i, found := index[entry.PlayerName]
+		if !found {
+			i = len(players)
+			index[entry.PlayerName] = i
+			players = append(players, LogPlayer{Name: entry.PlayerName})
+		}
+		player := &players[i]
+		player.Turns = max(player.Turns, entry.Turn)
+		if match := totalVP.FindStringSubmatch(entry.Message); match != nil {
+			player.VictoryPoints, _ = strconv.Atoi(match[1])
+		}
+		if match := casualtiesMsg.FindStringSubmatch(entry.Message); match != nil {
+			player.Casualties = match[1]
+		}
+		player.Dice.add(entry.Message)
+	}
+	return players
+}
+
+// identity returns the event and table of the game, or an empty string outside events
+func (g Game) identity() string {
+	if len(g.Entries) == 0 || g.Entries[0].Event == "" {
+		return ""
+	}
+	identity := g.Entries[0].Event
+	if g.Entries[0].Table > 0 {
+		identity += fmt.Sprintf(", table %d", g.Entries[0].Table)
+	}
+	return identity
+}
+
+// played returns the game and pause time of the summary logged at the end of the game, or whether it was ended
+func (g Game) played() string {
+	for _, entry := range g.Entries {
+		if match := gameSummary.FindStringSubmatch(entry.Message); match != nil {
+			return "Played " + match[1]
+		}
+	}
+	if g.Ended() {
+		return "Ended"
+	}
+	return "Not ended"
+}
+
+// kills returns the destroyed units of the game with the units that destroyed them, e.g. "Bob: Intercessors" and
+// "Alice: Necron Warriors"
+func (g Game) kills() [][2]string {
+	var kills [][2]string
+	for _, entry := range g.Entries {
+		if match := destroyedBy.FindStringSubmatch(entry.Message); match != nil {
+			kills = append(kills, [2]string{entry.PlayerName + ": " + match[1], match[2]})
+		}
+	}
+	return kills
+}
+
+// period returns the times of the first and last entries of the game
+func (g Game) period() (string, string) {
+	if len(g.Entries) == 0 {
+		return "", ""
+	}
+	return g.Entries[0].DateTime, g.Entries[len(g.Entries)-1].DateTime
+}
+
+// LogText returns a plain text summary of a game of the log, for reading in the terminal
+func LogText(game Game) string {
+	var text strings.Builder
+	start, end := game.period()
+	fmt.Fprintf(&text, "Game of %s to %s", start, end)
+	if identity := game.identity(); identity != "" {
+		fmt.Fprintf(&text, " (%s)", identity)
+	}
+	fmt.Fprintf(&text, "\n%s\n\n", game.played())
+
+	table := tabwriter.NewWriter(&text, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(table, "Player\tTurns\tVP\tCasualties\tDice")
+	for _, player := range game.Players() {
+		_, _ = fmt.Fprintf(table, "%s\t%d\t%d\t%s\t%s\n", player.Name, player.Turns, player.VictoryPoints,
+			player.Casualties, player.Dice)
+	}
+	_ = table.Flush()
+
+	if kills := game.kills(); len(kills) > 0 {
+		text.WriteString("\nDestroyed units:\n")
+		for _, kill := range kills {
+			fmt.Fprintf(&text, "  %s by %s\n", kill[0], kill[1])
+		}
+	}
+	return text.String()
+}
+
+// LogMarkdown returns a Markdown summary of a game of the log, like the battle report of a finished game
+func LogMarkdown(game Game) string {
+	var text strings.Builder
+	text.WriteString("# Battle Report\n\n")
+	if identity := game.identity(); identity != "" {
+		text.WriteString(identity + ", ")
+	}
+	start, end := game.period()
+	fmt.Fprintf(&text, "%s to %s\n\n- %s\n", start, end, game.played())
+
+	text.WriteString("\n| Player | Turns | Victory points | Casualties | Dice |\n|--------|-------|----------------|------------|------|\n")
+	for _, player := range game.Players() {
+		fmt.Fprintf(&text, "| %s | %d | %d | %s | %s |\n", cell(player.Name), player.Turns, player.VictoryPoints,
+			cell(player.Casualties), player.Dice)
+	}
+
+	if kills := game.kills(); len(kills) > 0 {
+		text.WriteString("\n## Destroyed Units\n\n| Unit | Destroyed by |\n|------|--------------|\n")
+		for _, kill := range kills {
+			fmt.Fprintf(&text, "| %s | %s |\n", cell(kill[0]), cell(kill[1]))
+		}
+	}
+	return text.String()
+}
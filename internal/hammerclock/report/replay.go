@@ -0,0 +1,147 @@
+package report
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+	hammerclockConfig "hammerclock/internal/hammerclock/config"
+)
+
+// turnStarted is the pattern of the log message starting a player's turn
+var turnStarted = regexp.MustCompile(`^Turn \d+ started$`)
+
+// Replay plays a game of the log back in the time its entries were logged
+type Replay struct {
+	Game     Game
+	Position int           // Number of entries played back
+	At       time.Duration // Time played back since the first entry
+	times    []time.Duration
+}
+
+// ReplayClock is the state of a player at the time played back
+type ReplayClock struct {
+	Name  string
+	Turn  int
+	Phase string
+	Time  time.Duration // Time spent in the player's turns, without the pauses
+}
+
+// NewReplay returns the replay of a game, at its start
+func NewReplay(game Game) *Replay {
+	r := &Replay{Game: game, times: make([]time.Duration, len(game.Entries))}
+	var start, last time.Time
+	for i, entry := range game.Entries {
+		logged, err := time.ParseInLocation(hammerclockConfig.DefaultLogDateTimeFormat, entry.DateTime, time.Local)
+		// Entries without a readable time, or logged before the previous one, are played back with the previous one
+		if err != nil || logged.Before(last) {
+			logged = last
+		}
+		if start.IsZero() {
+			start = logged
+		}
+		last = logged
+		r.times[i] = logged.Sub(start)
+	}
+	return r
+}
+
+// Duration returns the time from the first to the last entry of the game
+func (r *Replay) Duration() time.Duration {
+	if len(r.times) == 0 {
+		return 0
+	}
+	return r.times[len(r.times)-1]
+}
+
+// Done reports whether all entries were played back
+func (r *Replay) Done() bool {
+	return r.Position >= len(r.Game.Entries)
+}
+
+// PlayTo plays the game back up to the given time since the first entry
+func (r *Replay) PlayTo(at time.Duration) {
+	r.At = min(max(at, 0), r.Duration())
+	r.Position = 0
+	for r.Position < len(r.times) && r.times[r.Position] <= r.At {
+		r.Position++
+	}
+}
+
+// Step plays back the given number of entries more, or fewer if it is negative
+func (r *Replay) Step(entries int) {
+	r.Position = min(max(r.Position+entries, 0), len(r.Game.Entries))
+	r.At = 0
+	if r.Position > 0 {
+		r.At = r.times[r.Position-1]
+	}
+}
+
+// NextTurn plays back up to the start of the next turn, or to the end of the game after the last turn
+func (r *Replay) NextTurn() {
+	for i := r.Position; i < len(r.Game.Entries); i++ {
+		if turnStarted.MatchString(r.Game.Entries[i].Message) {
+			r.Step(i + 1 - r.Position)
+			return
+		}
+	}
+	r.Step(len(r.Game.Entries))
+}
+
+// PrevTurn goes back to the start of the turn before the one played back, or to the start of the game
+func (r *Replay) PrevTurn() {
+	for i := r.Position - 2; i >= 0; i-- {
+		if turnStarted.MatchString(r.Game.Entries[i].Message) {
+			r.Step(i + 1 - r.Position)
+			return
+		}
+	}
+	r.Step(-r.Position)
+}
+
+// Played returns the entries played back
+func (r *Replay) Played() []common.LogEntry {
+	return r.Game.Entries[:r.Position]
+}
+
+// Clocks returns the turn, phase and time of the players at the time played back. The time between two entries
+// counts for the player whose turn it was, unless the game was paused.
+func (r *Replay) Clocks() []ReplayClock {
+	players := r.Game.Players()
+	clocks := make([]ReplayClock, len(players))
+	index := map[string]int{}
+	for i, player := range players {
+		clocks[i].Name = player.Name
+		index[player.Name] = i
+	}
+
+	active, paused := -1, false
+	var since time.Duration
+	count := func(until time.Duration) {
+		if active >= 0 && !paused {
+			clocks[active].Time += until - since
+		}
+		since = until
+	}
+	for i, entry := range r.Played() {
+		count(r.times[i])
+		player := index[entry.PlayerName]
+		clocks[player].Turn = entry.Turn
+		clocks[player].Phase = entry.Phase
+		switch {
+		case turnStarted.MatchString(entry.Message), entry.Message == "Game started" && active < 0:
+			active = player
+		case entry.Message == "Game paused":
+			paused = true
+		case entry.Message == "Game resumed":
+			paused = false
+		case strings.HasPrefix(entry.Message, "Game ended"):
+			active = -1
+		}
+	}
+	if !r.Done() {
+		count(r.At)
+	}
+	return clocks
+}
@@ -0,0 +1,158 @@
+// Package report writes battle reports: a Markdown summary of a finished game with the players' clocks, victory
+// points and army lists, to share after the game.
+package report
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+)
+
+// FileName returns the name of the battle report of a game that ended at the given time
+func FileName(ended time.Time) string {
+	return "battle-report-" + ended.Format("2006-01-02-150405") + ".md"
+}
+
+// Write writes the battle report of a game to a file
+func Write(filename string, model *common.Model, ended time.Time) error {
+	return os.WriteFile(filename, []byte(Markdown(model, ended)), 0644)
+}
+
+// Casualties are the losses of a player's army in a game
+type Casualties struct {
+	Units      int // Units in the army list
+	Destroyed  int // Units marked destroyed
+	Points     int // Points of the army list
+	PointsLost int // Points of the destroyed units
+}
+
+// PlayerCasualties returns the losses of a player's army, from the units marked destroyed
+func PlayerCasualties(player *common.Player) Casualties {
+	casualties := Casualties{Units: len(player.ArmyList)}
+	for _, unit := range player.ArmyList {
+		casualties.Points += unit.Points
+		if unit.Status == "destroyed" {
+			casualties.Destroyed++
+			casualties.PointsLost += unit.Points
+		}
+	}
+	return casualties
+}
+
+// Markdown returns the battle report of a game, with the casualties of the armies and a section per player
+func Markdown(model *common.Model, ended time.Time) string {
+	var text strings.Builder
+	text.WriteString("# Battle Report\n\n")
+	if identity := options.Identity(model.Options); identity != "" {
+		text.WriteString(identity + ", ")
+	}
+	text.WriteString(ended.Format("2006-01-02 15:04") + "\n\n")
+	if model.Options.Default >= 0 && model.Options.Default < len(model.Options.Rules) {
+		fmt.Fprintf(&text, "- Ruleset: %s\n", model.Options.Rules[model.Options.Default].Name)
+	}
+	fmt.Fprintf(&text, "- Game time: %v, paused %v\n", model.TotalGameTime.Truncate(time.Second),
+		model.PausedTime.Truncate(time.Second))
+	for _, result := range model.SequenceResults {
+		fmt.Fprintf(&text, "- %s\n", result)
+	}
+	writeCasualties(&text, model.Players)
+	writeMVPUnits(&text, model.Players)
+
+	for _, player := range model.Players {
+		fmt.Fprintf(&text, "\n## %s\n\n", player.Name)
+		if player.Faction != "" {
+			fmt.Fprintf(&text, "- Faction: %s\n", player.Faction)
+		}
+		fmt.Fprintf(&text, "- Clock: %v\n", player.TimeElapsed.Truncate(time.Second))
+		fmt.Fprintf(&text, "- Turns: %d\n", player.TurnCount)
+		fmt.Fprintf(&text, "- Victory points: %d\n", player.VictoryPoints)
+		if dice := PlayerDice(player); dice.Rolls > 0 {
+			fmt.Fprintf(&text, "- Dice: %s\n", dice)
+		}
+		writeArmy(&text, player.ArmyList)
+	}
+	return text.String()
+}
+
+// writeCasualties writes the table of the units and points each player lost, if any player has an army list
+func writeCasualties(text *strings.Builder, players []*common.Player) {
+	if !slices.ContainsFunc(players, func(player *common.Player) bool { return len(player.ArmyList) > 0 }) {
+		return
+	}
+	text.WriteString("\n## Casualties\n\n")
+	text.WriteString("| Player | Units | Destroyed | Points | Points lost |\n|--------|-------|-----------|--------|-------------|\n")
+	for _, player := range players {
+		c := PlayerCasualties(player)
+		fmt.Fprintf(text, "| %s | %d | %d | %d | %d |\n", cell(player.Name), c.Units, c.Destroyed, c.Points, c.PointsLost)
+	}
+}
+
+// Kills are the units an opposing unit destroyed in a game
+type Kills struct {
+	Unit   string // Opposing unit, as "player: unit"
+	Units  int    // Units destroyed
+	Points int    // Points of the units destroyed
+}
+
+// MVPUnits returns the units that destroyed other units, from the most points destroyed to the fewest
+func MVPUnits(players []*common.Player) []Kills {
+	var kills []Kills
+	for _, player := range players {
+		for _, unit := range player.ArmyList {
+			if unit.Status != "destroyed" || unit.DestroyedBy == "" {
+				continue
+			}
+			i := slices.IndexFunc(kills, func(k Kills) bool { return k.Unit == unit.DestroyedBy })
+			if i < 0 {
+				kills = append(kills, Kills{Unit: unit.DestroyedBy})
+				i = len(kills) - 1
+			}
+			kills[i].Units++
+			kills[i].Points += unit.Points
+		}
+	}
+	slices.SortStableFunc(kills, func(a, b Kills) int {
+		if a.Points != b.Points {
+			return b.Points - a.Points
+		}
+		return b.Units - a.Units
+	})
+	return kills
+}
+
+// writeMVPUnits writes the table of the units that destroyed other units, if any were recorded
+func writeMVPUnits(text *strings.Builder, players []*common.Player) {
+	kills := MVPUnits(players)
+	if len(kills) == 0 {
+		return
+	}
+	text.WriteString("\n## MVP Units\n\n| Unit | Units destroyed | Points destroyed |\n|------|-----------------|------------------|\n")
+	for _, k := range kills {
+		fmt.Fprintf(text, "| %s | %d | %d |\n", cell(k.Unit), k.Units, k.Points)
+	}
+}
+
+// writeArmy writes the table of a player's units with their status and notes, if the player has an army list
+func writeArmy(text *strings.Builder, units []common.Unit) {
+	if len(units) == 0 {
+		return
+	}
+	text.WriteString("\n| Unit | Points | Status | Note |\n|------|--------|--------|------|\n")
+	for _, unit := range units {
+		status := unit.Status
+		if unit.DestroyedBy != "" {
+			status += " by " + unit.DestroyedBy
+		}
+		fmt.Fprintf(text, "| %s | %d | %s | %s |\n", cell(unit.Name), unit.Points, cell(status), cell(unit.Note))
+	}
+}
+
+// cell escapes the text of a Markdown table cell
+func cell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
@@ -0,0 +1,196 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+	"hammerclock/internal/hammerclock/rules"
+)
+
+func TestMVPUnitsRankedByPointsDestroyed(t *testing.T) {
+	players := []*common.Player{
+		{Name: "Alice", ArmyList: []common.Unit{
+			{Name: "Intercessors", Points: 200, Status: "destroyed", DestroyedBy: "Bob: Boyz"},
+			{Name: "Scouts", Points: 70, Status: "destroyed", DestroyedBy: "Bob: Boyz"},
+			{Name: "Captain", Points: 80, Status: "destroyed", DestroyedBy: "Bob: Gorkanaut"},
+			{Name: "Lieutenant", Points: 65, Status: "destroyed"},
+		}},
+		{Name: "Bob", ArmyList: []common.Unit{
+			{Name: "Gretchin", Points: 40, Status: "destroyed", DestroyedBy: "Alice: Intercessors"},
+		}},
+	}
+	kills := MVPUnits(players)
+	expected := []Kills{
+		{Unit: "Bob: Boyz", Units: 2, Points: 270},
+		{Unit: "Bob: Gorkanaut", Units: 1, Points: 80},
+		{Unit: "Alice: Intercessors", Units: 1, Points: 40},
+	}
+	if !slices.Equal(kills, expected) {
+		t.Errorf("Expected %v, got %v", expected, kills)
+	}
+
+	text := Markdown(&common.Model{Players: players}, time.Now())
+	if !strings.Contains(text, "| Bob: Boyz | 2 | 270 |") || !strings.Contains(text, "| Intercessors | 200 | destroyed by Bob: Boyz |") {
+		t.Errorf("Expected the MVP units and the units that destroyed each unit in the report, got\n%s", text)
+	}
+}
+
+func TestBattleReportListsPlayersAndUnits(t *testing.T) {
+	model := &common.Model{
+		Options:       options.Options{Rules: []rules.Rules{{Name: "Warhammer 40K"}}, EventName: "Spring Cup", TableNumber: 4},
+		TotalGameTime: 2*time.Hour + 500*time.Millisecond,
+		PausedTime:    5 * time.Minute,
+		Players: []*common.Player{
+			{Name: "Alice", Faction: "Space Marines", TimeElapsed: time.Hour, TurnCount: 5, VictoryPoints: 72,
+				ArmyList: []common.Unit{
+					{Name: "Intercessors", Points: 200, Status: "destroyed"},
+					{Name: "Captain", Points: 80, Note: "carries the relic | warlord"},
+				}},
+			{Name: "Bob", TimeElapsed: 55 * time.Minute, TurnCount: 5, VictoryPoints: 65},
+		},
+	}
+	ended := time.Date(2026, 4, 12, 18, 30, 0, 0, time.UTC)
+	text := Markdown(model, ended)
+
+	for _, expected := range []string{
+		"Spring Cup, Table 4, 2026-04-12 18:30",
+		"- Ruleset: Warhammer 40K",
+		"- Game time: 2h0m0s, paused 5m0s",
+		"## Alice",
+		"- Faction: Space Marines",
+		"- Victory points: 72",
+		"| Intercessors | 200 | destroyed |  |",
+		`| Captain | 80 |  | carries the relic \| warlord |`,
+		"## Bob",
+		"| Alice | 2 | 1 | 280 | 200 |",
+		"| Bob | 0 | 0 | 0 | 0 |",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected the report to contain %q, got\n%s", expected, text)
+		}
+	}
+	if strings.Count(text, "| Unit |") != 1 {
+		t.Errorf("Expected an army table only for the player with an army list, got\n%s", text)
+	}
+
+	filename := filepath.Join(t.TempDir(), FileName(ended))
+	if err := Write(filename, model, ended); err != nil {
+		t.Fatalf("Failed to write the report: %v", err)
+	}
+	if data, _ := os.ReadFile(filename); string(data) != text {
+		t.Error("Expected the report file to contain the report")
+	}
+	if name := FileName(ended); name != "battle-report-2026-04-12-183000.md" {
+		t.Errorf("Expected the report to be named after the end of the game, got %s", name)
+	}
+
+	model.Players[0].ArmyList = nil
+	if text := Markdown(model, ended); strings.Contains(text, "Casualties") {
+		t.Errorf("Expected no casualties without army lists, got\n%s", text)
+	}
+}
+
+func TestReadLogSummarizesGames(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "logs.csv")
+	log := `DateTime,PlayerName,Turn,Phase,Message,Event,Table
+2025-06-01 09:00:00,Alice,0,Command,Game started,,0
+2025-06-01 09:00:00,Bob,0,Command,Game started,,0
+2025-06-01 09:05:00,Alice,0,Command,Game ended - reset to initial state,,0
+2025-06-01 14:00:00,Alice,0,Command,Game started,Cup,3
+2025-06-01 14:00:00,Bob,0,Command,Game started,Cup,3
+2025-06-01 14:30:00,Alice,1,Shooting,Scored 5 VP for Hold (total 5 VP),Cup,3
+2025-06-01 14:35:00,Alice,1,Shooting,"Rolled 2d6+1 (turn 1, Shooting): 6 4 +1 = 11",Cup,3
+2025-06-01 14:40:00,Bob,1,Fight,Intercessors destroyed by Alice: Boyz,Cup,3
+2025-06-01 15:10:00,Bob,2,Command,Scored 10 VP (total 10 VP),Cup,3
+2025-06-01 15:20:00,Alice,2,Command,Scored 3 VP (total 8 VP),Cup,3
+2025-06-01 16:00:00,Alice,0,Command,Game ended - reset to initial state,Cup,3
+2025-06-01 16:00:00,Alice,0,Command,"Game summary - played 1h55m0s, paused 5m0s",Cup,3
+2025-06-01 16:00:00,Bob,0,Command,Game ended,Cup,3
+2025-06-01 16:00:00,Bob,0,Command,"Casualties - 1 of 5 units destroyed, 200 of 1000 pts lost",Cup,3
+`
+	if err := os.WriteFile(logFile, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	games, err := ReadLog(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read the log: %v", err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("Expected 2 games, got %d", len(games))
+	}
+	expected := []LogPlayer{
+		{Name: "Alice", Turns: 2, VictoryPoints: 8, Dice: DiceStats{Rolls: 1, Dice: 2, Sum: 10, Expected: 7}},
+		{Name: "Bob", Turns: 2, VictoryPoints: 10, Casualties: "1 of 5 units destroyed, 200 of 1000 pts lost"},
+	}
+	if players := games[1].Players(); !slices.Equal(players, expected) {
+		t.Errorf("Expected %v, got %v", expected, players)
+	}
+
+	text := LogText(games[1])
+	for _, s := range []string{"(Cup, table 3)", "Played 1h55m0s, paused 5m0s", "Bob: Intercessors by Alice: Boyz",
+		"1 roll, 2 dice, 43% above average"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("Expected %q in the summary, got\n%s", s, text)
+		}
+	}
+	if markdown := LogMarkdown(games[1]); !strings.Contains(markdown, "| Alice | 2 | 8 |  |") {
+		t.Errorf("Expected the players table in the Markdown summary, got\n%s", markdown)
+	}
+}
+
+func TestReplayPlaysBackTurnsWithTimings(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "logs.csv")
+	log := `DateTime,PlayerName,Turn,Phase,Message,Event,Table
+2025-06-01 14:00:00,Alice,0,Command,Game started,,0
+2025-06-01 14:10:00,Alice,0,Command,Turn 0 ended,,0
+2025-06-01 14:10:00,Bob,1,Command,Turn 1 started,,0
+2025-06-01 14:20:00,Bob,1,Command,Game paused,,0
+2025-06-01 14:30:00,Bob,1,Command,Game resumed,,0
+2025-06-01 14:40:00,Bob,1,Shooting,Started phase: Shooting,,0
+2025-06-01 14:50:00,Bob,1,Shooting,Turn 1 ended,,0
+2025-06-01 14:50:00,Alice,1,Command,Turn 1 started,,0
+2025-06-01 15:00:00,Alice,0,Command,Game ended - reset to initial state,,0
+`
+	if err := os.WriteFile(logFile, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	games, err := ReadLog(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read the log: %v", err)
+	}
+
+	replay := NewReplay(games[0])
+	if replay.Duration() != time.Hour {
+		t.Fatalf("Expected the game to take an hour, got %v", replay.Duration())
+	}
+
+	// The pause does not count for Bob, the time since the last entry does
+	replay.PlayTo(45 * time.Minute)
+	expected := []ReplayClock{
+		{Name: "Alice", Turn: 0, Phase: "Command", Time: 10 * time.Minute},
+		{Name: "Bob", Turn: 1, Phase: "Shooting", Time: 25 * time.Minute},
+	}
+	if clocks := replay.Clocks(); !slices.Equal(clocks, expected) || replay.Position != 6 {
+		t.Errorf("Expected %v after 6 entries, got %v after %d", expected, clocks, replay.Position)
+	}
+
+	replay.NextTurn()
+	if replay.Position != 8 || replay.At != 50*time.Minute {
+		t.Errorf("Expected Alice's second turn to start after 50m, got entry %d at %v", replay.Position, replay.At)
+	}
+	replay.PrevTurn()
+	if replay.Position != 3 {
+		t.Errorf("Expected to go back to Bob's turn, got entry %d", replay.Position)
+	}
+	replay.Step(10)
+	if !replay.Done() || replay.Clocks()[0].Time != 20*time.Minute {
+		t.Errorf("Expected the whole game played back, got %v", replay.Clocks())
+	}
+}
@@ -1,116 +1,135 @@
 package rules
 
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
 // Rules defines the rules for a specific game, including the name, phases, and whether players are only taking
 // one turn (in that case, phases are being ignored).
 type Rules struct {
 	Name                 string   `json:"name"`
 	Phases               []string `json:"phases"`
 	OneTurnForAllPlayers bool     `json:"oneTurnForAllPlayers"`
+	SecondaryObjectives  []string `json:"secondaryObjectives,omitempty"` // Objectives scored at the end of each turn
+	PreGame              []string `json:"preGame,omitempty"`             // Steps worked through before the clocks start, e.g. "Weather"
+	PostGame             []string `json:"postGame,omitempty"`            // Steps worked through after the game ends, e.g. "Injuries"
+	TimeBudget           int      `json:"timeBudget,omitempty"`          // Minutes on each player's clock, overriding the timeBudget option
+
+	PhaseNames  map[string][]string   `json:"phaseNames,omitempty"`  // Translations of the phases by locale, e.g. "de", in order
+	PhaseLimits map[string]PhaseLimit `json:"phaseLimits,omitempty"` // Time limits of phases, by phase name
+	KillPoints  []KillBracket         `json:"killPoints,omitempty"`  // Victory points scored for destroying units
+
+	RandomTables []RandomTable `json:"randomTables,omitempty"` // Tables rolled on with the dice roller
+
+	Source string `json:"-"` // File the rules were merged from, empty for rules of the options file
 }
 
-// AllRules contains all the rules available in the application
-var AllRules = []Rules{
-	warhammerRules,
-	killTeamRules,
-	necromundaRules,
-	ageOfSigmarRules,
-	warcryRules,
-	bloodBowlRules,
-	bunnyKingdomRules,
-	chessRules,
+// PhaseLimit limits the time a player may spend in a phase, e.g. in strictly timed demo games.
+// A limit of 0 seconds is not enforced.
+type PhaseLimit struct {
+	Soft   int    `json:"soft,omitempty"`   // Seconds after which the player is warned
+	Hard   int    `json:"hard,omitempty"`   // Seconds after which the phase is over and the judge is called
+	Action string `json:"action,omitempty"` // What happens at the hard limit: "advance" (default) or "pause"
+}
+
+// KillBracket scores victory points for destroying a unit of at least the given points, e.g. 1 VP for units up to
+// 99 points and 2 VP from 100 points
+type KillBracket struct {
+	MinPoints     int `json:"minPoints"`     // Points the destroyed unit is worth at least
+	VictoryPoints int `json:"victoryPoints"` // Victory points scored for destroying it
 }
 
-// warhammerRules Warhammer rules
-var warhammerRules = Rules{
-	Name: "Warhammer 40K (10th Edition)",
-	Phases: []string{
-		"Command Phase",
-		"Movement Phase",
-		"Shooting Phase",
-		"Charge Phase",
-		"Fight Phase",
-		"End Phase",
-	},
-	OneTurnForAllPlayers: false,
+// RandomTable is a table rolled on during the game, e.g. "Perils of the Warp", with entries for the results of its
+// dice
+type RandomTable struct {
+	Name    string       `json:"name"`
+	Dice    string       `json:"dice,omitempty"` // Dice rolled on the table, e.g. "2d6", see DiceText
+	Entries []TableEntry `json:"entries"`
 }
 
-// killTeamRules Kill Team rules
-var killTeamRules = Rules{
-	Name: "Kill Team (2021)",
-	Phases: []string{
-		"Initiative Phase",
-		"Movement Phase",
-		"Shooting Phase",
-		"Fight Phase",
-		"Morale Phase",
-	},
-	OneTurnForAllPlayers: false,
+// TableEntry is an entry of a random table for the results from Min to Max. Entries without results are numbered in
+// order from 1, so the entries of a d6 table need none.
+type TableEntry struct {
+	Min  int    `json:"min,omitempty"`
+	Max  int    `json:"max,omitempty"` // Highest result of the entry, Min if not given
+	Text string `json:"text"`
 }
 
-// necromundaRules Necromunda rules
-var necromundaRules = Rules{
-	Name: "Necromunda (2022 edition)",
-	Phases: []string{
-		"Recovery Phase",
-		"Action Phase",
-		"End Phase",
-	},
-	OneTurnForAllPlayers: false,
+// DiceText returns the dice rolled on the table: its dice, or a die with a side per entry, e.g. "d6"
+func (t RandomTable) DiceText() string {
+	if t.Dice != "" {
+		return t.Dice
+	}
+	return fmt.Sprintf("d%d", len(t.Entries))
 }
 
-// ageOfSigmarRules Age of Sigmar rules
-var ageOfSigmarRules = Rules{
-	Name: "Age of Sigmar (4th Edition)",
-	Phases: []string{
-		"Start of Turn Phase",
-		"Hero Phase",
-		"Movement Phase",
-		"Shooting Phase",
-		"Charge Phase",
-		"Combat Phase",
-		"End of Turn Phase",
-	},
-	OneTurnForAllPlayers: false,
+// Entry returns the text of the entry of the table for a result, and whether there is one
+func (t RandomTable) Entry(result int) (string, bool) {
+	for i, entry := range t.Entries {
+		low, high := entry.Min, max(entry.Max, entry.Min)
+		if entry.Min == 0 && entry.Max == 0 {
+			low, high = i+1, i+1
+		}
+		if result >= low && result <= high {
+			return entry.Text, true
+		}
+	}
+	return "", false
 }
 
-// warcryRules Warcry rules
-var warcryRules = Rules{
-	Name: "Warcry (3rd edition)",
-	Phases: []string{
-		"Set Up Phase",
-		"Players' Phase (activating models alternately)",
-		"End Phase",
-	},
-	OneTurnForAllPlayers: false,
+// FindRandomTable returns the table of the given name, ignoring case, and whether there is one
+func FindRandomTable(tables []RandomTable, name string) (RandomTable, bool) {
+	for _, table := range tables {
+		if strings.EqualFold(table.Name, strings.TrimSpace(name)) {
+			return table, true
+		}
+	}
+	return RandomTable{}, false
 }
 
-// bloodBowlRules Blood Bowl rules
-var bloodBowlRules = Rules{
-	Name: "Blood Bowl (2020 edition)",
-	Phases: []string{
-		"Pre-Match Phase",
-		"Kick-Off Phase",
-		"Team Turn (both teams alternate)",
-		"End of Turn Phase",
-		"Post-Match Phase",
-	},
-	OneTurnForAllPlayers: false,
+// LocalizedPhases returns the phases of the rules in the locale, e.g. "de" or "de-AT": the translation of the locale,
+// else that of its language, else the phases as defined. Translations that do not name every phase are ignored.
+func LocalizedPhases(r Rules, locale string) []string {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	language, _, _ := strings.Cut(locale, "-")
+	for _, l := range []string{locale, language} {
+		if names := r.PhaseNames[l]; l != "" && len(names) == len(r.Phases) {
+			return names
+		}
+	}
+	return r.Phases
 }
 
-// bunnyKingdomRules Bunny Kingdom rules
-var bunnyKingdomRules = Rules{
-	Name: "Bunny Kingdom",
-	Phases: []string{"Draft Phase (players select cards)",
-		"Build Phase (place cards on the board)",
-		"Scoring Phase (calculate points based on card placement)"},
-	OneTurnForAllPlayers: false,
+// KillScore returns the victory points scored for destroying a unit of the given points: those of the bracket with
+// the highest minimum the unit reaches, or 0 if it reaches none
+func KillScore(brackets []KillBracket, points int) int {
+	score, best := 0, -1
+	for _, bracket := range brackets {
+		if points >= bracket.MinPoints && bracket.MinPoints > best {
+			score, best = bracket.VictoryPoints, bracket.MinPoints
+		}
+	}
+	return score
 }
 
-// chessRules Chess rules
-var chessRules = Rules{
-	Name:                 "Chess",
-	Phases:               []string{},
-	OneTurnForAllPlayers: true,
+// defaultRulesJSON holds the bundled rulesets, embedded so they are available without any files on disk
+//
+//go:embed defaults.json
+var defaultRulesJSON []byte
+
+// AllRules contains all the rules available in the application
+var AllRules = mustParseRules(defaultRulesJSON)
+
+// mustParseRules parses the bundled rulesets, panicking if the embedded file is invalid
+func mustParseRules(data []byte) []Rules {
+	var rules []Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		panic("invalid bundled rulesets: " + err.Error())
+	}
+	return rules
 }
 
 // RulesetNames returns the names of the rulesets
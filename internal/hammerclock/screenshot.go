@@ -0,0 +1,115 @@
+package hammerclock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/logging"
+)
+
+// sgrAttributes are the ANSI select graphic rendition codes of the text attributes
+var sgrAttributes = []struct {
+	attr tcell.AttrMask
+	code string
+}{
+	{tcell.AttrBold, "1"},
+	{tcell.AttrDim, "2"},
+	{tcell.AttrItalic, "3"},
+	{tcell.AttrUnderline, "4"},
+	{tcell.AttrBlink, "5"},
+	{tcell.AttrReverse, "7"},
+	{tcell.AttrStrikeThrough, "9"},
+}
+
+// ScreenANSI returns the content of the screen as text, a line per row, with the colors and attributes of the cells
+// as ANSI escape sequences. Printing it in a terminal with true colors shows the screen as it was drawn.
+func ScreenANSI(screen tcell.Screen) string {
+	var text strings.Builder
+	width, height := screen.Size()
+	for y := 0; y < height; y++ {
+		last := ""
+		for x := 0; x < width; {
+			primary, combining, style, cellWidth := screen.GetContent(x, y)
+			if sgr := sgrSequence(style); sgr != last {
+				text.WriteString(sgr)
+				last = sgr
+			}
+			if primary == 0 {
+				primary = ' '
+			}
+			text.WriteRune(primary)
+			text.WriteString(string(combining))
+			x += max(cellWidth, 1)
+		}
+		text.WriteString("\x1b[0m\n")
+	}
+	return text.String()
+}
+
+// DrawANSI draws a primitive, e.g. the main view, on a screen of the given size and returns it as ScreenANSI does
+func DrawANSI(primitive tview.Primitive, width, height int) string {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		return ""
+	}
+	defer screen.Fini()
+	screen.SetSize(width, height)
+	primitive.SetRect(0, 0, width, height)
+	primitive.Draw(screen)
+	screen.Show()
+	return ScreenANSI(screen)
+}
+
+// sgrSequence returns the ANSI escape sequence that sets the colors and attributes of a style
+func sgrSequence(style tcell.Style) string {
+	fg, bg, attrs := style.Decompose()
+	codes := []string{"0"}
+	for _, a := range sgrAttributes {
+		if attrs&a.attr != 0 {
+			codes = append(codes, a.code)
+		}
+	}
+	if r, g, b := fg.RGB(); fg.Valid() && r >= 0 {
+		codes = append(codes, fmt.Sprintf("38;2;%d;%d;%d", r, g, b))
+	}
+	if r, g, b := bg.RGB(); bg.Valid() && r >= 0 {
+		codes = append(codes, fmt.Sprintf("48;2;%d;%d;%d", r, g, b))
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+// SaveScreenshot writes the screen as shown, with its colors, to a file in the directory and returns the file name
+func SaveScreenshot(screen tcell.Screen, dir string, now time.Time) (string, error) {
+	filename := filepath.Join(dir, "screenshot-"+now.Format("2006-01-02-150405")+".ans")
+	return filename, os.WriteFile(filename, []byte(ScreenANSI(screen)), 0644)
+}
+
+// handleScreenshot asks for the screen to be saved, once the messages before have been rendered
+func handleScreenshot(model common.Model) (common.Model, Command) {
+	return model, func() common.Message {
+		return &common.ScreenshotMsg{}
+	}
+}
+
+// handleScreenshotSaved tells the active player where the screenshot was saved, or why it could not be
+func handleScreenshotSaved(msg *common.ScreenshotSavedMsg, model common.Model) (common.Model, Command) {
+	newModel := copyPlayers(model)
+	for _, player := range newModel.Players {
+		if !player.IsTurn {
+			continue
+		}
+		if msg.Err != nil {
+			logging.AddLogEntry(player, &newModel, common.LogTypeWarning, "Screenshot failed: %v", msg.Err)
+		} else {
+			logging.AddLogEntry(player, &newModel, common.LogTypeGame, "Screenshot saved to %s", msg.File)
+		}
+		break
+	}
+	return newModel, noCommand
+}
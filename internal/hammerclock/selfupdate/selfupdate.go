@@ -0,0 +1,164 @@
+// Package selfupdate replaces the running executable with the binary of the latest GitHub release, for installs
+// without a Go toolchain. The release carries a binary per platform and a checksums.txt in the format of sha256sum,
+// and the download is only installed if its checksum matches.
+package selfupdate
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// LatestReleaseURL is the GitHub API endpoint of the latest release
+const LatestReleaseURL = "https://api.github.com/repos/itworks99/hammerclock/releases/latest"
+
+// ChecksumsFile is the name of the release asset listing the SHA-256 checksums of the binaries
+const ChecksumsFile = "checksums.txt"
+
+// downloadTimeout bounds each request, binaries included
+const downloadTimeout = 2 * time.Minute
+
+// Release is a GitHub release with its downloadable files
+type Release struct {
+	Tag    string  `json:"tag_name"`
+	Assets []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Version returns the version of the release, its tag without the leading v
+func (r Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// asset returns the download URL of the named file of the release
+func (r Release) asset(name string) (string, error) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL, nil
+		}
+	}
+	return "", fmt.Errorf("release %s has no %s", r.Tag, name)
+}
+
+// AssetName returns the name of the release binary for an operating system and architecture, e.g.
+// hammerclock-linux-amd64 or hammerclock-windows-amd64.exe
+func AssetName(goos, goarch string) string {
+	name := "hammerclock-" + goos + "-" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Latest fetches the latest release from the GitHub API at url
+func Latest(url string) (Release, error) {
+	var release Release
+	data, err := fetch(url)
+	if err != nil {
+		return release, err
+	}
+	err = json.Unmarshal(data, &release)
+	return release, err
+}
+
+// Install downloads the binary of the release for this platform, checks it against the release checksums and
+// replaces the executable with it
+func Install(release Release, executable string) error {
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	binaryURL, err := release.asset(name)
+	if err != nil {
+		return err
+	}
+	checksumsURL, err := release.asset(ChecksumsFile)
+	if err != nil {
+		return err
+	}
+
+	checksums, err := fetch(checksumsURL)
+	if err != nil {
+		return err
+	}
+	expected, err := checksum(checksums, name)
+	if err != nil {
+		return err
+	}
+	binary, err := fetch(binaryURL)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(binary)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return fmt.Errorf("checksum of %s does not match, expected %s, got %s", name, expected, actual)
+	}
+	return replace(executable, binary)
+}
+
+// checksum returns the checksum of the named file in a list in the format of sha256sum
+func checksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sha256sum marks files read in binary mode with an asterisk
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s", name)
+}
+
+// replace writes the binary next to the executable and moves it in its place. The running executable cannot be
+// overwritten on Windows, but it can be renamed, so it is moved aside first and removed on the next update.
+func replace(executable string, binary []byte) error {
+	dir := filepath.Dir(executable)
+	newFile := filepath.Join(dir, "."+filepath.Base(executable)+".new")
+	if err := os.WriteFile(newFile, binary, 0755); err != nil {
+		return err
+	}
+	oldFile := filepath.Join(dir, "."+filepath.Base(executable)+".old")
+	_ = os.Remove(oldFile)
+	if runtime.GOOS == "windows" {
+		if err := os.Rename(executable, oldFile); err != nil {
+			_ = os.Remove(newFile)
+			return err
+		}
+	}
+	if err := os.Rename(newFile, executable); err != nil {
+		_ = os.Remove(newFile)
+		if runtime.GOOS == "windows" {
+			_ = os.Rename(oldFile, executable)
+		}
+		return err
+	}
+	return nil
+}
+
+// fetch returns the body of the response to a GET request for the URL
+func fetch(url string) ([]byte, error) {
+	client := http.Client{Timeout: downloadTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %s from %s", resp.Status, url)
+	}
+	return io.ReadAll(resp.Body)
+}
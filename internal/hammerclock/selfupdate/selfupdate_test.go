@@ -0,0 +1,74 @@
+package selfupdate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// releaseServer serves a release with the binary for this platform, listed in checksums.txt with the given checksum
+func releaseServer(t *testing.T, binary []byte, sum string) *httptest.Server {
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"tag_name": "v0.2", "assets": [{"name": %q, "browser_download_url": "%s/bin"},
+			{"name": "checksums.txt", "browser_download_url": "%s/sums"}]}`, name, server.URL, server.URL)
+	})
+	mux.HandleFunc("/bin", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(binary) })
+	mux.HandleFunc("/sums", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "0000  hammerclock-plan9-386\n%s  %s\n", sum, name)
+	})
+	return server
+}
+
+func TestInstallReplacesExecutable(t *testing.T) {
+	binary := []byte("new hammerclock")
+	sum := sha256.Sum256(binary)
+	server := releaseServer(t, binary, hex.EncodeToString(sum[:]))
+
+	release, err := Latest(server.URL + "/latest")
+	if err != nil {
+		t.Fatalf("Failed to fetch the latest release: %v", err)
+	}
+	if release.Version() != "0.2" {
+		t.Errorf("Expected version 0.2, got %s", release.Version())
+	}
+
+	executable := filepath.Join(t.TempDir(), "hammerclock")
+	if err := os.WriteFile(executable, []byte("old hammerclock"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Install(release, executable); err != nil {
+		t.Fatalf("Failed to install the release: %v", err)
+	}
+	if data, _ := os.ReadFile(executable); string(data) != string(binary) {
+		t.Errorf("Expected the executable to be replaced, got %q", data)
+	}
+}
+
+func TestInstallRejectsChecksumMismatch(t *testing.T) {
+	server := releaseServer(t, []byte("tampered hammerclock"), "0123abcd")
+	release, err := Latest(server.URL + "/latest")
+	if err != nil {
+		t.Fatalf("Failed to fetch the latest release: %v", err)
+	}
+
+	executable := filepath.Join(t.TempDir(), "hammerclock")
+	if err := os.WriteFile(executable, []byte("old hammerclock"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Install(release, executable); err == nil {
+		t.Error("Expected the download to be rejected")
+	}
+	if data, _ := os.ReadFile(executable); string(data) != "old hammerclock" {
+		t.Errorf("Expected the executable to be kept, got %q", data)
+	}
+}
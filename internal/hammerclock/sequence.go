@@ -0,0 +1,81 @@
+package hammerclock
+
+import (
+	"slices"
+	"strings"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/logging"
+)
+
+// Sequences of steps a ruleset can define around a game
+const (
+	PreGame  = "PreGame"  // Worked through before the clocks start, e.g. the weather in Blood Bowl
+	PostGame = "PostGame" // Worked through after the game ends, e.g. the post-battle actions in Necromunda
+)
+
+// SequenceSteps returns the steps of a sequence of the current ruleset
+func SequenceSteps(model common.Model, sequence string) []string {
+	if model.Options.Default < 0 || model.Options.Default >= len(model.Options.Rules) {
+		return nil
+	}
+	rules := model.Options.Rules[model.Options.Default]
+	if sequence == PreGame {
+		return rules.PreGame
+	}
+	return rules.PostGame
+}
+
+// showSequenceStep returns a command showing a step of a sequence
+func showSequenceStep(sequence string, step int) Command {
+	return func() common.Message {
+		return &common.ShowModalMsg{Type: sequence, Step: step}
+	}
+}
+
+// handleStart starts, pauses or resumes the game like handleStartGame, but works through the pre-game sequence of the
+// ruleset before the game starts
+func handleStart(model common.Model) (common.Model, Command) {
+	if !model.GameStarted && !model.PreGameDone && len(SequenceSteps(model, PreGame)) > 0 {
+		return model, showSequenceStep(PreGame, 0)
+	}
+	return handleStartGame(model)
+}
+
+// handleCompleteStep records the result of a step of a sequence in the first player's action log and shows the next
+// step. After the last pre-game step the game starts, after the last post-game step it ends. The game ends with a
+// separate message, so the results of the post-game steps are part of the game recorded when it ends.
+func handleCompleteStep(msg *common.CompleteStepMsg, model common.Model) (common.Model, Command) {
+	steps := SequenceSteps(model, msg.Sequence)
+	if msg.Step < 0 || msg.Step >= len(steps) || len(model.Players) == 0 {
+		return model, noCommand
+	}
+
+	newModel := copyPlayers(model)
+	if result := strings.TrimSpace(msg.Result); result != "" {
+		result = steps[msg.Step] + ": " + result
+		newModel.SequenceResults = append(slices.Clone(model.SequenceResults), result)
+		title := "Pre-game"
+		if msg.Sequence == PostGame {
+			title = "Post-game"
+		}
+		logging.AddLogEntry(newModel.Players[0], &newModel, common.LogTypeGame, "%s - %s", title, result)
+	}
+
+	next := msg.Step + 1
+	if msg.Skip {
+		next = len(steps)
+	}
+	if next < len(steps) {
+		return newModel, showSequenceStep(msg.Sequence, next)
+	}
+
+	if msg.Sequence == PostGame {
+		return newModel, func() common.Message {
+			return &common.EndGameMsg{}
+		}
+	}
+	newModel.PreGameDone = true
+	newModel, _ = handleStartGame(newModel)
+	return newModel, noCommand
+}
@@ -0,0 +1,241 @@
+package hammerclock
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// MaxGames is the number of games a session can hold, one for each number key
+const MaxGames = 9
+
+// Session holds the independent games of one hammerclock instance, e.g. for an organizer supervising a few casual
+// tables. Each game has its own players, clocks and logs and keeps running in the background; one of them is shown.
+type Session struct {
+	Games  []common.Model
+	Active int // Index of the game shown
+
+	ids          []int              // Identifiers of the games, so messages reach their game while other games are closed
+	pending      [][]common.Message // Messages of the games in the background, delivered when they are shown
+	autosaveFile string             // Autosave and save files of the first game, numbered for the others
+	saveFile     string
+}
+
+// NewSession creates a session with a single game
+func NewSession(model common.Model) Session {
+	return Session{Games: []common.Model{model}, ids: []int{1}, pending: make([][]common.Message, 1),
+		autosaveFile: model.AutosaveFile, saveFile: model.SaveFile}
+}
+
+// Current returns the game shown
+func (s *Session) Current() *common.Model {
+	return &s.Games[s.Active]
+}
+
+// ID returns the identifier of the game at index, which stays the same while other games are opened and closed
+func (s *Session) ID(index int) int {
+	return s.ids[index]
+}
+
+// Game returns the game with the identifier, if it is still open
+func (s *Session) Game(id int) (*common.Model, bool) {
+	index := slices.Index(s.ids, id)
+	if index < 0 {
+		return nil, false
+	}
+	return &s.Games[index], true
+}
+
+// Update processes a message and returns an updated session and a command to execute. Ticks advance every game and
+// the organizer's round timer is shown in every game, CTRL+T, CTRL+W and the number keys manage the games, and all other messages go to the game shown.
+func (s Session) Update(msg common.Message) (Session, Command) {
+	switch msg := msg.(type) {
+	case *common.TickMsg, *common.RoundTimerMsg:
+		return s.updateAll(msg)
+	case *common.BackgroundMsg:
+		return s.queue(msg)
+	case *common.KeyPressMsg:
+		if newSession, cmd, handled := s.handleGameKey(msg); handled {
+			return newSession, cmd
+		}
+	}
+
+	newSession := s.clone()
+	var cmd Command
+	newSession.Games[s.Active], cmd = Update(msg, s.Games[s.Active])
+	return newSession, cmd
+}
+
+// clone returns a copy of the session that can be changed without changing the original
+func (s Session) clone() Session {
+	return Session{
+		Games:        slices.Clone(s.Games),
+		Active:       s.Active,
+		ids:          slices.Clone(s.ids),
+		pending:      slices.Clone(s.pending),
+		autosaveFile: s.autosaveFile,
+		saveFile:     s.saveFile,
+	}
+}
+
+// updateAll sends a message to every game. The commands of the games in the background are run with the command of
+// the game shown, and their messages come back as BackgroundMsg to wait until their game is shown.
+func (s Session) updateAll(msg common.Message) (Session, Command) {
+	newSession := s.clone()
+	cmd := Command(noCommand)
+	var background []Command
+	for i, game := range s.Games {
+		var gameCmd Command
+		newSession.Games[i], gameCmd = Update(msg, game)
+		if i == s.Active {
+			cmd = gameCmd
+			continue
+		}
+		id := s.ids[i]
+		background = append(background, func() common.Message {
+			if msg := gameCmd(); msg != nil {
+				return &common.BackgroundMsg{Game: id, Msg: msg}
+			}
+			return nil
+		})
+	}
+	if len(background) == 0 {
+		return newSession, cmd
+	}
+	return newSession, batch(append([]Command{cmd}, background...)...)
+}
+
+// queue keeps the message of a game in the background until the game is shown and rings the bell to alert the
+// players. Messages of games closed in the meantime are dropped, and those of the game shown are delivered.
+func (s Session) queue(msg *common.BackgroundMsg) (Session, Command) {
+	index := slices.Index(s.ids, msg.Game)
+	switch {
+	case index < 0:
+		return s, noCommand
+	case index == s.Active:
+		return s, func() common.Message {
+			return msg.Msg
+		}
+	}
+
+	newSession := s.clone()
+	if _, bell := msg.Msg.(*common.BellMsg); !bell {
+		newSession.pending[index] = append(slices.Clone(s.pending[index]), msg.Msg)
+	}
+	return newSession, func() common.Message {
+		return &common.BellMsg{}
+	}
+}
+
+// handleGameKey opens a new game with CTRL+T, closes the game shown with CTRL+W and shows a game with its number
+func (s Session) handleGameKey(msg *common.KeyPressMsg) (Session, Command, bool) {
+	switch {
+	case msg.Key == tcell.KeyCtrlT:
+		if len(s.Games) >= MaxGames {
+			return s, noCommand, true
+		}
+		newSession := s.clone()
+		id := slices.Max(s.ids) + 1
+		game := newGame(s.Games[s.Active])
+		game.AutosaveFile = gameFile(s.autosaveFile, id)
+		game.SaveFile = gameFile(s.saveFile, id)
+		newSession.Games = append(newSession.Games, game)
+		newSession.ids = append(newSession.ids, id)
+		newSession.pending = append(newSession.pending, nil)
+		return newSession.show(len(newSession.Games) - 1)
+	case msg.Key == tcell.KeyCtrlW:
+		// Running games are not closed, so a stray key does not lose a game in progress
+		if len(s.Games) == 1 || s.Games[s.Active].GameStarted || kioskLocked(s.Games[s.Active]) {
+			return s, noCommand, true
+		}
+		newSession := s.clone()
+		newSession.Games = slices.Delete(newSession.Games, s.Active, s.Active+1)
+		newSession.ids = slices.Delete(newSession.ids, s.Active, s.Active+1)
+		newSession.pending = slices.Delete(newSession.pending, s.Active, s.Active+1)
+		return newSession.show(min(s.Active, len(newSession.Games)-1))
+	case msg.Key == tcell.KeyRune && msg.Rune >= '1' && msg.Rune <= '9' && len(s.Games) > 1:
+		index := int(msg.Rune - '1')
+		if index >= len(s.Games) {
+			return s, noCommand, true
+		}
+		return s.show(index)
+	}
+	return s, noCommand, false
+}
+
+// show shows the game at index and delivers the messages it queued in the background, e.g. to show the dialog of a
+// flag fall, then asks what to do with the time lost to a suspend it noticed in the background
+func (s Session) show(index int) (Session, Command, bool) {
+	s = s.clone()
+	s.Active = index
+	msgs := s.pending[index]
+	s.pending[index] = nil
+	if s.Games[index].SuspendedFor > 0 {
+		msgs = append(slices.Clone(msgs), &common.ShowModalMsg{Type: "SuspendResolve"})
+	}
+	if len(msgs) == 0 {
+		return s, noCommand, true
+	}
+	return s, func() common.Message {
+		return &common.BatchMsg{Msgs: msgs}
+	}, true
+}
+
+// TabsText returns the numbers of the session's games with the game shown highlighted, e.g. "1 [2] 3",
+// or an empty string for a single game
+func (s *Session) TabsText() string {
+	if len(s.Games) < 2 {
+		return ""
+	}
+	tabs := make([]string, len(s.Games))
+	for i := range s.Games {
+		tabs[i] = fmt.Sprintf(" %d ", i+1)
+		if i == s.Active {
+			tabs[i] = fmt.Sprintf("[black:white] %d [-:-]", i+1)
+		}
+	}
+	return strings.Join(tabs, "")
+}
+
+// gameFile returns the file of the game with the identifier: the file itself for the first game, and the file numbered
+// after the game for the others, e.g. autosave-2.json, so the games do not overwrite each other's files
+func gameFile(filename string, id int) string {
+	if filename == "" || id == 1 {
+		return filename
+	}
+	ext := filepath.Ext(filename)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(filename, ext), id, ext)
+}
+
+// newGame creates a game with the options and players of the given game, at their initial state
+func newGame(model common.Model) common.Model {
+	game := NewModel()
+	game.Options = options.Copy(model.Options)
+	game.OptionsFile = model.OptionsFile
+	game.AuditFile = model.AuditFile
+	game.Kiosk = model.Kiosk
+	game.SavedOptions = options.Copy(model.SavedOptions)
+	game.FileOptions = options.Copy(model.FileOptions)
+	game.Phases = RulesetPhases(model.Options)
+	game.CurrentColorPalette = model.CurrentColorPalette
+	game.RoundEnds = model.RoundEnds
+	game.Announcement = model.Announcement
+	game.Players = make([]*common.Player, len(model.Players))
+	for i, player := range model.Players {
+		game.Players[i] = &common.Player{
+			Name:      player.Name,
+			Banner:    player.Banner,
+			Faction:   player.Faction,
+			IsTurn:    i == 0,
+			ActionLog: []common.LogEntry{},
+			ArmyList:  ProfileArmy(model.Options, player.Name),
+		}
+	}
+	return game
+}
@@ -0,0 +1,123 @@
+package spectate
+
+import (
+	"slices"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// quitKeys are the keys a spectator leaves with: q, Ctrl+C and Ctrl+D
+var quitKeys = []byte{'q', 'Q', 0x03, 0x04}
+
+// maxTerminalSize is the largest width or height drawn for a spectator
+const maxTerminalSize = 1000
+
+// session is a spectator's SSH connection with its session channel
+type session struct {
+	channel   ssh.Channel // Session channel, set before the shell starts
+	server    *Server
+	spectator *spectator
+	started   chan struct{} // Closed when the spectator's shell starts
+	quit      chan struct{} // Closed when the spectator presses a quit key
+}
+
+// ptyRequest is the payload of a "pty-req" request (RFC 4254, section 6.2)
+type ptyRequest struct {
+	Term                                     string
+	Columns, Rows, WidthPixels, HeightPixels uint32
+	Modes                                    string
+}
+
+// windowChange is the payload of a "window-change" request (RFC 4254, section 6.7)
+type windowChange struct {
+	Columns, Rows, WidthPixels, HeightPixels uint32
+}
+
+// handleChannels opens the session channel, refusing any other channel, until the connection closes
+func (s *session) handleChannels(channels <-chan ssh.NewChannel) {
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" || s.channel != nil {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "only a single session is supported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		s.channel = channel
+		go s.handleRequests(requests)
+		go s.readKeys()
+	}
+}
+
+// handleRequests handles the requests on the session channel: the terminal's size and the start of the shell
+func (s *session) handleRequests(requests <-chan *ssh.Request) {
+	shell := false
+	for request := range requests {
+		ok := false
+		switch request.Type {
+		case "pty-req":
+			// The terminal type is ignored, the frames are written for any terminal with true colors
+			var pty ptyRequest
+			if ok = ssh.Unmarshal(request.Payload, &pty) == nil; ok {
+				s.resize(int(pty.Columns), int(pty.Rows))
+			}
+		case "window-change":
+			var size windowChange
+			if ok = ssh.Unmarshal(request.Payload, &size) == nil; ok {
+				s.resize(int(size.Columns), int(size.Rows))
+			}
+		case "shell":
+			ok = !shell
+		}
+		if request.WantReply {
+			_ = request.Reply(ok, nil)
+		}
+		if request.Type == "shell" && ok {
+			shell = true
+			close(s.started)
+		}
+	}
+}
+
+// resize draws the next frames at the size of the spectator's terminal
+func (s *session) resize(width, height int) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+	s.server.mu.Lock()
+	s.spectator.width, s.spectator.height = min(width, maxTerminalSize), min(height, maxTerminalSize)
+	s.server.mu.Unlock()
+}
+
+// readKeys reads the keys the spectator presses, which only leave the game
+func (s *session) readKeys() {
+	buf := make([]byte, 256)
+	for {
+		n, err := s.channel.Read(buf)
+		if slices.ContainsFunc(buf[:n], func(b byte) bool { return slices.Contains(quitKeys, b) }) {
+			close(s.quit)
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// write sends text to the spectator's terminal, waiting while their window is full
+func (s *session) write(text string) error {
+	_, err := s.channel.Write([]byte(text))
+	return err
+}
+
+// end restores the spectator's terminal and closes the session channel
+func (s *session) end() {
+	if s.write(resetScreen) != nil {
+		return
+	}
+	exitStatus := ssh.Marshal(struct{ Status uint32 }{0})
+	_, _ = s.channel.SendRequest("exit-status", false, exitStatus)
+	_ = s.channel.CloseWrite()
+	_ = s.channel.Close()
+}
@@ -0,0 +1,263 @@
+// Package spectate lets spectators, e.g. the opponent on another laptop or a stream producer, watch the game over
+// SSH. Each spectator sees the main view drawn at the size of their terminal and cannot control the game.
+package spectate
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// HostKeyFileName is the name of the file keeping the server's host key, so spectators are not warned of a new key at
+// every start
+const HostKeyFileName = "spectate-host-key.pem"
+
+// AuthorizedKeysFileName is the name of the file with the public keys of the spectators allowed to watch from other
+// machines, in the format of OpenSSH's authorized_keys
+const AuthorizedKeysFileName = "spectate-authorized-keys"
+
+// Size of the spectator's terminal before it tells its own
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// handshakeTimeout is the time a client has to exchange the keys and open the session
+const handshakeTimeout = 30 * time.Second
+
+// Terminal sequences written around the frames
+const (
+	hideCursor  = "\x1b[?25l"
+	clearScreen = "\x1b[2J"
+	cursorHome  = "\x1b[H"
+	resetScreen = "\x1b[0m\x1b[2J\x1b[H\x1b[?25h"
+)
+
+// Server accepts spectators and sends them the frames drawn for them
+type Server struct {
+	listener   net.Listener
+	hostKey    ed25519.PrivateKey
+	config     *ssh.ServerConfig
+	mu         sync.Mutex
+	spectators map[*spectator]struct{}
+}
+
+// spectator is a client watching the game
+type spectator struct {
+	width, height int        // Size of the spectator's terminal, guarded by the server's lock
+	frames        chan frame // Latest frame not sent yet
+}
+
+// frame is the main view drawn at a size
+type frame struct {
+	text          string
+	width, height int
+}
+
+// Listen starts listening for spectators on the given address, e.g. ":2222", with the host key kept in the file.
+// Without any key in the authorized keys file, spectators are not asked to authenticate and only the loopback
+// interface is listened on.
+func Listen(addr, hostKeyFile, authorizedKeysFile string) (*Server, error) {
+	hostKey, err := LoadHostKey(hostKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	authorizedKeys, err := LoadAuthorizedKeys(authorizedKeysFile)
+	if err != nil {
+		return nil, err
+	}
+	addr, err = listenAddress(addr, len(authorizedKeys) > 0)
+	if err != nil {
+		return nil, err
+	}
+	config, err := serverConfig(hostKey, authorizedKeys)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{listener: listener, hostKey: hostKey, config: config, spectators: map[*spectator]struct{}{}}, nil
+}
+
+// Addr returns the address the server is listening on
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Fingerprint returns the SHA256 fingerprint of the host key, as ssh shows it on the first connection
+func (s *Server) Fingerprint() string {
+	publicKey, err := ssh.NewPublicKey(s.hostKey.Public())
+	if err != nil {
+		return ""
+	}
+	return ssh.FingerprintSHA256(publicKey)
+}
+
+// Spectators returns the number of spectators watching
+func (s *Server) Spectators() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.spectators)
+}
+
+// Run accepts spectators until done is closed
+func (s *Server) Run(done <-chan struct{}) {
+	go func() {
+		<-done
+		_ = s.listener.Close()
+	}()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serve(conn, done)
+	}
+}
+
+// Show sends each spectator the frame drawn at the size of their terminal. It must be called on the goroutine that
+// draws the view; draw returns the main view drawn at a size as ANSI text, a line per row.
+func (s *Server) Show(draw func(width, height int) string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	drawn := map[[2]int]string{}
+	for sp := range s.spectators {
+		size := [2]int{sp.width, sp.height}
+		text, ok := drawn[size]
+		if !ok {
+			text = draw(sp.width, sp.height)
+			drawn[size] = text
+		}
+		// Only the latest frame is kept for a spectator who is slow to take them
+		select {
+		case <-sp.frames:
+		default:
+		}
+		sp.frames <- frame{text: text, width: sp.width, height: sp.height}
+	}
+}
+
+// serve handles a spectator's connection until they leave or the server stops
+func (s *Server) serve(conn net.Conn, done <-chan struct{}) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	_, channels, requests, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	session := &session{
+		server:    s,
+		spectator: &spectator{width: defaultWidth, height: defaultHeight, frames: make(chan frame, 1)},
+		started:   make(chan struct{}),
+		quit:      make(chan struct{}),
+	}
+
+	left := make(chan struct{})
+	go func() {
+		session.handleChannels(channels)
+		close(left)
+	}()
+
+	select {
+	case <-session.started:
+	case <-left:
+		return
+	case <-done:
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+	s.mu.Lock()
+	s.spectators[session.spectator] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.spectators, session.spectator)
+		s.mu.Unlock()
+	}()
+
+	if session.write(hideCursor+clearScreen) != nil {
+		return
+	}
+	last := frame{}
+	for {
+		select {
+		case f := <-session.spectator.frames:
+			if f == last {
+				continue
+			}
+			text := cursorHome + strings.ReplaceAll(strings.TrimSuffix(f.text, "\n"), "\n", "\r\n")
+			if f.width != last.width || f.height != last.height {
+				text = clearScreen + text
+			}
+			if session.write(text) != nil {
+				return
+			}
+			last = f
+		case <-session.quit:
+			session.end()
+			waitForClose(left)
+			return
+		case <-left:
+			return
+		case <-done:
+			session.end()
+			waitForClose(left)
+			return
+		}
+	}
+}
+
+// waitForClose gives the spectator's client a moment to close the connection itself, so it does not report it as
+// lost
+func waitForClose(left <-chan struct{}) {
+	select {
+	case <-left:
+	case <-time.After(time.Second):
+	}
+}
+
+// LoadHostKey reads the host key from the file, creating a new key if there is none
+func LoadHostKey(filename string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		return key, os.WriteFile(filename, data, 0600)
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no host key in %s", filename)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("host key in %s is not an Ed25519 key", filename)
+	}
+	return key, nil
+}
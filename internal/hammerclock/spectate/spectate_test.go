@@ -0,0 +1,175 @@
+package spectate
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestLoadHostKeyKeepsKey(t *testing.T) {
+	file := filepath.Join(t.TempDir(), HostKeyFileName)
+	created, err := LoadHostKey(file)
+	if err != nil {
+		t.Fatalf("Failed to create the host key: %v", err)
+	}
+	loaded, err := LoadHostKey(file)
+	if err != nil {
+		t.Fatalf("Failed to load the host key: %v", err)
+	}
+	if !created.Equal(loaded) {
+		t.Error("Expected the host key created before")
+	}
+}
+
+func TestSpectatorsFromOtherMachinesNeedAKey(t *testing.T) {
+	dir := t.TempDir()
+	hostKeyFile, authorizedKeysFile := filepath.Join(dir, HostKeyFileName), filepath.Join(dir, AuthorizedKeysFileName)
+	if _, err := Listen("0.0.0.0:0", hostKeyFile, authorizedKeysFile); err == nil {
+		t.Error("Expected listening on all interfaces to need authorized keys")
+	}
+	server, err := Listen(":0", hostKeyFile, authorizedKeysFile)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer server.listener.Close()
+	if ip := server.Addr().(*net.TCPAddr).IP; !ip.IsLoopback() {
+		t.Errorf("Expected an address without a host to listen on the loopback interface, got %s", ip)
+	}
+}
+
+func TestSpectatorsAreAuthenticatedWithTheirKeys(t *testing.T) {
+	dir := t.TempDir()
+	_, spectatorKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, strangerKey, _ := ed25519.GenerateKey(rand.Reader)
+	spectatorSigner, _ := ssh.NewSignerFromKey(spectatorKey)
+	strangerSigner, _ := ssh.NewSignerFromKey(strangerKey)
+	authorizedKeys := "# Alice's laptop\n" + string(ssh.MarshalAuthorizedKey(spectatorSigner.PublicKey()))
+	if err := os.WriteFile(filepath.Join(dir, AuthorizedKeysFileName), []byte(authorizedKeys), 0600); err != nil {
+		t.Fatalf("Failed to write the authorized keys: %v", err)
+	}
+	server, err := Listen("127.0.0.1:0", filepath.Join(dir, HostKeyFileName), filepath.Join(dir, AuthorizedKeysFileName))
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go server.Run(done)
+
+	for signer, allowed := range map[ssh.Signer]bool{spectatorSigner: true, strangerSigner: false} {
+		config := &ssh.ClientConfig{
+			User: "spectator",
+			Auth: []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+				if ssh.FingerprintSHA256(key) != server.Fingerprint() {
+					t.Errorf("Expected the host key %s, got %s", server.Fingerprint(), ssh.FingerprintSHA256(key))
+				}
+				return nil
+			},
+			Timeout: 5 * time.Second,
+		}
+		client, err := ssh.Dial("tcp", server.Addr().String(), config)
+		if err == nil {
+			_ = client.Close()
+		}
+		if (err == nil) != allowed {
+			t.Errorf("Expected the key to be allowed %t, got %v", allowed, err)
+		}
+	}
+}
+
+// lockedBuffer collects the output of a command while the test reads it
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestSpectatorWatchesOverSSH(t *testing.T) {
+	client, err := exec.LookPath("ssh")
+	if err != nil {
+		t.Skip("No ssh client to connect with")
+	}
+	dir := t.TempDir()
+	server, err := Listen("127.0.0.1:0", filepath.Join(dir, HostKeyFileName), filepath.Join(dir, AuthorizedKeysFileName))
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go server.Run(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(20 * time.Millisecond):
+				server.Show(func(width, height int) string {
+					return "Alice " + strconv.Itoa(width) + "x" + strconv.Itoa(height) + "\n"
+				})
+			}
+		}
+	}()
+
+	port := strconv.Itoa(server.Addr().(*net.TCPAddr).Port)
+	cmd := exec.Command(client, "-tt", "-p", port, "-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=/dev/null", "-o", "LogLevel=ERROR", "-o", "BatchMode=yes", "spectator@127.0.0.1")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatalf("Failed to open the client's input: %v", err)
+	}
+	var output lockedBuffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start the client: %v", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.After(10 * time.Second)
+	for !strings.Contains(output.String(), "Alice 80x24") {
+		select {
+		case err := <-exited:
+			t.Fatalf("Expected the frame, the client exited with %v: %q", err, output.String())
+		case <-deadline:
+			_ = cmd.Process.Kill()
+			t.Fatalf("Expected the frame, got %q", output.String())
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+	if server.Spectators() != 1 {
+		t.Errorf("Expected a spectator, got %d", server.Spectators())
+	}
+
+	_, _ = stdin.Write([]byte("q"))
+	select {
+	case err := <-exited:
+		if err != nil {
+			t.Errorf("Expected the client to leave cleanly, got %v: %q", err, output.String())
+		}
+	case <-time.After(10 * time.Second):
+		_ = cmd.Process.Kill()
+		t.Fatalf("Expected the client to leave after q, got %q", output.String())
+	}
+}
@@ -0,0 +1,82 @@
+package spectate
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// errUnknownKey is returned when a spectator's public key is not among the authorized keys
+var errUnknownKey = errors.New("public key not authorized")
+
+// serverConfig returns the SSH configuration of the server. Without authorized keys, spectators are not asked to
+// authenticate, which Listen only allows on the loopback interface.
+func serverConfig(hostKey ed25519.PrivateKey, authorizedKeys []ssh.PublicKey) (*ssh.ServerConfig, error) {
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		return nil, err
+	}
+	config := &ssh.ServerConfig{NoClientAuth: len(authorizedKeys) == 0}
+	if len(authorizedKeys) > 0 {
+		config.PublicKeyCallback = func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			for _, authorized := range authorizedKeys {
+				if bytes.Equal(key.Marshal(), authorized.Marshal()) {
+					return nil, nil
+				}
+			}
+			return nil, errUnknownKey
+		}
+	}
+	config.AddHostKey(signer)
+	return config, nil
+}
+
+// LoadAuthorizedKeys reads the public keys of the spectators from a file in the format of OpenSSH's authorized_keys.
+// A missing file has no keys.
+func LoadAuthorizedKeys(filename string) ([]ssh.PublicKey, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var keys []ssh.PublicKey
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("invalid key in %s: %w", filename, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// listenAddress returns the address to listen on. Without authorized keys an address without a host, e.g. ":2222",
+// only listens on the loopback interface, and any other host is refused.
+func listenAddress(addr string, authorized bool) (string, error) {
+	if authorized {
+		return addr, nil
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if host == "" {
+		return net.JoinHostPort("127.0.0.1", port), nil
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return "", fmt.Errorf("spectators on other machines need their public keys in %s", AuthorizedKeysFileName)
+	}
+	return addr, nil
+}
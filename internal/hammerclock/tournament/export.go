@@ -0,0 +1,150 @@
+package tournament
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// exportGame is a player's game in an export, one line per player and game
+type exportGame struct {
+	Round                 int    `json:"round"`
+	Table                 int    `json:"table"`
+	Player                string `json:"player"`
+	Opponent              string `json:"opponent"`
+	Result                string `json:"result"` // W, L or D
+	VictoryPoints         int    `json:"victoryPoints"`
+	OpponentVictoryPoints int    `json:"opponentVictoryPoints"`
+	ClockSeconds          int    `json:"clockSeconds"` // Time on the player's clock
+}
+
+// exportStanding is a player's standing in an export
+type exportStanding struct {
+	Rank               int    `json:"rank"`
+	Player             string `json:"player"`
+	Wins               int    `json:"wins"`
+	Losses             int    `json:"losses"`
+	Draws              int    `json:"draws"`
+	Points             int    `json:"points"`
+	VictoryPoints      int    `json:"victoryPoints"`
+	StrengthOfSchedule int    `json:"strengthOfSchedule"`
+}
+
+// exportGames returns a line for each player of each finished game, in round and table order
+func (e *Event) exportGames() []exportGame {
+	results := slices.Clone(e.Results)
+	slices.SortFunc(results, func(a, b Result) int {
+		if a.Round != b.Round {
+			return a.Round - b.Round
+		}
+		return a.Table - b.Table
+	})
+
+	var games []exportGame
+	for _, result := range results {
+		for i, player := range result.Players {
+			game := exportGame{
+				Round:         result.Round,
+				Table:         result.Table,
+				Player:        player.Name,
+				Result:        result.Outcome(i),
+				VictoryPoints: player.VictoryPoints,
+				ClockSeconds:  int(player.TimeElapsed.Seconds()),
+			}
+
+			// Games of more than two players list all opponents
+			var opponents []string
+			for j, opponent := range result.Players {
+				if j != i {
+					opponents = append(opponents, opponent.Name)
+					game.OpponentVictoryPoints += opponent.VictoryPoints
+				}
+			}
+			game.Opponent = strings.Join(opponents, " / ")
+			games = append(games, game)
+		}
+	}
+	return games
+}
+
+// exportStandings returns the standings with the players' records and tiebreakers
+func (e *Event) exportStandings() []exportStanding {
+	standings := e.Standings()
+	exported := make([]exportStanding, len(standings))
+	for i, standing := range standings {
+		exported[i] = exportStanding{
+			Rank:               i + 1,
+			Player:             standing.Name,
+			Wins:               standing.Wins,
+			Losses:             standing.Losses,
+			Draws:              standing.Draws,
+			Points:             standing.Points,
+			VictoryPoints:      standing.VictoryPoints,
+			StrengthOfSchedule: standing.StrengthOfSchedule,
+		}
+	}
+	return exported
+}
+
+// ExportCSV writes the event's games as CSV, one line per player and game, in the column layout tournament
+// platforms such as Best Coast Pairings and Tabletop.to import results from
+func (e *Event) ExportCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"Round", "Table", "Player", "Opponent", "Result", "Victory Points",
+		"Opponent Victory Points", "Clock Seconds"})
+	for _, game := range e.exportGames() {
+		_ = writer.Write([]string{
+			strconv.Itoa(game.Round),
+			strconv.Itoa(game.Table),
+			game.Player,
+			game.Opponent,
+			game.Result,
+			strconv.Itoa(game.VictoryPoints),
+			strconv.Itoa(game.OpponentVictoryPoints),
+			strconv.Itoa(game.ClockSeconds),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportJSON writes the event's games and standings as JSON
+func (e *Event) ExportJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Event     string           `json:"event"`
+		Rounds    int              `json:"rounds"`
+		Games     []exportGame     `json:"games"`
+		Standings []exportStanding `json:"standings"`
+	}{e.Name, e.Rounds, e.exportGames(), e.exportStandings()})
+}
+
+// Export writes the event's results to a file, as CSV or JSON depending on the file's extension
+func (e *Event) Export(filename string) error {
+	var export func(io.Writer) error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		export = e.ExportCSV
+	case ".json":
+		export = e.ExportJSON
+	default:
+		return fmt.Errorf("unknown export format '%s', use .csv or .json", filepath.Ext(filename))
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := export(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,153 @@
+package tournament
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Entrant is a player registered for an event, with the faction played and the team, if any
+type Entrant struct {
+	Name    string `json:"name"`
+	Faction string `json:"faction,omitempty"`
+	Team    string `json:"team,omitempty"` // Players of the same team are not paired against each other if avoidable
+}
+
+// rosterColumns are the header names accepted for each column of a roster, in lower case
+var rosterColumns = map[string][]string{
+	"name":    {"name", "player", "player name"},
+	"faction": {"faction", "army"},
+	"team":    {"team"},
+}
+
+// LoadRoster reads the entrants of an event from a CSV file with a header line, e.g. "Name,Faction,Team" as
+// exported by most registration sites. Only the name column is required; other columns are ignored.
+func LoadRoster(filename string) ([]Entrant, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entrants, err := readRoster(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid roster '%s': %w", filename, err)
+	}
+	return entrants, nil
+}
+
+// readRoster reads the entrants of a roster in CSV format
+func readRoster(r io.Reader) ([]Entrant, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("the roster is empty")
+	}
+
+	// Spreadsheets often save UTF-8 files with a byte order mark, which would end up in the first header
+	columns := map[string]int{}
+	for i, header := range records[0] {
+		header = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+		for column, names := range rosterColumns {
+			if _, found := columns[column]; !found && slices.Contains(names, header) {
+				columns[column] = i
+			}
+		}
+	}
+	if _, found := columns["name"]; !found {
+		return nil, errors.New("the roster has no name column")
+	}
+
+	field := func(record []string, column string) string {
+		i, found := columns[column]
+		if !found || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var entrants []Entrant
+	for line, record := range records[1:] {
+		entrant := Entrant{Name: field(record, "name"), Faction: field(record, "faction"), Team: field(record, "team")}
+		if entrant.Name == "" {
+			continue
+		}
+		if slices.ContainsFunc(entrants, func(e Entrant) bool { return e.Name == entrant.Name }) {
+			return nil, fmt.Errorf("line %d: %s is listed twice", line+2, entrant.Name)
+		}
+		entrants = append(entrants, entrant)
+	}
+	return entrants, nil
+}
+
+// ImportRoster replaces the players of the event with the entrants of a roster. It fails once tables are assigned,
+// so a late import does not change the players of a running event.
+func (e *Event) ImportRoster(entrants []Entrant) error {
+	if len(e.Pairings) > 0 {
+		return errors.New("the tables of the event are already assigned")
+	}
+	if len(entrants) < e.Tables {
+		return fmt.Errorf("%d players are not enough for %d tables", len(entrants), e.Tables)
+	}
+	e.Roster = slices.Clone(entrants)
+	e.Players = make([]string, len(entrants))
+	for i, entrant := range entrants {
+		e.Players[i] = entrant.Name
+	}
+	return nil
+}
+
+// ImportRosterFile replaces the players of the event in eventFile with the entrants of the roster in rosterFile and
+// saves the event. The event file does not need to list any players yet.
+func ImportRosterFile(eventFile, rosterFile string) (*Event, error) {
+	entrants, err := LoadRoster(rosterFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var event *Event
+	err = locked(eventFile, func() error {
+		if event, err = read(eventFile); err != nil {
+			return err
+		}
+		if err := event.ImportRoster(entrants); err != nil {
+			return err
+		}
+		if err := event.validate(); err != nil {
+			return fmt.Errorf("invalid event file '%s': %w", eventFile, err)
+		}
+		return event.Save(eventFile)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Entrant returns the roster entry of a player, or just the name for players not on the roster
+func (e *Event) Entrant(name string) Entrant {
+	for _, entrant := range e.Roster {
+		if entrant.Name == name {
+			return entrant
+		}
+	}
+	return Entrant{Name: name}
+}
+
+// teammates tells whether a player is on the same team as one of the given players
+func (e *Event) teammates(name string, players []string) bool {
+	team := e.Entrant(name).Team
+	if team == "" {
+		return false
+	}
+	return slices.ContainsFunc(players, func(player string) bool { return e.Entrant(player).Team == team })
+}
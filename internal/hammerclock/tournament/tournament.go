@@ -0,0 +1,404 @@
+// Package tournament runs events of several rounds played on several tables. Each table plays its own hammerclock
+// game, started with the event file, round and table; when the game ends, its result and timings are rolled up into
+// the event record.
+package tournament
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"hammerclock/internal/hammerclock/common"
+
+	"github.com/gofrs/flock"
+)
+
+// Event is a tournament with a number of rounds played on a number of tables
+type Event struct {
+	Name     string    `json:"name"`
+	Rounds   int       `json:"rounds"`
+	Tables   int       `json:"tables"`
+	Players  []string  `json:"players"`
+	Roster   []Entrant `json:"roster,omitempty"`   // Factions and teams of the players, if imported from a roster
+	Pairings []Pairing `json:"pairings,omitempty"` // Players assigned to the tables, generated round by round
+	Results  []Result  `json:"results,omitempty"`  // Results of the finished games
+}
+
+// Pairing assigns players to a table in a round
+type Pairing struct {
+	Round   int      `json:"round"`
+	Table   int      `json:"table"`
+	Players []string `json:"players"`
+}
+
+// Result is the result of the game played on a table in a round
+type Result struct {
+	Round      int            `json:"round"`
+	Table      int            `json:"table"`
+	GameTime   time.Duration  `json:"gameTime"`
+	PausedTime time.Duration  `json:"pausedTime"`
+	FinishedAt time.Time      `json:"finishedAt"`
+	Players    []PlayerResult `json:"players"`
+}
+
+// PlayerResult is the result of a player in a game
+type PlayerResult struct {
+	Name          string        `json:"name"`
+	TimeElapsed   time.Duration `json:"timeElapsed"`
+	Turns         int           `json:"turns"`
+	VictoryPoints int           `json:"victoryPoints"`
+	Outcome       string        `json:"outcome,omitempty"` // W, L or D as entered, empty to decide by victory points
+}
+
+// Game outcomes of a player, as tournament platforms expect them
+const (
+	Win  = "W"
+	Loss = "L"
+	Draw = "D"
+)
+
+// Points for the outcomes of a game, the first criterion of the standings
+const (
+	winPoints  = 3
+	drawPoints = 1
+)
+
+// Standing is a player's total over the finished games of an event
+type Standing struct {
+	Name               string
+	Games              int
+	Wins               int
+	Losses             int
+	Draws              int
+	Points             int // 3 per win and 1 per draw
+	VictoryPoints      int
+	StrengthOfSchedule int // Sum of the points of the player's opponents
+	TimeElapsed        time.Duration
+}
+
+// Load reads an event from a file
+func Load(filename string) (*Event, error) {
+	event, err := read(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := event.validate(); err != nil {
+		return nil, fmt.Errorf("invalid event file '%s': %w", filename, err)
+	}
+	return event, nil
+}
+
+// read reads an event from a file without validating it
+func read(filename string) (*Event, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("invalid event file '%s': %w", filename, err)
+	}
+	return &event, nil
+}
+
+// Save writes the event to a file. The file is replaced at once, so the tables reading it never see half of it.
+func (e *Event) Save(filename string) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(filename), ".event-*")
+	if err != nil {
+		return err
+	}
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), filename)
+	}
+	if err != nil {
+		_ = os.Remove(temp.Name())
+	}
+	return err
+}
+
+// Update reads the event from a file, changes it and writes it back. The file is locked meanwhile, so the tables and
+// the organizer sharing the event file do not overwrite each other's changes. The event is not written back if
+// change fails.
+func Update(filename string, change func(event *Event) error) error {
+	return locked(filename, func() error {
+		event, err := Load(filename)
+		if err != nil {
+			return err
+		}
+		if err := change(event); err != nil {
+			return err
+		}
+		return event.Save(filename)
+	})
+}
+
+// locked runs f while holding the lock of the event file, a file next to it with the .lock extension
+func locked(filename string, f func() error) error {
+	lock := flock.New(filename + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking the event file '%s': %w", filename, err)
+	}
+	defer func() { _ = lock.Unlock() }()
+	return f()
+}
+
+// validate checks that the event has rounds, tables and at least one player per table
+func (e *Event) validate() error {
+	switch {
+	case e.Rounds < 1:
+		return errors.New("an event needs at least one round")
+	case e.Tables < 1:
+		return errors.New("an event needs at least one table")
+	case len(e.Players) < e.Tables:
+		return fmt.Errorf("%d players are not enough for %d tables", len(e.Players), e.Tables)
+	}
+	return nil
+}
+
+// Table returns the players assigned to a table in a round. The tables of a round are assigned when the first of
+// them is requested, so later rounds can pair the players by their results; they can only be assigned once all
+// results of the previous round are in.
+func (e *Event) Table(round, table int) (Pairing, error) {
+	if round < 1 || round > e.Rounds {
+		return Pairing{}, fmt.Errorf("round %d is not part of the event, it has %d rounds", round, e.Rounds)
+	}
+	if table < 1 || table > e.Tables {
+		return Pairing{}, fmt.Errorf("table %d is not part of the event, it has %d tables", table, e.Tables)
+	}
+
+	if !slices.ContainsFunc(e.Pairings, func(p Pairing) bool { return p.Round == round }) {
+		if missing := e.missingResults(round - 1); len(missing) > 0 {
+			return Pairing{}, fmt.Errorf("round %d cannot be paired before the results of round %d are in, missing tables %s",
+				round, round-1, strings.Trim(fmt.Sprint(missing), "[]"))
+		}
+		e.pairRound(round)
+	}
+	for _, pairing := range e.Pairings {
+		if pairing.Round == round && pairing.Table == table {
+			return pairing, nil
+		}
+	}
+	return Pairing{}, fmt.Errorf("no players are assigned to table %d in round %d", table, round)
+}
+
+// missingResults returns the tables of a round without a result, all tables if the round was not paired yet.
+// Round 0, before the first round, has none.
+func (e *Event) missingResults(round int) []int {
+	if round < 1 {
+		return nil
+	}
+	var missing []int
+	for table := 1; table <= e.Tables; table++ {
+		if !slices.ContainsFunc(e.Results, func(r Result) bool { return r.Round == round && r.Table == table }) {
+			missing = append(missing, table)
+		}
+	}
+	return missing
+}
+
+// pairRound assigns the players to the tables of a round, in the order of their standings. The players of the
+// first round are seated in the order they are listed. Players of the same team are not seated at the same table,
+// unless only teammates are left. Players left over after filling the tables evenly sit the round out.
+func (e *Event) pairRound(round int) {
+	perTable := len(e.Players) / e.Tables
+	players := make([]string, 0, len(e.Players))
+	for _, standing := range e.Standings() {
+		players = append(players, standing.Name)
+	}
+	for table := 1; table <= e.Tables; table++ {
+		seated := make([]string, 0, perTable)
+		for len(seated) < perTable {
+			next := max(slices.IndexFunc(players, func(name string) bool { return !e.teammates(name, seated) }), 0)
+			seated = append(seated, players[next])
+			players = slices.Delete(players, next, next+1)
+		}
+		e.Pairings = append(e.Pairings, Pairing{Round: round, Table: table, Players: seated})
+	}
+}
+
+// Record adds the result of a game to the event, replacing an earlier result of the same table and round
+func (e *Event) Record(result Result) {
+	e.Results = slices.DeleteFunc(e.Results, func(r Result) bool {
+		return r.Round == result.Round && r.Table == result.Table
+	})
+	e.Results = append(e.Results, result)
+}
+
+// Standings returns the players' totals over the finished games, ordered by points, with victory points and then
+// strength of schedule as tiebreakers. Players who are still tied keep the order they are listed in.
+func (e *Event) Standings() []Standing {
+	standings := make([]Standing, len(e.Players))
+	index := make(map[string]int, len(e.Players))
+	for i, name := range e.Players {
+		standings[i] = Standing{Name: name}
+		index[name] = i
+	}
+	for _, result := range e.Results {
+		for j, player := range result.Players {
+			i, ok := index[player.Name]
+			if !ok {
+				continue
+			}
+			standing := &standings[i]
+			standing.Games++
+			standing.VictoryPoints += player.VictoryPoints
+			standing.TimeElapsed += player.TimeElapsed
+			switch result.Outcome(j) {
+			case Win:
+				standing.Wins++
+				standing.Points += winPoints
+			case Draw:
+				standing.Draws++
+				standing.Points += drawPoints
+			default:
+				standing.Losses++
+			}
+		}
+	}
+
+	// The strength of schedule needs the points of every player, so it is added once they are known
+	for _, result := range e.Results {
+		for _, player := range result.Players {
+			i, ok := index[player.Name]
+			if !ok {
+				continue
+			}
+			for _, opponent := range result.Players {
+				if j, ok := index[opponent.Name]; ok && j != i {
+					standings[i].StrengthOfSchedule += standings[j].Points
+				}
+			}
+		}
+	}
+
+	slices.SortStableFunc(standings, func(a, b Standing) int {
+		return cmp.Or(
+			cmp.Compare(b.Points, a.Points),
+			cmp.Compare(b.VictoryPoints, a.VictoryPoints),
+			cmp.Compare(b.StrengthOfSchedule, a.StrengthOfSchedule),
+		)
+	})
+	return standings
+}
+
+// Outcome returns whether the player at index won, lost or drew the game: the outcome entered for the player, or
+// else by victory points
+func (r Result) Outcome(index int) string {
+	if outcome := r.Players[index].Outcome; outcome != "" {
+		return outcome
+	}
+
+	best := slices.MaxFunc(r.Players, func(a, b PlayerResult) int { return a.VictoryPoints - b.VictoryPoints })
+	tied := 0
+	for _, player := range r.Players {
+		if player.VictoryPoints == best.VictoryPoints {
+			tied++
+		}
+	}
+
+	switch {
+	case r.Players[index].VictoryPoints < best.VictoryPoints:
+		return Loss
+	case tied > 1:
+		return Draw
+	}
+	return Win
+}
+
+// EnterResult records the outcomes and victory points entered for the players of a table in a round. The times of
+// a result recorded by the table's clock are kept.
+func (e *Event) EnterResult(round, table int, players []PlayerResult) {
+	result := Result{Round: round, Table: table, FinishedAt: time.Now()}
+	for _, recorded := range e.Results {
+		if recorded.Round == round && recorded.Table == table {
+			result = recorded
+		}
+	}
+
+	entered := make([]PlayerResult, len(players))
+	for i, player := range players {
+		for _, recorded := range result.Players {
+			if recorded.Name == player.Name {
+				player.TimeElapsed = recorded.TimeElapsed
+				player.Turns = recorded.Turns
+			}
+		}
+		entered[i] = player
+	}
+	result.Players = entered
+	e.Record(result)
+}
+
+// Summary returns the event record as text: the results of the finished games and the standings
+func (e *Event) Summary() string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s - %d rounds, %d tables\n", e.Name, e.Rounds, e.Tables)
+
+	results := slices.Clone(e.Results)
+	slices.SortFunc(results, func(a, b Result) int {
+		return cmp.Or(cmp.Compare(a.Round, b.Round), cmp.Compare(a.Table, b.Table))
+	})
+	if len(results) > 0 {
+		text.WriteString("\nResults:\n")
+	}
+	for _, result := range results {
+		players := make([]string, len(result.Players))
+		for i, player := range result.Players {
+			players[i] = fmt.Sprintf("%s %d VP (%v)", player.Name, player.VictoryPoints, player.TimeElapsed.Truncate(time.Second))
+		}
+		fmt.Fprintf(&text, "  Round %d, table %d: %s - played %v, paused %v\n", result.Round, result.Table,
+			strings.Join(players, " vs "), result.GameTime.Truncate(time.Second), result.PausedTime.Truncate(time.Second))
+	}
+
+	text.WriteString("\nStandings:\n")
+	for i, standing := range e.Standings() {
+		fmt.Fprintf(&text, "  %d. %s - %d points (%d-%d-%d), %d VP, SoS %d, %v on the clock\n", i+1, standing.Name,
+			standing.Points, standing.Wins, standing.Losses, standing.Draws, standing.VictoryPoints,
+			standing.StrengthOfSchedule, standing.TimeElapsed.Truncate(time.Second))
+	}
+	return text.String()
+}
+
+// ResultFromModel captures the result of the game of a model, before the game is reset
+func ResultFromModel(model *common.Model, round, table int, finishedAt time.Time) Result {
+	result := Result{
+		Round:      round,
+		Table:      table,
+		GameTime:   model.TotalGameTime,
+		PausedTime: model.PausedTime,
+		FinishedAt: finishedAt,
+		Players:    make([]PlayerResult, len(model.Players)),
+	}
+	for i, player := range model.Players {
+		result.Players[i] = PlayerResult{
+			Name:          player.Name,
+			TimeElapsed:   player.TimeElapsed,
+			Turns:         player.TurnCount,
+			VictoryPoints: player.VictoryPoints,
+		}
+	}
+	return result
+}
+
+// RecordResult adds the result of a game to the event file. The file is read again first, so results recorded by
+// the other tables in the meantime are kept.
+func RecordResult(filename string, result Result) error {
+	return Update(filename, func(event *Event) error {
+		event.Record(result)
+		return nil
+	})
+}
@@ -0,0 +1,264 @@
+package tournament
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newEvent() *Event {
+	return &Event{Name: "Spring Cup", Rounds: 2, Tables: 2, Players: []string{"Alice", "Bob", "Carol", "Dave", "Eve"}}
+}
+
+func TestTableAssignsPlayersByStandings(t *testing.T) {
+	event := newEvent()
+
+	// The first round seats the players in the order they are listed, the one left over sits out
+	pairing, err := event.Table(1, 2)
+	if err != nil {
+		t.Fatalf("Expected a table, got error: %v", err)
+	}
+	if !slices.Equal(pairing.Players, []string{"Carol", "Dave"}) {
+		t.Errorf("Expected Carol and Dave at table 2, got %v", pairing.Players)
+	}
+	if len(event.Pairings) != 2 {
+		t.Errorf("Expected all tables of the round to be assigned, got %d", len(event.Pairings))
+	}
+
+	// Later rounds pair the players by their victory points
+	event.Record(Result{Round: 1, Table: 1, Players: []PlayerResult{{Name: "Alice", VictoryPoints: 40}, {Name: "Bob", VictoryPoints: 60}}})
+	event.Record(Result{Round: 1, Table: 2, Players: []PlayerResult{{Name: "Carol", VictoryPoints: 10}, {Name: "Dave", VictoryPoints: 70}}})
+	pairing, _ = event.Table(2, 1)
+	if !slices.Equal(pairing.Players, []string{"Dave", "Bob"}) {
+		t.Errorf("Expected the leaders at table 1, got %v", pairing.Players)
+	}
+
+	if _, err := event.Table(3, 1); err == nil {
+		t.Error("Expected an error for a round that is not part of the event")
+	}
+	if _, err := event.Table(1, 3); err == nil {
+		t.Error("Expected an error for a table that is not part of the event")
+	}
+}
+
+func TestTableWaitsForThePreviousRound(t *testing.T) {
+	event := newEvent()
+	if _, err := event.Table(1, 1); err != nil {
+		t.Fatalf("Expected a table, got error: %v", err)
+	}
+	event.Record(Result{Round: 1, Table: 1, Players: []PlayerResult{{Name: "Alice", VictoryPoints: 40}, {Name: "Bob", VictoryPoints: 60}}})
+
+	_, err := event.Table(2, 1)
+	if err == nil || !strings.Contains(err.Error(), "missing tables 2") {
+		t.Errorf("Expected round 2 to wait for the result of table 2, got error %v", err)
+	}
+	if len(event.Pairings) != 2 {
+		t.Errorf("Expected round 2 not to be paired, got %d pairings", len(event.Pairings))
+	}
+}
+
+func TestRecordReplacesResultOfSameTable(t *testing.T) {
+	event := newEvent()
+	event.Record(Result{Round: 1, Table: 1, Players: []PlayerResult{{Name: "Alice", VictoryPoints: 40}}})
+	event.Record(Result{Round: 1, Table: 1, Players: []PlayerResult{{Name: "Alice", VictoryPoints: 55}}})
+
+	standings := event.Standings()
+	if len(event.Results) != 1 || standings[0].Name != "Alice" || standings[0].VictoryPoints != 55 || standings[0].Games != 1 {
+		t.Errorf("Expected the second result to replace the first, got %+v", standings[0])
+	}
+}
+
+func TestRecordResultRollsUpIntoEventFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cup.json")
+	if err := newEvent().Save(filename); err != nil {
+		t.Fatalf("Failed to save the event: %v", err)
+	}
+
+	result := Result{Round: 1, Table: 2, GameTime: 90 * time.Minute, Players: []PlayerResult{
+		{Name: "Carol", TimeElapsed: 50 * time.Minute, VictoryPoints: 65},
+		{Name: "Dave", TimeElapsed: 40 * time.Minute, VictoryPoints: 30},
+	}}
+	if err := RecordResult(filename, result); err != nil {
+		t.Fatalf("Failed to record the result: %v", err)
+	}
+
+	event, err := Load(filename)
+	if err != nil {
+		t.Fatalf("Failed to load the event: %v", err)
+	}
+	summary := event.Summary()
+	for _, expected := range []string{"Round 1, table 2: Carol 65 VP (50m0s) vs Dave 30 VP (40m0s)", "1. Carol - 3 points (1-0-0), 65 VP"} {
+		if !strings.Contains(summary, expected) {
+			t.Errorf("Expected %q in the summary, got:\n%s", expected, summary)
+		}
+	}
+}
+
+func TestRecordResultKeepsResultsRecordedAtTheSameTime(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cup.json")
+	event := &Event{Name: "Spring Cup", Rounds: 1, Tables: 8}
+	for i := range 16 {
+		event.Players = append(event.Players, fmt.Sprintf("Player %d", i+1))
+	}
+	if err := event.Save(filename); err != nil {
+		t.Fatalf("Failed to save the event: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for table := 1; table <= event.Tables; table++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := RecordResult(filename, Result{Round: 1, Table: table}); err != nil {
+				t.Errorf("Failed to record the result of table %d: %v", table, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if event, err := Load(filename); err != nil || len(event.Results) != event.Tables {
+		t.Errorf("Expected the results of all tables, got %v (error %v)", event, err)
+	}
+}
+
+func TestLoadRejectsEventsWithoutTables(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cup.json")
+	event := newEvent()
+	event.Tables = 0
+	if err := event.Save(filename); err != nil {
+		t.Fatalf("Failed to save the event: %v", err)
+	}
+	if _, err := Load(filename); err == nil {
+		t.Error("Expected an error for an event without tables")
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	event := newEvent()
+	event.Record(Result{Round: 1, Table: 1, Players: []PlayerResult{
+		{Name: "Alice", TimeElapsed: 45 * time.Minute, VictoryPoints: 60},
+		{Name: "Bob", TimeElapsed: 50 * time.Minute, VictoryPoints: 60},
+	}})
+	event.Record(Result{Round: 1, Table: 2, Players: []PlayerResult{
+		{Name: "Carol", VictoryPoints: 20},
+		{Name: "Dave", VictoryPoints: 75},
+	}})
+
+	var csv strings.Builder
+	if err := event.ExportCSV(&csv); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	expected := "Round,Table,Player,Opponent,Result,Victory Points,Opponent Victory Points,Clock Seconds\n" +
+		"1,1,Alice,Bob,D,60,60,2700\n" +
+		"1,1,Bob,Alice,D,60,60,3000\n" +
+		"1,2,Carol,Dave,L,20,75,0\n" +
+		"1,2,Dave,Carol,W,75,20,0\n"
+	if csv.String() != expected {
+		t.Errorf("Unexpected CSV export:\n%s", csv.String())
+	}
+
+	var json strings.Builder
+	if err := event.ExportJSON(&json); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if !strings.Contains(json.String(), `"player": "Dave",`+"\n      \"wins\": 1") {
+		t.Errorf("Expected Dave's win in the standings, got:\n%s", json.String())
+	}
+
+	if err := event.Export(filepath.Join(t.TempDir(), "results.txt")); err == nil {
+		t.Error("Expected an error for an unknown export format")
+	}
+}
+
+func TestStandingsTiebreakers(t *testing.T) {
+	event := &Event{Name: "Spring Cup", Rounds: 2, Tables: 2, Players: []string{"Dave", "Carol", "Bob", "Alice"}}
+	event.Record(Result{Round: 1, Table: 1, Players: []PlayerResult{{Name: "Alice", VictoryPoints: 50}, {Name: "Bob", VictoryPoints: 40}}})
+	event.Record(Result{Round: 1, Table: 2, Players: []PlayerResult{{Name: "Carol", VictoryPoints: 50}, {Name: "Dave", VictoryPoints: 30}}})
+	event.Record(Result{Round: 2, Table: 1, Players: []PlayerResult{{Name: "Alice", VictoryPoints: 40}, {Name: "Carol", VictoryPoints: 40}}})
+	event.Record(Result{Round: 2, Table: 2, Players: []PlayerResult{{Name: "Bob", VictoryPoints: 50}, {Name: "Dave", VictoryPoints: 45}}})
+
+	// Alice and Carol have a win, a draw and 90 victory points each, but Alice's opponents scored more points
+	var names []string
+	for _, standing := range event.Standings() {
+		names = append(names, standing.Name)
+	}
+	if !slices.Equal(names, []string{"Alice", "Carol", "Bob", "Dave"}) {
+		t.Errorf("Unexpected order of the standings: %v", names)
+	}
+}
+
+func TestEnterResultKeepsClockTimes(t *testing.T) {
+	event := newEvent()
+	event.Record(Result{Round: 1, Table: 1, GameTime: time.Hour, Players: []PlayerResult{
+		{Name: "Alice", TimeElapsed: 35 * time.Minute, Turns: 5},
+		{Name: "Bob", TimeElapsed: 25 * time.Minute, Turns: 5},
+	}})
+
+	// A concession is entered as a loss, whatever the victory points
+	event.EnterResult(1, 1, []PlayerResult{
+		{Name: "Alice", VictoryPoints: 70, Outcome: Loss},
+		{Name: "Bob", VictoryPoints: 20, Outcome: Win},
+	})
+
+	result := event.Results[0]
+	if len(event.Results) != 1 || result.GameTime != time.Hour || result.Players[0].TimeElapsed != 35*time.Minute {
+		t.Errorf("Expected the clock times to be kept, got %+v", result)
+	}
+	if result.Outcome(0) != Loss || result.Outcome(1) != Win {
+		t.Errorf("Expected the entered outcomes, got %s and %s", result.Outcome(0), result.Outcome(1))
+	}
+}
+
+func TestImportRosterAvoidsTeammates(t *testing.T) {
+	dir := t.TempDir()
+	eventFile := filepath.Join(dir, "cup.json")
+	rosterFile := filepath.Join(dir, "players.csv")
+	roster := "\ufeffPlayer,Army,Team,Paid\n" +
+		"Alice,Space Marines,North,yes\n" +
+		"Bob,Orks,North,yes\n" +
+		"Carol,Necrons,South,no\n" +
+		",,,\n" +
+		"Dave,Aeldari,South,yes\n"
+	if err := os.WriteFile(rosterFile, []byte(roster), 0644); err != nil {
+		t.Fatalf("Failed to write the roster: %v", err)
+	}
+	// The event does not need to list players before the import
+	if err := (&Event{Name: "Spring Cup", Rounds: 2, Tables: 2}).Save(eventFile); err != nil {
+		t.Fatalf("Failed to save the event: %v", err)
+	}
+
+	if _, err := ImportRosterFile(eventFile, rosterFile); err != nil {
+		t.Fatalf("Expected the roster to be imported, got error: %v", err)
+	}
+	event, err := Load(eventFile)
+	if err != nil {
+		t.Fatalf("Failed to load the event: %v", err)
+	}
+	if !slices.Equal(event.Players, []string{"Alice", "Bob", "Carol", "Dave"}) {
+		t.Errorf("Expected the players of the roster, got %v", event.Players)
+	}
+	if entrant := event.Entrant("Carol"); entrant.Faction != "Necrons" || entrant.Team != "South" {
+		t.Errorf("Expected Carol's faction and team, got %+v", entrant)
+	}
+
+	// Alice and Bob are both on team North, so Alice plays the first player of another team
+	pairing, _ := event.Table(1, 1)
+	if !slices.Equal(pairing.Players, []string{"Alice", "Carol"}) {
+		t.Errorf("Expected teammates not to be paired, got %v", pairing.Players)
+	}
+	if err := event.ImportRoster([]Entrant{{Name: "Eve"}, {Name: "Frank"}}); err == nil {
+		t.Error("Expected no import once the tables are assigned")
+	}
+
+	if _, err := readRoster(strings.NewReader("Faction\nOrks\n")); err == nil {
+		t.Error("Expected an error for a roster without names")
+	}
+	if _, err := readRoster(strings.NewReader("Name\nAlice\nAlice\n")); err == nil {
+		t.Error("Expected an error for a player listed twice")
+	}
+}
@@ -47,7 +47,7 @@ func CreateAboutPanel(mainColor tcell.Color) *tview.Flex {
 	aboutPanel.SetBorder(true)
 	aboutPanel.SetTitle(" About ")
 	aboutPanel.SetBorderColor(tcell.ColorYellow)
-	aboutPanel.SetBackgroundColor(tcell.ColorBlack)
+	aboutPanel.SetBackgroundColor(tview.Styles.PrimitiveBackgroundColor)
 
 	return aboutPanel
 }
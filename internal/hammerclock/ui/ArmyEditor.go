@@ -0,0 +1,22 @@
+package ui
+
+import (
+	"github.com/rivo/tview"
+)
+
+// CreateArmyEditor creates the army list editor of a player: a text area with a unit per line, e.g.
+// "Intercessors, 200", into which a list in plain text or Markdown can also be pasted, and buttons to save or cancel.
+// Escape cancels.
+func CreateArmyEditor(playerName, text string, save func(text string), cancel func()) *tview.Form {
+	units := tview.NewTextArea().
+		SetText(text, true).
+		SetPlaceholder("One unit per line: name, points (or paste a list)")
+
+	form := tview.NewForm().
+		AddFormItem(units).
+		AddButton("Save", func() { save(units.GetText()) }).
+		AddButton("Cancel", cancel).
+		SetCancelFunc(cancel)
+	form.SetBorder(true).SetTitle(" Army List - " + playerName + " ")
+	return form
+}
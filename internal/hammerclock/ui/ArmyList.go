@@ -0,0 +1,256 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/palette"
+)
+
+// maxArmyLines is the most units listed in the expanded army section of a player panel
+const maxArmyLines = 10
+
+// armyText returns the army section of a player panel: the number of units and their points, followed by a line per
+// unit with its wounds left while the section is expanded. Units with a status, e.g. destroyed ones, are shown in red
+// and damaged units in yellow. The section is empty for players without an army list.
+func armyText(player *common.Player, expanded bool, colors palette.ColorPalette) string {
+	if len(player.ArmyList) == 0 {
+		return ""
+	}
+	points := 0
+	for _, unit := range player.ArmyList {
+		points += unit.Points
+	}
+	if !expanded {
+		return fmt.Sprintf("▸ Army: %d units, %d pts", len(player.ArmyList), points)
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "▾ Army: %d units, %d pts", len(player.ArmyList), points)
+	for _, unit := range player.ArmyList[:min(len(player.ArmyList), maxArmyLines)] {
+		line := unitLine(unit)
+		if unit.Status != "" {
+			line = fmt.Sprintf("[#%06x]%s[-]", colors.Red.Hex(), line)
+		} else if unit.WoundsLost > 0 {
+			line = fmt.Sprintf("[#%06x]%s[-]", colors.Yellow.Hex(), line)
+		}
+		text.WriteString("\n" + line)
+	}
+	if more := len(player.ArmyList) - maxArmyLines; more > 0 {
+		fmt.Fprintf(&text, "\n... and %d more", more)
+	}
+	return text.String()
+}
+
+// UnitStatusKeys maps the keys of the unit status list to the statuses they toggle
+var UnitStatusKeys = map[rune]string{
+	'd': "destroyed",
+	'h': "below half",
+	's': "shaken",
+}
+
+// unitLine returns the line of a unit in the army section and the unit status list
+func unitLine(unit common.Unit) string {
+	line := fmt.Sprintf("%s - %d pts", tview.Escape(unit.Name), unit.Points)
+	if unit.Wounds > 0 {
+		line += fmt.Sprintf(", %d/%d W", unit.Wounds-unit.WoundsLost, unit.Wounds)
+	}
+	if unit.Status != "" {
+		line += " (" + tview.Escape(unit.Status) + ")"
+	}
+	if unit.Note != "" {
+		line += ": " + tview.Escape(unit.Note)
+	}
+	return line
+}
+
+// CreateUnitStatusList creates the list of a player's units in which the selected unit is marked destroyed, below
+// half strength or shaken with the D, H and S keys. Pressing the key of the unit's status again clears it, and N
+// edits the unit's note. The callbacks receive the index of the unit in the army list. Escape closes the list.
+//
+// Units with wounds lose a wound with - or a right click, and heal one with +. A unit losing its last wound is marked
+// destroyed, and healing a destroyed unit clears its status.
+//
+// Large lists are filtered incrementally: / starts typing a filter, which shows only the units whose name or status
+// contains it. Enter keeps the filter and Escape clears it.
+func CreateUnitStatusList(playerName string, units []common.Unit, setStatus func(unit int, status string),
+	setWounds func(unit int, woundsLost int), editNote func(unit int), done func()) *tview.List {
+	units = slices.Clone(units)
+	list := tview.NewList().
+		ShowSecondaryText(false)
+	list.SetBorder(true)
+
+	var (
+		filter    string
+		filtering bool
+		shown     []int // Indexes of the units shown, in the army list
+	)
+	update := func() {
+		list.Clear()
+		shown = shown[:0]
+		for i, unit := range units {
+			if unitMatches(unit, filter) {
+				shown = append(shown, i)
+				list.AddItem(unitLine(unit), "", 0, nil)
+			}
+		}
+		switch {
+		case filtering:
+			list.SetTitle(" Filter: " + tview.Escape(filter) + "_ (Enter to keep, Esc to clear) ")
+		case filter != "":
+			list.SetTitle(" Units - " + playerName + " matching \"" + tview.Escape(filter) + "\" (D, H, S, N, / to filter) ")
+		default:
+			list.SetTitle(" Units - " + playerName + " (D destroyed, H below half, S shaken, -/+ wounds, N note, / filter) ")
+		}
+	}
+	update()
+
+	// damage changes the wounds lost by the unit of a list item, and the destroyed status with them
+	damage := func(item int, wounds int) {
+		i := shown[item]
+		lost := min(max(units[i].WoundsLost+wounds, 0), units[i].Wounds)
+		if lost == units[i].WoundsLost {
+			return
+		}
+		units[i].WoundsLost = lost
+		setWounds(i, lost)
+		switch {
+		case lost == units[i].Wounds && units[i].Status != "destroyed":
+			units[i].Status = "destroyed"
+			setStatus(i, units[i].Status)
+		case lost < units[i].Wounds && units[i].Status == "destroyed":
+			units[i].Status = ""
+			setStatus(i, units[i].Status)
+		}
+		list.SetItemText(item, unitLine(units[i]), "")
+	}
+
+	list.SetDoneFunc(done)
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if filtering {
+			switch event.Key() {
+			case tcell.KeyRune:
+				filter += string(event.Rune())
+			case tcell.KeyBackspace, tcell.KeyBackspace2:
+				if r := []rune(filter); len(r) > 0 {
+					filter = string(r[:len(r)-1])
+				}
+			case tcell.KeyEnter:
+				filtering = false
+			case tcell.KeyEscape:
+				filter, filtering = "", false
+			default:
+				return event
+			}
+			update()
+			return nil
+		}
+
+		if event.Key() == tcell.KeyEscape {
+			done()
+			return nil
+		}
+		if event.Key() != tcell.KeyRune {
+			return event
+		}
+		if event.Rune() == '/' {
+			filtering = true
+			update()
+			return nil
+		}
+		if len(shown) == 0 {
+			return event
+		}
+		item := list.GetCurrentItem()
+		i := shown[item]
+		switch unicode.ToLower(event.Rune()) {
+		case 'n':
+			editNote(i)
+			return nil
+		case '-':
+			damage(item, 1)
+			return nil
+		case '+', '=':
+			damage(item, -1)
+			return nil
+		}
+		status, found := UnitStatusKeys[unicode.ToLower(event.Rune())]
+		if !found {
+			return event
+		}
+		if units[i].Status == status {
+			status = ""
+		}
+		units[i].Status = status
+		list.SetItemText(item, unitLine(units[i]), "")
+		setStatus(i, status)
+		return nil
+	})
+	list.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		if action != tview.MouseRightClick || filtering {
+			return action, event
+		}
+		x, y := event.Position()
+		_, top, _, height := list.GetInnerRect()
+		offset, _ := list.GetOffset()
+		item := y - top + offset
+		if !list.InRect(x, y) || y >= top+height || item < 0 || item >= len(shown) {
+			return action, event
+		}
+		list.SetCurrentItem(item)
+		damage(item, 1)
+		return action, nil
+	})
+	return list
+}
+
+// unitMatches reports whether the name or status of a unit contains the filter, ignoring case
+func unitMatches(unit common.Unit, filter string) bool {
+	filter = strings.ToLower(filter)
+	return strings.Contains(strings.ToLower(unit.Name), filter) || strings.Contains(strings.ToLower(unit.Status), filter)
+}
+
+// CreateKillPicker creates the list of opposing units to pick the one that destroyed a unit from. The first entry
+// leaves it unrecorded, as does Escape.
+func CreateKillPicker(unitName string, opponents []string, selected func(opponent string), cancel func()) *tview.List {
+	picker := tview.NewList().
+		ShowSecondaryText(false).
+		AddItem("(not recorded)", "", 0, cancel)
+	for _, opponent := range opponents {
+		picker.AddItem(tview.Escape(opponent), "", 0, func() { selected(opponent) })
+	}
+
+	picker.SetDoneFunc(cancel)
+	picker.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			cancel()
+			return nil
+		}
+		return event
+	})
+	picker.SetBorder(true).SetTitle(" " + unitName + " destroyed by ")
+	return picker
+}
+
+// CreateUnitNotePrompt creates the prompt editing the note of a unit. Saving an empty note removes it, and Escape
+// cancels.
+func CreateUnitNotePrompt(unit common.Unit, save func(note string), cancel func()) *tview.InputField {
+	prompt := tview.NewInputField().
+		SetLabel(unit.Name + ": ").
+		SetText(unit.Note).
+		SetFieldWidth(0)
+	prompt.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			save(strings.TrimSpace(prompt.GetText()))
+			return
+		}
+		cancel()
+	})
+	prompt.SetBorder(true).SetTitle(" Unit Note ")
+	return prompt
+}
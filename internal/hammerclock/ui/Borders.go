@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"github.com/rivo/tview"
+)
+
+// BorderStyles lists the styles the borders of panels and dialogs are drawn in. The ASCII style is for terminals
+// and fonts that render the Unicode box-drawing characters poorly.
+var BorderStyles = []string{"single", "double", "rounded", "ascii", "none"}
+
+// borderSet holds the characters of a border style. The sides and corners are, in order: horizontal, vertical, top
+// left, top right, bottom left and bottom right. The joints, used by the grid of the options screen, are: left T,
+// right T, top T, bottom T and cross.
+type borderSet struct {
+	sides  [6]rune
+	focus  [6]rune // Sides and corners of the focused panel, i.e. the active player's
+	joints [5]rune
+}
+
+// borderSets holds the characters of each border style
+var borderSets = map[string]borderSet{
+	"single": {
+		sides:  [6]rune{'─', '│', '┌', '┐', '└', '┘'},
+		focus:  [6]rune{'═', '║', '╔', '╗', '╚', '╝'},
+		joints: [5]rune{'├', '┤', '┬', '┴', '┼'},
+	},
+	"double": {
+		sides:  [6]rune{'═', '║', '╔', '╗', '╚', '╝'},
+		focus:  [6]rune{'━', '┃', '┏', '┓', '┗', '┛'},
+		joints: [5]rune{'╠', '╣', '╦', '╩', '╬'},
+	},
+	"rounded": {
+		sides:  [6]rune{'─', '│', '╭', '╮', '╰', '╯'},
+		focus:  [6]rune{'═', '║', '╔', '╗', '╚', '╝'},
+		joints: [5]rune{'├', '┤', '┬', '┴', '┼'},
+	},
+	"ascii": {
+		sides:  [6]rune{'-', '|', '+', '+', '+', '+'},
+		focus:  [6]rune{'=', '#', '#', '#', '#', '#'},
+		joints: [5]rune{'+', '+', '+', '+', '+'},
+	},
+	"none": {
+		sides:  [6]rune{' ', ' ', ' ', ' ', ' ', ' '},
+		focus:  [6]rune{' ', ' ', ' ', ' ', ' ', ' '},
+		joints: [5]rune{' ', ' ', ' ', ' ', ' '},
+	},
+}
+
+// BorderStyleToIndex converts a border style to an index in BorderStyles
+func BorderStyleToIndex(style string) int {
+	for i, s := range BorderStyles {
+		if s == style {
+			return i
+		}
+	}
+	return 0 // Default to single lines
+}
+
+// ApplyBorderStyle sets the characters tview draws all borders with, falling back to single lines for unknown
+// styles. Borders of the none style are blank, so the layout does not shift when they are turned off.
+func ApplyBorderStyle(style string) {
+	set, found := borderSets[style]
+	if !found {
+		set = borderSets["single"]
+	}
+	b := &tview.Borders
+	b.Horizontal, b.Vertical, b.TopLeft, b.TopRight, b.BottomLeft, b.BottomRight =
+		set.sides[0], set.sides[1], set.sides[2], set.sides[3], set.sides[4], set.sides[5]
+	b.HorizontalFocus, b.VerticalFocus, b.TopLeftFocus, b.TopRightFocus, b.BottomLeftFocus, b.BottomRightFocus =
+		set.focus[0], set.focus[1], set.focus[2], set.focus[3], set.focus[4], set.focus[5]
+	b.LeftT, b.RightT, b.TopT, b.BottomT, b.Cross =
+		set.joints[0], set.joints[1], set.joints[2], set.joints[3], set.joints[4]
+}
+
+// dividerText returns the line dividing the upper part of a player panel, drawn in the current border style
+func dividerText() string {
+	line := make([]rune, 30)
+	for i := range line {
+		line[i] = tview.Borders.Horizontal
+	}
+	return string(line)
+}
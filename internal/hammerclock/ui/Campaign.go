@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"hammerclock/internal/hammerclock/campaign"
+	"hammerclock/internal/hammerclock/palette"
+)
+
+// campaignHeaders are the column headers of the campaign members table
+var campaignHeaders = []string{"Player", "Faction", "Games", "W-L-D", "VP", "XP", "Territory", "Injuries"}
+
+// CreateCampaignTable creates the table showing the members of a campaign. Selecting a member calls selected with
+// the member's name.
+func CreateCampaignTable(colors palette.ColorPalette, selected func(name string)) *tview.Table {
+	members := tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(true, false)
+	members.SetBorder(true).
+		SetTitle(" Campaign ").
+		SetBorderColor(colors.Cyan).
+		SetBackgroundColor(colors.Black)
+	members.SetSelectedFunc(func(row, _ int) {
+		if name, ok := members.GetCell(row, 0).GetReference().(string); ok {
+			selected(name)
+		}
+	})
+	return members
+}
+
+// UpdateCampaignTable shows the members of the campaign below the column headers, with the campaign name and its
+// number of games in the title
+func UpdateCampaignTable(table *tview.Table, c *campaign.Campaign, colors palette.ColorPalette) {
+	table.Clear()
+	table.SetTitle(fmt.Sprintf(" %s - %d games ", tview.Escape(c.Name), len(c.Games)))
+	for column, header := range campaignHeaders {
+		table.SetCell(0, column, tview.NewTableCell(header).
+			SetTextColor(colors.Yellow).
+			SetAttributes(tcell.AttrBold).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+	for i, member := range c.Members {
+		stats := c.Stats(member.Name)
+		row := []string{
+			tview.Escape(member.Name),
+			tview.Escape(member.Faction),
+			strconv.Itoa(stats.Games),
+			fmt.Sprintf("%d-%d-%d", stats.Wins, stats.Losses, stats.Draws),
+			strconv.Itoa(stats.VictoryPoints),
+			strconv.Itoa(member.XP),
+			strconv.Itoa(member.Territory),
+			tview.Escape(strings.Join(member.Injuries, ", ")),
+		}
+		for column, text := range row {
+			table.SetCell(i+1, column, tview.NewTableCell(text).SetTextColor(colors.White).SetExpansion(1))
+		}
+		table.GetCell(i+1, 0).SetReference(member.Name)
+	}
+}
+
+// CreateCampaignLog creates the text view showing the campaign log
+func CreateCampaignLog(colors palette.ColorPalette) *tview.TextView {
+	log := tview.NewTextView().SetDynamicColors(true)
+	log.SetBorder(true).
+		SetTitle(" Campaign Log ").
+		SetBorderColor(colors.Cyan).
+		SetBackgroundColor(colors.Black)
+	return log
+}
+
+// UpdateCampaignLog shows the campaign log, the latest entry last
+func UpdateCampaignLog(log *tview.TextView, c *campaign.Campaign) {
+	var text strings.Builder
+	for _, entry := range c.Log {
+		fmt.Fprintf(&text, "[::d]%s[::-] %s\n", entry.Time.Format("2006-01-02"), tview.Escape(entry.Message))
+	}
+	log.SetText(text.String())
+	log.ScrollToEnd()
+}
+
+// CreateCampaignForm creates the form for changing the state of a campaign member between the games: experience
+// and territory gained or lost, a new injury and the reason. Saving calls save with the change and quitting calls quit.
+func CreateCampaignForm(member campaign.Member, colors palette.ColorPalette, save func(change campaign.Change),
+	quit func()) *tview.Form {
+	form := tview.NewForm()
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s - %d XP, %d territory ", member.Name, member.XP, member.Territory)).
+		SetBorderColor(colors.Cyan)
+
+	xpBox := tview.NewInputField().
+		SetLabel("XP gained or spent: ").
+		SetAcceptanceFunc(tview.InputFieldInteger).
+		SetFieldWidth(5)
+	territoryBox := tview.NewInputField().
+		SetLabel("Territory gained or lost: ").
+		SetAcceptanceFunc(tview.InputFieldInteger).
+		SetFieldWidth(5)
+	injuryBox := tview.NewInputField().SetLabel("New injury: ").SetFieldWidth(30)
+	noteBox := tview.NewInputField().SetLabel("Note: ").SetFieldWidth(40)
+	form.AddFormItem(xpBox).AddFormItem(territoryBox).AddFormItem(injuryBox).AddFormItem(noteBox)
+
+	form.AddButton("Save", func() {
+		change := campaign.Change{Injury: injuryBox.GetText(), Note: noteBox.GetText()}
+		change.XP, _ = strconv.Atoi(xpBox.GetText())
+		change.Territory, _ = strconv.Atoi(territoryBox.GetText())
+		save(change)
+	})
+	form.AddButton("Quit", quit)
+	form.SetCancelFunc(quit)
+	return form
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"hammerclock/internal/hammerclock/palette"
+)
+
+// dashboardHeaders are the column headers of the organizer dashboard
+var dashboardHeaders = []string{"Table", "Round", "Active Player", "Status", "Game Time", "Remaining"}
+
+// CreateDashboard creates the organizer dashboard, a table with a row for each linked table
+func CreateDashboard(colors palette.ColorPalette) *tview.Table {
+	dashboard := tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(false, false)
+	dashboard.SetBorder(true).
+		SetBorderColor(colors.Cyan).
+		SetBackgroundColor(colors.Black)
+	UpdateDashboard(dashboard, nil, "", colors)
+	return dashboard
+}
+
+// CreateDashboardPrompt creates the input line the organizer enters the round time and announcements in
+func CreateDashboardPrompt(colors palette.ColorPalette) *tview.InputField {
+	return tview.NewInputField().
+		SetFieldBackgroundColor(colors.Black).
+		SetFieldTextColor(colors.White).
+		SetLabelColor(colors.Yellow)
+}
+
+// UpdateDashboard shows the given rows below the column headers, with lost tables in red, and the round timer
+// pushed to the tables in the title
+func UpdateDashboard(dashboard *tview.Table, rows [][]string, roundTimer string, colors palette.ColorPalette) {
+	title := " Hammerclock Dashboard - R round time, A announce, Q quit "
+	if roundTimer != "" {
+		title = " Hammerclock Dashboard - " + roundTimer + " - R round time, A announce, Q quit "
+	}
+	dashboard.SetTitle(tview.Escape(title))
+	dashboard.Clear()
+	for column, header := range dashboardHeaders {
+		dashboard.SetCell(0, column, tview.NewTableCell(header).
+			SetTextColor(colors.Yellow).
+			SetAttributes(tcell.AttrBold).
+			SetExpansion(1))
+	}
+	if len(rows) == 0 {
+		dashboard.SetCell(1, 0, tview.NewTableCell("Looking for tables on the local network...").SetTextColor(colors.White))
+	}
+	for i, row := range rows {
+		color := colors.White
+		if row[3] == "Connection lost" {
+			color = colors.Red
+		}
+		for column, text := range row {
+			dashboard.SetCell(i+1, column, tview.NewTableCell(tview.Escape(text)).SetTextColor(color).SetExpansion(1))
+		}
+	}
+}
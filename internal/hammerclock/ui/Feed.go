@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rivo/tview"
+	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/palette"
+)
+
+// CreateFeedScreen creates the screen showing the action logs of all players merged into a single feed
+func CreateFeedScreen(colors palette.ColorPalette) *tview.TextView {
+	feed := createLogView()
+	feed.SetChangedFunc(func() { feed.ScrollToEnd() })
+	setupLogViewInputHandling(feed)
+	feed.SetBorder(true).
+		SetTitle(" Game Feed (F to return) ").
+		SetTitleColor(colors.White).
+		SetBorderColor(colors.Cyan)
+	return feed
+}
+
+// feedEntry is an entry of a player's action log with the index of the player
+type feedEntry struct {
+	player int
+	entry  common.LogEntry
+}
+
+// FeedText returns the action logs of the players merged in chronological order. Each entry starts with the name of
+// its player in the color of their panel and is colored by type like in the action log panels. Entries of the same
+// second keep the order of the players.
+func FeedText(players []*common.Player, colors palette.ColorPalette, timestamps string) string {
+	var entries []feedEntry
+	for i, player := range players {
+		for _, entry := range player.ActionLog {
+			entries = append(entries, feedEntry{player: i, entry: entry})
+		}
+	}
+	// The log timestamps sort chronologically as text
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].entry.DateTime < entries[j].entry.DateTime
+	})
+
+	var text strings.Builder
+	for _, e := range entries {
+		playerColor := panelColor(PanelColors[e.player%len(PanelColors)], colors)
+		fmt.Fprintf(&text, "[#%06x::b]%s[-::-] [#%06x]%s[-]\n", playerColor.Hex(), tview.Escape(players[e.player].Name),
+			logEntryColor(e.entry.Type, colors).Hex(), displayLogEntry(e.entry, timestamps))
+	}
+	return text.String()
+}
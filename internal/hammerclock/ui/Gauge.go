@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"hammerclock/internal/hammerclock/palette"
+)
+
+// gaugeWidth is the width of the time budget gauge in a player panel, in cells
+const gaugeWidth = 20
+
+// Thresholds of the time budget gauge: it turns yellow once this share of the budget is used, then red
+const (
+	gaugeWarning  = 0.75
+	gaugeCritical = 0.9
+)
+
+// gaugeEighths are the block characters filling a cell of a gauge by eighths, from empty to full
+var gaugeEighths = []rune(" ▏▎▍▌▋▊▉█")
+
+// BudgetGauge returns a gauge of the given width, in block characters, showing the share of the time budget used
+// with the percentage after it. It is green, turns yellow and then red as the budget runs out, and is empty for
+// clocks without a budget.
+func BudgetGauge(elapsed, budget time.Duration, width int, colors palette.ColorPalette) string {
+	if budget <= 0 || width <= 0 {
+		return ""
+	}
+	used := min(max(float64(elapsed)/float64(budget), 0), 1)
+
+	color := colors.Green
+	switch {
+	case used >= gaugeCritical:
+		color = colors.Red
+	case used >= gaugeWarning:
+		color = colors.Yellow
+	}
+
+	eighths := int(used * float64(width*8))
+	bar := strings.Repeat(string(gaugeEighths[8]), eighths/8)
+	if eighths < width*8 {
+		bar += string(gaugeEighths[eighths%8])
+	}
+	bar += strings.Repeat(" ", width-len([]rune(bar)))
+	return fmt.Sprintf("[#%06x:#%06x]%s[-:-] %3.0f%%", color.Hex(), colors.DimWhite.Hex(), bar, used*100)
+}
+
+// sparkWidth is the number of recent turns shown in the sparkline of a player panel
+const sparkWidth = 12
+
+// sparkLevels are the block characters of a sparkline, from the shortest to the longest turn
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline returns a sparkline of the last width durations, one block character each, scaled to the longest of
+// them. It is empty without any durations.
+func Sparkline(durations []time.Duration, width int) string {
+	if len(durations) > width {
+		durations = durations[len(durations)-width:]
+	}
+	longest := time.Duration(0)
+	for _, d := range durations {
+		longest = max(longest, d)
+	}
+
+	line := make([]rune, len(durations))
+	for i, d := range durations {
+		level := 0
+		if longest > 0 {
+			level = int(float64(max(d, 0)) / float64(longest) * float64(len(sparkLevels)-1))
+		}
+		line[i] = sparkLevels[level]
+	}
+	return string(line)
+}
@@ -8,8 +8,22 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/palette"
 )
 
+// LogTimestampFormats lists the timestamp formats available for the action log panels.
+var LogTimestampFormats = []string{"full", "time", "none"}
+
+// LogTimestampsToIndex converts the log timestamp format to an index in LogTimestampFormats
+func LogTimestampsToIndex(format string) int {
+	for i, f := range LogTimestampFormats {
+		if f == format {
+			return i
+		}
+	}
+	return 1 // Default to time only
+}
+
 // createLogView initializes a scrollable, word-wrapped text view for logs.
 func createLogView() *tview.TextView {
 	return tview.NewTextView().
@@ -45,8 +59,29 @@ func setupLogViewInputHandling(logView *tview.TextView) {
 	})
 }
 
-// SetLogContent updates the log view with the provided log entries.
-func SetLogContent(logView *tview.TextView, logEntries interface{}) {
+// ScrollPlayerLog scrolls the action log of a player panel by the given number of lines,
+// or to its beginning or end.
+func ScrollPlayerLog(panel *tview.Flex, lines int, toTop bool, toBottom bool) {
+	lower, ok := panel.GetItem(1).(*tview.Flex)
+	if !ok || lower.GetItemCount() < 2 {
+		return
+	}
+	logView := lower.GetItem(1).(*tview.Flex).GetItem(0).(*tview.TextView)
+
+	switch {
+	case toTop:
+		logView.ScrollToBeginning()
+	case toBottom:
+		logView.ScrollToEnd()
+	default:
+		row, _ := logView.GetScrollOffset()
+		logView.ScrollTo(max(row+lines, 0), 0)
+	}
+}
+
+// SetLogContent updates the log view with the provided log entries, colored by entry type.
+// The timestamps argument selects how entry timestamps are shown: full, time or none.
+func SetLogContent(logView *tview.TextView, logEntries interface{}, colors palette.ColorPalette, timestamps string) {
 	if logView == nil {
 		return
 	}
@@ -55,7 +90,7 @@ func SetLogContent(logView *tview.TextView, logEntries interface{}) {
 	switch entries := logEntries.(type) {
 	case []interface{}:
 		for _, entry := range entries {
-			logText.WriteString(formatLogEntry(entry))
+			logText.WriteString(formatLogEntry(entry, colors, timestamps))
 		}
 	case []string:
 		for _, entry := range entries {
@@ -64,10 +99,10 @@ func SetLogContent(logView *tview.TextView, logEntries interface{}) {
 	default:
 		if slice, ok := tryGetSlice(logEntries); ok {
 			for _, entry := range slice {
-				logText.WriteString(formatLogEntry(entry))
+				logText.WriteString(formatLogEntry(entry, colors, timestamps))
 			}
 		} else {
-			logText.WriteString(formatLogEntry(logEntries))
+			logText.WriteString(formatLogEntry(logEntries, colors, timestamps))
 		}
 	}
 
@@ -77,10 +112,10 @@ func SetLogContent(logView *tview.TextView, logEntries interface{}) {
 }
 
 // formatLogEntry converts a log entry to a string.
-func formatLogEntry(entry interface{}) string {
+func formatLogEntry(entry interface{}, colors palette.ColorPalette, timestamps string) string {
 	switch e := entry.(type) {
 	case common.LogEntry:
-		return displayLogEntry(e) + "\n"
+		return fmt.Sprintf("[#%06x]%s[-]\n", logEntryColor(e.Type, colors).Hex(), displayLogEntry(e, timestamps))
 	case fmt.Stringer:
 		return e.String() + "\n"
 	default:
@@ -103,9 +138,35 @@ func tryGetSlice(obj interface{}) ([]interface{}, bool) {
 }
 
 // displayLogEntry returns a simplified string representation for UI display.
-func displayLogEntry(le common.LogEntry) string {
+func displayLogEntry(le common.LogEntry, timestamps string) string {
+	switch timestamps {
+	case "none":
+		return tview.Escape(le.Message)
+	case "full":
+		return tview.Escape(fmt.Sprintf("[%s] %s", le.DateTime, le.Message))
+	}
 	if spaceIdx := strings.Index(le.DateTime, " "); spaceIdx != -1 {
-		return fmt.Sprintf("[%s] %s", le.DateTime[spaceIdx+1:], le.Message)
+		return tview.Escape(fmt.Sprintf("[%s] %s", le.DateTime[spaceIdx+1:], le.Message))
+	}
+	return tview.Escape(fmt.Sprintf("[%s] %s", le.DateTime, le.Message))
+}
+
+// logEntryColor returns the color used to display log entries of the given type.
+func logEntryColor(entryType common.LogEntryType, colors palette.ColorPalette) tcell.Color {
+	switch entryType {
+	case common.LogTypeTurn:
+		return colors.Cyan
+	case common.LogTypePhase:
+		return colors.Green
+	case common.LogTypeScore:
+		return colors.Yellow
+	case common.LogTypeWarning:
+		return colors.Red
+	case common.LogTypeNote:
+		return colors.White
+	case common.LogTypeDice:
+		return colors.Blue
+	default:
+		return colors.DimWhite
 	}
-	return fmt.Sprintf("[%s] %s", le.DateTime, le.Message)
 }
@@ -30,8 +30,28 @@ func CreateMenuBar(options []MenuOption) *tview.TextView {
 	return menuText
 }
 
+// SetMenuSelectedFunc makes the options of a menu bar clickable.
+// The handler is called with the key of the clicked option.
+func SetMenuSelectedFunc(menu *tview.TextView, handler func(key string)) *tview.TextView {
+	menu.SetRegions(true).
+		SetHighlightedFunc(func(added, removed, remaining []string) {
+			if len(added) == 0 {
+				return
+			}
+			// Clear the highlight so the same option can be clicked again
+			menu.Highlight()
+			handler(added[0])
+		})
+	return menu
+}
+
 // formatMenuOption formats a single menu option for display in the menu bar.
 func formatMenuOption(option MenuOption) string {
-	menuItem := fmt.Sprintf("[white]%s[d:] %s", option.Key, option.Description)
+	return FormatMenuOption(option, "white")
+}
+
+// FormatMenuOption formats a menu option as a clickable region with the key in the given color.
+func FormatMenuOption(option MenuOption, keyColor string) string {
+	menuItem := fmt.Sprintf(`["%s"][%s]%s[d:] %s[""]`, option.Key, keyColor, option.Key, option.Description)
 	return menuItem
 }
@@ -0,0 +1,22 @@
+package ui
+
+import (
+	"github.com/rivo/tview"
+)
+
+// CreateOptionsDiff creates a dialog listing the customized options, closed with its Close button
+func CreateOptionsDiff(text string, close func()) *tview.Flex {
+	diffView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWordWrap(true).
+		SetText(text)
+
+	closeButton := tview.NewButton("Close").SetSelectedFunc(close)
+
+	dialog := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(diffView, 0, 1, false).
+		AddItem(closeButton, 1, 0, true)
+	dialog.SetBorder(true).SetTitle(" Customized Options ")
+	return dialog
+}
@@ -8,14 +8,70 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
 	"hammerclock/internal/hammerclock/palette"
 	"hammerclock/internal/hammerclock/rules"
 )
 
+// tickIntervals lists the tick intervals in milliseconds offered on the options screen, named in tickIntervalNames
+var (
+	tickIntervals     = []int{250, 500, 1000, 2000}
+	tickIntervalNames = []string{"250ms (smooth)", "500ms", "1s", "2s (low power)"}
+)
+
+// TickIntervalToIndex converts a tick interval in milliseconds to the index of the closest offered interval
+func TickIntervalToIndex(milliseconds int) int {
+	closest := 2 // Default to 1s
+	if milliseconds == 0 {
+		return closest
+	}
+	for i, interval := range tickIntervals {
+		if abs(interval-milliseconds) < abs(tickIntervals[closest]-milliseconds) {
+			closest = i
+		}
+	}
+	return closest
+}
+
+// abs returns the absolute value of an integer
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// FlagFallActions lists what can happen when a player runs out of time
+var FlagFallActions = []string{"continue", "pause", "over", "end"}
+
+// FlagFallToIndex converts a flag fall action to an index in FlagFallActions
+func FlagFallToIndex(action string) int {
+	for i, a := range FlagFallActions {
+		if a == action {
+			return i
+		}
+	}
+	return 0 // Default to keep counting
+}
+
+// TimeOddsPresets lists the time odds offered on the options screen. Other ratios and handicaps, e.g. 5:4 or -15m,
+// can be set in the options file.
+var TimeOddsPresets = []string{"none", "2:1", "3:2", "-10m", "-20m", "-30m"}
+
+// TimeOddsToIndex converts time odds to an index in TimeOddsPresets
+func TimeOddsToIndex(odds string) int {
+	for i, o := range TimeOddsPresets {
+		if o == odds {
+			return i
+		}
+	}
+	return 0 // Default to even clocks
+}
+
 // CreateOptionsScreen creates the options screen with various settings
 func CreateOptionsScreen(model *common.Model, msgChan chan<- common.Message) *tview.Grid {
 	optionsPanel := tview.NewGrid().
-		SetRows(10).
+		SetRows(33).
 		SetColumns(0).
 		SetBorders(true)
 
@@ -56,6 +112,9 @@ func CreateOptionsScreen(model *common.Model, msgChan chan<- common.Message) *tv
 	// CreateAboutPanel player name input fields
 	playerNamesBox := createPlayerNameFields(model, msgChan)
 
+	// CreateAboutPanel fields for importing the players' army lists from BattleScribe rosters
+	rostersBox := createRosterFields(model, msgChan)
+
 	// CreateAboutPanel dropdown for color palettes
 	colorPaletteBox := tview.NewDropDown().
 		SetLabel("Select color palette: ").
@@ -68,6 +127,15 @@ func CreateOptionsScreen(model *common.Model, msgChan chan<- common.Message) *tv
 		updateRulesetContent(model, currentRulesetContentBox)
 	})
 
+	// CreateAboutPanel checkbox for leaving the terminal's background, e.g. of translucent themes
+	transparentBackgroundBox := tview.NewCheckbox().
+		SetLabel("Transparent background: ").
+		SetChecked(model.Options.TransparentBackground).
+		SetLabelColor(model.CurrentColorPalette.White)
+	transparentBackgroundBox.SetChangedFunc(func(checked bool) {
+		msgChan <- &common.SetTransparentBackgroundMsg{Value: checked}
+	})
+
 	// CreateAboutPanel dropdown for time format
 	timeFormatBox := tview.NewDropDown().
 		SetLabel("Select time format: ").
@@ -80,6 +148,194 @@ func CreateOptionsScreen(model *common.Model, msgChan chan<- common.Message) *tv
 		updateRulesetContent(model, currentRulesetContentBox)
 	})
 
+	// CreateAboutPanel input field for the language of the phase names
+	localeBox := tview.NewInputField().
+		SetLabel("Phase names language (e.g. de): ").
+		SetText(model.Options.Locale).
+		SetLabelColor(model.CurrentColorPalette.White).
+		SetFieldWidth(8)
+	localeBox.SetChangedFunc(func(text string) {
+		msgChan <- &common.SetLocaleMsg{Locale: text}
+	})
+
+	// CreateAboutPanel dropdown for the timestamps shown in the action logs
+	logTimestampsBox := tview.NewDropDown().
+		SetLabel("Log timestamps: ").
+		SetOptions(LogTimestampFormats, nil).
+		SetCurrentOption(LogTimestampsToIndex(model.Options.LogTimestamps)).
+		SetLabelColor(model.CurrentColorPalette.White)
+	logTimestampsBox.SetSelectedFunc(func(option string, index int) {
+		msgChan <- &common.SetLogTimestampsMsg{Format: option}
+		updateRulesetContent(model, currentRulesetContentBox)
+	})
+
+	// CreateAboutPanel dropdown for the arrangement of the player panels
+	layoutBox := tview.NewDropDown().
+		SetLabel("Player panel layout: ").
+		SetOptions(Layouts, nil).
+		SetCurrentOption(LayoutToIndex(model.Options.Layout)).
+		SetLabelColor(model.CurrentColorPalette.White)
+	layoutBox.SetSelectedFunc(func(option string, index int) {
+		msgChan <- &common.SetLayoutMsg{Layout: option}
+	})
+
+	// CreateAboutPanel dropdown for the cue shown on the new player's panel after a turn switch
+	turnCueBox := tview.NewDropDown().
+		SetLabel("Turn switch cue: ").
+		SetOptions(TurnCues, nil).
+		SetCurrentOption(TurnCueToIndex(model.Options.TurnCue)).
+		SetLabelColor(model.CurrentColorPalette.White)
+	turnCueBox.SetSelectedFunc(func(option string, index int) {
+		msgChan <- &common.SetTurnCueMsg{Cue: option}
+	})
+
+	// CreateAboutPanel dropdown for the style borders are drawn in
+	borderStyleBox := tview.NewDropDown().
+		SetLabel("Border style: ").
+		SetOptions(BorderStyles, nil).
+		SetCurrentOption(BorderStyleToIndex(model.Options.BorderStyle)).
+		SetLabelColor(model.CurrentColorPalette.White)
+	borderStyleBox.SetSelectedFunc(func(option string, index int) {
+		msgChan <- &common.SetBorderStyleMsg{Style: option}
+	})
+
+	// CreateAboutPanel input field for the widgets of the player panels, in order
+	panelWidgetsBox := tview.NewInputField().
+		SetLabel("Panel widgets: ").
+		SetText(strings.Join(ShownPanelWidgets(model.Options.PanelWidgets), ", ")).
+		SetLabelColor(model.CurrentColorPalette.White).
+		SetFieldWidth(40)
+	panelWidgetsBox.SetChangedFunc(func(text string) {
+		msgChan <- &common.SetPanelWidgetsMsg{Widgets: ParsePanelWidgets(text)}
+	})
+
+	// CreateAboutPanel dropdown for the interval between clock updates
+	tickIntervalBox := tview.NewDropDown().
+		SetLabel("Tick interval: ").
+		SetOptions(tickIntervalNames, nil).
+		SetCurrentOption(TickIntervalToIndex(model.Options.TickInterval)).
+		SetLabelColor(model.CurrentColorPalette.White)
+	tickIntervalBox.SetSelectedFunc(func(option string, index int) {
+		msgChan <- &common.SetTickIntervalMsg{Milliseconds: tickIntervals[index]}
+		updateRulesetContent(model, currentRulesetContentBox)
+	})
+
+	// CreateAboutPanel input field and dropdown for the time budget and what happens when it runs out
+	timeBudgetBox := tview.NewInputField().
+		SetLabel("Time budget (minutes, 0 = none): ").
+		SetText(strconv.Itoa(model.Options.TimeBudget)).
+		SetAcceptanceFunc(tview.InputFieldInteger).
+		SetLabelColor(model.CurrentColorPalette.White).
+		SetFieldWidth(5)
+	timeBudgetBox.SetChangedFunc(func(text string) {
+		minutes, _ := strconv.Atoi(text)
+		msgChan <- &common.SetTimeBudgetMsg{Minutes: minutes}
+	})
+	byoYomiPeriodsBox := tview.NewInputField().
+		SetLabel("Byo-yomi periods (0 = none): ").
+		SetText(strconv.Itoa(model.Options.ByoYomiPeriods)).
+		SetAcceptanceFunc(tview.InputFieldInteger).
+		SetLabelColor(model.CurrentColorPalette.White).
+		SetFieldWidth(3)
+	byoYomiSecondsBox := tview.NewInputField().
+		SetLabel("Byo-yomi period (seconds): ").
+		SetText(strconv.Itoa(model.Options.ByoYomiSeconds)).
+		SetAcceptanceFunc(tview.InputFieldInteger).
+		SetLabelColor(model.CurrentColorPalette.White).
+		SetFieldWidth(4)
+	setByoYomi := func(string) {
+		periods, _ := strconv.Atoi(byoYomiPeriodsBox.GetText())
+		seconds, _ := strconv.Atoi(byoYomiSecondsBox.GetText())
+		msgChan <- &common.SetByoYomiMsg{Periods: periods, Seconds: seconds}
+	}
+	byoYomiPeriodsBox.SetChangedFunc(setByoYomi)
+	byoYomiSecondsBox.SetChangedFunc(setByoYomi)
+	gameSizeBox := tview.NewInputField().
+		SetLabel("Game size (points, 0 = none): ").
+		SetText(strconv.Itoa(model.Options.GameSize)).
+		SetAcceptanceFunc(tview.InputFieldInteger).
+		SetLabelColor(model.CurrentColorPalette.White).
+		SetFieldWidth(6)
+	gameSizeBox.SetChangedFunc(func(text string) {
+		points, _ := strconv.Atoi(text)
+		msgChan <- &common.SetGameSizeMsg{Points: points}
+	})
+	timeOddsBox := tview.NewDropDown().
+		SetLabel("Time odds of the first player: ").
+		SetOptions(TimeOddsPresets, nil).
+		SetCurrentOption(TimeOddsToIndex(model.Options.TimeOdds)).
+		SetLabelColor(model.CurrentColorPalette.White)
+	timeOddsBox.SetSelectedFunc(func(option string, index int) {
+		msgChan <- &common.SetTimeOddsMsg{Odds: option}
+	})
+	flagFallBox := tview.NewDropDown().
+		SetLabel("When time runs out: ").
+		SetOptions(FlagFallActions, nil).
+		SetCurrentOption(FlagFallToIndex(model.Options.FlagFall)).
+		SetLabelColor(model.CurrentColorPalette.White)
+	flagFallBox.SetSelectedFunc(func(option string, index int) {
+		msgChan <- &common.SetFlagFallMsg{Action: option}
+		updateRulesetContent(model, currentRulesetContentBox)
+	})
+
+	// CreateAboutPanel input field for the grace period after a turn switch
+	gracePeriodBox := tview.NewInputField().
+		SetLabel("Grace period after turn switch (seconds): ").
+		SetText(strconv.Itoa(model.Options.GracePeriod)).
+		SetAcceptanceFunc(tview.InputFieldInteger).
+		SetLabelColor(model.CurrentColorPalette.White).
+		SetFieldWidth(4)
+	gracePeriodBox.SetChangedFunc(func(text string) {
+		seconds, _ := strconv.Atoi(text)
+		msgChan <- &common.SetGracePeriodMsg{Seconds: seconds}
+	})
+
+	// CreateAboutPanel checkboxes for the extra clock elements in the top bar
+	clockShowDateBox := tview.NewCheckbox().
+		SetLabel("Show Date Next To Clock: ").
+		SetChecked(model.Options.ClockShowDate).
+		SetLabelColor(model.CurrentColorPalette.White)
+	clockShowDateBox.SetChangedFunc(func(checked bool) {
+		msgChan <- &common.SetClockShowDateMsg{Value: checked}
+	})
+	clockShowGameTimeBox := tview.NewCheckbox().
+		SetLabel("Show Game Time Next To Clock: ").
+		SetChecked(model.Options.ClockShowGameTime).
+		SetLabelColor(model.CurrentColorPalette.White)
+	clockShowGameTimeBox.SetChangedFunc(func(checked bool) {
+		msgChan <- &common.SetClockShowGameTimeMsg{Value: checked}
+	})
+
+	// CreateAboutPanel input field for the custom banner
+	bannerBox := tview.NewInputField().
+		SetLabel("Banner: ").
+		SetText(model.Options.Banner).
+		SetLabelColor(model.CurrentColorPalette.White).
+		SetFieldWidth(30)
+	bannerBox.SetChangedFunc(func(text string) {
+		msgChan <- &common.SetBannerMsg{Text: strings.TrimSpace(text)}
+	})
+
+	// CreateAboutPanel input fields for the event and table identifying the table in logs and exports
+	eventNameBox := tview.NewInputField().
+		SetLabel("Event: ").
+		SetText(model.Options.EventName).
+		SetLabelColor(model.CurrentColorPalette.White).
+		SetFieldWidth(30)
+	eventNameBox.SetChangedFunc(func(text string) {
+		msgChan <- &common.SetEventNameMsg{Text: strings.TrimSpace(text)}
+	})
+	tableNumberBox := tview.NewInputField().
+		SetLabel("Table number (0 for none): ").
+		SetText(strconv.Itoa(model.Options.TableNumber)).
+		SetAcceptanceFunc(tview.InputFieldInteger).
+		SetLabelColor(model.CurrentColorPalette.White).
+		SetFieldWidth(4)
+	tableNumberBox.SetChangedFunc(func(text string) {
+		number, _ := strconv.Atoi(text)
+		msgChan <- &common.SetTableNumberMsg{Number: number}
+	})
+
 	// CreateAboutPanel checkbox for "One Turn For All Players"
 	oneTurnForAllPlayersBox := tview.NewCheckbox().
 		SetLabel("One Turn For All Players: ").
@@ -101,14 +357,147 @@ func CreateOptionsScreen(model *common.Model, msgChan chan<- common.Message) *tv
 		updateRulesetContent(model, currentRulesetContentBox)
 	})
 
+	battleReportBox := tview.NewCheckbox().
+		SetLabel("Write Battle Reports: ").
+		SetChecked(model.Options.BattleReport).
+		SetLabelColor(model.CurrentColorPalette.White)
+	battleReportBox.SetChangedFunc(func(checked bool) {
+		msgChan <- &common.SetBattleReportMsg{Value: checked}
+	})
+
+	resumeGameBox := tview.NewCheckbox().
+		SetLabel("Offer to Resume Unfinished Games: ").
+		SetChecked(model.Options.ResumeGame).
+		SetLabelColor(model.CurrentColorPalette.White)
+	resumeGameBox.SetChangedFunc(func(checked bool) {
+		msgChan <- &common.SetResumeGameMsg{Value: checked}
+	})
+
+	// CreateAboutPanel checkbox for the end-of-turn secondary objective prompt
+	secondaryObjectivesBox := tview.NewCheckbox().
+		SetLabel("Prompt Secondary Objectives: ").
+		SetChecked(model.Options.PromptSecondaryObjectives).
+		SetLabelColor(model.CurrentColorPalette.White)
+	secondaryObjectivesBox.SetChangedFunc(func(checked bool) {
+		msgChan <- &common.SetPromptSecondaryObjectivesMsg{Value: checked}
+		updateRulesetContent(model, currentRulesetContentBox)
+	})
+
+	// CreateAboutPanel checkbox for vim-style key bindings
+	vimBindingsBox := tview.NewCheckbox().
+		SetLabel("Vim Key Bindings: ").
+		SetChecked(model.Options.VimBindings).
+		SetLabelColor(model.CurrentColorPalette.White)
+	vimBindingsBox.SetChangedFunc(func(checked bool) {
+		msgChan <- &common.SetVimBindingsMsg{Value: checked}
+		updateRulesetContent(model, currentRulesetContentBox)
+	})
+
+	// CreateAboutPanel checkbox for pausing the clocks while a dialog is open
+	pauseOnModalBox := tview.NewCheckbox().
+		SetLabel("Pause on Dialogs: ").
+		SetChecked(model.Options.PauseOnModal).
+		SetLabelColor(model.CurrentColorPalette.White)
+	pauseOnModalBox.SetChangedFunc(func(checked bool) {
+		msgChan <- &common.SetPauseOnModalMsg{Value: checked}
+		updateRulesetContent(model, currentRulesetContentBox)
+	})
+
+	// CreateAboutPanel checkbox for pausing the game when the terminal loses the focus
+	pauseOnFocusLossBox := tview.NewCheckbox().
+		SetLabel("Pause on Focus Loss: ").
+		SetChecked(model.Options.PauseOnFocusLoss).
+		SetLabelColor(model.CurrentColorPalette.White)
+	pauseOnFocusLossBox.SetChangedFunc(func(checked bool) {
+		msgChan <- &common.SetPauseOnFocusLossMsg{Value: checked}
+		updateRulesetContent(model, currentRulesetContentBox)
+	})
+
+	// CreateAboutPanel checkbox for posting the events of the game to the Discord webhook
+	discordBox := tview.NewCheckbox().
+		SetLabel("Discord Notifications: ").
+		SetChecked(model.Options.Discord.Enabled).
+		SetLabelColor(model.CurrentColorPalette.White)
+	discordBox.SetChangedFunc(func(checked bool) {
+		msgChan <- &common.SetDiscordNotificationsMsg{Value: checked}
+		updateRulesetContent(model, currentRulesetContentBox)
+	})
+
+	// CreateAboutPanel buttons to save the options or discard the unsaved changes
+	saveButton := tview.NewButton("Save").SetSelectedFunc(func() {
+		msgChan <- &common.SaveOptionsMsg{}
+	})
+	revertButton := tview.NewButton("Revert").SetSelectedFunc(func() {
+		msgChan <- &common.RevertOptionsMsg{}
+	})
+	resetAllButton := tview.NewButton("Reset All").SetSelectedFunc(func() {
+		msgChan <- &common.ResetOptionMsg{}
+	})
+	restoreButton := tview.NewButton("Restore Backup").SetSelectedFunc(func() {
+		msgChan <- &common.RestoreOptionsBackupMsg{}
+	})
+	diffButton := tview.NewButton("Show Changes").SetSelectedFunc(func() {
+		msgChan <- &common.ShowOptionsDiffMsg{}
+	})
+
+	// CreateAboutPanel dropdown to reset a single option to its default
+	resetFieldBox := tview.NewDropDown().
+		SetLabel("Reset to default: ").
+		SetOptions(options.ResettableFields, func(option string, index int) {
+			msgChan <- &common.ResetOptionMsg{Field: option}
+		}).
+		SetLabelColor(model.CurrentColorPalette.White)
+
+	buttonsBox := tview.NewFlex().
+		AddItem(saveButton, 10, 0, false).
+		AddItem(tview.NewBox(), 2, 0, false).
+		AddItem(revertButton, 10, 0, false).
+		AddItem(tview.NewBox(), 2, 0, false).
+		AddItem(restoreButton, 16, 0, false).
+		AddItem(tview.NewBox(), 2, 0, false).
+		AddItem(resetAllButton, 11, 0, false).
+		AddItem(tview.NewBox(), 2, 0, false).
+		AddItem(diffButton, 14, 0, false).
+		AddItem(tview.NewBox(), 0, 1, false)
+
 	// Add components to options box
 	optionsBox.AddItem(rulesetBox, 0, 1, false).
 		AddItem(playerCountBox, 0, 1, false).
 		AddItem(playerNamesBox, 0, 1, false).
+		AddItem(rostersBox, 0, 1, false).
 		AddItem(colorPaletteBox, 0, 1, false).
+		AddItem(transparentBackgroundBox, 0, 1, false).
 		AddItem(timeFormatBox, 0, 1, false).
+		AddItem(localeBox, 0, 1, false).
+		AddItem(logTimestampsBox, 0, 1, false).
+		AddItem(layoutBox, 0, 1, false).
+		AddItem(turnCueBox, 0, 1, false).
+		AddItem(borderStyleBox, 0, 1, false).
+		AddItem(panelWidgetsBox, 0, 1, false).
+		AddItem(tickIntervalBox, 0, 1, false).
+		AddItem(timeBudgetBox, 0, 1, false).
+		AddItem(timeOddsBox, 0, 1, false).
+		AddItem(flagFallBox, 0, 1, false).
+		AddItem(byoYomiPeriodsBox, 0, 1, false).
+		AddItem(byoYomiSecondsBox, 0, 1, false).
+		AddItem(gracePeriodBox, 0, 1, false).
+		AddItem(gameSizeBox, 0, 1, false).
+		AddItem(clockShowDateBox, 0, 1, false).
+		AddItem(clockShowGameTimeBox, 0, 1, false).
+		AddItem(bannerBox, 0, 1, false).
+		AddItem(eventNameBox, 0, 1, false).
+		AddItem(tableNumberBox, 0, 1, false).
+		AddItem(resetFieldBox, 0, 1, false).
+		AddItem(buttonsBox, 0, 1, false).
 		AddItem(oneTurnForAllPlayersBox, 0, 1, false).
-		AddItem(csvLogBox, 0, 1, false)
+		AddItem(csvLogBox, 0, 1, false).
+		AddItem(battleReportBox, 0, 1, false).
+		AddItem(resumeGameBox, 0, 1, false).
+		AddItem(secondaryObjectivesBox, 0, 1, false).
+		AddItem(vimBindingsBox, 0, 1, false).
+		AddItem(pauseOnModalBox, 0, 1, false).
+		AddItem(pauseOnFocusLossBox, 0, 1, false).
+		AddItem(discordBox, 0, 1, false)
 
 	// Add options box and help content to options panel
 	optionsPanel.AddItem(optionsBox, 0, 0, 1, 2, 0, 0, false)
@@ -117,7 +506,7 @@ func CreateOptionsScreen(model *common.Model, msgChan chan<- common.Message) *tv
 		SetTextAlign(tview.AlignCenter).
 		SetTextColor(model.CurrentColorPalette.White).
 		SetDynamicColors(true).
-		SetText("[b]Use mouse to change setting\n Press [-]O[b] to return to the main screen")
+		SetText("[b]Use mouse to change setting, changes are kept until saved or reverted\n Press [-]O[b] to return to the main screen")
 
 	// Add a message handler to update content on model changes
 	updateRulesetContent(model, currentRulesetContentBox)
@@ -129,13 +518,24 @@ func CreateOptionsScreen(model *common.Model, msgChan chan<- common.Message) *tv
 	optionsPanel.AddItem(helpContentBox, 4, 0, 1, 2, 0, 0, false)
 
 	optionsPanel.SetBorder(true).
-		SetTitle(" options ").
+		SetTitle(OptionsTitle(false, "")).
 		SetBorderColor(model.CurrentColorPalette.Cyan).
 		SetBackgroundColor(model.CurrentColorPalette.Black)
 
 	return optionsPanel
 }
 
+// OptionsTitle returns the title of the options screen, marking unsaved changes and save errors
+func OptionsTitle(unsaved bool, saveError string) string {
+	switch {
+	case saveError != "":
+		return " options (error saving: " + tview.Escape(saveError) + ") "
+	case unsaved:
+		return " options (unsaved changes) "
+	}
+	return " options "
+}
+
 // updateRulesetContent updates the content of the ruleset display
 func updateRulesetContent(model *common.Model, textView *tview.Flex) {
 	var leftText, rightText strings.Builder
@@ -177,15 +577,37 @@ func updateRulesetContent(model *common.Model, textView *tview.Flex) {
 	leftText.WriteString("\n\n")
 
 	leftText.WriteString(fmt.Sprintf(
-		" [b]Time Format:[-] %s\n\n",
+		" [b]Time Format:[-] %s\n\n [b]Log Timestamps:[-] %s\n\n",
 		model.Options.TimeFormat,
+		model.Options.LogTimestamps,
 	))
 
 	// Build right column content
+	if minutes := model.Options.Rules[model.Options.Default].TimeBudget; minutes > 0 {
+		rightText.WriteString(fmt.Sprintf(" [b]Time Budget:[-] %d minutes per player\n\n", minutes))
+	}
 	rightText.WriteString(" [b]Phases:[-]\n")
 	for i, phase := range model.Phases {
 		rightText.WriteString(fmt.Sprintf("  %d. %s\n", i+1, phase))
 	}
+	if objectives := model.Options.Rules[model.Options.Default].SecondaryObjectives; len(objectives) > 0 {
+		rightText.WriteString("\n [b]Secondary Objectives:[-]\n")
+		for i, objective := range objectives {
+			rightText.WriteString(fmt.Sprintf("  %d. %s\n", i+1, objective))
+		}
+	}
+	if steps := model.Options.Rules[model.Options.Default].PreGame; len(steps) > 0 {
+		rightText.WriteString("\n [b]Pre-game:[-]\n")
+		for i, step := range steps {
+			rightText.WriteString(fmt.Sprintf("  %d. %s\n", i+1, step))
+		}
+	}
+	if steps := model.Options.Rules[model.Options.Default].PostGame; len(steps) > 0 {
+		rightText.WriteString("\n [b]Post-game:[-]\n")
+		for i, step := range steps {
+			rightText.WriteString(fmt.Sprintf("  %d. %s\n", i+1, step))
+		}
+	}
 
 	leftColumn := createTextColumn(leftText.String(), model.CurrentColorPalette.White)
 	rightColumn := createTextColumn(rightText.String(), model.CurrentColorPalette.White)
@@ -253,3 +675,36 @@ func createPlayerNameFields(model *common.Model, msgChan chan<- common.Message)
 
 	return playerNamesFlex
 }
+
+// createRosterFields creates a field per player for the BattleScribe roster file (.ros or .rosz) imported as the
+// player's army list when ENTER is pressed
+func createRosterFields(model *common.Model, msgChan chan<- common.Message) *tview.Grid {
+	rostersGrid := tview.NewGrid().
+		SetRows(1).
+		SetColumns(0).
+		SetBorders(false)
+
+	for i := range model.Players {
+		label := ""
+		if i == 0 {
+			label = "Import BattleScribe rosters: "
+		}
+		inputField := tview.NewInputField().
+			SetLabel(label).
+			SetPlaceholder(model.Players[i].Name).
+			SetLabelColor(model.CurrentColorPalette.White).
+			SetFieldWidth(16)
+
+		idx := i
+		inputField.SetDoneFunc(func(key tcell.Key) {
+			if key != tcell.KeyEnter {
+				return
+			}
+			msgChan <- &common.ImportRosterMsg{PlayerIndex: idx, File: strings.TrimSpace(inputField.GetText())}
+		})
+
+		rostersGrid.AddItem(inputField, 1, i, 1, 1, 0, 0, false)
+	}
+
+	return rostersGrid
+}
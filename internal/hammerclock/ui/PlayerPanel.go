@@ -2,94 +2,169 @@ package ui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/options"
+	"hammerclock/internal/hammerclock/palette"
 )
 
-// CreatePlayerPanel creates a player panel
+// PanelColors lists the border colors of the player panels, in player order
+var PanelColors = []string{"blue", "yellow", "green", "red"}
+
+// Layouts lists the arrangements of the player panels: side by side, stacked, or stacked in portrait windows
+var Layouts = []string{"horizontal", "vertical", "auto"}
+
+// LayoutToIndex converts a layout to an index in Layouts
+func LayoutToIndex(layout string) int {
+	for i, l := range Layouts {
+		if l == layout {
+			return i
+		}
+	}
+	return 0 // Default to side by side
+}
+
+// TurnCues lists the cues shown on the new player's panel for a moment after a turn switch
+var TurnCues = []string{"flash", "invert", "none"}
+
+// TurnCueToIndex converts a turn switch cue to an index in TurnCues
+func TurnCueToIndex(cue string) int {
+	for i, c := range TurnCues {
+		if c == cue {
+			return i
+		}
+	}
+	return 0 // Default to a flash of the border
+}
+
+// PanelWidgets lists the widgets a player panel can show below the player's name, in their default order: the time
+// budget gauge, the clock, the turn with the phase and victory points, the sparkline of recent turns, the army list
+// and the log
+var PanelWidgets = []string{"gauge", "clock", "phase", "turns", "army", "log"}
+
+// ShownPanelWidgets returns the widgets shown in the player panels in display order: the listed ones, or all of them
+// if none are listed. Unknown and repeated names are skipped.
+func ShownPanelWidgets(listed []string) []string {
+	if len(listed) == 0 {
+		return PanelWidgets
+	}
+	var widgets []string
+	for _, widget := range listed {
+		if slices.Contains(PanelWidgets, widget) && !slices.Contains(widgets, widget) {
+			widgets = append(widgets, widget)
+		}
+	}
+	return widgets
+}
+
+// ParsePanelWidgets returns the widgets of a comma-separated list, e.g. "clock, phase, log". Listing all widgets in
+// their default order returns none, so the options keep showing everything.
+func ParsePanelWidgets(text string) []string {
+	var widgets []string
+	for _, widget := range strings.Split(text, ",") {
+		if widget = strings.ToLower(strings.TrimSpace(widget)); widget != "" {
+			widgets = append(widgets, widget)
+		}
+	}
+	if slices.Equal(widgets, PanelWidgets) {
+		return nil
+	}
+	return widgets
+}
+
+// widgetItems returns the index of each widget among the items of the upper part of a player panel. The name is
+// always the first item and the phase widget has a divider above it. The log is not in the upper part.
+func widgetItems(widgets []string) map[string]int {
+	items := map[string]int{"name": 0}
+	for _, widget := range widgets {
+		switch widget {
+		case "log":
+			continue
+		case "phase":
+			items["divider"] = len(items)
+		}
+		items[widget] = len(items)
+	}
+	return items
+}
+
+// CreatePlayerPanel creates a player panel with the widgets of the options. The action log, if shown, fills the
+// bottom of the panel wherever it is listed.
 func CreatePlayerPanel(player *common.Player, color string, model *common.Model) *tview.Flex {
 	panel := tview.NewFlex().SetDirection(tview.FlexRow)
 	upper := tview.NewFlex().SetDirection(tview.FlexRow)
 	lower := tview.NewFlex().SetDirection(tview.FlexRow)
+	widgets := ShownPanelWidgets(model.Options.PanelWidgets)
+
+	// The banner is shown above the player name, growing the upper part of the panel
+	nameText := "\nPlayer: " + player.Name
+	if player.Faction != "" {
+		nameText += " (" + player.Faction + ")"
+	}
+	if player.Banner != "" {
+		nameText = player.Banner + "\n" + nameText
+	}
 
 	playerName := tview.NewTextView().
-		SetText("\nPlayer: " + player.Name).
-		SetTextAlign(tview.AlignCenter).
-		SetTextColor(model.CurrentColorPalette.White)
-	elapsedTime := tview.NewTextView().
-		SetText(fmt.Sprintf("Time Elapsed: %v", player.TimeElapsed)).
-		SetTextAlign(tview.AlignCenter).
-		SetTextColor(model.CurrentColorPalette.White)
-	horizontalDivider := tview.NewTextView().
-		SetText(strings.Repeat("─", 30)).
-		SetTextAlign(tview.AlignCenter).
-		SetTextColor(model.CurrentColorPalette.DimWhite)
-	currentTurnAndPhase := tview.NewTextView().
+		SetText(nameText).
 		SetTextAlign(tview.AlignCenter).
 		SetTextColor(model.CurrentColorPalette.White)
+	upper.AddItem(playerName, 2+bannerHeight(player), 1, false)
 
-	setPhaseText := func() {
-		if !model.Options.Rules[model.Options.Default].OneTurnForAllPlayers {
-			currentTurnAndPhase.SetText(fmt.Sprintf("Turn: %d | Phase: %s", player.TurnCount, model.Phases[player.CurrentPhase]))
-		} else {
-			currentTurnAndPhase.SetText(fmt.Sprintf("Turn: %d", player.TurnCount))
-		}
+	// Each widget but the log takes a line, filled in by UpdatePlayerPanels
+	items := widgetItems(widgets)
+	for range len(items) - 1 {
+		line := tview.NewTextView().
+			SetDynamicColors(true).
+			SetTextAlign(tview.AlignCenter).
+			SetTextColor(model.CurrentColorPalette.White)
+		upper.AddItem(line, 1, 1, false)
 	}
-	setPhaseText()
+	upper.AddItem(tview.NewBox(), 0, 1, false)
 
-	upper.AddItem(playerName, 2, 1, false).
-		AddItem(tview.NewBox(), 1, 1, false).
-		AddItem(elapsedTime, 1, 1, false).
-		AddItem(horizontalDivider, 1, 0, false).
-		AddItem(currentTurnAndPhase, 1, 1, false).
-		AddItem(tview.NewBox(), 0, 1, false)
+	if slices.Contains(widgets, "log") {
+		logTitle := tview.NewTextView().
+			SetTextAlign(tview.AlignLeft).
+			SetText(logTitleText(false)).
+			SetTextColor(model.CurrentColorPalette.White)
 
-	logTitle := tview.NewTextView().
-		SetTextAlign(tview.AlignLeft).
-		SetText("\nAction Log:").
-		SetTextColor(model.CurrentColorPalette.White)
-
-	// Creating a scrollable log view
-	logView := createLogView()
+		// Creating a scrollable log view
+		logView := createLogView()
 
-	// Set initial content if any exists
-	if len(player.ActionLog) > 0 {
-		// Use LogPanel.SetLogContent to consistently format log entries
-		SetLogContent(logView, player.ActionLog)
-	}
+		// Set initial content if any exists
+		if len(player.ActionLog) > 0 {
+			// Use LogPanel.SetLogContent to consistently format log entries
+			SetLogContent(logView, player.ActionLog, model.CurrentColorPalette, model.Options.LogTimestamps)
+		}
 
-	// CreateAboutPanel a container with the log view
-	logContainer := createLogContainer(logView)
-	lower.AddItem(logTitle, 3, 0, false)
-	lower.AddItem(logContainer, 0, 1, true)
+		// CreateAboutPanel a container with the log view
+		logContainer := createLogContainer(logView)
+		lower.AddItem(logTitle, 3, 0, false)
+		lower.AddItem(logContainer, 0, 1, true)
 
-	borderColor := model.CurrentColorPalette.Black
-	switch color {
-	case "blue":
-		borderColor = model.CurrentColorPalette.Blue
-	case "yellow":
-		borderColor = model.CurrentColorPalette.Yellow
-	case "green":
-		borderColor = model.CurrentColorPalette.Green
-	case "red":
-		borderColor = model.CurrentColorPalette.Red
+		panel.AddItem(upper, 0, 0, false) // Sized to its widgets by updateWidgets
+		panel.AddItem(lower, 0, 3, true)
+	} else {
+		panel.AddItem(upper, 0, 1, false)
+		panel.AddItem(lower, 0, 0, false)
 	}
 
-	panel.AddItem(upper, 7, 0, false)
-	panel.AddItem(lower, 0, 3, true)
+	borderColor := panelColor(color, model.CurrentColorPalette)
 	panel.SetBorder(true).
 		SetBackgroundColor(model.CurrentColorPalette.Black).
 		SetBorderColor(borderColor)
-	horizontalDivider.SetTextColor(borderColor)
+	updateWidgets(panel, items, player, model, borderColor, model.CurrentColorPalette.White)
 
 	// Add mouse capture for smooth player selection
 	panel.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
 		if action == tview.MouseLeftClick {
-			// Only select if not already selected
-			if !player.IsTurn {
+			// Only select if not already selected and the input is not locked
+			if !player.IsTurn && !model.InputLocked {
 				for _, p := range model.Players {
 					if p == player {
 						// Set this player as active
@@ -115,52 +190,240 @@ func CreatePlayerPanel(player *common.Player, color string, model *common.Model)
 
 // UpdatePlayerPanels updates the player panels with the current player data
 func UpdatePlayerPanels(players []*common.Player, panels []*tview.Flex, model *common.Model) {
+	items := widgetItems(ShownPanelWidgets(model.Options.PanelWidgets))
 	for i, player := range players {
-		currentPlayerPanel := panels[i].GetItem(0).(*tview.Flex)
-		gameInfoBox := currentPlayerPanel.GetItem(0).(*tview.TextView)
-		elapsedTimeBox := currentPlayerPanel.GetItem(2).(*tview.TextView)
-		horizontalDivider := currentPlayerPanel.GetItem(3).(*tview.TextView)
-		currentTurnAndPhase := currentPlayerPanel.GetItem(4).(*tview.TextView)
-
-		elapsedTimeBox.SetText(fmt.Sprintf("Time Elapsed: %v", player.TimeElapsed))
-		if !model.Options.Rules[model.Options.Default].OneTurnForAllPlayers {
-			currentTurnAndPhase.SetText(fmt.Sprintf("Turn: %d | Phase: %s", player.TurnCount, model.Phases[player.CurrentPhase]))
-		} else {
-			currentTurnAndPhase.SetText(fmt.Sprintf("Turn: %d", player.TurnCount))
-		}
+		panels[i].SetBorderColor(panelColor(PanelColors[i%len(PanelColors)], model.CurrentColorPalette)).
+			SetTitleColor(model.CurrentColorPalette.White)
 
+		textColor := model.CurrentColorPalette.DimWhite
 		if !model.GameStarted {
 			panels[i].SetTitle("")
-			gameInfoBox.SetTextColor(model.CurrentColorPalette.DimWhite)
-			elapsedTimeBox.SetTextColor(model.CurrentColorPalette.DimWhite)
-			currentTurnAndPhase.SetTextColor(model.CurrentColorPalette.DimWhite)
 			panels[i].Blur() // Remove focus
 		} else if player.IsTurn {
 			panels[i].SetTitle(" ACTIVE TURN ")
-			gameInfoBox.SetTextColor(model.CurrentColorPalette.White)
-			elapsedTimeBox.SetTextColor(model.CurrentColorPalette.White)
-			currentTurnAndPhase.SetTextColor(model.CurrentColorPalette.White)
+			textColor = model.CurrentColorPalette.White
 			// Set focus to get double-line border
 			panels[i].Focus(func(p tview.Primitive) {
 				// Delegate function - we don't need to do anything here
 			})
 		} else {
 			panels[i].SetTitle("")
-			gameInfoBox.SetTextColor(model.CurrentColorPalette.DimWhite)
-			elapsedTimeBox.SetTextColor(model.CurrentColorPalette.DimWhite)
-			currentTurnAndPhase.SetTextColor(model.CurrentColorPalette.DimWhite)
 			panels[i].Blur() // Remove focus
 		}
-		horizontalDivider.SetTextColor(panels[i].GetBorderColor())
+		if player.Flagged && model.GameStarted {
+			// A player who ran out of time is shown in red, whoever's turn it is
+			panels[i].SetTitle(" FLAG ").
+				SetTitleColor(model.CurrentColorPalette.Red).
+				SetBorderColor(model.CurrentColorPalette.Red)
+		}
+
+		updateWidgets(panels[i], items, player, model, panels[i].GetBorderColor(), textColor)
+		setUpperBackground(panels[i].GetItem(0).(*tview.Flex), tview.Styles.PrimitiveBackgroundColor)
 
 		lower := panels[i].GetItem(1).(*tview.Flex)
-		if lower != nil && lower.GetItemCount() > 1 {
+		if lower.GetItemCount() > 1 {
+			logTitle := lower.GetItem(0).(*tview.TextView)
+			logTitle.SetText(logTitleText((model.Options.VimBindings || model.LogFocused) && i == model.FocusedLog))
 			logContainer := lower.GetItem(1).(*tview.Flex)
 			// The log container has the log view as its only item now
 			logView := logContainer.GetItem(0).(*tview.TextView)
 
 			// Update log panel content
-			SetLogContent(logView, player.ActionLog)
+			SetLogContent(logView, player.ActionLog, model.CurrentColorPalette, model.Options.LogTimestamps)
+		}
+	}
+}
+
+// updateWidgets fills in the widgets of the upper part of a player panel, at the items given by widgetItems. The
+// name, clock and phase are drawn in the text color, the divider in the border color; a flagged clock is red. The
+// army section grows with the units listed, so the upper part is resized to fit it above the log.
+func updateWidgets(panel *tview.Flex, items map[string]int, player *common.Player, model *common.Model,
+	borderColor, textColor tcell.Color) {
+	upper := panel.GetItem(0).(*tview.Flex)
+	height := 1 + bannerHeight(player) + len(items)
+	for widget, item := range items {
+		view := upper.GetItem(item).(*tview.TextView)
+		var text string
+		switch widget {
+		case "name":
+			view.SetTextColor(textColor)
+			continue
+		case "gauge":
+			text = playerGaugeText(player, model)
+		case "clock":
+			text = tview.Escape(playerTimeText(player, model))
+			if player.Flagged && model.GameStarted {
+				view.SetTextColor(model.CurrentColorPalette.Red)
+			} else {
+				view.SetTextColor(textColor)
+			}
+		case "divider":
+			text = dividerText()
+			view.SetTextColor(borderColor)
+		case "phase":
+			text = tview.Escape(turnAndPhaseText(player, model))
+			if showVictoryPoints(model) {
+				text += fmt.Sprintf(" | [#%06x::b]VP: %d[-::-]", model.CurrentColorPalette.Yellow.Hex(), player.VictoryPoints)
+			}
+			view.SetTextColor(textColor)
+		case "turns":
+			text = turnDurationsText(player)
+			view.SetTextColor(model.CurrentColorPalette.DimWhite)
+		case "army":
+			text = armyText(player, model.ArmyExpanded, model.CurrentColorPalette)
+			view.SetTextColor(textColor)
+			lines := 0
+			if text != "" {
+				lines = strings.Count(text, "\n") + 1
+			}
+			upper.ResizeItem(view, lines, 1)
+			height += lines - 1
+		}
+		if view.GetText(false) != text {
+			view.SetText(text)
+		}
+	}
+	// Without the log, the upper part fills the panel
+	if panel.GetItem(1).(*tview.Flex).GetItemCount() > 0 {
+		panel.ResizeItem(upper, height, 0)
+	}
+}
+
+// bannerHeight returns the number of lines of a player's banner
+func bannerHeight(player *common.Player) int {
+	if player.Banner == "" {
+		return 0
+	}
+	return strings.Count(player.Banner, "\n") + 1
+}
+
+// ShowTurnCue highlights a player panel updated by UpdatePlayerPanels after the turn switched to its player. The
+// flash cue draws the border in white, the invert cue fills the upper part of the panel with the border color.
+func ShowTurnCue(panel *tview.Flex, cue string, colors palette.ColorPalette) {
+	upper := panel.GetItem(0).(*tview.Flex)
+	switch cue {
+	case "flash":
+		panel.SetBorderColor(colors.White)
+	case "invert":
+		setUpperBackground(upper, panel.GetBorderColor())
+		textColor := colors.Black
+		if textColor == tcell.ColorDefault {
+			// With a transparent background the palette has no black to write on the border color with
+			textColor = tcell.ColorBlack
+		}
+		for i := 0; i < upper.GetItemCount(); i++ {
+			if view, ok := upper.GetItem(i).(*tview.TextView); ok {
+				view.SetTextColor(textColor)
+			}
+		}
+	default:
+		return
+	}
+	panel.SetTitle(" YOUR TURN ").SetTitleColor(colors.White)
+}
+
+// setUpperBackground sets the background of the upper part of a player panel and of everything in it
+func setUpperBackground(upper *tview.Flex, color tcell.Color) {
+	upper.SetBackgroundColor(color)
+	for i := 0; i < upper.GetItemCount(); i++ {
+		if box, ok := upper.GetItem(i).(interface{ SetBackgroundColor(tcell.Color) *tview.Box }); ok {
+			box.SetBackgroundColor(color)
 		}
 	}
 }
+
+// panelColor returns the palette color for a player panel color name
+func panelColor(color string, colors palette.ColorPalette) tcell.Color {
+	switch color {
+	case "blue":
+		return colors.Blue
+	case "yellow":
+		return colors.Yellow
+	case "green":
+		return colors.Green
+	case "red":
+		return colors.Red
+	}
+	return colors.Black
+}
+
+// playerTimeText returns the time shown in a player panel: the elapsed time, or the remaining time if the players
+// have a time budget. Once the budget is used up, it is the time left in the player's byo-yomi period, or the remaining
+// time turning negative without byo-yomi or after the last period.
+func playerTimeText(player *common.Player, model *common.Model) string {
+	var text string
+	budget := options.PlayerTimeBudget(model.Options, slices.Index(model.Players, player))
+	switch {
+	case budget <= 0:
+		text = fmt.Sprintf("Time Elapsed: %v", player.TimeElapsed.Truncate(time.Second))
+	case player.ByoYomiPeriod > 0 && !player.Flagged:
+		// A linked client does not know the host's number of periods
+		period := fmt.Sprint(player.ByoYomiPeriod)
+		if periods := model.Options.ByoYomiPeriods; periods > 0 {
+			period += fmt.Sprintf("/%d", periods)
+		}
+		text = fmt.Sprintf("Byo-Yomi %s: %v", period, max(player.PeriodLeft, 0).Truncate(time.Second))
+	default:
+		remaining := budget - player.TimeElapsed
+		text = fmt.Sprintf("Time Remaining: %v", remaining.Truncate(time.Second))
+	}
+	if player.IsTurn && model.GraceRemaining > 0 {
+		text += fmt.Sprintf(" (grace %v)", model.GraceRemaining.Round(time.Second))
+	}
+	return text
+}
+
+// playerGaugeText returns the gauge of the share of the time budget a player has used, empty without a budget
+func playerGaugeText(player *common.Player, model *common.Model) string {
+	budget := options.PlayerTimeBudget(model.Options, slices.Index(model.Players, player))
+	return BudgetGauge(player.TimeElapsed, budget, gaugeWidth, model.CurrentColorPalette)
+}
+
+// turnDurationsText returns the sparkline of a player's recent turn durations with the last one, empty before the
+// player's first turn ended
+func turnDurationsText(player *common.Player) string {
+	if len(player.TurnDurations) == 0 {
+		return ""
+	}
+	last := player.TurnDurations[len(player.TurnDurations)-1]
+	return fmt.Sprintf("Turns: %s (last %v)", Sparkline(player.TurnDurations, sparkWidth), last.Truncate(time.Second))
+}
+
+// turnAndPhaseText returns the turn and phase summary shown in a player panel, followed by the victory points
+func turnAndPhaseText(player *common.Player, model *common.Model) string {
+	text := fmt.Sprintf("Turn: %d", player.TurnCount)
+	if !model.Options.Rules[model.Options.Default].OneTurnForAllPlayers && player.CurrentPhase < len(model.Phases) {
+		text += fmt.Sprintf(" | Phase: %s", model.Phases[player.CurrentPhase])
+	}
+	return text
+}
+
+// showVictoryPoints reports whether the victory points are shown: during the game, with secondary objectives to
+// score, or once a player scored
+func showVictoryPoints(model *common.Model) bool {
+	if model.GameStarted || len(model.Options.Rules[model.Options.Default].SecondaryObjectives) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(model.Players, func(player *common.Player) bool { return player.VictoryPoints != 0 })
+}
+
+// VictoryPointsText returns the victory points of the players for the status bar, e.g. "VP: Alice 12 - Bob 8", or
+// an empty string while they are not shown
+func VictoryPointsText(model *common.Model) string {
+	if !showVictoryPoints(model) {
+		return ""
+	}
+	scores := make([]string, len(model.Players))
+	for i, player := range model.Players {
+		scores[i] = fmt.Sprintf("%s %d", player.Name, player.VictoryPoints)
+	}
+	return "VP: " + strings.Join(scores, " - ")
+}
+
+// logTitleText returns the title of the action log, marked when the log has keyboard focus
+func logTitleText(focused bool) string {
+	if focused {
+		return "\n» Action Log:"
+	}
+	return "\nAction Log:"
+}
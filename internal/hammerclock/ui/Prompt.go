@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// JudgeSuggestions are offered in the judge prompt, one for each intervention
+var JudgeSuggestions = []string{"penalty 1 2 slow play", "score 1 -1", "pause", "exit"}
+
+// DiceSuggestions are offered in the dice roller
+var DiceSuggestions = []string{"d6", "2d6", "d3", "d6+1", "3d6", "d20"}
+
+// CreatePrompt creates a single-line input dialog, such as the command palette or the note prompt.
+// Suggestions matching the typed text are offered for autocompletion. The done function receives
+// the entered text, or an empty string if the prompt was cancelled.
+func CreatePrompt(title string, label string, suggestions []string, done func(text string)) *tview.InputField {
+	prompt := tview.NewInputField().
+		SetLabel(label).
+		SetFieldWidth(0)
+
+	if len(suggestions) > 0 {
+		prompt.SetAutocompleteFunc(func(currentText string) []string {
+			var matches []string
+			for _, suggestion := range suggestions {
+				if strings.HasPrefix(strings.ToLower(suggestion), strings.ToLower(currentText)) {
+					matches = append(matches, suggestion)
+				}
+			}
+			return matches
+		})
+	}
+
+	prompt.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			done(strings.TrimSpace(prompt.GetText()))
+			return
+		}
+		done("")
+	})
+
+	prompt.SetBorder(true).SetTitle(" " + title + " ")
+	return prompt
+}
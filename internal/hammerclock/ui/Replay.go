@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"hammerclock/internal/hammerclock/palette"
+	"hammerclock/internal/hammerclock/report"
+)
+
+// replayHeaders are the column headers of the replay's player table
+var replayHeaders = []string{"Player", "Turn", "Phase", "Time"}
+
+// ReplayHelp lists the keys of the replay
+const ReplayHelp = "SPACE play/pause, LEFT/RIGHT step, PGUP/PGDN turn, +/- speed, HOME/END start/end, ESC quit"
+
+// CreateReplayTable creates the table showing the players of a game played back
+func CreateReplayTable(colors palette.ColorPalette) *tview.Table {
+	table := tview.NewTable().SetFixed(1, 0)
+	table.SetBorder(true).
+		SetTitle(" Replay ").
+		SetBorderColor(colors.Cyan).
+		SetBackgroundColor(colors.Black)
+	return table
+}
+
+// UpdateReplayTable shows the turn, phase and time of each player at the time played back, with the time and the
+// speed of the replay in the title
+func UpdateReplayTable(table *tview.Table, replay *report.Replay, speed int, playing bool, colors palette.ColorPalette) {
+	state := "paused"
+	if playing {
+		state = fmt.Sprintf("playing at %dx", speed)
+	}
+	table.SetTitle(fmt.Sprintf(" Replay - %v of %v, %s ", replay.At.Truncate(time.Second),
+		replay.Duration().Truncate(time.Second), state))
+
+	table.Clear()
+	for column, header := range replayHeaders {
+		table.SetCell(0, column, tview.NewTableCell(header).
+			SetTextColor(colors.Yellow).
+			SetAttributes(tcell.AttrBold).
+			SetExpansion(1))
+	}
+	for i, clock := range replay.Clocks() {
+		row := []string{
+			tview.Escape(clock.Name),
+			strconv.Itoa(clock.Turn),
+			tview.Escape(clock.Phase),
+			clock.Time.Truncate(time.Second).String(),
+		}
+		for column, text := range row {
+			table.SetCell(i+1, column, tview.NewTableCell(text).SetTextColor(colors.White).SetExpansion(1))
+		}
+	}
+}
+
+// CreateReplayLog creates the text view showing the entries played back
+func CreateReplayLog(colors palette.ColorPalette) *tview.TextView {
+	log := tview.NewTextView().SetDynamicColors(true)
+	log.SetBorder(true).
+		SetTitle(" Game Log ").
+		SetBorderColor(colors.Cyan).
+		SetBackgroundColor(colors.Black)
+	return log
+}
+
+// UpdateReplayLog shows the entries played back, the latest last
+func UpdateReplayLog(log *tview.TextView, replay *report.Replay) {
+	var text strings.Builder
+	for _, entry := range replay.Played() {
+		fmt.Fprintf(&text, "[::d]%s[::-] %s (turn %d): %s\n", entry.DateTime, tview.Escape(entry.PlayerName),
+			entry.Turn, tview.Escape(entry.Message))
+	}
+	log.SetText(text.String())
+	log.ScrollToEnd()
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"hammerclock/internal/hammerclock/calc"
+)
+
+// ScratchpadHeight is the height of the scratchpad calculator below the player panels, in lines
+const ScratchpadHeight = 8
+
+// CreateScratchpad creates the scratchpad calculator: the calculations so far above a line to enter the next one,
+// e.g. "17*6+45" or "avg 10d6". closed is called when ESC is pressed in the entry line.
+func CreateScratchpad(borderColor tcell.Color, backgroundColor tcell.Color, closed func()) *tview.Flex {
+	history := tview.NewTextView().SetDynamicColors(true)
+	history.SetChangedFunc(func() { history.ScrollToEnd() })
+	history.SetBackgroundColor(backgroundColor)
+
+	entry := tview.NewInputField().
+		SetLabel("= ").
+		SetFieldWidth(0).
+		SetPlaceholder("17*6+45, avg 10d6, max 2d6+1 (ESC to close)")
+	entry.SetDoneFunc(func(key tcell.Key) {
+		if key != tcell.KeyEnter {
+			closed()
+			return
+		}
+		if entry.GetText() == "" {
+			return
+		}
+		_, _ = fmt.Fprintln(history, ScratchpadLine(entry.GetText()))
+		entry.SetText("")
+	})
+
+	scratchpad := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(history, 0, 1, false).
+		AddItem(entry, 1, 0, true)
+	scratchpad.SetBorder(true).SetTitle(" Scratchpad (= to toggle) ")
+	scratchpad.SetBorderColor(borderColor)
+	scratchpad.SetBackgroundColor(backgroundColor)
+	return scratchpad
+}
+
+// ScratchpadLine returns the line of the scratchpad for a calculation, e.g. "17*6+45 = 147", with the error in red
+// if it cannot be calculated
+func ScratchpadLine(expression string) string {
+	result, err := calc.Eval(expression)
+	if err != nil {
+		return fmt.Sprintf("%s [red]%s[-]", tview.Escape(expression), tview.Escape(err.Error()))
+	}
+	return fmt.Sprintf("%s = [::b]%s[::-]", tview.Escape(expression), result)
+}
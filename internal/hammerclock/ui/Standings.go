@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"hammerclock/internal/hammerclock/palette"
+	"hammerclock/internal/hammerclock/tournament"
+)
+
+// standingsHeaders are the column headers of the standings table
+var standingsHeaders = []string{"#", "Player", "Points", "W-L-D", "VP", "SoS", "Clock"}
+
+// outcomeOptions are the outcomes offered in the results form, the first one decides by victory points
+var outcomeOptions = []string{"by VP", tournament.Win, tournament.Loss, tournament.Draw}
+
+// CreateStandingsTable creates the table showing the standings of a tournament event
+func CreateStandingsTable(colors palette.ColorPalette) *tview.Table {
+	standings := tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(false, false)
+	standings.SetBorder(true).
+		SetTitle(" Standings ").
+		SetBorderColor(colors.Cyan).
+		SetBackgroundColor(colors.Black)
+	return standings
+}
+
+// UpdateStandingsTable shows the standings below the column headers
+func UpdateStandingsTable(table *tview.Table, standings []tournament.Standing, colors palette.ColorPalette) {
+	table.Clear()
+	for column, header := range standingsHeaders {
+		table.SetCell(0, column, tview.NewTableCell(header).
+			SetTextColor(colors.Yellow).
+			SetAttributes(tcell.AttrBold).
+			SetExpansion(1))
+	}
+	for i, standing := range standings {
+		row := []string{
+			strconv.Itoa(i + 1),
+			tview.Escape(standing.Name),
+			strconv.Itoa(standing.Points),
+			fmt.Sprintf("%d-%d-%d", standing.Wins, standing.Losses, standing.Draws),
+			strconv.Itoa(standing.VictoryPoints),
+			strconv.Itoa(standing.StrengthOfSchedule),
+			standing.TimeElapsed.Truncate(time.Second).String(),
+		}
+		for column, text := range row {
+			table.SetCell(i+1, column, tview.NewTableCell(text).SetTextColor(colors.White).SetExpansion(1))
+		}
+	}
+}
+
+// CreateResultsForm creates the form for entering the result of a table in a round of a tournament event. The
+// players' fields are filled in from the recorded result, if any. Choosing another round or table calls selected,
+// saving calls save with the entered results and quitting calls quit.
+func CreateResultsForm(event *tournament.Event, round, table int, players []string, colors palette.ColorPalette,
+	selected func(round, table int), save func(players []tournament.PlayerResult), quit func()) *tview.Form {
+	form := tview.NewForm()
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Results - Round %d, Table %d ", round, table)).
+		SetBorderColor(colors.Cyan)
+
+	// The dropdowns call selected only on a change, as the new form selects the same round and table again
+	rounds := make([]string, event.Rounds)
+	for i := range rounds {
+		rounds[i] = strconv.Itoa(i + 1)
+	}
+	roundBox := tview.NewDropDown().SetLabel("Round: ").SetOptions(rounds, nil).SetCurrentOption(round - 1)
+	roundBox.SetSelectedFunc(func(_ string, index int) {
+		if index+1 != round {
+			selected(index+1, table)
+		}
+	})
+	tables := make([]string, event.Tables)
+	for i := range tables {
+		tables[i] = strconv.Itoa(i + 1)
+	}
+	tableBox := tview.NewDropDown().SetLabel("Table: ").SetOptions(tables, nil).SetCurrentOption(table - 1)
+	tableBox.SetSelectedFunc(func(_ string, index int) {
+		if index+1 != table {
+			selected(round, index+1)
+		}
+	})
+	form.AddFormItem(roundBox).AddFormItem(tableBox)
+
+	recorded := map[string]tournament.PlayerResult{}
+	for _, result := range event.Results {
+		if result.Round == round && result.Table == table {
+			for _, player := range result.Players {
+				recorded[player.Name] = player
+			}
+		}
+	}
+
+	outcomeBoxes := make([]*tview.DropDown, len(players))
+	pointsBoxes := make([]*tview.InputField, len(players))
+	for i, name := range players {
+		outcomeBoxes[i] = tview.NewDropDown().
+			SetLabel(name+": ").
+			SetOptions(outcomeOptions, nil).
+			SetCurrentOption(max(slices.Index(outcomeOptions, recorded[name].Outcome), 0))
+		pointsBoxes[i] = tview.NewInputField().
+			SetLabel("  Victory points: ").
+			SetText(strconv.Itoa(recorded[name].VictoryPoints)).
+			SetAcceptanceFunc(tview.InputFieldInteger).
+			SetFieldWidth(5)
+		form.AddFormItem(outcomeBoxes[i]).AddFormItem(pointsBoxes[i])
+	}
+
+	form.AddButton("Save", func() {
+		results := make([]tournament.PlayerResult, len(players))
+		for i, name := range players {
+			results[i] = tournament.PlayerResult{Name: name}
+			results[i].VictoryPoints, _ = strconv.Atoi(pointsBoxes[i].GetText())
+			if index, _ := outcomeBoxes[i].GetCurrentOption(); index > 0 {
+				results[i].Outcome = outcomeOptions[index]
+			}
+		}
+		save(results)
+	})
+	form.AddButton("Quit", quit)
+	form.SetCancelFunc(quit)
+	return form
+}
@@ -2,10 +2,12 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"hammerclock/internal/hammerclock/rules"
 )
 
 // CreateStatusPanel creates a panel that displays the game statusbar
@@ -28,8 +30,58 @@ func CreateStatusPanel(status string, borderColor tcell.Color, backgroundColor t
 	return statusPanel
 }
 
-// UpdateWithGameTime updates the status panel to include the total game time
-func UpdateWithGameTime(panel *tview.Flex, status string, totalGameTime time.Duration) {
+// PhaseTimeText formats a phase name with the time spent in it as minutes and seconds
+func PhaseTimeText(phase string, elapsed time.Duration) string {
+	seconds := int(elapsed.Seconds())
+	return fmt.Sprintf("%s — %02d:%02d", phase, seconds/60, seconds%60)
+}
+
+// PhaseLimitText returns the mark shown after the phase time once the phase is over its soft or hard limit
+func PhaseLimitText(limit rules.PhaseLimit, elapsed time.Duration) string {
+	switch {
+	case limit.Hard > 0 && elapsed >= time.Duration(limit.Hard)*time.Second:
+		return " ⛔ OVER HARD LIMIT"
+	case limit.Soft > 0 && elapsed >= time.Duration(limit.Soft)*time.Second:
+		return " ⚠ over soft limit"
+	}
+	return ""
+}
+
+// RoundTimerText formats the organizer's round countdown and announcement at the given time, e.g.
+// "Round: 14:32 left | Dice down in 15 minutes", or returns an empty string without either
+func RoundTimerText(ends time.Time, announcement string, now time.Time) string {
+	var parts []string
+	if !ends.IsZero() {
+		if remaining := ends.Sub(now); remaining > 0 {
+			seconds := int(remaining.Seconds())
+			parts = append(parts, fmt.Sprintf("Round: %02d:%02d left", seconds/60, seconds%60))
+		} else {
+			parts = append(parts, "Round over")
+		}
+	}
+	if announcement != "" {
+		parts = append(parts, announcement)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// StartCountdownText formats the countdown to the scheduled start of the game at the given time, e.g.
+// "Game starts in 04:12"
+func StartCountdownText(startsAt time.Time, now time.Time) string {
+	seconds := int(max(startsAt.Sub(now), 0).Round(time.Second).Seconds())
+	return fmt.Sprintf("Game starts in %02d:%02d", seconds/60, seconds%60)
+}
+
+// UpdateWithGameTime updates the status panel to include the total game time and, once the game was paused,
+// the time spent paused, followed by the victory points of the players if given
+func UpdateWithGameTime(panel *tview.Flex, status string, totalGameTime, pausedTime time.Duration, scores string) {
 	statusTextView := panel.GetItem(0).(*tview.TextView)
-	statusTextView.SetText(fmt.Sprintf("%s | Total Game Time: %v", status, totalGameTime))
+	text := fmt.Sprintf("%s | Total Game Time: %v", status, totalGameTime.Truncate(time.Second))
+	if pausedTime >= time.Second {
+		text += fmt.Sprintf(" | Paused: %v", pausedTime.Truncate(time.Second))
+	}
+	if scores != "" {
+		text += " | " + scores
+	}
+	statusTextView.SetText(text)
 }
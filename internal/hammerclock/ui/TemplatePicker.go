@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// CreateTemplatePicker creates a list of game templates to start a new game from. The last entry saves
+// the current game setup as a new template. Escape closes the list by calling cancel.
+func CreateTemplatePicker(names []string, selected func(index int), save func(), cancel func()) *tview.List {
+	picker := tview.NewList().
+		ShowSecondaryText(false)
+
+	for i, name := range names {
+		index := i
+		picker.AddItem(name, "", 0, func() {
+			selected(index)
+		})
+	}
+	picker.AddItem("Save current setup as template...", "", 0, save)
+
+	picker.SetDoneFunc(cancel)
+	picker.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			cancel()
+			return nil
+		}
+		return event
+	})
+
+	picker.SetBorder(true).SetTitle(" Game Templates ")
+	return picker
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"hammerclock/internal/hammerclock/common"
+)
+
+// TimerSuggestions are offered when starting an auxiliary timer, as a label followed by minutes, or when scheduling
+// the start of the game
+var TimerSuggestions = []string{"Deployment 10", "Rules lookup 5", "Pizza 30", "start 5", "clear"}
+
+// CreateTimersPanel creates the panel showing the auxiliary countdown timers
+func CreateTimersPanel(borderColor tcell.Color, backgroundColor tcell.Color) *tview.TextView {
+	timersPanel := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+	timersPanel.SetBorder(true).SetTitle(" Timers ")
+	timersPanel.SetBorderColor(borderColor)
+	timersPanel.SetBackgroundColor(backgroundColor)
+	return timersPanel
+}
+
+// TimersText returns the text of the timers panel, e.g. "Deployment 09:12 | Rules lookup DONE".
+// Timers that ran out are shown in red.
+func TimersText(timers []common.Timer) string {
+	parts := make([]string, len(timers))
+	for i, timer := range timers {
+		label := tview.Escape(timer.Label)
+		if timer.Done {
+			parts[i] = fmt.Sprintf("[red::b]%s DONE[-::-]", label)
+			continue
+		}
+		seconds := int(timer.Remaining.Round(time.Second).Seconds())
+		parts[i] = fmt.Sprintf("%s %02d:%02d", label, seconds/60, seconds%60)
+	}
+	return strings.Join(parts, " | ")
+}
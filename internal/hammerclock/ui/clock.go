@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
@@ -30,6 +31,20 @@ func Display(format string, color tcell.Color) *tview.TextView {
 	return hClock
 }
 
+// ClockText builds the text of the clock area: the wall clock, optionally preceded by the date
+// and followed by the total elapsed game time.
+func ClockText(now time.Time, format string, showDate bool, showGameTime bool, gameTime time.Duration) string {
+	text := now.Format(TimeFormat(format))
+	if showDate {
+		text = now.Format("2006-01-02") + " " + text
+	}
+	if showGameTime {
+		seconds := int(gameTime.Seconds())
+		text += fmt.Sprintf(" | %02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return text
+}
+
 // TimeFormatToIndex converts the time format string to an index
 func TimeFormatToIndex(format string) int {
 	if format == "AMPM" {
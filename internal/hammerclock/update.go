@@ -1,11 +1,17 @@
 package hammerclock
 
 import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"hammerclock/internal/hammerclock/common"
 	"hammerclock/internal/hammerclock/logging"
-	"hammerclock/internal/hammerclock/palette"
+	"hammerclock/internal/hammerclock/options"
+	"hammerclock/internal/hammerclock/report"
 	"hammerclock/internal/hammerclock/rules"
 
 	"github.com/gdamore/tcell/v2"
@@ -20,11 +26,40 @@ func noCommand() common.Message {
 	return nil
 }
 
-// Update processes a message and returns an updated model and a command to execute
+// batch returns a Command that runs the commands in order and returns their messages as one BatchMsg
+func batch(cmds ...Command) Command {
+	return func() common.Message {
+		var msgs []common.Message
+		for _, cmd := range cmds {
+			if cmd == nil {
+				continue
+			}
+			if msg := cmd(); msg != nil {
+				msgs = append(msgs, msg)
+			}
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+		return &common.BatchMsg{Msgs: msgs}
+	}
+}
+
+// Update processes a message and returns an updated model and a command to execute. Turn switches, phase changes and
+// the end of the game are recorded in the history, so they can be undone; other changes, e.g. to the victory points,
+// are not.
 func Update(msg common.Message, model common.Model) (common.Model, Command) {
+	newModel, cmd := update(msg, model)
+	return recordHistory(model, newModel), cmd
+}
+
+// update processes a message like Update, without recording the history
+func update(msg common.Message, model common.Model) (common.Model, Command) {
 	switch msg := msg.(type) {
 	case *common.StartGameMsg:
-		return handleStartGame(model)
+		return handleStart(model)
+	case *common.CompleteStepMsg:
+		return handleCompleteStep(msg, model)
 	case *common.EndGameMsg:
 		return handleEndGame(model)
 	case *common.EndGameConfirmMsg:
@@ -43,17 +78,73 @@ func Update(msg common.Message, model common.Model) (common.Model, Command) {
 		return handlePrevPhase(model)
 	case *common.ShowOptionsMsg:
 		return handleShowOptions(model)
+	case *common.UnlockOptionsMsg:
+		return handleUnlockOptions(msg, model)
 	case *common.ShowAboutMsg:
 		return handleShowAbout(model)
+	case *common.ShowFeedMsg:
+		return handleShowFeed(model)
+	case *common.ShowScratchpadMsg:
+		return handleToggleScratchpad(model)
 	case *common.ShowMainScreenMsg:
 		return handleShowMainScreen(model)
 	case *common.RestoreMainUIMsg:
 		return model, noCommand
+	case *common.ModalOpenedMsg:
+		return handleModalOpened(model)
+	case *common.ModalClosedMsg:
+		return handleModalClosed(model)
+	case *common.FocusChangedMsg:
+		return handleFocusChanged(msg, model)
 	case *common.TickMsg:
-		return handleTick(model)
+		return handleTick(msg, model)
+	case *common.ResolveSuspendMsg:
+		return handleResolveSuspend(msg, model)
+	case *common.LinkStateMsg:
+		return handleLinkState(msg, model)
+	case *common.RoundTimerMsg:
+		return handleRoundTimer(msg, model)
 	case *common.KeyPressMsg:
 		return handleKeyPress(msg, model)
+	case *common.RunCommandMsg:
+		return handleRunCommand(msg, model)
+	case *common.AddNoteMsg:
+		newModel, cmd := handleAddNote(msg, model)
+		return recordMacroStep(newModel, macroNote+msg.Text), cmd
+	case *common.RollDiceMsg:
+		return handleRollDice(msg, model)
+	case *common.RollTableMsg:
+		return handleRollTable(msg, model)
+	case *common.AddTimerMsg:
+		newModel, cmd := handleAddTimer(msg, model)
+		return recordMacroStep(newModel, macroTimer+timerStep(msg)), cmd
+	case *common.ClearTimersMsg:
+		return handleClearTimers(model)
+	case *common.JudgeLoginMsg:
+		return handleJudgeLogin(msg, model)
+	case *common.JudgeActionMsg:
+		return handleJudgeAction(msg, model)
+	case *common.SaveTemplateMsg:
+		return handleSaveTemplate(msg, model)
+	case *common.ApplyTemplateMsg:
+		return handleApplyTemplate(msg, model)
 	// Handle option update messages
+	case *common.SaveOptionsMsg:
+		return handleSaveOptions(model)
+	case *common.OptionsSavedMsg:
+		return handleOptionsSaved(msg, model)
+	case *common.RevertOptionsMsg:
+		return handleRevertOptions(model)
+	case *common.ResetOptionMsg:
+		return handleResetOption(msg, model)
+	case *common.RestoreOptionsBackupMsg:
+		return handleRestoreOptionsBackup(model)
+	case *common.OptionsBackupRestoredMsg:
+		return handleOptionsBackupRestored(msg, model)
+	case *common.ShowOptionsDiffMsg:
+		return model, func() common.Message {
+			return &common.ShowModalMsg{Type: "OptionsDiff"}
+		}
 	case *common.SetRulesetMsg:
 		return handleSetRuleset(msg, model)
 	case *common.SetPlayerCountMsg:
@@ -62,14 +153,145 @@ func Update(msg common.Message, model common.Model) (common.Model, Command) {
 		return handleSetPlayerName(msg, model)
 	case *common.SetColorPaletteMsg:
 		return handleSetColorPalette(msg, model)
+	case *common.SetTransparentBackgroundMsg:
+		newModel := model
+		newModel.Options.TransparentBackground = msg.Value
+		newModel.CurrentColorPalette = OptionsColorPalette(newModel.Options, newModel.Options.ColorPalette)
+		return newModel, noCommand
 	case *common.SetTimeFormatMsg:
 		return handleSetTimeFormat(msg, model)
+	case *common.SetLogTimestampsMsg:
+		return handleSetLogTimestamps(msg, model)
+	case *common.SetClockShowDateMsg:
+		newModel := model
+		newModel.Options.ClockShowDate = msg.Value
+		return newModel, noCommand
+	case *common.SetClockShowGameTimeMsg:
+		newModel := model
+		newModel.Options.ClockShowGameTime = msg.Value
+		return newModel, noCommand
+	case *common.SetBannerMsg:
+		newModel := model
+		newModel.Options.Banner = msg.Text
+		return newModel, noCommand
+	case *common.SetLayoutMsg:
+		newModel := model
+		newModel.Options.Layout = msg.Layout
+		return newModel, noCommand
+	case *common.SetTurnCueMsg:
+		newModel := model
+		newModel.Options.TurnCue = msg.Cue
+		return newModel, noCommand
+	case *common.SetBorderStyleMsg:
+		newModel := model
+		newModel.Options.BorderStyle = msg.Style
+		return newModel, noCommand
+	case *common.SetPanelWidgetsMsg:
+		newModel := model
+		newModel.Options.PanelWidgets = msg.Widgets
+		return newModel, noCommand
+	case *common.SetEventNameMsg:
+		newModel := model
+		newModel.Options.EventName = msg.Text
+		return newModel, noCommand
+	case *common.SetTableNumberMsg:
+		newModel := model
+		newModel.Options.TableNumber = max(msg.Number, 0)
+		return newModel, noCommand
 	case *common.SetOneTurnForAllPlayersMsg:
 		return handleSetOneTurnForAllPlayers(msg, model)
+	case *common.SetPromptSecondaryObjectivesMsg:
+		newModel := model
+		newModel.Options.PromptSecondaryObjectives = msg.Value
+		return newModel, noCommand
+	case *common.SetArmyListMsg:
+		return handleSetArmyList(msg, model)
+	case *common.ImportRosterMsg:
+		return handleImportRoster(msg, model)
+	case *common.RosterImportedMsg:
+		return handleRosterImported(msg, model)
+	case *common.SetUnitStatusMsg:
+		return handleSetUnitStatus(msg, model)
+	case *common.SetUnitWoundsMsg:
+		return handleSetUnitWounds(msg, model)
+	case *common.SetUnitNoteMsg:
+		return handleSetUnitNote(msg, model)
+	case *common.OfferSavedGameMsg:
+		return handleOfferSavedGame(msg, model)
+	case *common.ResumeSavedGameMsg:
+		return handleResumeSavedGame(msg, model)
+	case *common.GameSavedMsg:
+		return handleGameSaved(msg, model)
+	case *common.ScheduleStartMsg:
+		return handleScheduleStart(msg, model)
+	case *common.ScreenshotSavedMsg:
+		return handleScreenshotSaved(msg, model)
+	case *common.SetUnitDestroyedByMsg:
+		return handleSetUnitDestroyedBy(msg, model)
+	case *common.ScoreSecondaryObjectivesMsg:
+		return handleScoreSecondaryObjectives(msg, model)
+	case *common.SetVimBindingsMsg:
+		newModel := model
+		newModel.Options.VimBindings = msg.Value
+		return newModel, noCommand
+	case *common.SetPauseOnModalMsg:
+		newModel := model
+		newModel.Options.PauseOnModal = msg.Value
+		return newModel, noCommand
+	case *common.SetTimeBudgetMsg:
+		newModel := model
+		newModel.Options.TimeBudget = max(msg.Minutes, 0)
+		return newModel, noCommand
+	case *common.SetByoYomiMsg:
+		newModel := model
+		newModel.Options.ByoYomiPeriods = max(msg.Periods, 0)
+		newModel.Options.ByoYomiSeconds = max(msg.Seconds, 0)
+		return newModel, noCommand
+	case *common.SetGameSizeMsg:
+		newModel := model
+		newModel.Options.GameSize = max(msg.Points, 0)
+		return newModel, noCommand
+	case *common.SetGracePeriodMsg:
+		newModel := model
+		newModel.Options.GracePeriod = max(msg.Seconds, 0)
+		return newModel, noCommand
+	case *common.SetLocaleMsg:
+		newModel := model
+		newModel.Options.Locale = strings.TrimSpace(msg.Locale)
+		newModel.Phases = RulesetPhases(newModel.Options)
+		return newModel, noCommand
+	case *common.SetTimeOddsMsg:
+		newModel := model
+		newModel.Options.TimeOdds = msg.Odds
+		return newModel, noCommand
+	case *common.SetFlagFallMsg:
+		newModel := model
+		newModel.Options.FlagFall = msg.Action
+		return newModel, noCommand
+	case *common.SetTickIntervalMsg:
+		newModel := model
+		newModel.Options.TickInterval = msg.Milliseconds
+		return newModel, noCommand
+	case *common.SetPauseOnFocusLossMsg:
+		newModel := model
+		newModel.Options.PauseOnFocusLoss = msg.Value
+		return newModel, noCommand
+	case *common.SetDiscordNotificationsMsg:
+		newModel := model
+		newModel.Options.Discord.Enabled = msg.Value
+		return newModel, noCommand
 	case *common.SetEnableLogMsg:
 		newModel := model
 		newModel.Options.LoggingEnabled = msg.Value
 		return newModel, noCommand
+	case *common.SetBattleReportMsg:
+		newModel := model
+		newModel.Options.BattleReport = msg.Value
+		return newModel, noCommand
+	case *common.SetResumeGameMsg:
+		newModel := model
+		newModel.Options.ResumeGame = msg.Value
+		return newModel, noCommand
 	default:
 		return model, noCommand
 	}
@@ -80,6 +302,11 @@ func handleStartGame(model common.Model) (common.Model, Command) {
 	// Create a copy of the model to avoid modifying the original
 	newModel := model
 
+	// A game lost on time can only be ended
+	if model.GameStatus == gameOver {
+		return model, noCommand
+	}
+
 	// Toggle between start and pause
 	if model.GameStatus == gamePaused {
 		// Resume the game
@@ -88,7 +315,7 @@ func handleStartGame(model common.Model) (common.Model, Command) {
 		// Log action for active player(s)
 		for i, player := range model.Players {
 			if player.IsTurn {
-				logging.AddLogEntry(newModel.Players[i], &newModel, "Game resumed")
+				logging.AddLogEntry(newModel.Players[i], &newModel, common.LogTypeGame, "Game resumed")
 			}
 		}
 	} else if model.GameStatus == gameInProgress {
@@ -98,13 +325,16 @@ func handleStartGame(model common.Model) (common.Model, Command) {
 		// Log action for active player(s)
 		for i, player := range model.Players {
 			if player.IsTurn {
-				logging.AddLogEntry(newModel.Players[i], &newModel, "Game paused")
+				logging.AddLogEntry(newModel.Players[i], &newModel, common.LogTypeGame, "Game paused")
 			}
 		}
 	} else {
-		// Start the game if not already started
+		// Start the game if not already started, the actions of the last game can no longer be undone
 		newModel.GameStatus = gameInProgress
 		newModel.GameStarted = true
+		newModel.History = nil
+		newModel.Redo = nil
+		newModel.StartsAt = time.Time{}
 
 		// Check if any player has IsTurn set to true (a panel is focused)
 		anyPlayerSelected := false
@@ -128,14 +358,100 @@ func handleStartGame(model common.Model) (common.Model, Command) {
 		// Log action for active player(s)
 		for i, player := range newModel.Players {
 			if player.IsTurn {
-				logging.AddLogEntry(newModel.Players[i], &newModel, "Game started")
+				logging.AddLogEntry(newModel.Players[i], &newModel, common.LogTypeGame, "Game started")
 			}
 		}
+		checkArmyPoints(&newModel)
+		logTimeOdds(&newModel)
 	}
 
 	return newModel, noCommand
 }
 
+// logTimeOdds logs the time on the first player's clock at the start of a game with time odds
+func logTimeOdds(model *common.Model) {
+	if len(model.Players) == 0 {
+		return
+	}
+	budget := options.PlayerTimeBudget(model.Options, 0)
+	if others := options.GameTimeBudget(model.Options); budget != others {
+		logging.AddLogEntry(model.Players[0], model, common.LogTypeGame, "Time odds %s - %v on the clock against %v",
+			model.Options.TimeOdds, budget, others)
+	}
+}
+
+// handleModalOpened handles the ModalOpenedMsg, pausing a running game if enabled in the options
+func handleModalOpened(model common.Model) (common.Model, Command) {
+	if !model.Options.PauseOnModal || model.GameStatus != gameInProgress || model.Linked {
+		return model, noCommand
+	}
+
+	newModel := model
+	newModel.GameStatus = gamePaused
+	newModel.ModalPaused = true
+	for i, player := range model.Players {
+		if player.IsTurn {
+			logging.AddLogEntry(newModel.Players[i], &newModel, common.LogTypeGame, "Game paused while a dialog is open")
+		}
+	}
+	return newModel, noCommand
+}
+
+// handleModalClosed handles the ModalClosedMsg, resuming the game if it was paused by the dialog.
+// Games that were ended or resumed in the meantime are left alone.
+func handleModalClosed(model common.Model) (common.Model, Command) {
+	if !model.ModalPaused {
+		return model, noCommand
+	}
+
+	newModel := model
+	newModel.ModalPaused = false
+	if model.GameStatus != gamePaused {
+		return newModel, noCommand
+	}
+
+	newModel.GameStatus = gameInProgress
+	for i, player := range model.Players {
+		if player.IsTurn {
+			logging.AddLogEntry(newModel.Players[i], &newModel, common.LogTypeGame, "Game resumed")
+		}
+	}
+	return newModel, noCommand
+}
+
+// handleFocusChanged handles the FocusChangedMsg. If enabled in the options, a running game is paused when the
+// terminal loses the focus, and the players are asked whether to resume it once the focus is back.
+func handleFocusChanged(msg *common.FocusChangedMsg, model common.Model) (common.Model, Command) {
+	if msg.Focused {
+		if !model.FocusPaused {
+			return model, noCommand
+		}
+
+		newModel := model
+		newModel.FocusPaused = false
+		if model.GameStatus != gamePaused {
+			return newModel, noCommand
+		}
+		return newModel, func() common.Message {
+			return &common.ShowModalMsg{Type: "ResumeConfirm"}
+		}
+	}
+
+	if !model.Options.PauseOnFocusLoss || model.GameStatus != gameInProgress || model.Linked {
+		return model, noCommand
+	}
+
+	newModel := model
+	newModel.GameStatus = gamePaused
+	newModel.FocusPaused = true
+	for i, player := range model.Players {
+		if player.IsTurn {
+			logging.AddLogEntry(newModel.Players[i], &newModel, common.LogTypeGame, "Game paused, the terminal lost focus")
+		}
+	}
+	return newModel, noCommand
+}
+
 // handleEndGame handles the endGameMsg
 func handleEndGame(model common.Model) (common.Model, Command) {
 	// CreateAboutPanel a copy of the model to avoid modifying the original
@@ -143,10 +459,21 @@ func handleEndGame(model common.Model) (common.Model, Command) {
 
 	// Only handle if the game was started
 	if model.GameStarted {
+		// Copy the players, so the result of the ended game stays available in the original model
+		newModel.Players = make([]*common.Player, len(model.Players))
+		for i, player := range model.Players {
+			newPlayer := *player
+			newModel.Players[i] = &newPlayer
+		}
+
 		// Reset game state
 		newModel.GameStatus = gameNotStarted
 		newModel.GameStarted = false
 		newModel.TotalGameTime = 0
+		newModel.PausedTime = 0
+		newModel.LastTurnSwitch = nil
+		newModel.PreGameDone = false
+		newModel.SequenceResults = nil
 
 		// Log action for players
 		for i := range model.Players {
@@ -154,17 +481,31 @@ func handleEndGame(model common.Model) (common.Model, Command) {
 			newModel.Players[i].TimeElapsed = 0
 			newModel.Players[i].TurnCount = 0
 			newModel.Players[i].CurrentPhase = 0
-
-			// Clear the action log
+			newModel.Players[i].PhaseElapsed = 0
+			newModel.Players[i].TurnStart = 0
+			newModel.Players[i].TurnDurations = nil
+			newModel.Players[i].Flagged = false
+			newModel.Players[i].ByoYomiPeriod = 0
+			newModel.Players[i].PeriodLeft = 0
+
+			// Clear the action log, and the status and notes of the units
 			newModel.Players[i].ActionLog = []common.LogEntry{}
+			newModel.Players[i].ArmyList = resetArmyList(model.Players[i].ArmyList)
 
 			// Keep turn state of player 1
 			if i == 0 {
 				newModel.Players[i].IsTurn = true
-				logging.AddLogEntry(newModel.Players[i], &newModel, "Game ended - reset to initial state")
+				logging.AddLogEntry(newModel.Players[i], &newModel, common.LogTypeGame, "Game ended - reset to initial state")
+				logging.AddLogEntry(newModel.Players[i], &newModel, common.LogTypeGame, "Game summary - played %v, paused %v",
+					model.TotalGameTime.Truncate(time.Second), model.PausedTime.Truncate(time.Second))
 			} else {
 				newModel.Players[i].IsTurn = false
-				logging.AddLogEntry(newModel.Players[i], &newModel, "Game ended")
+				logging.AddLogEntry(newModel.Players[i], &newModel, common.LogTypeGame, "Game ended")
+			}
+			if casualties := report.PlayerCasualties(model.Players[i]); casualties.Units > 0 {
+				logging.AddLogEntry(newModel.Players[i], &newModel, common.LogTypeGame,
+					"Casualties - %d of %d units destroyed, %d of %d pts lost", casualties.Destroyed, casualties.Units,
+					casualties.PointsLost, casualties.Points)
 			}
 		}
 	}
@@ -181,8 +522,21 @@ func handleEndGameConfirm(msg *common.EndGameConfirmMsg, model common.Model) (co
 
 	// If user confirmed ending the game, proceed with the game ending logic
 	if msg.Confirmed {
+		newModel := copyPlayers(model)
+		if !checkPIN(&newModel, "end game", msg.PIN) {
+			return newModel, func() common.Message {
+				return &common.BellMsg{}
+			}
+		}
+		// The clocks stop while the post-game sequence of the ruleset is worked through, the game ends after it
+		if len(SequenceSteps(newModel, PostGame)) > 0 {
+			if newModel.GameStatus == gameInProgress {
+				newModel.GameStatus = gamePaused
+			}
+			return newModel, showSequenceStep(PostGame, 0)
+		}
 		// Get the updated model after ending the game
-		newModel, _ := handleEndGame(model)
+		newModel, _ = handleEndGame(newModel)
 		return newModel, restoreUICmd
 	}
 
@@ -192,6 +546,9 @@ func handleEndGameConfirm(msg *common.EndGameConfirmMsg, model common.Model) (co
 
 // handleShowEndGameConfirm handles the showEndGameConfirmMsg
 func handleShowEndGameConfirm(model common.Model) (common.Model, Command) {
+	if kioskLocked(model) {
+		return model, noCommand
+	}
 	// Return the model unchanged and a command that will show the confirmation dialog
 	return model, func() common.Message {
 		// This will be handled by the main.go to show the dialog
@@ -201,6 +558,9 @@ func handleShowEndGameConfirm(model common.Model) (common.Model, Command) {
 
 // handleShowExitConfirm handles the showExitConfirmMsg
 func handleShowExitConfirm(model common.Model) (common.Model, Command) {
+	if kioskLocked(model) {
+		return model, noCommand
+	}
 	// Return the model unchanged and a command that will show the confirmation dialog
 	return model, func() common.Message {
 		// This will be handled by the main.go to show the dialog
@@ -210,9 +570,24 @@ func handleShowExitConfirm(model common.Model) (common.Model, Command) {
 
 // handleSwitchTurns handles the switchTurnsMsg
 func handleSwitchTurns(model common.Model) (common.Model, Command) {
+	if model.GameStatus == gameOver {
+		return model, noCommand
+	}
+
 	// CreateAboutPanel a copy of the model to avoid modifying the original
 	newModel := model
 	newPlayers := make([]*common.Player, len(model.Players))
+	endedPlayerIndex := -1
+
+	// Remember the state before the switch so it can be reverted
+	lastTurnSwitch := &common.TurnSwitch{
+		Players:       make([]common.Player, len(model.Players)),
+		TotalGameTime: model.TotalGameTime,
+	}
+	for i, player := range model.Players {
+		lastTurnSwitch.Players[i] = *player
+	}
+	newModel.LastTurnSwitch = lastTurnSwitch
 
 	// Log for currently active players that their turn is ending
 	for i, player := range model.Players {
@@ -221,7 +596,16 @@ func handleSwitchTurns(model common.Model) (common.Model, Command) {
 		newPlayers[i] = &newPlayer
 
 		if player.IsTurn {
-			logging.AddLogEntry(newPlayers[i], &newModel, "Turn %d ended", player.TurnCount)
+			// Clip the durations, so the copy kept to revert the switch is not changed
+			newPlayers[i].TurnDurations = append(slices.Clip(player.TurnDurations), player.TimeElapsed-player.TurnStart)
+			logging.AddLogEntry(newPlayers[i], &newModel, common.LogTypeTurn, "Turn %d ended", player.TurnCount)
+			// Ending the turn within a byo-yomi period keeps the period
+			if player.ByoYomiPeriod > 0 && !player.Flagged {
+				newPlayers[i].PeriodLeft = time.Duration(model.Options.ByoYomiSeconds) * time.Second
+			}
+			if endedPlayerIndex < 0 {
+				endedPlayerIndex = i
+			}
 		}
 
 		// Switch turns
@@ -231,10 +615,12 @@ func handleSwitchTurns(model common.Model) (common.Model, Command) {
 			// Increment turn count when a player's turn begins
 			newPlayers[i].TurnCount++
 			newPlayers[i].CurrentPhase = 0
+			newPlayers[i].PhaseElapsed = 0
+			newPlayers[i].TurnStart = player.TimeElapsed
 			// Log for newly active players that their turn is starting
-			logging.AddLogEntry(newPlayers[i], &newModel, "Turn %d started", newPlayers[i].TurnCount)
+			logging.AddLogEntry(newPlayers[i], &newModel, common.LogTypeTurn, "Turn %d started", newPlayers[i].TurnCount)
 			if len(model.Phases) > 0 {
-				logging.AddLogEntry(newPlayers[i], &newModel, "Turn %d - Entered phase: %s", newPlayers[i].TurnCount, model.Phases[0])
+				logging.AddLogEntry(newPlayers[i], &newModel, common.LogTypePhase, "Turn %d - Entered phase: %s", newPlayers[i].TurnCount, model.Phases[0])
 			}
 		}
 	}
@@ -242,11 +628,284 @@ func handleSwitchTurns(model common.Model) (common.Model, Command) {
 	// Update the model with the new players
 	newModel.Players = newPlayers
 
+	// Give the new player time to take over the table before their clock starts
+	newModel.GraceRemaining = time.Duration(model.Options.GracePeriod) * time.Second
+
 	// If we're not on the main screen, this is a good time to return to it
 	if model.CurrentScreen != "main" {
 		newModel.CurrentScreen = "main"
 	}
 
+	// Ask for secondary objective scores of the player whose turn just ended
+	if shouldPromptSecondaryObjectives(model) && endedPlayerIndex >= 0 {
+		return newModel, func() common.Message {
+			return &common.ShowModalMsg{Type: "SecondaryObjectives", PlayerIndex: endedPlayerIndex}
+		}
+	}
+
+	return newModel, noCommand
+}
+
+// handleRevertTurnSwitch reverts the most recent turn switch if it happened within the revert window.
+// The players are restored to their state before the switch, and the time that elapsed since the switch
+// is moved from the new active player back to the previous one.
+func handleRevertTurnSwitch(model common.Model) (common.Model, Command) {
+	lastTurnSwitch := model.LastTurnSwitch
+	if lastTurnSwitch == nil || len(lastTurnSwitch.Players) != len(model.Players) {
+		return model, noCommand
+	}
+
+	elapsed := model.TotalGameTime - lastTurnSwitch.TotalGameTime
+	if elapsed > time.Duration(model.Options.RevertWindow)*time.Second {
+		return model, noCommand
+	}
+
+	newModel := model
+	newModel.LastTurnSwitch = nil
+	newModel.GraceRemaining = 0
+	newPlayers := make([]*common.Player, len(lastTurnSwitch.Players))
+	for i := range lastTurnSwitch.Players {
+		newPlayer := lastTurnSwitch.Players[i]
+		newPlayer.ActionLog = append([]common.LogEntry{}, model.Players[i].ActionLog...)
+		newPlayers[i] = &newPlayer
+
+		if newPlayer.IsTurn {
+			newPlayer.TimeElapsed += elapsed
+			newPlayer.PhaseElapsed += elapsed
+			logging.AddLogEntry(newPlayers[i], &newModel, common.LogTypeTurn, "Turn switch reverted (%v credited back)", elapsed)
+		}
+	}
+	newModel.Players = newPlayers
+
+	return newModel, noCommand
+}
+
+// handleAddNote adds a note to the action log of the active player(s)
+func handleAddNote(msg *common.AddNoteMsg, model common.Model) (common.Model, Command) {
+	newModel := model
+	newPlayers := make([]*common.Player, len(model.Players))
+
+	for i, player := range model.Players {
+		newPlayer := *player
+		newPlayers[i] = &newPlayer
+
+		if player.IsTurn {
+			logging.AddLogEntry(newPlayers[i], &newModel, common.LogTypeNote, "Note: %s", msg.Text)
+		}
+	}
+
+	newModel.Players = newPlayers
+	return newModel, noCommand
+}
+
+// handleRollDice logs a roll of the dice roller to the active player's action log, tagged with their turn and phase,
+// so the rolls are in the CSV log and the reports next to the times
+func handleRollDice(msg *common.RollDiceMsg, model common.Model) (common.Model, Command) {
+	if len(model.Players) == 0 {
+		return model, noCommand
+	}
+	newModel := copyPlayers(model)
+	index := max(slices.IndexFunc(newModel.Players, func(player *common.Player) bool { return player.IsTurn }), 0)
+	player := newModel.Players[index]
+	phase := ""
+	if player.CurrentPhase >= 0 && player.CurrentPhase < len(model.Phases) {
+		phase = ", " + model.Phases[player.CurrentPhase]
+	}
+	logging.AddLogEntry(player, &newModel, common.LogTypeDice, "Rolled %s (turn %d%s): %s", msg.Roll.Expr,
+		player.TurnCount, phase, msg.Roll)
+	return newModel, noCommand
+}
+
+// handleRollTable logs a roll on a random table of the ruleset to the active player's action log with the entry
+// rolled, tagged with their turn and phase like the rolls of the dice roller
+func handleRollTable(msg *common.RollTableMsg, model common.Model) (common.Model, Command) {
+	table, found := rules.FindRandomTable(model.Options.Rules[model.Options.Default].RandomTables, msg.Table)
+	if len(model.Players) == 0 || !found {
+		return model, noCommand
+	}
+	entry, found := table.Entry(msg.Roll.Total())
+	if !found {
+		entry = "no entry"
+	}
+	newModel := copyPlayers(model)
+	index := max(slices.IndexFunc(newModel.Players, func(player *common.Player) bool { return player.IsTurn }), 0)
+	player := newModel.Players[index]
+	phase := ""
+	if player.CurrentPhase >= 0 && player.CurrentPhase < len(model.Phases) {
+		phase = ", " + model.Phases[player.CurrentPhase]
+	}
+	logging.AddLogEntry(player, &newModel, common.LogTypeDice, "Rolled on %s (turn %d%s): %s, %s", table.Name,
+		player.TurnCount, phase, msg.Roll, entry)
+	return newModel, noCommand
+}
+
+// handleShowDiceRoller asks for the dice to roll
+func handleShowDiceRoller(model common.Model) (common.Model, Command) {
+	return model, func() common.Message {
+		return &common.ShowModalMsg{Type: "DiceRoller"}
+	}
+}
+
+// handleSaveTemplate saves the current ruleset, players, color palette and clock setup as a named game template.
+// A template with the same name is replaced.
+func handleSaveTemplate(msg *common.SaveTemplateMsg, model common.Model) (common.Model, Command) {
+	name := strings.TrimSpace(msg.Name)
+	if name == "" {
+		return model, noCommand
+	}
+
+	playerNames := make([]string, len(model.Players))
+	for i, player := range model.Players {
+		playerNames[i] = player.Name
+	}
+	template := options.GameTemplate{
+		Name:         name,
+		Ruleset:      model.Options.Rules[model.Options.Default].Name,
+		PlayerCount:  len(model.Players),
+		PlayerNames:  playerNames,
+		ColorPalette: model.Options.ColorPalette,
+
+		TimeBudget:     model.Options.TimeBudget,
+		TimeOdds:       model.Options.TimeOdds,
+		FlagFall:       model.Options.FlagFall,
+		ByoYomiPeriods: model.Options.ByoYomiPeriods,
+		ByoYomiSeconds: model.Options.ByoYomiSeconds,
+		GracePeriod:    model.Options.GracePeriod,
+		GameSize:       model.Options.GameSize,
+	}
+
+	newModel := model
+	newModel.Options.Templates = append([]options.GameTemplate{}, model.Options.Templates...)
+	for i, existing := range newModel.Options.Templates {
+		if existing.Name == name {
+			newModel.Options.Templates[i] = template
+			return newModel, noCommand
+		}
+	}
+	newModel.Options.Templates = append(newModel.Options.Templates, template)
+	return newModel, noCommand
+}
+
+// handleApplyTemplate sets up a new game from a template: the ruleset, color palette, clock setup and players are
+// replaced by the ones of the template. Templates can only be applied before the game starts.
+func handleApplyTemplate(msg *common.ApplyTemplateMsg, model common.Model) (common.Model, Command) {
+	if model.GameStarted || msg.Index < 0 || msg.Index >= len(model.Options.Templates) {
+		return model, noCommand
+	}
+	template := model.Options.Templates[msg.Index]
+
+	newModel := model
+	for i, rule := range model.Options.Rules {
+		if rule.Name == template.Ruleset {
+			newModel.Options.Default = i
+			newModel.Phases = RulesetPhases(newModel.Options)
+			break
+		}
+	}
+	if template.ColorPalette != "" {
+		newModel.Options.ColorPalette = template.ColorPalette
+		newModel.CurrentColorPalette = OptionsColorPalette(newModel.Options, template.ColorPalette)
+	}
+	if template.FlagFall != "" {
+		newModel.Options.TimeBudget = template.TimeBudget
+		newModel.Options.TimeOdds = template.TimeOdds
+		newModel.Options.FlagFall = template.FlagFall
+		newModel.Options.ByoYomiPeriods = template.ByoYomiPeriods
+		newModel.Options.ByoYomiSeconds = template.ByoYomiSeconds
+		newModel.Options.GracePeriod = template.GracePeriod
+		newModel.Options.GameSize = template.GameSize
+	}
+
+	playerCount := max(template.PlayerCount, len(template.PlayerNames), 1)
+	newModel.Options.PlayerCount = playerCount
+	newModel.Options.PlayerNames = make([]string, playerCount)
+	newPlayers := make([]*common.Player, playerCount)
+	for i := range newPlayers {
+		name := fmt.Sprintf("Player %d", i+1)
+		if i < len(template.PlayerNames) && template.PlayerNames[i] != "" {
+			name = template.PlayerNames[i]
+		}
+		newModel.Options.PlayerNames[i] = name
+		newPlayers[i] = &common.Player{
+			Name:      name,
+			IsTurn:    i == 0,
+			ActionLog: []common.LogEntry{},
+			ArmyList:  ProfileArmy(model.Options, name),
+		}
+		// Keep the banner configured for the player position
+		if i < len(model.Players) {
+			newPlayers[i].Banner = model.Players[i].Banner
+		}
+	}
+	newModel.Players = newPlayers
+	newModel.FocusedLog = 0
+	newModel.LastTurnSwitch = nil
+
+	return newModel, noCommand
+}
+
+// shouldPromptSecondaryObjectives reports whether secondary objectives should be scored at the end of a turn
+func shouldPromptSecondaryObjectives(model common.Model) bool {
+	return model.GameStarted &&
+		model.Options.PromptSecondaryObjectives &&
+		len(model.Options.Rules[model.Options.Default].SecondaryObjectives) > 0
+}
+
+// handleScoreSecondaryObjectives adds the scored secondary objectives to the player's victory points
+func handleScoreSecondaryObjectives(msg *common.ScoreSecondaryObjectivesMsg, model common.Model) (common.Model, Command) {
+	restoreUICmd := func() common.Message {
+		return &common.ShowMainScreenMsg{}
+	}
+
+	if msg.PlayerIndex < 0 || msg.PlayerIndex >= len(model.Players) {
+		return model, restoreUICmd
+	}
+
+	newModel := copyPlayers(model)
+	// A wrong PIN asks for the scores again
+	if len(msg.Scores) > 0 && !checkPIN(&newModel, "score", msg.PIN) {
+		return newModel, func() common.Message {
+			return &common.ShowModalMsg{Type: "SecondaryObjectives", PlayerIndex: msg.PlayerIndex}
+		}
+	}
+	newPlayer := newModel.Players[msg.PlayerIndex]
+
+	objectives := model.Options.Rules[model.Options.Default].SecondaryObjectives
+	for i, score := range msg.Scores {
+		if i >= len(objectives) || score == 0 {
+			continue
+		}
+		newPlayer.VictoryPoints += score
+		logging.AddLogEntry(newPlayer, &newModel, common.LogTypeScore, "Scored %d VP for %s (total %d VP)",
+			score, objectives[i], newPlayer.VictoryPoints)
+	}
+
+	return newModel, restoreUICmd
+}
+
+// handleAdjustVictoryPoints adds points, or takes them away if negative, to the victory points of a player, see
+// armyPlayer. The change is logged with the turn and phase it was made in. With an action PIN, scores are only changed
+// through the PIN-protected dialogs.
+func handleAdjustVictoryPoints(model common.Model, points int) (common.Model, Command) {
+	playerIndex := armyPlayer(model)
+	if !model.GameStarted || playerIndex < 0 || pinRequired(model) {
+		return model, noCommand
+	}
+
+	newModel := copyPlayers(model)
+	player := newModel.Players[playerIndex]
+	player.VictoryPoints += points
+	when := fmt.Sprintf("turn %d", player.TurnCount)
+	if player.CurrentPhase >= 0 && player.CurrentPhase < len(model.Phases) {
+		when += ", " + model.Phases[player.CurrentPhase]
+	}
+	if points >= 0 {
+		logging.AddLogEntry(player, &newModel, common.LogTypeScore, "Scored %d VP in %s (total %d VP)",
+			points, when, player.VictoryPoints)
+	} else {
+		logging.AddLogEntry(player, &newModel, common.LogTypeScore, "Lost %d VP in %s (total %d VP)",
+			-points, when, player.VictoryPoints)
+	}
 	return newModel, noCommand
 }
 
@@ -264,9 +923,10 @@ func handleNextPhase(model common.Model) (common.Model, Command) {
 
 		if player.IsTurn && player.CurrentPhase < len(model.Phases)-1 {
 			newPlayers[i].CurrentPhase = player.CurrentPhase + 1
+			newPlayers[i].PhaseElapsed = 0
 
 			// Log the phase change
-			logging.AddLogEntry(newPlayers[i], &newModel, "Started phase: %s",
+			logging.AddLogEntry(newPlayers[i], &newModel, common.LogTypePhase, "Started phase: %s",
 				model.Phases[newPlayers[i].CurrentPhase])
 		}
 	}
@@ -296,9 +956,10 @@ func handlePrevPhase(model common.Model) (common.Model, Command) {
 
 		if player.IsTurn && player.CurrentPhase > 0 {
 			newPlayers[i].CurrentPhase = player.CurrentPhase - 1
+			newPlayers[i].PhaseElapsed = 0
 
 			// Log the phase change
-			logging.AddLogEntry(newPlayers[i], &newModel, "Started phase: %s",
+			logging.AddLogEntry(newPlayers[i], &newModel, common.LogTypePhase, "Started phase: %s",
 				model.Phases[newPlayers[i].CurrentPhase])
 		}
 	}
@@ -316,12 +977,20 @@ func handlePrevPhase(model common.Model) (common.Model, Command) {
 
 // handleShowOptions handles the showOptionsMsg
 func handleShowOptions(model common.Model) (common.Model, Command) {
+	if kioskLocked(model) {
+		return model, noCommand
+	}
 	// CreateAboutPanel a copy of the model to avoid modifying the original
 	newModel := model
 
 	// Toggle between main screen and options screen
 	if model.CurrentScreen == "options" {
 		newModel.CurrentScreen = "main"
+	} else if pinRequired(model) {
+		// The options could turn the PIN off, so the options screen asks for it first
+		return model, func() common.Message {
+			return &common.ShowModalMsg{Type: "OptionsPIN"}
+		}
 	} else {
 		newModel.CurrentScreen = "options"
 	}
@@ -329,8 +998,32 @@ func handleShowOptions(model common.Model) (common.Model, Command) {
 	return newModel, noCommand
 }
 
+// handleUnlockOptions opens the options screen if the action PIN entered for it matches
+func handleUnlockOptions(msg *common.UnlockOptionsMsg, model common.Model) (common.Model, Command) {
+	if kioskLocked(model) || model.CurrentScreen == "options" {
+		return model, noCommand
+	}
+	newModel := copyPlayers(model)
+	if !checkPIN(&newModel, "options", msg.PIN) {
+		return newModel, func() common.Message {
+			return &common.BellMsg{}
+		}
+	}
+	newModel.CurrentScreen = "options"
+	return newModel, noCommand
+}
+
+// optionsLocked reports whether the options may not be saved, reset or restored, as the options screen was not
+// opened with the action PIN
+func optionsLocked(model common.Model) bool {
+	return pinRequired(model) && model.CurrentScreen != "options"
+}
+
 // handleShowAbout handles the showAboutMsg
 func handleShowAbout(model common.Model) (common.Model, Command) {
+	if kioskLocked(model) {
+		return model, noCommand
+	}
 	// CreateAboutPanel a copy of the model to avoid modifying the original
 	newModel := model
 
@@ -344,6 +1037,31 @@ func handleShowAbout(model common.Model) (common.Model, Command) {
 	return newModel, noCommand
 }
 
+// handleShowFeed toggles between the main screen and the combined game feed screen
+func handleShowFeed(model common.Model) (common.Model, Command) {
+	newModel := model
+	if model.CurrentScreen == "feed" {
+		newModel.CurrentScreen = "main"
+	} else {
+		newModel.CurrentScreen = "feed"
+	}
+	return newModel, noCommand
+}
+
+// handleToggleScratchpad shows or hides the scratchpad calculator
+func handleToggleScratchpad(model common.Model) (common.Model, Command) {
+	newModel := model
+	newModel.ScratchpadShown = !model.ScratchpadShown
+	return newModel, noCommand
+}
+
+// handleToggleArmyList expands or collapses the army sections of the player panels
+func handleToggleArmyList(model common.Model) (common.Model, Command) {
+	newModel := model
+	newModel.ArmyExpanded = !model.ArmyExpanded
+	return newModel, noCommand
+}
+
 // handleShowMainScreen handles the showMainScreenMsg
 func handleShowMainScreen(model common.Model) (common.Model, Command) {
 	// CreateAboutPanel a copy of the model to avoid modifying the original
@@ -358,8 +1076,43 @@ func handleShowMainScreen(model common.Model) (common.Model, Command) {
 	}
 }
 
+// suspendThreshold is the gap between two ticks from which the system is considered to have been suspended
+const suspendThreshold = 10 * time.Second
+
 // handleTick handles the TickMsg
-func handleTick(model common.Model) (common.Model, Command) {
+func handleTick(msg *common.TickMsg, model common.Model) (common.Model, Command) {
+	// The time since the last tick is measured, so the clocks do not depend on the tick interval. Ticks without
+	// a time, and the first tick, count as one tick interval.
+	elapsed := options.TickDuration(model.Options)
+	if !msg.Time.IsZero() {
+		lastTick := model.LastTick
+		model.LastTick = msg.Time
+		if !lastTick.IsZero() {
+			elapsed = max(msg.Time.Sub(lastTick), 0)
+		}
+	}
+
+	// The auxiliary timers run whatever the game status, and ring the bell when they run out
+	newModel, expired := tickTimers(elapsed, model)
+	newModel, cmd := tickClocks(elapsed, newModel)
+	if !newModel.StartsAt.IsZero() && !newModel.LastTick.Before(newModel.StartsAt) {
+		newModel, cmd = startScheduledGame(newModel)
+	}
+	if newModel.Demo {
+		newModel = advanceDemo(newModel)
+	}
+	if expired {
+		return newModel, withBell(cmd)
+	}
+	return newModel, cmd
+}
+
+// tickClocks advances the game and player clocks by the time elapsed since the last tick
+func tickClocks(elapsed time.Duration, model common.Model) (common.Model, Command) {
+	if elapsed >= suspendThreshold && model.GameStatus == gameInProgress && !model.Linked {
+		return handleSuspend(elapsed, model)
+	}
+
 	// Only increment time if the game is in progress (not paused)
 	if model.GameStarted && model.GameStatus == gameInProgress {
 		// CreateAboutPanel a copy of the model to avoid modifying the original
@@ -367,7 +1120,12 @@ func handleTick(model common.Model) (common.Model, Command) {
 		newPlayers := make([]*common.Player, len(model.Players))
 
 		// Increment total game time
-		newModel.TotalGameTime += 1 * time.Second
+		newModel.TotalGameTime += elapsed
+
+		// The grace period after a turn switch is used up before the active player's clock counts
+		grace := min(elapsed, newModel.GraceRemaining)
+		newModel.GraceRemaining -= grace
+		elapsed -= grace
 
 		for i, player := range model.Players {
 			// CreateAboutPanel a copy of each player
@@ -375,26 +1133,482 @@ func handleTick(model common.Model) (common.Model, Command) {
 			newPlayers[i] = &newPlayer
 
 			if player.IsTurn {
-				newPlayers[i].TimeElapsed += 1 * time.Second
+				newPlayers[i].TimeElapsed += elapsed
+				newPlayers[i].PhaseElapsed += elapsed
 			}
 		}
 
 		// Update the model with the new players
 		newModel.Players = newPlayers
+
+		// Check the limits of the active player's phase
+		for i, player := range newPlayers {
+			if !player.IsTurn {
+				continue
+			}
+			switch limit, crossed := crossedPhaseLimit(player, elapsed, &newModel); crossed {
+			case "soft":
+				logging.AddLogEntry(player, &newModel, common.LogTypeWarning, "Soft limit of %v reached in %s",
+					time.Duration(limit.Soft)*time.Second, newModel.Phases[player.CurrentPhase])
+			case "hard":
+				return handleHardPhaseLimit(i, limit, newModel)
+			}
+			break
+		}
+
+		// Check whether the active player ran out of time
+		for i, player := range newPlayers {
+			budget := options.PlayerTimeBudget(model.Options, i)
+			if budget > 0 && player.IsTurn && !player.Flagged && player.TimeElapsed >= budget &&
+				!countByoYomi(player, elapsed, budget, &newModel) {
+				return handleFlagFall(i, newModel)
+			}
+		}
 		return newModel, noCommand
 	}
 
+	// Time spent paused is tracked separately from the play time
+	if model.GameStarted && model.GameStatus == gamePaused {
+		model.PausedTime += elapsed
+	}
+
 	// Don't return a TickCommand here as we already have a ticker in main.go
 	return model, noCommand
 }
 
+// ClocksRunning reports whether the active player's clock is running
+func ClocksRunning(model *common.Model) bool {
+	return model.GameStarted && model.GameStatus == gameInProgress
+}
+
+// handleLinkState handles the LinkStateMsg, replacing the game state with the one received from the linked host
+func handleLinkState(msg *common.LinkStateMsg, model common.Model) (common.Model, Command) {
+	newModel := model
+	newModel.GameStatus = msg.Status
+	newModel.GameStarted = msg.GameStarted
+	newModel.TotalGameTime = msg.TotalGameTime
+	newModel.PausedTime = msg.PausedTime
+	newModel.LinkShared = msg.Shared
+
+	newPlayers := make([]*common.Player, len(msg.Players))
+	for i, linked := range msg.Players {
+		newPlayer := common.Player{}
+		if i < len(model.Players) {
+			newPlayer = *model.Players[i]
+		}
+		newPlayer.Name = linked.Name
+		newPlayer.TimeElapsed = linked.TimeElapsed
+		newPlayer.PhaseElapsed = linked.PhaseElapsed
+		newPlayer.IsTurn = linked.IsTurn
+		newPlayer.CurrentPhase = linked.CurrentPhase
+		newPlayer.TurnCount = linked.TurnCount
+		newPlayer.VictoryPoints = linked.VictoryPoints
+		newPlayer.Flagged = linked.Flagged
+		newPlayer.ByoYomiPeriod = linked.ByoYomiPeriod
+		newPlayer.PeriodLeft = linked.PeriodLeft
+		newPlayers[i] = &newPlayer
+	}
+	newModel.Players = newPlayers
+	return newModel, noCommand
+}
+
+// crossedPhaseLimit returns the limit of the player's current phase and which of its limits, "soft" or "hard", the
+// phase time crossed during the last elapsed time, or an empty string if it crossed none
+func crossedPhaseLimit(player *common.Player, elapsed time.Duration, model *common.Model) (rules.PhaseLimit, string) {
+	if player.CurrentPhase >= len(model.Phases) {
+		return rules.PhaseLimit{}, ""
+	}
+	// Limits are set by the phase names as defined, not their translations
+	ruleset := model.Options.Rules[model.Options.Default]
+	if player.CurrentPhase >= len(ruleset.Phases) {
+		return rules.PhaseLimit{}, ""
+	}
+	limit, ok := ruleset.PhaseLimits[ruleset.Phases[player.CurrentPhase]]
+	if !ok {
+		return limit, ""
+	}
+
+	previous := player.PhaseElapsed - elapsed
+	crossed := func(seconds int) bool {
+		limit := time.Duration(seconds) * time.Second
+		return seconds > 0 && previous < limit && player.PhaseElapsed >= limit
+	}
+	switch {
+	case crossed(limit.Hard):
+		return limit, "hard"
+	case crossed(limit.Soft):
+		return limit, "soft"
+	}
+	return limit, ""
+}
+
+// handleHardPhaseLimit calls the judge when the phase of the player at index reached its hard limit, and moves the
+// player on to the next phase, or the next player after the last phase, or pauses the game. The player must
+// already be a copy owned by the model.
+func handleHardPhaseLimit(index int, limit rules.PhaseLimit, model common.Model) (common.Model, Command) {
+	newModel := model
+	player := newModel.Players[index]
+	logging.AddLogEntry(player, &newModel, common.LogTypeWarning, "Hard limit of %v reached in %s - judge called",
+		time.Duration(limit.Hard)*time.Second, model.Phases[player.CurrentPhase])
+
+	cmd := Command(noCommand)
+	switch {
+	case limit.Action == "pause":
+		newModel.GameStatus = gamePaused
+		logging.AddLogEntry(player, &newModel, common.LogTypeGame, "Game paused")
+	case player.CurrentPhase < len(model.Phases)-1:
+		newModel, cmd = handleNextPhase(newModel)
+	default:
+		newModel, cmd = handleSwitchTurns(newModel)
+	}
+
+	// The judge is called with the bell, unless the switch asks for the secondary objectives first
+	return newModel, withBell(cmd)
+}
+
+// withBell returns a Command that rings the bell if the given command has no message of its own
+func withBell(cmd Command) Command {
+	return func() common.Message {
+		if msg := cmd(); msg != nil {
+			return msg
+		}
+		return &common.BellMsg{}
+	}
+}
+
+// tickTimers counts the auxiliary timers down by the elapsed time and reports whether any of them ran out
+func tickTimers(elapsed time.Duration, model common.Model) (common.Model, bool) {
+	if len(model.Timers) == 0 {
+		return model, false
+	}
+
+	newModel := model
+	newModel.Timers = make([]common.Timer, len(model.Timers))
+	expired := false
+	for i, timer := range model.Timers {
+		if !timer.Done {
+			timer.Remaining = max(timer.Remaining-elapsed, 0)
+			if timer.Remaining == 0 {
+				timer.Done = true
+				expired = true
+			}
+		}
+		newModel.Timers[i] = timer
+	}
+	return newModel, expired
+}
+
+// handleAddTimer starts an auxiliary countdown timer
+func handleAddTimer(msg *common.AddTimerMsg, model common.Model) (common.Model, Command) {
+	if msg.Duration <= 0 {
+		return model, noCommand
+	}
+	newModel := model
+	newModel.Timers = append(append([]common.Timer{}, model.Timers...), common.Timer{Label: msg.Label, Remaining: msg.Duration})
+	return newModel, noCommand
+}
+
+// handleClearTimers removes all auxiliary timers and cancels the scheduled start
+func handleClearTimers(model common.Model) (common.Model, Command) {
+	newModel := model
+	newModel.Timers = nil
+	newModel.StartsAt = time.Time{}
+	return newModel, noCommand
+}
+
+// handleScheduleStart counts down to the start of a game that has not started yet, e.g. to start all tables of a
+// tournament round at the same time
+func handleScheduleStart(msg *common.ScheduleStartMsg, model common.Model) (common.Model, Command) {
+	if model.GameStarted || model.Linked {
+		return model, noCommand
+	}
+	newModel := copyPlayers(model)
+	newModel.StartsAt = msg.At
+	if !msg.At.IsZero() && len(newModel.Players) > 0 {
+		logging.AddLogEntry(newModel.Players[0], &newModel, common.LogTypeGame, "Game start scheduled for %s",
+			msg.At.Format("15:04:05"))
+	}
+	return newModel, noCommand
+}
+
+// startScheduledGame starts the first player's clock once the countdown to the scheduled start ran out, ringing the
+// bell
+func startScheduledGame(model common.Model) (common.Model, Command) {
+	newModel := model
+	newModel.StartsAt = time.Time{}
+	if model.GameStarted {
+		return newModel, noCommand
+	}
+	newModel, _ = handleStartGame(copyPlayers(newModel))
+	return newModel, func() common.Message {
+		return &common.BellMsg{}
+	}
+}
+
+// handleRoundTimer shows the round countdown and announcement pushed by the organizer, ringing the bell for a
+// new announcement
+func handleRoundTimer(msg *common.RoundTimerMsg, model common.Model) (common.Model, Command) {
+	newModel := model
+	newModel.RoundEnds = msg.Ends
+	newModel.Announcement = msg.Announcement
+	if msg.Announcement != "" && msg.Announcement != model.Announcement {
+		return newModel, func() common.Message {
+			return &common.BellMsg{}
+		}
+	}
+	return newModel, noCommand
+}
+
+// handleShowAddTimer asks for the label and duration of a new auxiliary timer
+func handleShowAddTimer(model common.Model) (common.Model, Command) {
+	return model, func() common.Message {
+		return &common.ShowModalMsg{Type: "AddTimer"}
+	}
+}
+
+// countByoYomi counts the time the active player spent after their time budget ran out against their byo-yomi
+// periods, the player must already be a copy owned by the model. When a period is used up, the next one starts. It
+// reports whether the player still has time, which is false without byo-yomi and once the last period is used up.
+func countByoYomi(player *common.Player, elapsed, budget time.Duration, model *common.Model) bool {
+	periods := model.Options.ByoYomiPeriods
+	period := time.Duration(model.Options.ByoYomiSeconds) * time.Second
+	if periods <= 0 || period <= 0 {
+		return false
+	}
+
+	if player.ByoYomiPeriod == 0 {
+		player.ByoYomiPeriod = 1
+		player.PeriodLeft = period
+		elapsed = player.TimeElapsed - budget
+		logging.AddLogEntry(player, model, common.LogTypeWarning, "Main time used up - byo-yomi period 1 of %d", periods)
+	}
+	player.PeriodLeft -= elapsed
+	for player.PeriodLeft <= 0 {
+		if player.ByoYomiPeriod >= periods {
+			return false
+		}
+		player.ByoYomiPeriod++
+		player.PeriodLeft += period
+		logging.AddLogEntry(player, model, common.LogTypeWarning, "Byo-yomi period %d of %d", player.ByoYomiPeriod, periods)
+	}
+	return true
+}
+
+// handleFlagFall marks a player who ran out of time, the player must already be a copy owned by the model.
+// Depending on the options, the clock keeps counting into negative time, or the game is paused or ended.
+func handleFlagFall(index int, model common.Model) (common.Model, Command) {
+	newModel := model
+	newModel.Players[index].Flagged = true
+	logging.AddLogEntry(newModel.Players[index], &newModel, common.LogTypeWarning, "Flag fell - %s is out of time",
+		newModel.Players[index].Name)
+
+	switch model.Options.FlagFall {
+	case "pause":
+		newModel.GameStatus = gamePaused
+		logging.AddLogEntry(newModel.Players[index], &newModel, common.LogTypeGame, "Game paused")
+	case "over":
+		// The bell is rung with the dialog
+		return handleGameOver(index, newModel)
+	case "end":
+		newModel, _ = handleEndGame(newModel)
+	}
+
+	if !model.Options.FlagSound {
+		return newModel, noCommand
+	}
+	return newModel, func() common.Message {
+		return &common.BellMsg{}
+	}
+}
+
+// handleGameOver stops the clocks for good after a player lost on time, the players must already be copies owned by
+// the model. The result is logged for all players and shown in a dialog; the game keeps its state until it is ended.
+func handleGameOver(loser int, model common.Model) (common.Model, Command) {
+	newModel := model
+	newModel.GameStatus = gameOver
+	for _, player := range newModel.Players {
+		logging.AddLogEntry(player, &newModel, common.LogTypeGame, "Game over - %s", timeoutResult(newModel, loser))
+	}
+
+	return newModel, func() common.Message {
+		return &common.ShowModalMsg{Type: "GameOver", PlayerIndex: loser}
+	}
+}
+
+// timeoutWinner returns the index of the winner of a game lost on time: the player with the most victory points who
+// still has time, or -1 if they are tied
+func timeoutWinner(model common.Model, loser int) int {
+	winner, tied := -1, false
+	for i, player := range model.Players {
+		if i == loser || player.Flagged {
+			continue
+		}
+		switch {
+		case winner < 0 || player.VictoryPoints > model.Players[winner].VictoryPoints:
+			winner, tied = i, false
+		case player.VictoryPoints == model.Players[winner].VictoryPoints:
+			tied = true
+		}
+	}
+	if tied {
+		return -1
+	}
+	return winner
+}
+
+// timeoutResult returns the result of a game lost on time, e.g. "Bob lost on time, Alice wins"
+func timeoutResult(model common.Model, loser int) string {
+	result := model.Players[loser].Name + " lost on time"
+	if winner := timeoutWinner(model, loser); winner >= 0 {
+		result += ", " + model.Players[winner].Name + " wins"
+	}
+	return result
+}
+
+// handleSuspend pauses the game after the system was suspended and asks the user what to do with the lost time
+func handleSuspend(suspended time.Duration, model common.Model) (common.Model, Command) {
+	newModel := model
+	newModel.GameStatus = gamePaused
+	newModel.SuspendedFor = suspended
+	for i, player := range model.Players {
+		if player.IsTurn {
+			logging.AddLogEntry(newModel.Players[i], &newModel, common.LogTypeWarning,
+				"System was suspended for %s", formatSuspended(suspended))
+		}
+	}
+
+	return newModel, func() common.Message {
+		return &common.ShowModalMsg{Type: "SuspendResolve"}
+	}
+}
+
+// handleResolveSuspend handles the ResolveSuspendMsg, adding the suspended time to the active players, discarding
+// it or leaving the game paused
+func handleResolveSuspend(msg *common.ResolveSuspendMsg, model common.Model) (common.Model, Command) {
+	if model.SuspendedFor == 0 {
+		return model, noCommand
+	}
+	// A wrong PIN asks again, as the suspended time is still to be resolved
+	if msg.Action == "add" {
+		model = copyPlayers(model)
+		if !checkPIN(&model, "add suspended time", msg.PIN) {
+			return model, func() common.Message {
+				return &common.ShowModalMsg{Type: "SuspendResolve"}
+			}
+		}
+	}
+
+	newModel := model
+	newModel.SuspendedFor = 0
+	newPlayers := make([]*common.Player, len(model.Players))
+	for i, player := range model.Players {
+		newPlayer := *player
+		newPlayers[i] = &newPlayer
+	}
+	newModel.Players = newPlayers
+
+	var action string
+	switch msg.Action {
+	case "add":
+		newModel.TotalGameTime += model.SuspendedFor
+		for _, player := range newPlayers {
+			if player.IsTurn {
+				player.TimeElapsed += model.SuspendedFor
+				player.PhaseElapsed += model.SuspendedFor
+			}
+		}
+		action = "Added"
+	case "discard":
+		action = "Discarded"
+	default:
+		return newModel, noCommand
+	}
+
+	if model.GameStatus == gamePaused {
+		newModel.GameStatus = gameInProgress
+	}
+	for i, player := range newPlayers {
+		if player.IsTurn {
+			logging.AddLogEntry(newModel.Players[i], &newModel, common.LogTypeGame, "%s %s of suspended time",
+				action, formatSuspended(model.SuspendedFor))
+		}
+	}
+	return newModel, noCommand
+}
+
+// formatSuspended formats the time the system was suspended, e.g. "45s", "18m" or "1h05m"
+func formatSuspended(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
 // handleKeyPress handles the keyPressMsg
 func handleKeyPress(msg *common.KeyPressMsg, model common.Model) (common.Model, Command) {
+	// Any key completes or cancels a pending multi-key binding
+	pendingKey := model.PendingKey
+	model.PendingKey = 0
+
+	if msg.Key == tcell.KeyCtrlL {
+		return handleToggleInputLock(model)
+	}
+	// Screenshots do not change the game, so they can be taken while the input is locked and on linked clients
+	if msg.Key == tcell.KeyCtrlP {
+		return handleScreenshot(model)
+	}
+	// Linked clients of a shared game send its keys to the host, which plays them for both terminals
+	if model.Linked && model.LinkShared && !model.InputLocked && SharedKey(msg) {
+		return model, func() common.Message {
+			return &common.ForwardKeyMsg{Key: msg.Key, Rune: msg.Rune}
+		}
+	}
+	// Linked clients only mirror the host, the game is played on the host
+	if (model.InputLocked || model.Linked) && !isAllowedWhileLocked(msg) {
+		return model, noCommand
+	}
+
+	if msg.Key == tcell.KeyRune {
+		switch msg.Rune {
+		case 'm', 'M':
+			return handleToggleMacroRecording(model)
+		case '@':
+			return handleReplayMacro(model)
+		}
+		if strings.ContainsRune(macroKeys, msg.Rune) {
+			model = recordMacroStep(model, macroKeyName(msg.Rune))
+		}
+	}
+
+	if model.Options.VimBindings && msg.Key == tcell.KeyRune {
+		if newModel, cmd, handled := handleVimKey(msg.Rune, pendingKey, model); handled {
+			return newModel, cmd
+		}
+	}
+
+	if newModel, cmd, handled := handleLogKey(msg, model); handled {
+		return newModel, cmd
+	}
+
 	switch msg.Key {
 	case tcell.KeyEscape, tcell.KeyCtrlC:
 		// Quit the application
 		// This will be handled in the main function
 		return model, noCommand
+	case tcell.KeyCtrlS:
+		// Save the game to continue it later
+		return handleSaveGame(model)
+	case tcell.KeyCtrlZ:
+		// Undo the latest turn switch, phase change or end of the game
+		return handleUndo(model)
+	case tcell.KeyCtrlR:
+		// Redo the latest undone action
+		return handleRedo(model)
 	case tcell.KeyRune:
 		switch string(msg.Rune) {
 		case "o", "O":
@@ -402,9 +1616,15 @@ func handleKeyPress(msg *common.KeyPressMsg, model common.Model) (common.Model,
 		case "a", "A":
 			// Toggle about screen
 			return handleShowAbout(model)
+		case "f", "F":
+			// Toggle the combined game feed screen
+			return handleShowFeed(model)
+		case "u", "U":
+			// Expand or collapse the army lists
+			return handleToggleArmyList(model)
 		case "s", "S":
 			// Start/pause/resume game
-			return handleStartGame(model)
+			return handleStart(model)
 		case "e", "E":
 			// End game (only if game has started)
 			if model.GameStarted {
@@ -426,6 +1646,45 @@ func handleKeyPress(msg *common.KeyPressMsg, model common.Model) (common.Model,
 		case " ":
 			// Switch turns
 			return handleSwitchTurns(model)
+		case "r", "R":
+			// Revert the most recent turn switch
+			return handleRevertTurnSwitch(model)
+		case "t", "T":
+			// Pick a game template, only before the game starts
+			if !model.GameStarted {
+				return model, func() common.Message {
+					return &common.ShowModalMsg{Type: "Templates"}
+				}
+			}
+		case "n", "N":
+			// Add a note to the active player's log
+			return model, func() common.Message {
+				return &common.ShowModalMsg{Type: "AddNote"}
+			}
+		case "c", "C":
+			// Start an auxiliary countdown timer
+			return handleShowAddTimer(model)
+		case "x", "X":
+			// Roll dice
+			return handleShowDiceRoller(model)
+		case "=":
+			// Show or hide the scratchpad calculator
+			return handleToggleScratchpad(model)
+		case "+":
+			// Score a victory point for the active player
+			return handleAdjustVictoryPoints(model, 1)
+		case "-":
+			// Take a victory point away from the active player
+			return handleAdjustVictoryPoints(model, -1)
+		case "J":
+			// Unlock judge mode, or intervene as the judge
+			return handleShowJudge(model)
+		case "L":
+			// Edit the army list of the active player
+			return handleShowArmyEditor(model)
+		case "d", "D":
+			// Mark units of the active player destroyed, below half strength or shaken
+			return handleShowUnitStatus(model)
 		}
 	default:
 		// Handle other keys if needed
@@ -434,19 +1693,316 @@ func handleKeyPress(msg *common.KeyPressMsg, model common.Model) (common.Model,
 	return model, noCommand
 }
 
-// SetupInputCapture sets up the input capture for the tview application
-func SetupInputCapture(app *tview.Application, msgChan chan<- common.Message) {
+// handleToggleInputLock locks or unlocks the game-mutating keys
+func handleToggleInputLock(model common.Model) (common.Model, Command) {
+	newModel := model
+	newModel.InputLocked = !model.InputLocked
+	return newModel, noCommand
+}
+
+// kioskLocked reports whether the options and about screens, ending the game and quitting are disabled because the
+// clock runs in kiosk mode on an unattended display. The judge unlocks them with the judge passphrase.
+func kioskLocked(model common.Model) bool {
+	return model.Kiosk && !model.JudgeMode
+}
+
+// isAllowedWhileLocked reports whether a key may be used while the input is locked.
+// Only keys that do not change the game are allowed: quitting (with confirmation), the about and feed screens, the
+// army lists, log navigation, the scratchpad and judge mode, which is protected by its passphrase.
+func isAllowedWhileLocked(msg *common.KeyPressMsg) bool {
+	switch msg.Key {
+	case tcell.KeyRune:
+		return strings.ContainsRune("qQaAfFuUhjklgGJ=", msg.Rune)
+	case tcell.KeyEscape, tcell.KeyCtrlC, tcell.KeyTab, tcell.KeyBacktab,
+		tcell.KeyPgUp, tcell.KeyPgDn, tcell.KeyHome, tcell.KeyEnd:
+		return true
+	default:
+		return false
+	}
+}
+
+// sharedKeys are the game keys a linked terminal of a shared game may play on the host: starting and pausing,
+// switching turns and changing phases, and taking them back. Keys opening a dialog stay on the host.
+const sharedKeys = "sSpPbBrR "
+
+// SharedKey reports whether a key may be played on the host of a shared game from a linked terminal
+func SharedKey(msg *common.KeyPressMsg) bool {
+	switch msg.Key {
+	case tcell.KeyRune:
+		return strings.ContainsRune(sharedKeys, msg.Rune)
+	case tcell.KeyCtrlZ, tcell.KeyCtrlR:
+		return true
+	default:
+		return false
+	}
+}
+
+// macroKeys are the game and bookkeeping keys that can be recorded into a macro
+const macroKeys = "sSpPbB +-"
+
+// Prefixes of the macro steps that are not keys: a note added to the active player's log, e.g. "note:Objective
+// held", and an auxiliary timer, e.g. "timer:5m0s Reinforcements"
+const (
+	macroNote  = "note:"
+	macroTimer = "timer:"
+)
+
+// recordMacroStep adds a step to the macro being recorded
+func recordMacroStep(model common.Model, step string) common.Model {
+	if !model.RecordingMacro {
+		return model
+	}
+	newModel := model
+	newModel.Options.Macro = append(slices.Clip(model.Options.Macro), step)
+	return newModel
+}
+
+// timerStep returns the macro step of a timer, its duration followed by its label
+func timerStep(msg *common.AddTimerMsg) string {
+	return strings.TrimSpace(msg.Duration.String() + " " + msg.Label)
+}
+
+// handleToggleMacroRecording starts recording a new macro, or stops the current recording
+func handleToggleMacroRecording(model common.Model) (common.Model, Command) {
+	newModel := model
+	newModel.RecordingMacro = !model.RecordingMacro
+	if newModel.RecordingMacro {
+		newModel.Options.Macro = nil
+	}
+	return newModel, noCommand
+}
+
+// handleReplayMacro replays the recorded macro keys in order. The commands of the keys are run in order, like those
+// of keys pressed one after the other.
+func handleReplayMacro(model common.Model) (common.Model, Command) {
+	if model.RecordingMacro {
+		return model, noCommand
+	}
+
+	newModel := model
+	var cmds []Command
+	for _, name := range model.Options.Macro {
+		var cmd Command
+		if text, ok := strings.CutPrefix(name, macroNote); ok {
+			newModel, cmd = handleAddNote(&common.AddNoteMsg{Text: text}, newModel)
+		} else if step, ok := strings.CutPrefix(name, macroTimer); ok {
+			duration, label, _ := strings.Cut(step, " ")
+			if d, err := time.ParseDuration(duration); err == nil {
+				newModel, cmd = handleAddTimer(&common.AddTimerMsg{Label: label, Duration: d}, newModel)
+			}
+		} else if key, ok := macroKeyRune(name); ok {
+			newModel, cmd = handleKeyPress(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: key}, newModel)
+		}
+		cmds = append(cmds, cmd)
+	}
+	return newModel, batch(cmds...)
+}
+
+// macroKeyName returns the name under which a key is stored in a macro
+func macroKeyName(key rune) string {
+	if key == ' ' {
+		return "SPACE"
+	}
+	return strings.ToLower(string(key))
+}
+
+// macroKeyRune returns the key stored under a name in a macro, and whether it can be replayed
+func macroKeyRune(name string) (rune, bool) {
+	if strings.EqualFold(name, "SPACE") {
+		return ' ', true
+	}
+	runes := []rune(strings.ToLower(name))
+	if len(runes) != 1 || !strings.ContainsRune(macroKeys, runes[0]) {
+		return 0, false
+	}
+	return runes[0], true
+}
+
+// handleVimKey handles the vim-style key bindings and reports whether the key was consumed
+func handleVimKey(key rune, pendingKey rune, model common.Model) (common.Model, Command, bool) {
+	switch key {
+	case 'h':
+		return focusLog(model, model.FocusedLog-1), noCommand, true
+	case 'l':
+		return focusLog(model, model.FocusedLog+1), noCommand, true
+	case 'j':
+		return model, scrollLogCommand(common.ScrollLogMsg{PlayerIndex: model.FocusedLog, Lines: 1}), true
+	case 'k':
+		return model, scrollLogCommand(common.ScrollLogMsg{PlayerIndex: model.FocusedLog, Lines: -1}), true
+	case 'g':
+		if pendingKey == 'g' {
+			return model, scrollLogCommand(common.ScrollLogMsg{PlayerIndex: model.FocusedLog, ToTop: true}), true
+		}
+		newModel := model
+		newModel.PendingKey = 'g'
+		return newModel, noCommand, true
+	case 'G':
+		return model, scrollLogCommand(common.ScrollLogMsg{PlayerIndex: model.FocusedLog, ToBottom: true}), true
+	case ':':
+		return model, func() common.Message {
+			return &common.ShowModalMsg{Type: "CommandPalette"}
+		}, true
+	}
+	return model, noCommand, false
+}
+
+// logPageLines is the number of lines scrolled by PgUp and PgDn
+const logPageLines = 10
+
+// handleLogKey handles the keys for focusing and scrolling the action logs and reports whether the key was consumed
+func handleLogKey(msg *common.KeyPressMsg, model common.Model) (common.Model, Command, bool) {
+	switch msg.Key {
+	case tcell.KeyTab:
+		// The first Tab focuses the current log, the following ones move to the next player's log
+		if !model.LogFocused {
+			newModel := model
+			newModel.LogFocused = true
+			return newModel, noCommand, true
+		}
+		return focusLog(model, model.FocusedLog+1), noCommand, true
+	case tcell.KeyBacktab:
+		newModel := focusLog(model, model.FocusedLog-1)
+		newModel.LogFocused = true
+		return newModel, noCommand, true
+	case tcell.KeyEscape:
+		if model.LogFocused {
+			newModel := model
+			newModel.LogFocused = false
+			return newModel, noCommand, true
+		}
+	case tcell.KeyPgUp:
+		return model, scrollLogCommand(common.ScrollLogMsg{PlayerIndex: model.FocusedLog, Lines: -logPageLines}), true
+	case tcell.KeyPgDn:
+		return model, scrollLogCommand(common.ScrollLogMsg{PlayerIndex: model.FocusedLog, Lines: logPageLines}), true
+	case tcell.KeyHome:
+		return model, scrollLogCommand(common.ScrollLogMsg{PlayerIndex: model.FocusedLog, ToTop: true}), true
+	case tcell.KeyEnd:
+		return model, scrollLogCommand(common.ScrollLogMsg{PlayerIndex: model.FocusedLog, ToBottom: true}), true
+	case tcell.KeyRune:
+		if !model.LogFocused {
+			break
+		}
+		switch msg.Rune {
+		case 'j':
+			return model, scrollLogCommand(common.ScrollLogMsg{PlayerIndex: model.FocusedLog, Lines: 1}), true
+		case 'k':
+			return model, scrollLogCommand(common.ScrollLogMsg{PlayerIndex: model.FocusedLog, Lines: -1}), true
+		}
+	}
+	return model, noCommand, false
+}
+
+// focusLog moves the keyboard log focus to the given player, wrapping around at the ends
+func focusLog(model common.Model, index int) common.Model {
+	if len(model.Players) == 0 {
+		return model
+	}
+	newModel := model
+	newModel.FocusedLog = (index%len(model.Players) + len(model.Players)) % len(model.Players)
+	return newModel
+}
+
+// scrollLogCommand returns a command that scrolls a player's action log in the view
+func scrollLogCommand(msg common.ScrollLogMsg) Command {
+	return func() common.Message {
+		return &msg
+	}
+}
+
+// paletteCommands maps the command palette entries to their update handlers
+var paletteCommands = map[string]func(common.Model) (common.Model, Command){
+	"start":      handleStart,
+	"pause":      handleStartGame,
+	"resume":     handleStartGame,
+	"end":        handleShowEndGameConfirm,
+	"switch":     handleSwitchTurns,
+	"next":       handleNextPhase,
+	"prev":       handlePrevPhase,
+	"options":    handleShowOptions,
+	"about":      handleShowAbout,
+	"feed":       handleShowFeed,
+	"army":       handleToggleArmyList,
+	"armylist":   handleShowArmyEditor,
+	"units":      handleShowUnitStatus,
+	"screenshot": handleScreenshot,
+	"quit":       handleShowExitConfirm,
+	"timer":      handleShowAddTimer,
+	"roll":       handleShowDiceRoller,
+	"calc":       handleToggleScratchpad,
+	"judge":      handleShowJudge,
+	"undo":       handleUndo,
+	"redo":       handleRedo,
+}
+
+// CommandNames returns the sorted names of the commands available in the command palette
+func CommandNames() []string {
+	names := make([]string, 0, len(paletteCommands))
+	for name := range paletteCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// handleRunCommand handles the runCommandMsg
+func handleRunCommand(msg *common.RunCommandMsg, model common.Model) (common.Model, Command) {
+	handler, ok := paletteCommands[msg.Name]
+	if !ok {
+		return model, noCommand
+	}
+
+	// Only run state changes that make sense for the current game status
+	switch msg.Name {
+	case "start":
+		if model.GameStarted {
+			return model, noCommand
+		}
+	case "pause":
+		if model.GameStatus != gameInProgress {
+			return model, noCommand
+		}
+	case "resume":
+		if model.GameStatus != gamePaused {
+			return model, noCommand
+		}
+	case "end":
+		if !model.GameStarted {
+			return model, noCommand
+		}
+	}
+	return handler(model)
+}
+
+// vimKeys are the keys of the vim-style bindings, only taken from the focused view while they are enabled
+const vimKeys = "hjklgG:"
+
+// SetupInputCapture sets up the input capture for the tview application. vimBindings reports whether the vim-style
+// bindings are enabled; it is called on the UI goroutine.
+func SetupInputCapture(app *tview.Application, msgChan chan<- common.Message, vimBindings func() bool) {
 	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Let text input, lists and dialog buttons receive keys without triggering shortcuts
+		switch app.GetFocus().(type) {
+		case *tview.InputField, *tview.TextArea, *tview.Button, *tview.List:
+			return event
+		}
+
 		// Send a KeyPressMsg to the message channel
 		msgChan <- &common.KeyPressMsg{Key: event.Key(), Rune: event.Rune()}
 
 		// Handle specific keys and prevent them from propagating
 		switch event.Key() {
-		case tcell.KeyEscape, tcell.KeyCtrlC:
+		case tcell.KeyEscape, tcell.KeyCtrlC, tcell.KeyCtrlL, tcell.KeyCtrlP, tcell.KeyCtrlS, tcell.KeyCtrlT, tcell.KeyCtrlW,
+			tcell.KeyCtrlZ, tcell.KeyCtrlR, tcell.KeyTab, tcell.KeyBacktab, tcell.KeyPgUp, tcell.KeyPgDn, tcell.KeyHome,
+			tcell.KeyEnd:
 			return nil
 		case tcell.KeyRune:
 			switch event.Rune() {
-			case 'o', 'O', 'a', 'A', 's', 'S', 'e', 'E', 'p', 'P', 'b', 'B', 'q', 'Q', ' ':
+			case 'o', 'O', 'a', 'A', 'f', 'F', 'u', 'U', 's', 'S', 'e', 'E', 'p', 'P', 'b', 'B', 'q', 'Q', ' ',
+				'm', 'M', '@', 'r', 'R', 'n', 'N', 't', 'T', 'c', 'C', 'J', 'L',
+				'd', 'D', 'x', 'X', '=', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+				return nil
+			}
+			if strings.ContainsRune(vimKeys, event.Rune()) && vimBindings() {
 				return nil
 			}
 		default:
@@ -457,11 +2013,107 @@ func SetupInputCapture(app *tview.Application, msgChan chan<- common.Message) {
 }
 
 // Option update handlers
+// handleSaveOptions writes the current options to the options file. The options overridden by the environment
+// or the command line are only written if they were changed on the options screen.
+func handleSaveOptions(model common.Model) (common.Model, Command) {
+	if optionsLocked(model) {
+		return model, noCommand
+	}
+	saved := options.Copy(model.Options)
+	fileOptions := options.WithoutOverrides(model.Options, model.SavedOptions, model.FileOptions)
+	filename := model.OptionsFile
+	return model, func() common.Message {
+		err := options.SaveOptions(fileOptions, filename, true)
+		return &common.OptionsSavedMsg{Options: saved, FileOptions: fileOptions, Err: err}
+	}
+}
+
+// handleOptionsSaved marks the options as saved, or shows why they could not be
+func handleOptionsSaved(msg *common.OptionsSavedMsg, model common.Model) (common.Model, Command) {
+	newModel := model
+	if msg.Err != nil {
+		newModel.OptionsError = msg.Err.Error()
+		return newModel, noCommand
+	}
+
+	newModel.OptionsError = ""
+	newModel.SavedOptions = msg.Options
+	newModel.FileOptions = msg.FileOptions
+	return newModel, noCommand
+}
+
+// handleRevertOptions discards the unsaved changes to the options and rebuilds the options screen
+func handleRevertOptions(model common.Model) (common.Model, Command) {
+	newModel := model
+	newModel.Options = options.Copy(model.SavedOptions)
+	newModel.OptionsError = ""
+	return applyReloadedOptions(newModel)
+}
+
+// handleRestoreOptionsBackup restores the options file from its most recent backup
+func handleRestoreOptionsBackup(model common.Model) (common.Model, Command) {
+	if optionsLocked(model) {
+		return model, noCommand
+	}
+	filename := model.OptionsFile
+	return model, func() common.Message {
+		restored, err := options.RestoreBackup(filename)
+		return &common.OptionsBackupRestoredMsg{Options: restored, Err: err}
+	}
+}
+
+// handleOptionsBackupRestored applies the restored options and rebuilds the options screen, or shows why the backup
+// could not be restored
+func handleOptionsBackupRestored(msg *common.OptionsBackupRestoredMsg, model common.Model) (common.Model, Command) {
+	newModel := model
+	if msg.Err != nil {
+		newModel.OptionsError = msg.Err.Error()
+		return newModel, noCommand
+	}
+
+	newModel.Options = msg.Options
+	newModel.SavedOptions = options.Copy(msg.Options)
+	newModel.FileOptions = options.Copy(msg.Options)
+	newModel.OptionsError = ""
+	return applyReloadedOptions(newModel)
+}
+
+// handleResetOption resets one option, or all options, to the defaults and rebuilds the options screen
+func handleResetOption(msg *common.ResetOptionMsg, model common.Model) (common.Model, Command) {
+	if optionsLocked(model) {
+		return model, noCommand
+	}
+	newModel := model
+	newModel.Options = options.Copy(model.Options)
+	if !options.ResetField(&newModel.Options, msg.Field) {
+		return model, noCommand
+	}
+	return applyReloadedOptions(newModel)
+}
+
+// applyReloadedOptions updates the state derived from the options after they were replaced,
+// and returns a command that rebuilds the options screen
+func applyReloadedOptions(newModel common.Model) (common.Model, Command) {
+	if newModel.Options.Default < len(newModel.Options.Rules) {
+		newModel.Phases = RulesetPhases(newModel.Options)
+	}
+	newModel.CurrentColorPalette = OptionsColorPalette(newModel.Options, newModel.Options.ColorPalette)
+
+	return newModel, func() common.Message {
+		return &common.ReloadOptionsScreenMsg{}
+	}
+}
+
+// OptionsChanged reports whether the options differ from the ones last loaded or saved
+func OptionsChanged(model *common.Model) bool {
+	return !reflect.DeepEqual(model.Options, model.SavedOptions)
+}
+
 // handleSetRuleset handles changes to the selected ruleset
 func handleSetRuleset(msg *common.SetRulesetMsg, model common.Model) (common.Model, Command) {
 	newModel := model
 	newModel.Options.Default = msg.Index
-	newModel.Phases = model.Options.Rules[msg.Index].Phases
+	newModel.Phases = RulesetPhases(newModel.Options)
 	return newModel, noCommand
 }
 
@@ -500,7 +2152,7 @@ func handleSetPlayerName(msg *common.SetPlayerNameMsg, model common.Model) (comm
 func handleSetColorPalette(msg *common.SetColorPaletteMsg, model common.Model) (common.Model, Command) {
 	newModel := model
 	newModel.Options.ColorPalette = msg.Name
-	newModel.CurrentColorPalette = palette.ColorPaletteByName(msg.Name)
+	newModel.CurrentColorPalette = OptionsColorPalette(newModel.Options, msg.Name)
 	return newModel, noCommand
 }
 
@@ -511,6 +2163,13 @@ func handleSetTimeFormat(msg *common.SetTimeFormatMsg, model common.Model) (comm
 	return newModel, noCommand
 }
 
+// handleSetLogTimestamps handles changes to the timestamp display of the action logs
+func handleSetLogTimestamps(msg *common.SetLogTimestampsMsg, model common.Model) (common.Model, Command) {
+	newModel := model
+	newModel.Options.LogTimestamps = msg.Format
+	return newModel, noCommand
+}
+
 // handleSetOneTurnForAllPlayers handles changes to the "One Turn For All Players" option
 func handleSetOneTurnForAllPlayers(msg *common.SetOneTurnForAllPlayersMsg, model common.Model) (common.Model, Command) {
 	newModel := model
@@ -1,13 +1,21 @@
 package hammerclock
 
 import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"hammerclock/internal/hammerclock/common"
+	"hammerclock/internal/hammerclock/dice"
+	"hammerclock/internal/hammerclock/options"
 	"hammerclock/internal/hammerclock/palette"
+	"hammerclock/internal/hammerclock/rules"
 	"hammerclock/internal/hammerclock/ui"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
@@ -17,21 +25,46 @@ type View struct {
 	MainView              *tview.Flex           // The main container for the UI layout.
 	PlayerPanelsContainer *tview.Flex           // Container for player panels.
 	PlayerPanels          []*tview.Flex         // List of individual player panels.
+	TopBar                *tview.Flex           // The top bar holding the menu, ruleset name, round and clock.
 	TopMenu               *tview.TextView       // The top menu bar.
+	TabsDisplay           *tview.TextView       // Text view for displaying the numbers of the session's games.
 	BottomMenu            *tview.TextView       // The bottom menu bar.
 	StatusPanel           *tview.Flex           // Panel displaying the current game status.
+	TimersPanel           *tview.TextView       // Panel displaying the auxiliary timers, hidden while there are none.
+	NameDisplay           *tview.TextView       // Text view for displaying the banner and ruleset name.
 	ClockDisplay          *tview.TextView       // Text view for displaying the clock.
+	RoundDisplay          *tview.TextView       // Text view for displaying the battle round.
 	OptionsScreen         *tview.Grid           // Grid layout for the options screen.
 	AboutScreen           *tview.Flex           // Flex layout for the about screen.
+	FeedScreen            *tview.TextView       // Text view for the combined game feed screen.
+	Scratchpad            *tview.Flex           // Scratchpad calculator below the player panels, hidden until shown.
 	MessageChan           chan<- common.Message // Channel for sending messages to the application.
+	Screen                tcell.Screen          // Screen the application draws on, for screenshots.
 	CurrentScreen         string                // Tracks the currently displayed screen.
+	PlayerNames           []string              // Names of the players the player panels were created for.
+	panelWidgets          []string              // Widgets the player panels were created with.
+	modalOpen             bool                  // Indicates if a modal dialog is displayed over the main UI.
+	shownGame             int                   // Index of the session's game the screens were built for.
+	turnPlayer            int                   // Index of the player whose turn it was at the last render, -1 for none.
+	turnCueUntil          time.Time             // End of the cue on the panel of the player who took over the turn.
+	countdown             bool                  // Indicates if the game was counting down to a scheduled start at the last render.
+	scratchpadShown       bool                  // Indicates if the scratchpad was shown at the last render.
+	vimBindings           bool                  // Indicates if the vim-style bindings were enabled at the last render.
+	outbox                chan common.Message   // Messages of the dialog callbacks, passed on to MessageChan in order.
 }
 
+// outboxSize is the number of messages of the dialog callbacks that can wait to be passed on to the application
+const outboxSize = 64
+
+// turnCueDuration is how long the cue is shown on the new player's panel after a turn switch
+const turnCueDuration = time.Second
+
 // NewView initializes and returns a new View instance.
 // It sets up the main UI components and applies the current color palette.
 func NewView(model *common.Model, msgChan chan<- common.Message) *View {
 	app := tview.NewApplication()
 	palette.ApplyColorPalette(model.CurrentColorPalette)
+	ui.ApplyBorderStyle(model.Options.BorderStyle)
 
 	mainView := tview.NewFlex().SetDirection(tview.FlexRow)
 	topFlex := createTopFlex(model)
@@ -43,31 +76,74 @@ func NewView(model *common.Model, msgChan chan<- common.Message) *View {
 	optionsScreen := ui.CreateOptionsScreen(model, msgChan)
 	aboutScreen := ui.CreateAboutPanel(model.CurrentColorPalette.White)
 
-	statusPanel := ui.CreateStatusPanel(string(model.GameStatus), model.CurrentColorPalette.Cyan, model.CurrentColorPalette.Black)
+	timersPanel := ui.CreateTimersPanel(model.CurrentColorPalette.Cyan, model.CurrentColorPalette.Black)
+	mainView.AddItem(timersPanel, 0, 0, false)
+
+	scratchpad := ui.CreateScratchpad(model.CurrentColorPalette.Cyan, model.CurrentColorPalette.Black, func() {
+		msgChan <- &common.ShowScratchpadMsg{}
+	})
+	mainView.AddItem(scratchpad, 0, 0, false)
+
+	statusPanel := ui.CreateStatusPanel(StatusLabel(model.Options, model.GameStatus), model.CurrentColorPalette.Cyan, model.CurrentColorPalette.Black)
 	mainView.AddItem(statusPanel, 3, 0, false)
 
-	bottomMenu := createBottomMenu(model.GameStatus)
+	bottomMenu := createBottomMenu(model.GameStatus, kioskLocked(*model))
 	mainView.AddItem(bottomMenu, 1, 0, false)
 
+	// Clicking a menu option behaves like pressing its key
+	menuSelected := func(key string) {
+		msgChan <- menuKeyMsg(key)
+	}
+	ui.SetMenuSelectedFunc(topFlex.GetItem(0).(*tview.TextView), menuSelected)
+	ui.SetMenuSelectedFunc(bottomMenu, menuSelected)
+
+	outbox := make(chan common.Message, outboxSize)
+	go func() {
+		for msg := range outbox {
+			msgChan <- msg
+		}
+	}()
+
 	return &View{
 		App:                   app,
 		MainView:              mainView,
 		PlayerPanelsContainer: playerPanelsContainer,
 		PlayerPanels:          playerPanels,
+		TopBar:                topFlex,
 		TopMenu:               topFlex.GetItem(0).(*tview.TextView),
+		TabsDisplay:           topFlex.GetItem(1).(*tview.TextView),
 		BottomMenu:            bottomMenu,
 		StatusPanel:           statusPanel,
+		TimersPanel:           timersPanel,
+		NameDisplay:           topFlex.GetItem(2).(*tview.TextView),
 		ClockDisplay:          topFlex.GetItem(4).(*tview.TextView),
+		RoundDisplay:          topFlex.GetItem(3).(*tview.TextView),
 		OptionsScreen:         optionsScreen,
 		AboutScreen:           aboutScreen,
+		FeedScreen:            ui.CreateFeedScreen(model.CurrentColorPalette),
+		Scratchpad:            scratchpad,
 		MessageChan:           msgChan,
 		CurrentScreen:         "", // Initialize with an empty screen.
+		PlayerNames:           playerNames(model.Players),
+		panelWidgets:          ui.ShownPanelWidgets(model.Options.PanelWidgets),
+		turnPlayer:            -1,
+		vimBindings:           model.Options.VimBindings,
+		outbox:                outbox,
 	}
 }
 
 // Render updates the UI based on the current model state.
 // It refreshes player panels, status panel, and menu text, and switches screens as needed.
 func (view *View) Render(model *common.Model) {
+	view.vimBindings = model.Options.VimBindings
+	// Rebuild the player panels when the players were replaced, e.g. by a game template, or show other widgets
+	if playersChanged(view.PlayerNames, model.Players) ||
+		!slices.Equal(view.panelWidgets, ui.ShownPanelWidgets(model.Options.PanelWidgets)) {
+		view.reloadPlayerPanels(model)
+	}
+	// tview draws all borders with the same global characters, so a new style shows everywhere on the next draw
+	ui.ApplyBorderStyle(model.Options.BorderStyle)
+
 	if model.CurrentScreen != view.CurrentScreen {
 		view.CurrentScreen = model.CurrentScreen
 		view.PlayerPanelsContainer.Clear()
@@ -76,6 +152,8 @@ func (view *View) Render(model *common.Model) {
 			view.PlayerPanelsContainer.AddItem(view.OptionsScreen, 0, 1, false)
 		case "about":
 			view.PlayerPanelsContainer.AddItem(view.AboutScreen, 0, 1, false)
+		case "feed":
+			view.PlayerPanelsContainer.AddItem(view.FeedScreen, 0, 1, false)
 		default:
 			for _, panel := range view.PlayerPanels {
 				view.PlayerPanelsContainer.AddItem(panel, 0, 1, false)
@@ -83,29 +161,582 @@ func (view *View) Render(model *common.Model) {
 		}
 	}
 
+	_, _, width, height := view.PlayerPanelsContainer.GetRect()
+	view.PlayerPanelsContainer.SetDirection(playerPanelsDirection(model.Options.Layout, width, height))
+
+	status := StatusLabel(model.Options, model.GameStatus)
+	if phaseTime := currentPhaseTime(model); phaseTime != "" {
+		status += " | " + phaseTime
+	}
+	if model.RecordingMacro {
+		status += " | ● Recording macro"
+	}
+	if model.InputLocked {
+		status += " | Input locked (Ctrl+L to unlock)"
+	}
+	if model.JudgeMode {
+		status += " | Judge mode"
+	}
+	if roundTimer := roundTimerText(model); roundTimer != "" {
+		status += " | " + roundTimer
+	}
+	if !model.StartsAt.IsZero() {
+		status += " | " + ui.StartCountdownText(model.StartsAt, tickTime(model))
+	}
+
 	ui.UpdatePlayerPanels(model.Players, view.PlayerPanels, model)
-	updateStatusPanel(view.StatusPanel, string(model.GameStatus), model)
-	updateMenuText(view.BottomMenu, model.GameStatus)
+	if player := view.turnCuePlayer(model, time.Now()); player >= 0 && player < len(view.PlayerPanels) {
+		ui.ShowTurnCue(view.PlayerPanels[player], model.Options.TurnCue, model.CurrentColorPalette)
+	}
+	view.OptionsScreen.SetTitle(ui.OptionsTitle(OptionsChanged(model), model.OptionsError))
+	updateRoundDisplay(view.RoundDisplay, model)
+	if text := nameText(model); view.NameDisplay.GetText(false) != text {
+		view.NameDisplay.SetText(text)
+	}
+	updateStatusPanel(view.StatusPanel, status, model)
+	view.updateTimersPanel(model)
+	view.updateScratchpad(model)
+	if model.CurrentScreen == "feed" {
+		text := ui.FeedText(model.Players, model.CurrentColorPalette, model.Options.LogTimestamps)
+		if view.FeedScreen.GetText(false) != text {
+			view.FeedScreen.SetText(text)
+		}
+	}
+	updateMenuText(view.BottomMenu, model.GameStatus, kioskLocked(*model))
+}
+
+// updateTimersPanel shows the auxiliary timers, hiding the panel while there are none
+func (view *View) updateTimersPanel(model *common.Model) {
+	height := 0
+	if len(model.Timers) > 0 {
+		height = 3
+	}
+	view.MainView.ResizeItem(view.TimersPanel, height, 0)
+	if text := ui.TimersText(model.Timers); view.TimersPanel.GetText(false) != text {
+		view.TimersPanel.SetText(text)
+	}
+}
+
+// updateScratchpad shows or hides the scratchpad calculator, moving the keyboard focus to its entry line while shown
+func (view *View) updateScratchpad(model *common.Model) {
+	if model.ScratchpadShown == view.scratchpadShown {
+		return
+	}
+	view.scratchpadShown = model.ScratchpadShown
+	if model.ScratchpadShown {
+		view.MainView.ResizeItem(view.Scratchpad, ui.ScratchpadHeight, 0)
+		view.App.SetFocus(view.Scratchpad)
+		return
+	}
+	view.MainView.ResizeItem(view.Scratchpad, 0, 0)
+	view.App.SetFocus(view.MainView)
+}
+
+// VimBindings reports whether the vim-style bindings were enabled at the last render, for the input capture
+func (view *View) VimBindings() bool {
+	return view.vimBindings
+}
+
+// RenderSession renders the game shown and the numbers of the session's games in the top bar.
+func (view *View) RenderSession(session *Session) {
+	// Another game has its own players and options, so its screens are rebuilt
+	if session.Active != view.shownGame {
+		view.shownGame = session.Active
+		view.turnPlayer = -1 // Showing another game is not a turn switch
+		view.reloadPlayerPanels(session.Current())
+		view.ReloadOptionsScreen(session.Current())
+	}
+	view.Render(session.Current())
+	if text := session.TabsText(); view.TabsDisplay.GetText(false) != text {
+		view.TabsDisplay.SetText(text)
+	}
+}
+
+// turnCuePlayer returns the index of the player whose panel shows the turn switch cue at now, or -1 if there is none.
+// The cue starts when the turn passes from one player to another and lasts turnCueDuration, or until the next
+// render after that.
+func (view *View) turnCuePlayer(model *common.Model, now time.Time) int {
+	player := -1
+	if model.GameStarted {
+		player = slices.IndexFunc(model.Players, func(p *common.Player) bool { return p.IsTurn })
+	}
+	if player != view.turnPlayer {
+		// Turn switches cue the new player, and so does the end of the countdown to a scheduled start
+		if player >= 0 && (view.turnPlayer >= 0 || view.countdown) && model.Options.TurnCue != "none" {
+			view.turnCueUntil = now.Add(turnCueDuration)
+		} else {
+			view.turnCueUntil = time.Time{}
+		}
+		view.turnPlayer = player
+	}
+	view.countdown = !model.StartsAt.IsZero()
+	if player < 0 || !now.Before(view.turnCueUntil) {
+		return -1
+	}
+	return player
+}
+
+// nameText returns the text of the top bar name display: the custom banner, if set, followed by the ruleset name.
+func nameText(model *common.Model) string {
+	text := "[white]" + model.Options.Rules[model.Options.Default].Name + "[-]"
+	if model.Options.Banner != "" {
+		text = "[yellow::b]" + tview.Escape(model.Options.Banner) + "[-::-] | " + text
+	}
+	if identity := options.Identity(model.Options); identity != "" {
+		text = "[black:yellow:b] " + tview.Escape(identity) + " [-:-:-] " + text
+	}
+	return text
+}
+
+// updateRoundDisplay shows the current battle round in the top bar for rulesets with alternating turns.
+func updateRoundDisplay(roundDisplay *tview.TextView, model *common.Model) {
+	text := ""
+	if round := BattleRound(model); round > 0 {
+		text = fmt.Sprintf("Battle Round: %d", round)
+	}
+	if roundDisplay.GetText(false) != text {
+		roundDisplay.SetText(text)
+	}
+}
+
+// BattleRound computes the shared battle round from the players' turn counts. A round is complete once
+// every player has had a turn in it. It returns 0 if no game is running or players don't alternate turns.
+func BattleRound(model *common.Model) int {
+	if !model.GameStarted || len(model.Players) == 0 || model.Options.Rules[model.Options.Default].OneTurnForAllPlayers {
+		return 0
+	}
+	round := model.Players[0].TurnCount
+	for _, player := range model.Players[1:] {
+		round = min(round, player.TurnCount)
+	}
+	return round + 1
+}
+
+// roundTimerText returns the round countdown and announcement pushed by the organizer, counted down to the last tick
+func roundTimerText(model *common.Model) string {
+	return ui.RoundTimerText(model.RoundEnds, model.Announcement, tickTime(model))
+}
+
+// tickTime returns the time of the last tick, which the countdowns are shown at, or the current time before the first
+func tickTime(model *common.Model) time.Time {
+	if model.LastTick.IsZero() {
+		return time.Now()
+	}
+	return model.LastTick
+}
+
+// currentPhaseTime returns the current phase of the first active player and the time spent in it,
+// e.g. "Shooting Phase — 04:12", or an empty string if no game is running. Phases over their limit are marked.
+func currentPhaseTime(model *common.Model) string {
+	if !model.GameStarted {
+		return ""
+	}
+	for _, player := range model.Players {
+		if player.IsTurn && player.CurrentPhase < len(model.Phases) {
+			phase := model.Phases[player.CurrentPhase]
+			limit := model.Options.Rules[model.Options.Default].PhaseLimits[phase]
+			return ui.PhaseTimeText(phase, player.PhaseElapsed) + ui.PhaseLimitText(limit, player.PhaseElapsed)
+		}
+	}
+	return ""
+}
+
+// ReloadOptionsScreen rebuilds the options screen from the model, e.g. after the options were reverted.
+func (view *View) ReloadOptionsScreen(model *common.Model) {
+	optionsScreen := ui.CreateOptionsScreen(model, view.MessageChan)
+	if view.CurrentScreen == "options" {
+		view.PlayerPanelsContainer.Clear()
+		view.PlayerPanelsContainer.AddItem(optionsScreen, 0, 1, false)
+	}
+	view.OptionsScreen = optionsScreen
 }
 
 // UpdateClock updates the clock display with the current time.
 // The time format is determined by the model's options.
 func (view *View) UpdateClock(model *common.Model) {
-	currentTime := time.Now().Format(ui.TimeFormat(model.Options.TimeFormat))
-	if view.ClockDisplay.GetText(false) != currentTime {
-		view.ClockDisplay.SetText(currentTime)
+	clockText := ui.ClockText(time.Now(), model.Options.TimeFormat, model.Options.ClockShowDate,
+		model.Options.ClockShowGameTime, model.TotalGameTime)
+	if view.ClockDisplay.GetText(false) != clockText {
+		view.ClockDisplay.SetText(clockText)
+		view.TopBar.ResizeItem(view.ClockDisplay, len(clockText)+1, 0)
 	}
 }
 
+// ScrollLog scrolls the action log of a player panel.
+func (view *View) ScrollLog(msg *common.ScrollLogMsg) {
+	if msg.PlayerIndex < 0 || msg.PlayerIndex >= len(view.PlayerPanels) {
+		return
+	}
+	ui.ScrollPlayerLog(view.PlayerPanels[msg.PlayerIndex], msg.Lines, msg.ToTop, msg.ToBottom)
+}
+
+// ShowCommandPalette displays the command palette over the main UI.
+func (view *View) ShowCommandPalette() {
+	commandPalette := ui.CreatePrompt("Command", ":", CommandNames(), func(command string) {
+		if command == "" {
+			view.RestoreMainView()
+			return
+		}
+		view.closeModal(&common.RunCommandMsg{Name: strings.ToLower(command)})
+	})
+	showCenteredModal(view, commandPalette, 40, 3)
+}
+
+// ShowTemplatePicker displays the list of game templates to set up a new game from.
+func (view *View) ShowTemplatePicker(model *common.Model) {
+	names := make([]string, len(model.Options.Templates))
+	for i, template := range model.Options.Templates {
+		names[i] = template.Name
+	}
+
+	picker := ui.CreateTemplatePicker(names,
+		func(index int) {
+			view.closeModal(&common.ApplyTemplateMsg{Index: index})
+		},
+		func() {
+			templatePrompt := ui.CreatePrompt("Save Template", "Name: ", nil, func(name string) {
+				if name == "" {
+					view.RestoreMainView()
+					return
+				}
+				view.closeModal(&common.SaveTemplateMsg{Name: name})
+			})
+			showCenteredModal(view, templatePrompt, 60, 3)
+		},
+		view.RestoreMainView,
+	)
+	showCenteredModal(view, picker, 60, len(names)+3)
+}
+
+// ShowArmyEditor displays the army list editor for a player.
+func (view *View) ShowArmyEditor(model *common.Model, playerIndex int) {
+	if playerIndex < 0 || playerIndex >= len(model.Players) {
+		return
+	}
+	player := model.Players[playerIndex]
+	editor := ui.CreateArmyEditor(player.Name, armyListText(player.ArmyList),
+		func(text string) {
+			view.closeModal(&common.SetArmyListMsg{PlayerIndex: playerIndex, Units: parseArmyList(text)})
+		},
+		view.RestoreMainView,
+	)
+	showCenteredModal(view, editor, 60, 20)
+}
+
+// ShowUnitStatus displays the list of a player's units to mark them destroyed, below half strength or shaken, to
+// track their wounds or to edit their notes. A unit marked destroyed asks for the opposing unit that destroyed it, if the opponents have army
+// lists. The list stays open until Escape is pressed, a note is edited or a destroyed unit is recorded.
+func (view *View) ShowUnitStatus(model *common.Model, playerIndex int) {
+	if playerIndex < 0 || playerIndex >= len(model.Players) {
+		return
+	}
+	player := model.Players[playerIndex]
+	opponents := opposingUnits(model, playerIndex)
+	list := ui.CreateUnitStatusList(player.Name, player.ArmyList,
+		func(unit int, status string) {
+			view.send(&common.SetUnitStatusMsg{PlayerIndex: playerIndex, Unit: unit, Status: status})
+			if status != "destroyed" || len(opponents) == 0 {
+				return
+			}
+			picker := ui.CreateKillPicker(player.ArmyList[unit].Name, opponents,
+				func(opponent string) {
+					view.closeModal(&common.SetUnitDestroyedByMsg{PlayerIndex: playerIndex, Unit: unit, DestroyedBy: opponent})
+				},
+				view.RestoreMainView,
+			)
+			showCenteredModal(view, picker, 60, min(len(opponents), 20)+3)
+		},
+		func(unit int, woundsLost int) {
+			view.send(&common.SetUnitWoundsMsg{PlayerIndex: playerIndex, Unit: unit, WoundsLost: woundsLost})
+		},
+		func(unit int) {
+			prompt := ui.CreateUnitNotePrompt(player.ArmyList[unit],
+				func(note string) {
+					view.closeModal(&common.SetUnitNoteMsg{PlayerIndex: playerIndex, Unit: unit, Note: note})
+				},
+				view.RestoreMainView,
+			)
+			showCenteredModal(view, prompt, 60, 3)
+		},
+		view.RestoreMainView,
+	)
+	showCenteredModal(view, list, 70, min(len(player.ArmyList), 20)+2)
+}
+
+// ShowOptionsDiff displays the options that differ from the defaults and from the options file.
+func (view *View) ShowOptionsDiff(model *common.Model) {
+	var text strings.Builder
+	text.WriteString("[::b]Changed from defaults:[::-]\n")
+	writeDiffLines(&text, options.Diff(options.DefaultOptions, model.Options))
+	text.WriteString("\n[::b]Unsaved changes to " + tview.Escape(model.OptionsFile) + ":[::-]\n")
+	writeDiffLines(&text, options.Diff(model.SavedOptions, model.Options))
+
+	showCenteredModal(view, ui.CreateOptionsDiff(text.String(), view.RestoreMainView), 80, 20)
+}
+
+// writeDiffLines writes the lines of an options diff, or a note if there are no differences.
+func writeDiffLines(text *strings.Builder, diff []string) {
+	if len(diff) == 0 {
+		text.WriteString("  (none)\n")
+	}
+	for _, line := range diff {
+		text.WriteString("  " + tview.Escape(line) + "\n")
+	}
+}
+
+// ShowNotePrompt displays a prompt for adding a note to the active player's action log.
+func (view *View) ShowNotePrompt() {
+	notePrompt := ui.CreatePrompt("Add Note", "Note: ", nil, func(text string) {
+		if text == "" {
+			view.RestoreMainView()
+			return
+		}
+		view.closeModal(&common.AddNoteMsg{Text: text})
+	})
+	showCenteredModal(view, notePrompt, 60, 3)
+}
+
+// ShowDiceRoller displays a prompt for the dice to roll, e.g. "2d6" or "d3+1", or the name of a random table of the
+// ruleset to roll on, which shows the entry rolled. The dice are rolled here, so the update only logs the results.
+func (view *View) ShowDiceRoller(model *common.Model) {
+	tables := model.Options.Rules[model.Options.Default].RandomTables
+	label, suggestions := "Dice: ", ui.DiceSuggestions
+	if len(tables) > 0 {
+		label, suggestions = "Dice or table: ", slices.Clone(ui.DiceSuggestions)
+		for _, table := range tables {
+			suggestions = append(suggestions, table.Name)
+		}
+	}
+	dicePrompt := ui.CreatePrompt("Roll Dice", label, suggestions, func(text string) {
+		if table, found := rules.FindRandomTable(tables, text); found {
+			view.showTableRoll(table)
+			return
+		}
+		expr, err := dice.Parse(text)
+		if err != nil {
+			view.RestoreMainView()
+			return
+		}
+		view.closeModal(&common.RollDiceMsg{Roll: expr.Roll(rand.IntN)})
+	})
+	showCenteredModal(view, dicePrompt, 60, 3)
+}
+
+// showTableRoll rolls on a random table and shows the entry rolled until the dialog is closed
+func (view *View) showTableRoll(table rules.RandomTable) {
+	expr, err := dice.Parse(table.DiceText())
+	if err != nil {
+		view.RestoreMainView()
+		return
+	}
+	roll := expr.Roll(rand.IntN)
+	entry, found := table.Entry(roll.Total())
+	if !found {
+		entry = "No entry for this result"
+	}
+	view.send(&common.RollTableMsg{Table: table.Name, Roll: roll})
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("%s: %s\n\n%s", expr, roll, entry)).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			view.closeModal()
+		})
+	modal.SetBorder(true)
+	modal.SetTitle(" " + table.Name + " ")
+	ShowConfirmationModal(view, modal)
+}
+
+// ShowTimerPrompt displays a prompt for starting an auxiliary timer, e.g. "Deployment 10" for ten minutes.
+// "start" followed by minutes or a time of day, e.g. "start 19:30", schedules the start of the game instead.
+// Entering "clear" removes all timers and the scheduled start.
+func (view *View) ShowTimerPrompt() {
+	timerPrompt := ui.CreatePrompt("Add Timer", "Label and minutes: ", ui.TimerSuggestions, func(text string) {
+		if strings.EqualFold(text, "clear") {
+			view.closeModal(&common.ClearTimersMsg{})
+			return
+		}
+		if fields := strings.Fields(text); len(fields) == 2 && strings.EqualFold(fields[0], "start") {
+			if at, ok := ParseStartTime(fields[1], time.Now()); ok {
+				view.closeModal(&common.ScheduleStartMsg{At: at})
+				return
+			}
+		}
+		label, duration, ok := parseTimer(text)
+		if !ok {
+			view.RestoreMainView()
+			return
+		}
+		view.closeModal(&common.AddTimerMsg{Label: label, Duration: duration})
+	})
+	showCenteredModal(view, timerPrompt, 60, 3)
+}
+
+// parseTimer parses the label and duration of an auxiliary timer. The duration is the last word, in minutes
+// or as a duration like "90s" or "1m30s". Timers without a label are called "Timer".
+func parseTimer(text string) (string, time.Duration, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", 0, false
+	}
+
+	last := fields[len(fields)-1]
+	duration, err := time.ParseDuration(last)
+	if minutes, convErr := strconv.ParseFloat(last, 64); convErr == nil {
+		duration, err = time.Duration(minutes*float64(time.Minute)), nil
+	}
+	if err != nil || duration <= 0 {
+		return "", 0, false
+	}
+
+	label := strings.Join(fields[:len(fields)-1], " ")
+	if label == "" {
+		label = "Timer"
+	}
+	return label, duration, true
+}
+
+// ParseStartTime parses the scheduled start of a game: a time of day like "19:30", the next time the clock shows it,
+// or a countdown in minutes or as a duration like "90s".
+func ParseStartTime(text string, now time.Time) (time.Time, bool) {
+	if clock, err := time.ParseInLocation("15:04", text, now.Location()); err == nil {
+		at := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, true
+	}
+	if _, countdown, ok := parseTimer(text); ok {
+		return now.Add(countdown), true
+	}
+	return time.Time{}, false
+}
+
+// ShowJudgeLogin displays a prompt for the passphrase that unlocks judge mode.
+func (view *View) ShowJudgeLogin() {
+	loginPrompt := ui.CreatePrompt("Judge Mode", "Passphrase: ", nil, func(passphrase string) {
+		if passphrase == "" {
+			view.RestoreMainView()
+			return
+		}
+		view.closeModal(&common.JudgeLoginMsg{Passphrase: passphrase})
+	})
+	loginPrompt.SetMaskCharacter('*')
+	showCenteredModal(view, loginPrompt, 60, 3)
+}
+
+// showPINPrompt displays a prompt for the action PIN of a protected action. An empty PIN cancels the action.
+func (view *View) showPINPrompt(title string, done func(pin string)) {
+	pinPrompt := ui.CreatePrompt(title, "PIN: ", nil, done)
+	pinPrompt.SetMaskCharacter('*')
+	showCenteredModal(view, pinPrompt, 60, 3)
+}
+
+// ShowOptionsPIN displays a prompt for the action PIN that opens the options screen
+func (view *View) ShowOptionsPIN() {
+	view.showPINPrompt("Options", func(pin string) {
+		if pin == "" {
+			view.RestoreMainView()
+			return
+		}
+		view.closeModal(&common.UnlockOptionsMsg{PIN: pin})
+	})
+}
+
+// ShowJudgePrompt displays a prompt for an intervention of the judge, e.g. "penalty 2 3 slow play" to add three
+// minutes to the second player's clock.
+func (view *View) ShowJudgePrompt() {
+	judgePrompt := ui.CreatePrompt("Judge", "Action: ", ui.JudgeSuggestions, func(text string) {
+		action, ok := parseJudgeAction(text)
+		if !ok {
+			view.RestoreMainView()
+			return
+		}
+		view.closeModal(action)
+	})
+	showCenteredModal(view, judgePrompt, 70, 3)
+}
+
+// parseJudgeAction parses an intervention of the judge: "penalty <player> <minutes> [reason]",
+// "score <player> <points> [reason]", "pause [reason]" or "exit". Players are numbered from 1 and penalties are in
+// minutes or a duration like "90s".
+func parseJudgeAction(text string) (*common.JudgeActionMsg, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil, false
+	}
+	action := &common.JudgeActionMsg{Action: strings.ToLower(fields[0])}
+
+	switch action.Action {
+	case "pause":
+		action.Reason = strings.Join(fields[1:], " ")
+	case "exit":
+	case "penalty", "score":
+		if len(fields) < 3 {
+			return nil, false
+		}
+		player, err := strconv.Atoi(fields[1])
+		if err != nil || player < 1 {
+			return nil, false
+		}
+		action.Player = player - 1
+		action.Reason = strings.Join(fields[3:], " ")
+
+		if action.Action == "score" {
+			points, err := strconv.Atoi(fields[2])
+			if err != nil || points == 0 {
+				return nil, false
+			}
+			action.Points = points
+			break
+		}
+		_, penalty, ok := parseTimer(fields[2])
+		if !ok {
+			return nil, false
+		}
+		action.Penalty = penalty
+	default:
+		return nil, false
+	}
+	return action, true
+}
+
 // RestoreMainView sets the main view to the main view layout.
 func (view *View) RestoreMainView() {
+	view.closeModal()
+}
+
+// closeModal sets the main view to the main view layout. If a modal dialog was open, the dialog being closed is
+// reported before the given messages, so the game is resumed before the dialog's action is applied.
+func (view *View) closeModal(msgs ...common.Message) {
 	view.App.SetRoot(view.MainView, true)
+	if view.modalOpen {
+		view.modalOpen = false
+		msgs = append([]common.Message{&common.ModalClosedMsg{}}, msgs...)
+	}
+	view.send(msgs...)
+}
+
+// Beep rings the terminal bell through the screen, so it does not interfere with the drawing. Call it on the UI
+// goroutine.
+func (view *View) Beep() {
+	if view.Screen != nil {
+		_ = view.Screen.Beep()
+	}
+}
+
+// send sends messages to the application in order. Dialog callbacks run on the UI goroutine, which the update
+// loop waits for while rendering, so the messages are queued in the outbox and sent by its goroutine.
+func (view *View) send(msgs ...common.Message) {
+	for _, msg := range msgs {
+		view.outbox <- msg
+	}
 }
 
 // updateStatusPanel updates the status panel with the current game status.
 // It also changes the border color based on the game status.
 func updateStatusPanel(panel *tview.Flex, status string, model *common.Model) {
-	ui.UpdateWithGameTime(panel, status, model.TotalGameTime)
+	ui.UpdateWithGameTime(panel, status, model.TotalGameTime, model.PausedTime, ui.VictoryPointsText(model))
 
 	switch model.GameStatus {
 	case gameNotStarted:
@@ -118,8 +749,8 @@ func updateStatusPanel(panel *tview.Flex, status string, model *common.Model) {
 }
 
 // updateMenuText updates the bottom menu text based on the current game status.
-// It modifies the description of menu options dynamically.
-func updateMenuText(menu *tview.TextView, status common.GameStatus) {
+// It modifies the description of menu options dynamically and leaves out the ones disabled in kiosk mode.
+func updateMenuText(menu *tview.TextView, status common.GameStatus, kiosk bool) {
 	instructions := []ui.MenuOption{
 		{Key: "S", Description: "Start Game"},
 		{Key: "E", Description: "End Game"},
@@ -146,6 +777,9 @@ func updateMenuText(menu *tview.TextView, status common.GameStatus) {
 			menuString.WriteString("   ")
 		}
 
+		if kiosk && (option.Key == "E" || option.Key == "Q") {
+			continue
+		}
 		// Special case for End Game option - dimmed and only visible when game started
 		if option.Key == "E" {
 			if status == gameNotStarted {
@@ -153,33 +787,52 @@ func updateMenuText(menu *tview.TextView, status common.GameStatus) {
 				continue
 			}
 			// Show dimmed when game is started
-			menuString.WriteString("[#888888]" + option.Key + "[d:] " + option.Description)
+			menuString.WriteString(ui.FormatMenuOption(option, "#888888"))
 		} else {
-			menuString.WriteString("[white]" + option.Key + "[d:] " + option.Description)
+			menuString.WriteString(ui.FormatMenuOption(option, "white"))
 		}
 	}
 	menu.SetText(menuString.String())
 }
 
+// menuKeyMsg converts the key of a clicked menu option into the equivalent key press message.
+func menuKeyMsg(key string) *common.KeyPressMsg {
+	if key == "SPACE" {
+		return &common.KeyPressMsg{Key: tcell.KeyRune, Rune: ' '}
+	}
+	return &common.KeyPressMsg{Key: tcell.KeyRune, Rune: []rune(strings.ToLower(key))[0]}
+}
+
 // createTopFlex creates the top flex layout containing the menu, name display, and clock.
 func createTopFlex(model *common.Model) *tview.Flex {
 	topFlex := tview.NewFlex().SetDirection(tview.FlexColumn)
 
-	topMenu := ui.CreateMenuBar([]ui.MenuOption{
-		{Key: "O", Description: "Options"},
-		{Key: "A", Description: "About"},
-	}).SetDynamicColors(true)
+	var screens []ui.MenuOption
+	if !model.Kiosk {
+		screens = []ui.MenuOption{
+			{Key: "O", Description: "Options"},
+			{Key: "A", Description: "About"},
+		}
+	}
+	topMenu := ui.CreateMenuBar(screens).SetDynamicColors(true)
 	topFlex.AddItem(topMenu, 0, 1, false)
 
-	topFlex.AddItem(tview.NewBox(), 0, 1, false)
+	tabsDisplay := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true)
+	topFlex.AddItem(tabsDisplay, 0, 1, false)
 
 	nameDisplay := tview.NewTextView().
 		SetTextAlign(tview.AlignCenter).
 		SetDynamicColors(true).
-		SetText("[white]" + model.Options.Rules[model.Options.Default].Name + "[-]")
+		SetText(nameText(model))
 	topFlex.AddItem(nameDisplay, 0, 1, false)
 
-	topFlex.AddItem(tview.NewBox(), 0, 1, false)
+	roundDisplay := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetTextColor(model.CurrentColorPalette.White)
+	topFlex.AddItem(roundDisplay, 0, 1, false)
 
 	hClock := ui.Display(model.Options.TimeFormat, model.CurrentColorPalette.White)
 	topFlex.AddItem(hClock, 10, 0, false)
@@ -187,35 +840,89 @@ func createTopFlex(model *common.Model) *tview.Flex {
 	return topFlex
 }
 
+// reloadPlayerPanels recreates the player panels for the current players.
+func (view *View) reloadPlayerPanels(model *common.Model) {
+	_, view.PlayerPanels = createPlayerPanels(model)
+	view.PlayerNames = playerNames(model.Players)
+	view.panelWidgets = ui.ShownPanelWidgets(model.Options.PanelWidgets)
+	if view.CurrentScreen == "main" || view.CurrentScreen == "" {
+		view.PlayerPanelsContainer.Clear()
+		for _, panel := range view.PlayerPanels {
+			view.PlayerPanelsContainer.AddItem(panel, 0, 1, false)
+		}
+	}
+}
+
+// playersChanged reports whether the players differ from the ones with the given names.
+func playersChanged(names []string, players []*common.Player) bool {
+	if len(names) != len(players) {
+		return true
+	}
+	for i, player := range players {
+		if player.Name != names[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// playerNames returns the names of the players.
+func playerNames(players []*common.Player) []string {
+	names := make([]string, len(players))
+	for i, player := range players {
+		names[i] = player.Name
+	}
+	return names
+}
+
 // createPlayerPanels creates the player panels and their container.
 // Each panel is assigned a color from a predefined list.
 func createPlayerPanels(model *common.Model) (*tview.Flex, []*tview.Flex) {
-	container := tview.NewFlex().SetDirection(tview.FlexColumn)
+	container := tview.NewFlex().SetDirection(playerPanelsDirection(model.Options.Layout, 0, 0))
 	playerPanels := make([]*tview.Flex, len(model.Players))
-	colors := []string{"blue", "yellow", "green", "red"}
-
 	for i, player := range model.Players {
-		panel := ui.CreatePlayerPanel(player, colors[i%len(colors)], model)
+		panel := ui.CreatePlayerPanel(player, ui.PanelColors[i%len(ui.PanelColors)], model)
 		playerPanels[i] = panel
 		container.AddItem(panel, 0, 1, false)
 	}
 	return container, playerPanels
 }
 
+// playerPanelsDirection returns the direction the player panels are arranged in for a layout and the size of the
+// area they fill. The auto layout stacks them in portrait areas; terminal cells are about twice as tall as wide.
+func playerPanelsDirection(layout string, width, height int) int {
+	switch {
+	case layout == "vertical", layout == "auto" && width < 2*height:
+		return tview.FlexRow
+	default:
+		return tview.FlexColumn
+	}
+}
+
 // createBottomMenu creates the bottom menu bar and initializes its text.
-func createBottomMenu(status common.GameStatus) *tview.TextView {
+func createBottomMenu(status common.GameStatus, kiosk bool) *tview.TextView {
 	menu := ui.CreateMenuBar(nil).SetDynamicColors(true)
-	updateMenuText(menu, status)
+	updateMenuText(menu, status, kiosk)
 	return menu
 }
 
-// CreateEndGameConfirmationModal creates a modal dialog asking for confirmation to end the game
-func CreateEndGameConfirmationModal(view *View) *tview.Modal {
+// CreateEndGameConfirmationModal creates a modal dialog asking for confirmation to end the game, and for the action
+// PIN if the options protect ending the game
+func CreateEndGameConfirmationModal(view *View, model *common.Model) *tview.Modal {
+	protected := pinRequired(*model)
 	modal := tview.NewModal().
 		SetText("Would you like to end the current game?").
 		AddButtons([]string{"Yes", "No"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonIndex == 0 { // "Yes" is the first button (index 0)
+			if buttonIndex == 0 && protected {
+				view.showPINPrompt("End Game", func(pin string) {
+					if pin == "" {
+						view.RestoreMainView()
+						return
+					}
+					view.closeModal(&common.EndGameConfirmMsg{Confirmed: true, PIN: pin})
+				})
+			} else if buttonIndex == 0 { // "Yes" is the first button (index 0)
 				view.MessageChan <- &common.EndGameConfirmMsg{Confirmed: true}
 			} else {
 				view.MessageChan <- &common.EndGameConfirmMsg{Confirmed: false}
@@ -229,6 +936,26 @@ func CreateEndGameConfirmationModal(view *View) *tview.Modal {
 	return modal
 }
 
+// CreateGameOverModal creates a modal dialog showing the result of a game a player lost on time
+func CreateGameOverModal(view *View, model *common.Model, loser int) *tview.Modal {
+	text := "Game over"
+	if loser >= 0 && loser < len(model.Players) {
+		text = timeoutResult(*model, loser)
+	}
+	modal := tview.NewModal().
+		SetText(text + ".\nThe clocks are stopped, press E to end the game.").
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			view.closeModal()
+		})
+
+	// Style the modal
+	modal.SetBorder(true)
+	modal.SetTitle(" Game Over ")
+
+	return modal
+}
+
 // CreateExitConfirmationModal creates a modal dialog asking for confirmation to exit the application
 func CreateExitConfirmationModal(view *View) *tview.Modal {
 	modal := tview.NewModal().
@@ -249,8 +976,175 @@ func CreateExitConfirmationModal(view *View) *tview.Modal {
 	return modal
 }
 
+// CreateResumeConfirmationModal creates a modal dialog asking whether to resume a game that was paused because
+// the terminal lost the focus
+func CreateResumeConfirmationModal(view *View) *tview.Modal {
+	modal := tview.NewModal().
+		SetText("The game was paused while the terminal was in the background. Resume the game?").
+		AddButtons([]string{"Resume", "Stay Paused"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonIndex == 0 { // "Resume" is the first button (index 0)
+				view.closeModal(&common.RunCommandMsg{Name: "resume"})
+			} else {
+				view.RestoreMainView()
+			}
+		})
+
+	// Style the modal
+	modal.SetBorder(true)
+	modal.SetTitle(" Resume Game ")
+
+	return modal
+}
+
+// CreateSavedGameModal creates a modal dialog asking whether to continue the unfinished game of the autosave or to
+// start fresh
+func CreateSavedGameModal(view *View, model *common.Model) *tview.Modal {
+	text := "An unfinished game was saved."
+	if model.SavedGame != nil {
+		text = "An unfinished game was saved: " + SavedGameText(model.SavedGame) + "."
+		if model.SavedGame.Interrupted {
+			text = "Hammerclock did not shut down cleanly. " + text
+		}
+	}
+	modal := tview.NewModal().
+		SetText(text + " Continue it, paused, or start fresh?").
+		AddButtons([]string{"Continue", "Start Fresh"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			// Escape keeps the autosave, so the game is offered again at the next start
+			if buttonIndex < 0 {
+				view.closeModal()
+				return
+			}
+			view.closeModal(&common.ResumeSavedGameMsg{Continue: buttonIndex == 0})
+		})
+
+	// Style the modal
+	modal.SetBorder(true)
+	modal.SetTitle(" Resume Game ")
+
+	return modal
+}
+
+// CreateSuspendModal creates a modal dialog asking what to do with the time the system was suspended. Adding the time
+// asks for the action PIN if the options protect it.
+func CreateSuspendModal(view *View, model *common.Model) *tview.Modal {
+	actions := []string{"add", "discard", "pause"}
+	protected := pinRequired(*model)
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("System was suspended for %s — add this time to the active player, discard it, or pause?",
+			formatSuspended(model.SuspendedFor))).
+		AddButtons([]string{"Add", "Discard", "Pause"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			// Escape keeps the game paused
+			action := "pause"
+			if buttonIndex >= 0 && buttonIndex < len(actions) {
+				action = actions[buttonIndex]
+			}
+			if action == "add" && protected {
+				view.showPINPrompt("Add Suspended Time", func(pin string) {
+					if pin == "" {
+						action = "pause"
+					}
+					view.closeModal(&common.ResolveSuspendMsg{Action: action, PIN: pin})
+				})
+				return
+			}
+			view.closeModal(&common.ResolveSuspendMsg{Action: action})
+		})
+
+	// Style the modal
+	modal.SetBorder(true)
+	modal.SetTitle(" System Suspended ")
+
+	return modal
+}
+
+// CreateSecondaryObjectivesModal creates a form asking for the secondary objective scores of a player, and for the
+// action PIN if the options protect scoring
+func CreateSecondaryObjectivesModal(view *View, model *common.Model, playerIndex int) *tview.Form {
+	objectives := model.Options.Rules[model.Options.Default].SecondaryObjectives
+	form := tview.NewForm()
+
+	for _, objective := range objectives {
+		form.AddInputField(objective+": ", "0", 5, tview.InputFieldInteger, nil)
+	}
+	protected := pinRequired(*model)
+	if protected {
+		form.AddPasswordField("PIN: ", "", 10, '*', nil)
+	}
+
+	form.AddButton("Score", func() {
+		scores := make([]int, len(objectives))
+		for i := range objectives {
+			inputField := form.GetFormItem(i).(*tview.InputField)
+			scores[i], _ = strconv.Atoi(inputField.GetText())
+		}
+		pin := ""
+		if protected {
+			pin = form.GetFormItem(len(objectives)).(*tview.InputField).GetText()
+		}
+		view.MessageChan <- &common.ScoreSecondaryObjectivesMsg{PlayerIndex: playerIndex, Scores: scores, PIN: pin}
+	})
+	form.AddButton("Skip", func() {
+		view.MessageChan <- &common.ScoreSecondaryObjectivesMsg{PlayerIndex: playerIndex}
+	})
+
+	// Style the form
+	playerName := ""
+	if playerIndex >= 0 && playerIndex < len(model.Players) {
+		playerName = model.Players[playerIndex].Name
+	}
+	form.SetBorder(true)
+	form.SetTitle(fmt.Sprintf(" Secondary Objectives - %s ", playerName))
+
+	return form
+}
+
+// CreateSequenceStepModal creates a form for a step of the ruleset's pre- or post-game sequence, with a field for
+// the step's result, e.g. the weather rolled. ESC closes it without starting or ending the game.
+func CreateSequenceStepModal(view *View, model *common.Model, sequence string, step int) *tview.Form {
+	steps := SequenceSteps(*model, sequence)
+	form := tview.NewForm()
+	if step < 0 || step >= len(steps) {
+		return form
+	}
+
+	form.AddTextView("Step: ", steps[step], 40, 2, true, false)
+	form.AddInputField("Result: ", "", 30, nil, nil)
+	next := "Next"
+	if step == len(steps)-1 {
+		next = "Done"
+	}
+	complete := func(skip bool) {
+		result := form.GetFormItem(1).(*tview.InputField).GetText()
+		view.closeModal(&common.CompleteStepMsg{Sequence: sequence, Step: step, Result: result, Skip: skip})
+	}
+	form.AddButton(next, func() { complete(false) })
+	form.AddButton("Skip the rest", func() { complete(true) })
+	form.SetCancelFunc(func() { view.closeModal() })
+
+	title := "Pre-game"
+	if sequence == PostGame {
+		title = "Post-game"
+	}
+	form.SetBorder(true)
+	form.SetTitle(fmt.Sprintf(" %s - Step %d of %d ", title, step+1, len(steps)))
+	return form
+}
+
 // ShowConfirmationModal displays a confirmation modal in the application
 func ShowConfirmationModal(view *View, modal *tview.Modal) {
+	showCenteredModal(view, modal, 60, 10)
+}
+
+// ShowFormModal displays a form as a modal dialog in the application
+func ShowFormModal(view *View, form *tview.Form) {
+	showCenteredModal(view, form, 60, form.GetFormItemCount()*2+5)
+}
+
+// showCenteredModal layers the given primitive over the main UI, centered with the given size
+func showCenteredModal(view *View, modal tview.Primitive, width, height int) {
 	// Center the modal in a flex container
 	flex := tview.NewFlex().
 		AddItem(nil, 0, 1, false).
@@ -258,9 +1152,9 @@ func ShowConfirmationModal(view *View, modal *tview.Modal) {
 			tview.NewFlex().
 				SetDirection(tview.FlexRow).
 				AddItem(nil, 0, 1, false).
-				AddItem(modal, 10, 1, true).
+				AddItem(modal, height, 1, true).
 				AddItem(nil, 0, 1, false),
-			60, 1, true,
+			width, 1, true,
 		).
 		AddItem(nil, 0, 1, false)
 
@@ -271,4 +1165,46 @@ func ShowConfirmationModal(view *View, modal *tview.Modal) {
 
 	// Set the pages as the application's root
 	view.App.SetRoot(pages, true)
+
+	if !view.modalOpen {
+		view.modalOpen = true
+		view.send(&common.ModalOpenedMsg{})
+	}
+}
+
+// focusScreen wraps the terminal screen to pass on focus changes, which tview does not forward to the application
+type focusScreen struct {
+	tcell.Screen
+	msgChan chan<- common.Message
+}
+
+// NewFocusScreen creates and initializes the terminal screen. Terminals that report focus changes send a
+// FocusChangedMsg whenever their window gains or loses the focus; other terminals work as before.
+func NewFocusScreen(msgChan chan<- common.Message) (tcell.Screen, error) {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return nil, err
+	}
+	if err := screen.Init(); err != nil {
+		return nil, err
+	}
+	screen.EnableFocus()
+	return &focusScreen{Screen: screen, msgChan: msgChan}, nil
+}
+
+// Init does nothing, the screen is initialized by NewFocusScreen so that errors can be reported
+func (s *focusScreen) Init() error {
+	return nil
+}
+
+// PollEvent returns the next event, sending focus changes to the application instead of returning them
+func (s *focusScreen) PollEvent() tcell.Event {
+	for {
+		event := s.Screen.PollEvent()
+		focus, ok := event.(*tcell.EventFocus)
+		if !ok {
+			return event
+		}
+		s.msgChan <- &common.FocusChangedMsg{Focused: focus.Focused}
+	}
 }
@@ -1,12 +1,20 @@
 package hammerclock
 
 import (
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+	"unicode"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"hammerclock/internal/hammerclock/common"
 	"hammerclock/internal/hammerclock/options"
+	"hammerclock/internal/hammerclock/palette"
 	"hammerclock/internal/hammerclock/rules"
+	"hammerclock/internal/hammerclock/ui"
 )
 
 var testModel = &common.Model{
@@ -54,6 +62,23 @@ func TestUpdateClock(t *testing.T) {
 	}
 }
 
+func TestUpdateClockWithDateAndGameTime(t *testing.T) {
+	model := *testModel
+	model.Options.ClockShowDate = true
+	model.Options.ClockShowGameTime = true
+	model.TotalGameTime = 3723 * time.Second
+	view := NewView(&model, make(chan common.Message, 10))
+
+	view.UpdateClock(&model)
+	text := view.ClockDisplay.GetText(false)
+	if !strings.Contains(text, time.Now().Format("2006-01-02")) {
+		t.Errorf("Expected the date in the clock display, got %q", text)
+	}
+	if !strings.HasSuffix(text, "| 01:02:03") {
+		t.Errorf("Expected the game time in the clock display, got %q", text)
+	}
+}
+
 func TestRender(t *testing.T) {
 	model := testModel
 	view := NewView(model, make(chan common.Message, 10))
@@ -67,7 +92,7 @@ func TestRender(t *testing.T) {
 func TestCreateEndGameConfirmationModal(t *testing.T) {
 
 	view := NewView(testModel, make(chan common.Message, 10))
-	modal := CreateEndGameConfirmationModal(view)
+	modal := CreateEndGameConfirmationModal(view, testModel)
 
 	if modal == nil {
 		t.Error("Modal creation failed")
@@ -84,3 +109,608 @@ func TestRestoreMainView(t *testing.T) {
 	view := NewView(testModel, make(chan common.Message, 10))
 	view.RestoreMainView()
 }
+
+func TestModalOpenedAndClosedMessages(t *testing.T) {
+	msgChan := make(chan common.Message, 10)
+	view := NewView(testModel, msgChan)
+
+	ShowConfirmationModal(view, tview.NewModal())
+	if msg := receiveMessage(t, msgChan); msg == nil {
+		return
+	} else if _, ok := msg.(*common.ModalOpenedMsg); !ok {
+		t.Errorf("Expected ModalOpenedMsg, got %T", msg)
+	}
+
+	view.closeModal(&common.AddNoteMsg{Text: "note"})
+	if msg := receiveMessage(t, msgChan); msg == nil {
+		return
+	} else if _, ok := msg.(*common.ModalClosedMsg); !ok {
+		t.Errorf("Expected ModalClosedMsg first, got %T", msg)
+	}
+	if msg := receiveMessage(t, msgChan); msg == nil {
+		return
+	} else if _, ok := msg.(*common.AddNoteMsg); !ok {
+		t.Errorf("Expected the dialog's AddNoteMsg after closing it, got %T", msg)
+	}
+
+	// Dialogs opened and closed in quick succession report it in order
+	for range 5 {
+		ShowConfirmationModal(view, tview.NewModal())
+		view.closeModal()
+	}
+	for i := range 10 {
+		msg := receiveMessage(t, msgChan)
+		if _, opened := msg.(*common.ModalOpenedMsg); opened != (i%2 == 0) {
+			t.Fatalf("Expected the dialogs to be opened and closed in turn, got %T as message %d", msg, i+1)
+		}
+	}
+}
+
+// receiveMessage waits for the next message sent by the view, failing the test if none arrives
+func receiveMessage(t *testing.T, msgChan chan common.Message) common.Message {
+	t.Helper()
+	select {
+	case msg := <-msgChan:
+		return msg
+	case <-time.After(time.Second):
+		t.Error("Expected a message from the view, got none")
+		return nil
+	}
+}
+
+func TestMenuKeyMsg(t *testing.T) {
+	if msg := menuKeyMsg("SPACE"); msg.Rune != ' ' {
+		t.Errorf("Expected space rune for SPACE, got %q", msg.Rune)
+	}
+	if msg := menuKeyMsg("O"); msg.Rune != 'o' {
+		t.Errorf("Expected 'o' rune for O, got %q", msg.Rune)
+	}
+}
+
+func TestBattleRound(t *testing.T) {
+	model := *testModel
+	model.GameStarted = true
+	model.Options.Rules = []rules.Rules{{Name: "Alternating", Phases: model.Phases}}
+	model.Players = []*common.Player{
+		{Name: "Player 1", TurnCount: 1},
+		{Name: "Player 2", TurnCount: 2, IsTurn: true},
+	}
+
+	if round := BattleRound(&model); round != 2 {
+		t.Errorf("Expected battle round 2, got %d", round)
+	}
+
+	model.Options.Rules[0].OneTurnForAllPlayers = true
+	if round := BattleRound(&model); round != 0 {
+		t.Errorf("Expected no battle round when all players take one turn together, got %d", round)
+	}
+}
+
+func TestFocusScreenSendsFocusChanges(t *testing.T) {
+	simulation := tcell.NewSimulationScreen("UTF-8")
+	if err := simulation.Init(); err != nil {
+		t.Fatalf("Failed to initialize the simulation screen: %v", err)
+	}
+	defer simulation.Fini()
+
+	msgChan := make(chan common.Message, 10)
+	screen := &focusScreen{Screen: simulation, msgChan: msgChan}
+	_ = simulation.PostEvent(tcell.NewEventFocus(false))
+	_ = simulation.PostEvent(tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone))
+
+	if _, ok := screen.PollEvent().(*tcell.EventKey); !ok {
+		t.Fatal("Expected the key event to be passed on")
+	}
+	if msg, ok := (<-msgChan).(*common.FocusChangedMsg); !ok || msg.Focused {
+		t.Errorf("Expected a FocusChangedMsg for the lost focus, got %v", msg)
+	}
+}
+
+func TestScreenANSI(t *testing.T) {
+	simulation := tcell.NewSimulationScreen("UTF-8")
+	if err := simulation.Init(); err != nil {
+		t.Fatalf("Failed to initialize the simulation screen: %v", err)
+	}
+	defer simulation.Fini()
+	simulation.SetSize(4, 2)
+
+	red := tcell.StyleDefault.Foreground(tcell.NewRGBColor(255, 0, 0)).Bold(true)
+	simulation.SetContent(0, 0, 'H', nil, red)
+	simulation.SetContent(1, 0, 'i', nil, red)
+	simulation.SetContent(2, 0, '界', nil, tcell.StyleDefault)
+	simulation.Show()
+
+	expected := "\x1b[0;1;38;2;255;0;0mHi\x1b[0m界\x1b[0m\n" +
+		"\x1b[0m    \x1b[0m\n"
+	if text := ScreenANSI(simulation); text != expected {
+		t.Errorf("Expected %q, got %q", expected, text)
+	}
+}
+
+func TestLinkedTerminalForwardsSharedGameKeys(t *testing.T) {
+	model := *testModel
+	model.Linked = true
+	space := &common.KeyPressMsg{Key: tcell.KeyRune, Rune: ' '}
+
+	if _, cmd := Update(space, model); cmd != nil && cmd() != nil {
+		t.Errorf("Expected a mirroring terminal to ignore the key, got %T", cmd())
+	}
+
+	model.LinkShared = true
+	newModel, cmd := Update(space, model)
+	if cmd == nil {
+		t.Fatal("Expected the key to be sent to the host")
+	}
+	if msg, ok := cmd().(*common.ForwardKeyMsg); !ok || msg.Rune != ' ' {
+		t.Errorf("Expected a ForwardKeyMsg for the space key, got %+v", cmd())
+	}
+	if newModel.Players[0].IsTurn != model.Players[0].IsTurn {
+		t.Error("Expected the turn to be switched by the host, not locally")
+	}
+
+	if _, cmd := Update(&common.KeyPressMsg{Key: tcell.KeyRune, Rune: 'n'}, model); cmd != nil && cmd() != nil {
+		t.Errorf("Expected keys opening a dialog to stay on the host, got %T", cmd())
+	}
+}
+
+func TestDrawANSIDrawsMainView(t *testing.T) {
+	model := testModel
+	view := NewView(model, make(chan common.Message, 10))
+	view.Render(model)
+
+	text := DrawANSI(view.MainView, 80, 24)
+	if lines := strings.Count(text, "\n"); lines != 24 {
+		t.Errorf("Expected 24 lines, got %d", lines)
+	}
+	if !strings.Contains(text, model.Players[0].Name) {
+		t.Errorf("Expected the player's panel with %q, got %q", model.Players[0].Name, text)
+	}
+}
+
+func TestParseTimer(t *testing.T) {
+	tests := []struct {
+		text     string
+		label    string
+		duration time.Duration
+		ok       bool
+	}{
+		{"Deployment 10", "Deployment", 10 * time.Minute, true},
+		{"Rules lookup 1.5", "Rules lookup", 90 * time.Second, true},
+		{"Pizza 45s", "Pizza", 45 * time.Second, true},
+		{"5", "Timer", 5 * time.Minute, true},
+		{"Deployment", "", 0, false},
+		{"Deployment 0", "", 0, false},
+		{"", "", 0, false},
+	}
+	for _, test := range tests {
+		label, duration, ok := parseTimer(test.text)
+		if label != test.label || duration != test.duration || ok != test.ok {
+			t.Errorf("parseTimer(%q) = %q, %v, %v, expected %q, %v, %v",
+				test.text, label, duration, ok, test.label, test.duration, test.ok)
+		}
+	}
+}
+
+func TestParseJudgeAction(t *testing.T) {
+	tests := []struct {
+		text   string
+		action *common.JudgeActionMsg
+	}{
+		{"penalty 2 3 slow play", &common.JudgeActionMsg{Action: "penalty", Player: 1, Penalty: 3 * time.Minute, Reason: "slow play"}},
+		{"penalty 1 90s", &common.JudgeActionMsg{Action: "penalty", Penalty: 90 * time.Second}},
+		{"score 1 -3 wrong secondary", &common.JudgeActionMsg{Action: "score", Points: -3, Reason: "wrong secondary"}},
+		{"Pause rules dispute", &common.JudgeActionMsg{Action: "pause", Reason: "rules dispute"}},
+		{"exit", &common.JudgeActionMsg{Action: "exit"}},
+		{"penalty 0 3", nil},
+		{"score 1 0", nil},
+		{"penalty 1", nil},
+		{"resign", nil},
+		{"", nil},
+	}
+	for _, test := range tests {
+		action, ok := parseJudgeAction(test.text)
+		if ok != (test.action != nil) || (ok && *action != *test.action) {
+			t.Errorf("parseJudgeAction(%q) = %+v, %v, expected %+v", test.text, action, ok, test.action)
+		}
+	}
+}
+
+func TestPlayerPanelsDirection(t *testing.T) {
+	tests := []struct {
+		layout        string
+		width, height int
+		direction     int
+	}{
+		{"horizontal", 60, 80, tview.FlexColumn},
+		{"vertical", 200, 50, tview.FlexRow},
+		{"auto", 200, 50, tview.FlexColumn},
+		{"auto", 80, 60, tview.FlexRow},
+		{"", 80, 60, tview.FlexColumn},
+	}
+	for _, test := range tests {
+		if got := playerPanelsDirection(test.layout, test.width, test.height); got != test.direction {
+			t.Errorf("Expected direction %d for the %q layout in %dx%d, got %d",
+				test.direction, test.layout, test.width, test.height, got)
+		}
+	}
+}
+
+func TestTurnCueFollowsTurnSwitch(t *testing.T) {
+	at := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	model := &common.Model{
+		GameStarted: true,
+		Options:     options.Options{TurnCue: "flash"},
+		Players:     []*common.Player{{Name: "Alice", IsTurn: true}, {Name: "Bob"}},
+	}
+	view := &View{turnPlayer: -1}
+
+	if player := view.turnCuePlayer(model, at); player != -1 {
+		t.Errorf("Expected no cue for the first turn, got player %d", player)
+	}
+
+	model.Players[0].IsTurn, model.Players[1].IsTurn = false, true
+	if player := view.turnCuePlayer(model, at.Add(time.Minute)); player != 1 {
+		t.Errorf("Expected the cue on Bob's panel after the switch, got player %d", player)
+	}
+	if player := view.turnCuePlayer(model, at.Add(time.Minute+turnCueDuration)); player != -1 {
+		t.Errorf("Expected the cue to end after %v, got player %d", turnCueDuration, player)
+	}
+
+	model.Options.TurnCue = "none"
+	model.Players[0].IsTurn, model.Players[1].IsTurn = true, false
+	if player := view.turnCuePlayer(model, at.Add(2*time.Minute)); player != -1 {
+		t.Errorf("Expected no cue when turned off, got player %d", player)
+	}
+}
+
+func TestAsciiBorderStyle(t *testing.T) {
+	defer ui.ApplyBorderStyle("single")
+
+	ui.ApplyBorderStyle("ascii")
+	b := tview.Borders
+	for _, r := range []rune{b.Horizontal, b.Vertical, b.TopLeft, b.BottomRight, b.HorizontalFocus, b.VerticalFocus, b.Cross} {
+		if r > unicode.MaxASCII {
+			t.Errorf("Expected only ASCII border characters, got %q", r)
+		}
+	}
+	if b.Horizontal == b.HorizontalFocus {
+		t.Error("Expected the active player's panel to keep a different border")
+	}
+
+	ui.ApplyBorderStyle("unknown")
+	if tview.Borders.TopLeft != '┌' {
+		t.Errorf("Expected single lines for an unknown style, got %q", tview.Borders.TopLeft)
+	}
+}
+
+func TestBudgetGaugeFillsAndChangesColor(t *testing.T) {
+	colors := palette.ColorPaletteByName(palette.ColorPalettes()[0])
+	if gauge := ui.BudgetGauge(time.Minute, 0, 10, colors); gauge != "" {
+		t.Errorf("Expected no gauge without a time budget, got %q", gauge)
+	}
+
+	tests := []struct {
+		elapsed time.Duration
+		bar     string
+		color   tcell.Color
+	}{
+		{25 * time.Minute, "██▌       ", colors.Green},
+		{80 * time.Minute, "████████  ", colors.Yellow},
+		{95 * time.Minute, "█████████▌", colors.Red},
+		{2 * time.Hour, "██████████", colors.Red},
+	}
+	for _, test := range tests {
+		gauge := ui.BudgetGauge(test.elapsed, 100*time.Minute, 10, colors)
+		if !strings.Contains(gauge, test.bar) {
+			t.Errorf("Expected the bar %q after %v, got %q", test.bar, test.elapsed, gauge)
+		}
+		if !strings.HasPrefix(gauge, fmt.Sprintf("[#%06x:", test.color.Hex())) {
+			t.Errorf("Expected color %06x after %v, got %q", test.color.Hex(), test.elapsed, gauge)
+		}
+	}
+}
+
+func TestFeedMergesLogsChronologically(t *testing.T) {
+	players := []*common.Player{
+		{Name: "Alice", ActionLog: []common.LogEntry{
+			{DateTime: "2025-01-02 15:00:00", Message: "Turn 1 started", Type: common.LogTypeTurn},
+			{DateTime: "2025-01-02 15:20:00", Message: "Turn 1 ended", Type: common.LogTypeTurn},
+		}},
+		{Name: "Bob", ActionLog: []common.LogEntry{
+			{DateTime: "2025-01-02 15:05:00", Message: "Note: objective contested", Type: common.LogTypeNote},
+			{DateTime: "2025-01-02 15:20:00", Message: "Turn 1 started", Type: common.LogTypeTurn},
+		}},
+	}
+
+	feed := ui.FeedText(players, palette.ColorPaletteByName(palette.ColorPalettes()[0]), "none")
+	plain := tview.NewTextView().SetDynamicColors(true).SetText(feed).GetText(true)
+	expected := "Alice Turn 1 started\nBob Note: objective contested\nAlice Turn 1 ended\nBob Turn 1 started\n"
+	if plain != expected {
+		t.Errorf("Expected the feed\n%s\ngot\n%s", expected, plain)
+	}
+}
+
+func TestPanelWidgets(t *testing.T) {
+	widgets := ui.ShownPanelWidgets(ui.ParsePanelWidgets("Clock, phase,, sparkles, clock"))
+	if !slices.Equal(widgets, []string{"clock", "phase"}) {
+		t.Errorf("Expected the known widgets once each in order, got %v", widgets)
+	}
+	if listed := ui.ParsePanelWidgets(strings.Join(ui.PanelWidgets, ", ")); listed != nil {
+		t.Errorf("Expected all widgets in the default order not to be stored, got %v", listed)
+	}
+
+	model := *testModel
+	model.Options.PanelWidgets = []string{"phase", "clock"}
+	view := NewView(&model, make(chan common.Message, 10))
+	upper := view.PlayerPanels[0].GetItem(0).(*tview.Flex)
+	// The name, the divider and turn of the phase widget, the clock and the blank line at the bottom
+	if upper.GetItemCount() != 5 {
+		t.Errorf("Expected 5 items in the upper part of the panel, got %d", upper.GetItemCount())
+	}
+	if lower := view.PlayerPanels[0].GetItem(1).(*tview.Flex); lower.GetItemCount() != 0 {
+		t.Errorf("Expected no action log, got %d items", lower.GetItemCount())
+	}
+}
+
+// TestVictoryPointsShown tests that the victory points are shown in the player panels and the status bar once the
+// game started
+func TestVictoryPointsShown(t *testing.T) {
+	model := *testModel
+	model.CurrentColorPalette = palette.ColorPaletteByName(palette.ColorPalettes()[0])
+	model.Players = []*common.Player{{Name: "Alice", IsTurn: true, VictoryPoints: 12}, {Name: "Bob"}}
+	view := NewView(&model, make(chan common.Message, 10))
+	status := func() string { return view.StatusPanel.GetItem(0).(*tview.TextView).GetText(true) }
+	panelText := func(player int) string {
+		var lines []string
+		upper := view.PlayerPanels[player].GetItem(0).(*tview.Flex)
+		for i := range upper.GetItemCount() {
+			if text, ok := upper.GetItem(i).(*tview.TextView); ok {
+				lines = append(lines, text.GetText(true))
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	view.Render(&model)
+	if !strings.HasSuffix(status(), " | VP: Alice 12 - Bob 0") {
+		t.Errorf("Expected the scores in the status bar, got %q", status())
+	}
+	if !strings.Contains(panelText(1), "Turn: 0 | VP: 0") {
+		t.Errorf("Expected the victory points after the phase, got %q", panelText(1))
+	}
+
+	model.Players[0].VictoryPoints = 0
+	view.Render(&model)
+	if strings.Contains(status(), "VP") || strings.Contains(panelText(0), "VP") {
+		t.Errorf("Expected no victory points before anyone scored, got %q", status())
+	}
+}
+
+// TestVimKeysPassedWithoutVimBindings tests that the keys of the vim-style bindings reach the focused view unless
+// the bindings are enabled
+func TestVimKeysPassedWithoutVimBindings(t *testing.T) {
+	model := *testModel
+	view := NewView(&model, make(chan common.Message, 10))
+	SetupInputCapture(view.App, make(chan common.Message, 10), view.VimBindings)
+	capture := view.App.GetInputCapture()
+	key := func(r rune) *tcell.EventKey { return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone) }
+
+	if capture(key('j')) == nil || capture(key(':')) == nil {
+		t.Error("Expected the vim keys to be passed on without the vim bindings")
+	}
+	if capture(key('s')) != nil {
+		t.Error("Expected the game keys to be taken")
+	}
+	model.Options.VimBindings = true
+	view.Render(&model)
+	if capture(key('j')) != nil || capture(key(':')) != nil {
+		t.Error("Expected the vim keys to be taken with the vim bindings")
+	}
+}
+
+func TestArmySectionExpands(t *testing.T) {
+	model := *testModel
+	model.CurrentColorPalette = palette.ColorPaletteByName(palette.ColorPalettes()[0])
+	model.Players = []*common.Player{
+		{Name: "Alice", IsTurn: true, ArmyList: []common.Unit{
+			{Name: "Intercessors", Points: 200},
+			{Name: "Redemptor Dreadnought", Points: 210, Status: "destroyed"},
+		}},
+		{Name: "Bob"},
+	}
+	view := NewView(&model, make(chan common.Message, 10))
+	army := func(player int) string {
+		upper := view.PlayerPanels[player].GetItem(0).(*tview.Flex)
+		return upper.GetItem(upper.GetItemCount() - 2).(*tview.TextView).GetText(true)
+	}
+
+	if text := army(0); text != "▸ Army: 2 units, 410 pts" {
+		t.Errorf("Expected the collapsed army totals, got %q", text)
+	}
+	if text := army(1); text != "" {
+		t.Errorf("Expected no army section without an army list, got %q", text)
+	}
+
+	model.ArmyExpanded = true
+	view.Render(&model)
+	expected := "▾ Army: 2 units, 410 pts\nIntercessors - 200 pts\nRedemptor Dreadnought - 210 pts (destroyed)"
+	if text := army(0); text != expected {
+		t.Errorf("Expected the units of the army\n%s\ngot\n%s", expected, text)
+	}
+}
+
+// TestParseArmyList tests that the text of the army list editor is parsed into units and back
+func TestParseArmyList(t *testing.T) {
+	units := parseArmyList("Intercessors, 200, 10W\n\n  Redemptor Dreadnought 210 (12 wounds)\nCaptain\nSquad 5, 90")
+	expected := []common.Unit{
+		{Name: "Intercessors", Points: 200, Wounds: 10},
+		{Name: "Redemptor Dreadnought", Points: 210, Wounds: 12},
+		{Name: "Captain"},
+		{Name: "Squad 5", Points: 90},
+	}
+	if !slices.Equal(units, expected) {
+		t.Errorf("Expected %v, got %v", expected, units)
+	}
+	if text := armyListText(units[2:]); text != "Captain, 0\nSquad 5, 90" {
+		t.Errorf("Expected a unit per line, got %q", text)
+	}
+	if text := armyListText(units[:1]); text != "Intercessors, 200, 10W" {
+		t.Errorf("Expected the wounds after the points, got %q", text)
+	}
+
+	markdown := `# Gladius Task Force
+
+## Battleline
+- Intercessors (200 pts)
+* **Assault Intercessors** [75 points]
+1. Captain - 80pts
+
+---
+| Unit | Points | Status |
+|------|--------|--------|
+| Redemptor Dreadnought | 210 | destroyed |`
+	units = parseArmyList(markdown)
+	expected = []common.Unit{
+		{Name: "Intercessors", Points: 200},
+		{Name: "Assault Intercessors", Points: 75},
+		{Name: "Captain", Points: 80},
+		{Name: "Redemptor Dreadnought", Points: 210},
+	}
+	if !slices.Equal(units, expected) {
+		t.Errorf("Expected the units of the Markdown list %v, got %v", expected, units)
+	}
+}
+
+// TestUnitStatusKeysToggle tests that the keys of the unit status list toggle the status of the selected unit
+func TestUnitStatusKeysToggle(t *testing.T) {
+	units := []common.Unit{{Name: "Intercessors", Points: 200}, {Name: "Captain", Points: 80}}
+	var statuses []string
+	list := ui.CreateUnitStatusList("Alice", units, func(unit int, status string) {
+		statuses = append(statuses, fmt.Sprintf("%d:%s", unit, status))
+	}, func(int, int) {}, func(unit int) {
+		statuses = append(statuses, fmt.Sprintf("%d:note", unit))
+	}, func() {})
+
+	press := func(r rune) { list.GetInputCapture()(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)) }
+	press('d')
+	list.SetCurrentItem(1)
+	press('S')
+	press('h')
+	press('h')
+	press('n')
+
+	expected := []string{"0:destroyed", "1:shaken", "1:below half", "1:", "1:note"}
+	if !slices.Equal(statuses, expected) {
+		t.Errorf("Expected the statuses %v, got %v", expected, statuses)
+	}
+	if text, _ := list.GetItemText(0); text != "Intercessors - 200 pts (destroyed)" {
+		t.Errorf("Expected the unit to show its status, got %q", text)
+	}
+	if units[0].Status != "" {
+		t.Error("Expected the army list of the model to be left to the update")
+	}
+}
+
+// TestUnitStatusListTracksWounds tests that - and + change the wounds of the selected unit, marking it destroyed
+// when it loses its last wound and clearing that when it is healed
+func TestUnitStatusListTracksWounds(t *testing.T) {
+	units := []common.Unit{{Name: "Captain", Points: 80, Wounds: 2}, {Name: "Intercessors", Points: 200}}
+	var changes []string
+	list := ui.CreateUnitStatusList("Alice", units, func(unit int, status string) {
+		changes = append(changes, fmt.Sprintf("%d:%s", unit, status))
+	}, func(unit int, woundsLost int) {
+		changes = append(changes, fmt.Sprintf("%d:-%dW", unit, woundsLost))
+	}, func(int) {}, func() {})
+
+	press := func(r rune) { list.GetInputCapture()(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)) }
+	press('-')
+	if text, _ := list.GetItemText(0); text != "Captain - 80 pts, 1/2 W" {
+		t.Errorf("Expected the wounds left, got %q", text)
+	}
+	press('-')
+	press('-')
+	press('+')
+	list.SetCurrentItem(1)
+	press('-')
+
+	expected := []string{"0:-1W", "0:-2W", "0:destroyed", "0:-1W", "0:"}
+	if !slices.Equal(changes, expected) {
+		t.Errorf("Expected the changes %v, got %v", expected, changes)
+	}
+}
+
+// TestUnitStatusListFilter tests that the unit status list is filtered by name or status while typing
+func TestUnitStatusListFilter(t *testing.T) {
+	units := []common.Unit{
+		{Name: "Intercessors", Points: 200},
+		{Name: "Assault Intercessors", Points: 75, Status: "shaken"},
+		{Name: "Captain", Points: 80},
+	}
+	var marked []int
+	list := ui.CreateUnitStatusList("Alice", units, func(unit int, status string) {
+		marked = append(marked, unit)
+	}, func(int, int) {}, func(int) {}, func() {})
+	capture := list.GetInputCapture()
+	typeText := func(text string) {
+		for _, r := range text {
+			capture(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+	}
+
+	typeText("/inter")
+	if count := list.GetItemCount(); count != 2 {
+		t.Errorf("Expected the units matching the name, got %d", count)
+	}
+	capture(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	list.SetCurrentItem(1)
+	typeText("d")
+	if !slices.Equal(marked, []int{1}) {
+		t.Errorf("Expected the filtered unit to be marked by its index in the army list, got %v", marked)
+	}
+
+	typeText("/")
+	capture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	typeText("/SHAKEN")
+	capture(tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone))
+	if count := list.GetItemCount(); count != 0 {
+		t.Errorf("Expected no unit to be shaken any more, got %d", count)
+	}
+	capture(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	if count := list.GetItemCount(); count != 3 {
+		t.Errorf("Expected all units once the filter is cleared, got %d", count)
+	}
+}
+
+func TestStatusLabel(t *testing.T) {
+	opts := options.Options{StatusLabels: map[string]string{"paused": "Dice down"}}
+	if label := StatusLabel(opts, gamePaused); label != "Dice down" {
+		t.Errorf("Expected the label of the options, got %q", label)
+	}
+	if label := StatusLabel(opts, gameInProgress); label != "Game In Progress" {
+		t.Errorf("Expected the status without a label, got %q", label)
+	}
+
+	opts.Locale = "de_AT"
+	if label := StatusLabel(opts, gameInProgress); label != "Spiel läuft" {
+		t.Errorf("Expected the German status for de_AT, got %q", label)
+	}
+	if label := StatusLabel(opts, gamePaused); label != "Dice down" {
+		t.Errorf("Expected the label of the options to win over the translation, got %q", label)
+	}
+}
+
+func TestTransparentBackground(t *testing.T) {
+	opts := options.Options{TransparentBackground: true}
+	colors := OptionsColorPalette(opts, "dracula")
+	if colors.Black != tcell.ColorDefault {
+		t.Errorf("Expected the terminal's background, got %v", colors.Black)
+	}
+	if colors.Cyan != palette.ColorPaletteByName("dracula").Cyan {
+		t.Error("Expected the other colors of the palette to stay")
+	}
+
+	model, _ := Update(&common.SetTransparentBackgroundMsg{Value: false}, common.Model{Options: opts})
+	if model.Options.TransparentBackground || model.CurrentColorPalette.Black == tcell.ColorDefault {
+		t.Error("Expected the palette's black back after turning the transparent background off")
+	}
+}